@@ -10,6 +10,9 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"runtime/debug"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,8 +23,15 @@ import (
 	"github.com/dundee/gdu/v5/build"
 	"github.com/dundee/gdu/v5/internal/common"
 	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/audit"
+	"github.com/dundee/gdu/v5/pkg/compress"
 	"github.com/dundee/gdu/v5/pkg/device"
 	gfs "github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/junk"
+	"github.com/dundee/gdu/v5/pkg/predicate"
+	"github.com/dundee/gdu/v5/pkg/priority"
+	"github.com/dundee/gdu/v5/pkg/query"
+	"github.com/dundee/gdu/v5/pkg/remove"
 	"github.com/dundee/gdu/v5/pkg/timefilter"
 	"github.com/dundee/gdu/v5/report"
 	"github.com/dundee/gdu/v5/stdout"
@@ -29,6 +39,9 @@ import (
 	"github.com/dundee/gdu/v5/webui"
 )
 
+// ErrPartialResults is returned when the non-interactive analysis finished, but some directories or files could not be read
+var ErrPartialResults = errors.New("results are partial")
+
 // UI is common interface for both terminal UI and text output
 type UI interface {
 	ListDevices(getter device.DevicesInfoGetter) error
@@ -39,15 +52,23 @@ type UI interface {
 	SetIgnoreDirPaths(paths []string)
 	SetIgnoreDirPatterns(paths []string) error
 	SetIgnoreFromFile(ignoreFile string) error
+	SetIgnoreFiles(ignoreFiles []string, useDirFiles bool) error
 	SetIgnoreHidden(value bool)
+	SetIgnoreSnapshots(value bool)
 	SetIncludeTypes(types []string)
 	SetFollowSymlinks(value bool)
 	SetShowAnnexedSize(value bool)
 	SetAnalyzer(analyzer common.Analyzer)
 	SetTimeFilter(timeFilter common.TimeFilter)
+	SetFileFilter(fileFilter common.FileFilter)
+	SetMaxDepth(depth int)
+	SetThrottle(throttle common.Throttle)
+	SetIOLimiter(limiter common.IOLimiter)
 	SetArchiveBrowsing(value bool)
+	SetSharedUsage(value bool)
 	SetCollapsePath(value bool)
 	SetShowSymlinkTarget(value bool)
+	SetShowInodes(capacity int64)
 	StartUILoop() error
 }
 
@@ -61,14 +82,26 @@ type Flags struct {
 	OutputFile         string    `yaml:"output-file"`
 	OutputAttrs        string    `yaml:"output-attrs"`
 	IgnoreFromFile     string    `yaml:"ignore-from-file"`
+	IgnoreFiles        []string  `yaml:"ignore-files"`
+	UseIgnoreFiles     bool      `yaml:"use-ignore-files"`
 	IgnoreDirs         []string  `yaml:"ignore-dirs"`
 	IgnoreDirPatterns  []string  `yaml:"ignore-dir-patterns"`
 	TypeFilter         []string  `yaml:"type"`
 	ExcludeTypeFilter  []string  `yaml:"exclude-type"`
 	MaxCores           int       `yaml:"max-cores"`
+	IOWorkers          string    `yaml:"io-workers"`
+	RateLimit          int       `yaml:"rate-limit"`
+	MaxLoad            float64   `yaml:"max-load"`
+	LowPriority        bool      `yaml:"low-priority"`
 	Top                int       `yaml:"top"`
 	Depth              int       `yaml:"depth"`
+	MaxDepth           int       `yaml:"max-depth"`
+	MinSize            string    `yaml:"min-size"`
+	MaxSize            string    `yaml:"max-size"`
+	FileModes          []string  `yaml:"file-mode"`
 	SequentialScanning bool      `yaml:"sequential-scanning"`
+	Compact            bool      `yaml:"compact"`
+	MaxMemory          string    `yaml:"max-memory"`
 	ShowDisks          bool      `yaml:"-"`
 	ShowApparentSize   bool      `yaml:"show-apparent-size"`
 	ShowRelativeSize   bool      `yaml:"show-relative-size"`
@@ -76,6 +109,8 @@ type Flags struct {
 	ShowVersion        bool      `yaml:"-"`
 	ShowItemCount      bool      `yaml:"show-item-count"`
 	ShowMTime          bool      `yaml:"show-mtime"`
+	GitStatus          bool      `yaml:"git-status"`
+	ShowInodes         bool      `yaml:"show-inodes"`
 	NoColor            bool      `yaml:"no-color"`
 	Mouse              bool      `yaml:"mouse"`
 	NonInteractive     bool      `yaml:"non-interactive"`
@@ -83,7 +118,10 @@ type Flags struct {
 	NoProgress         bool      `yaml:"no-progress"`
 	NoUnicode          bool      `yaml:"no-unicode"`
 	NoCross            bool      `yaml:"no-cross"`
+	SkipNetwork        bool      `yaml:"skip-network"`
+	DevicesLocalOnly   bool      `yaml:"devices-local-only"`
 	NoHidden           bool      `yaml:"no-hidden"`
+	IncludeSnapshots   bool      `yaml:"include-snapshots"`
 	NoDelete           bool      `yaml:"no-delete"`
 	NoViewFile         bool      `yaml:"no-view-file"`
 	NoSpawnShell       bool      `yaml:"no-spawn-shell"`
@@ -92,6 +130,13 @@ type Flags struct {
 	Profiling          bool      `yaml:"profiling"`
 	ReadFromStorage    bool      `yaml:"read-from-storage"`
 	DbPath             string    `yaml:"db"`
+	History            bool      `yaml:"history"`
+	KeepScans          int       `yaml:"keep-scans"`
+	At                 string    `yaml:"-"`
+	Forecast           bool      `yaml:"-"`
+	ListScans          bool      `yaml:"-"`
+	Query              string    `yaml:"-"`
+	QueryFormat        string    `yaml:"-"`
 	Summarize          bool      `yaml:"summarize"`
 	UseSIPrefix        bool      `yaml:"use-si-prefix"`
 	NoPrefix           bool      `yaml:"no-prefix"`
@@ -101,16 +146,32 @@ type Flags struct {
 	ChangeCwd          bool      `yaml:"change-cwd"`
 	DeleteInBackground bool      `yaml:"delete-in-background"`
 	DeleteInParallel   bool      `yaml:"delete-in-parallel"`
+	ShredPasses        int       `yaml:"shred-passes"`
+	ShredForce         bool      `yaml:"shred-force"`
+	CompressFormat     string    `yaml:"compress-format"`
 	Since              string    `yaml:"since"`
 	Until              string    `yaml:"until"`
 	MaxAge             string    `yaml:"max-age"`
 	MinAge             string    `yaml:"min-age"`
 	ArchiveBrowsing    bool      `yaml:"archive-browsing"`
+	SharedUsage        bool      `yaml:"shared-usage"`
 	CollapsePath       bool      `yaml:"collapse-path"`
 	ShowSymlinkTarget  bool      `yaml:"show-symlink-target"`
 	BrowseParentDirs   bool      `yaml:"browse-parent-dirs"`
+	LoadPlan           string    `yaml:"-"`
+	ApplyPlan          string    `yaml:"-"`
+	Yes                bool      `yaml:"-"`
+	ShowTrash          bool      `yaml:"-"`
+	AuditFile          string    `yaml:"audit-file"`
+	DryRun             bool      `yaml:"-"`
 	Web                bool      `yaml:"-"`
 	WebConfig          WebConfig `yaml:"web"`
+
+	// Keys rebinds or disables (with "none") actions of the interactive mode
+	Keys map[string]string `yaml:"keys,omitempty"`
+
+	// JunkRules add categories of known junk or replace the built-in ones
+	JunkRules []junk.Rule `yaml:"junk-rules,omitempty"`
 }
 
 // WebConfig defines the web UI options that can be set from the config file.
@@ -123,7 +184,7 @@ type WebConfig struct {
 // ShouldRunInNonInteractiveMode checks if the application should run in non-interactive mode
 // based on the flags set.
 func (f *Flags) ShouldRunInNonInteractiveMode(istty bool) bool {
-	if f.NonInteractive {
+	if f.NonInteractive || f.Forecast || f.ListScans || f.Query != "" {
 		return true
 	}
 
@@ -199,6 +260,7 @@ type Sorting struct {
 // App defines the main application
 type App struct {
 	Writer      io.Writer
+	Input       io.Reader
 	TermApp     common.TermApplication
 	Screen      tcell.Screen
 	Getter      device.DevicesInfoGetter
@@ -206,6 +268,12 @@ type App struct {
 	PathChecker func(string) (fs.FileInfo, error)
 	Args        []string
 	Istty       bool
+	auditLog    *audit.Logger
+	compressFmt compress.Format
+	keys        *tui.KeyBindings
+	junk        *junk.Classifier
+	treeMemory  int64
+	paths       []string
 }
 
 func init() {
@@ -235,6 +303,34 @@ func (a *App) Run() error {
 		return fmt.Errorf("--interactive and --non-interactive cannot be used at once")
 	}
 
+	if a.Flags.ShredPasses < 1 {
+		return fmt.Errorf("--shred-passes must be positive")
+	}
+
+	if a.Flags.CompressFormat != "" {
+		format, err := compress.ParseFormat(a.Flags.CompressFormat)
+		if err != nil {
+			return err
+		}
+		a.compressFmt = format
+	}
+
+	if len(a.Flags.Keys) > 0 {
+		keys, err := tui.NewKeyBindings(a.Flags.Keys)
+		if err != nil {
+			return err
+		}
+		a.keys = keys
+	}
+
+	if len(a.Flags.JunkRules) > 0 {
+		classifier, err := junk.NewClassifier(a.Flags.JunkRules, junk.DefaultRules())
+		if err != nil {
+			return err
+		}
+		a.junk = classifier
+	}
+
 	outputAttributes, err := parseJSONAttributes(a.Flags.OutputAttrs)
 	if err != nil {
 		return err
@@ -243,19 +339,91 @@ func (a *App) Run() error {
 		return errors.New("--output-attrs requires --output-file")
 	}
 
-	path := a.getPath()
-	path, err = filepath.Abs(path)
+	if a.Flags.AuditFile != "" || a.Flags.DryRun {
+		a.auditLog, err = audit.Open(a.Flags.AuditFile, a.Flags.DryRun)
+		if err != nil {
+			return fmt.Errorf("opening audit file: %w", err)
+		}
+		defer a.auditLog.Close()
+	}
+
+	if a.Flags.ApplyPlan != "" {
+		return a.applyPlan()
+	}
+
+	if a.Flags.ShowTrash {
+		return a.listTrash()
+	}
+
+	if a.Flags.Forecast {
+		return a.showForecast()
+	}
+
+	if a.Flags.ListScans {
+		return a.listScans()
+	}
+
+	if a.Flags.Query != "" {
+		return a.runQuery()
+	}
+
+	a.paths, err = a.getPaths()
 	if err != nil {
 		return err
 	}
+	path := a.paths[0]
+	if len(a.paths) > 1 && a.Flags.DbPath != "" {
+		return errors.New("--db cannot be used with multiple paths")
+	}
+	if len(a.paths) > 1 && a.Flags.BrowseParentDirs {
+		return errors.New("--browse-parent-dirs cannot be used with multiple paths")
+	}
+	if a.Flags.History && (a.Flags.DbPath == "" || strings.HasSuffix(a.Flags.DbPath, ".badger")) {
+		return errors.New("--history requires SQLite database (--db *.sqlite)")
+	}
+	if a.Flags.At != "" {
+		if !a.Flags.ReadFromStorage || a.Flags.DbPath == "" || strings.HasSuffix(a.Flags.DbPath, ".badger") {
+			return errors.New("--at requires --read-from-storage and SQLite database (--db *.sqlite)")
+		}
+		// files of older scans can not be deleted
+		a.Flags.NoDelete = true
+	}
+	if a.compact() && a.Flags.DbPath != "" {
+		return errors.New("--compact and --max-memory cannot be used with --db")
+	}
+	if a.compact() && a.Flags.SequentialScanning {
+		return errors.New("--compact and --sequential cannot be used at once")
+	}
+	if a.Flags.IOWorkers != "" && a.Flags.SequentialScanning {
+		return errors.New("--io-workers cannot be used with --sequential")
+	}
+	if a.Flags.RateLimit < 0 {
+		return errors.New("--rate-limit must not be negative")
+	}
+	if a.Flags.MaxLoad < 0 {
+		return errors.New("--max-load must not be negative")
+	}
+	if err := a.setMemoryLimit(); err != nil {
+		return err
+	}
 
 	ui, err = a.createUI(outputAttributes)
 	if err != nil {
 		return err
 	}
 
+	if a.Flags.ShowInodes {
+		ui.SetShowInodes(a.getInodeCapacity(a.paths))
+	}
+
+	if a.Flags.LoadPlan != "" {
+		if err := a.loadPlan(ui); err != nil {
+			return err
+		}
+	}
+
 	if a.Flags.DbPath != "" {
-		if !a.Flags.ReadFromStorage {
+		if !a.Flags.ReadFromStorage && !a.Flags.History {
 			// Remove existing db before re-scan
 			if strings.HasSuffix(a.Flags.DbPath, ".badger") {
 				os.RemoveAll(a.Flags.DbPath)
@@ -270,12 +438,29 @@ func (a *App) Run() error {
 			if err != nil {
 				return fmt.Errorf("creating sqlite analyzer: %w", err)
 			}
+			if a.Flags.History && !a.Flags.ReadFromStorage {
+				sqliteAnalyzer.KeepHistory(a.getHistoryScan(path), a.Flags.KeepScans)
+			}
+			if a.Flags.At != "" {
+				if err := a.loadScanAt(sqliteAnalyzer); err != nil {
+					return err
+				}
+			}
 			ui.SetAnalyzer(sqliteAnalyzer)
 		}
 	}
 	if a.Flags.SequentialScanning {
 		ui.SetAnalyzer(analyze.CreateSeqAnalyzer())
 	}
+	if a.compact() {
+		ui.SetAnalyzer(analyze.CreateCompactAnalyzer(a.treeMemory))
+	}
+	if len(a.paths) > 1 {
+		path, err = a.setMultiPathAnalyzer(ui)
+		if err != nil {
+			return err
+		}
+	}
 	if a.Flags.FollowSymlinks {
 		ui.SetFollowSymlinks(true)
 	}
@@ -285,6 +470,9 @@ func (a *App) Run() error {
 	if a.Flags.ArchiveBrowsing {
 		ui.SetArchiveBrowsing(true)
 	}
+	if a.Flags.SharedUsage {
+		ui.SetSharedUsage(true)
+	}
 	if a.Flags.CollapsePath {
 		ui.SetCollapsePath(true)
 	}
@@ -295,9 +483,17 @@ func (a *App) Run() error {
 			return err
 		}
 	}
-	if err := a.setNoCross(path); err != nil {
+	if err := a.setFileFilters(ui); err != nil {
 		return err
 	}
+	for _, p := range a.paths {
+		if err := a.setNoCross(p); err != nil {
+			return err
+		}
+		if err := a.setSkipNetwork(p); err != nil {
+			return err
+		}
+	}
 
 	// Process type filters
 	if len(a.Flags.TypeFilter) > 0 {
@@ -321,24 +517,136 @@ func (a *App) Run() error {
 		}
 	}
 
+	if len(a.Flags.IgnoreFiles) > 0 || a.Flags.UseIgnoreFiles {
+		if err := ui.SetIgnoreFiles(a.Flags.IgnoreFiles, a.Flags.UseIgnoreFiles); err != nil {
+			return err
+		}
+	}
+
 	if a.Flags.NoHidden {
 		ui.SetIgnoreHidden(true)
 	}
+	ui.SetIgnoreSnapshots(!a.Flags.IncludeSnapshots)
 
 	a.setMaxProcs()
+	if err := a.setIOWorkers(ui); err != nil {
+		return err
+	}
+	if err := a.setThrottling(ui); err != nil {
+		return err
+	}
 
 	if err := a.runAction(ui, path); err != nil {
 		return err
 	}
 
-	return ui.StartUILoop()
+	if err := ui.StartUILoop(); err != nil {
+		return err
+	}
+	return checkScanErrors(ui)
+}
+
+// checkScanErrors returns ErrPartialResults if some items could not be read by the non-interactive analysis.
+// The interactive modes show the errors to the user instead.
+func checkScanErrors(ui UI) error {
+	switch ui.(type) {
+	case *stdout.UI, *report.UI:
+	default:
+		return nil
+	}
+	reporter, ok := ui.(common.ScanErrorReporter)
+	if !ok {
+		return nil
+	}
+	switch errs := reporter.GetScanErrors(); len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("%w: %s could not be read", ErrPartialResults, errs[0].Path)
+	default:
+		return fmt.Errorf("%w: %d items could not be read", ErrPartialResults, len(errs))
+	}
+}
+
+// getPaths returns absolute paths given as arguments, current directory by default.
+// Duplicates and paths nested in other given paths are dropped so that nothing is counted twice.
+func (a *App) getPaths() ([]string, error) {
+	args := a.Args
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	paths := make([]string, 0, len(args))
+	for _, arg := range args {
+		path, err := filepath.Abs(arg)
+		if err != nil {
+			return nil, err
+		}
+		if slices.ContainsFunc(paths, func(p string) bool { return isWithin(path, p) }) {
+			log.Printf("Skipping path %s already included in other path", path)
+			continue
+		}
+		paths = slices.DeleteFunc(paths, func(p string) bool { return isWithin(p, path) })
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// isWithin reports whether the path is the dir itself or is nested in it
+func isWithin(path, dir string) bool {
+	if path == dir {
+		return true
+	}
+	return strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// setMultiPathAnalyzer makes the UI scan all paths concurrently under a synthetic root item
+// and returns the path of the root
+func (a *App) setMultiPathAnalyzer(ui UI) (string, error) {
+	paths := make([]string, 0, len(a.paths))
+	for _, p := range a.paths {
+		p = build.RootPathPrefix + p
+		if _, err := a.PathChecker(p); err != nil {
+			return "", err
+		}
+		paths = append(paths, p)
+	}
+
+	analyzer := analyze.CreateMultiPathAnalyzer(paths, func() common.Analyzer {
+		if a.compact() {
+			// every path gets its share of the memory
+			return analyze.CreateCompactAnalyzer(a.treeMemory / int64(len(paths)))
+		}
+		if a.Flags.SequentialScanning {
+			return analyze.CreateSeqAnalyzer()
+		}
+		return analyze.CreateAnalyzer()
+	})
+	ui.SetAnalyzer(analyzer)
+	return analyzer.RootPath(), nil
+}
+
+// compact returns true if the analyzed tree should be stored in compact form
+func (a *App) compact() bool {
+	return a.Flags.Compact || a.Flags.MaxMemory != ""
 }
 
-func (a *App) getPath() string {
-	if len(a.Args) == 1 {
-		return a.Args[0]
+// setMemoryLimit sets --max-memory as the soft memory limit of the runtime.
+// Three quarters of it are left for the compact tree, the rest for the scan itself.
+func (a *App) setMemoryLimit() error {
+	if a.Flags.MaxMemory == "" {
+		return nil
+	}
+	limit, err := query.ParseSize(a.Flags.MaxMemory)
+	if err != nil {
+		return fmt.Errorf("invalid --max-memory: %w", err)
+	}
+	if limit <= 0 {
+		return errors.New("--max-memory must be positive")
 	}
-	return "."
+	debug.SetMemoryLimit(limit)
+	a.treeMemory = limit / 4 * 3
+	return nil
 }
 
 func (a *App) setMaxProcs() {
@@ -352,6 +660,60 @@ func (a *App) setMaxProcs() {
 	log.Printf("Max cores set to %d", runtime.GOMAXPROCS(0))
 }
 
+// setIOWorkers sets the limit of directories read at once,
+// "auto" means the limit is tuned by the latency of the reads
+func (a *App) setIOWorkers(ui UI) error {
+	switch a.Flags.IOWorkers {
+	case "":
+		return nil
+	case "auto":
+		ui.SetIOLimiter(analyze.CreateAdaptiveIOLimiter())
+		log.Print("Adaptive number of I/O workers")
+		return nil
+	}
+
+	workers, err := strconv.Atoi(a.Flags.IOWorkers)
+	if err != nil || workers < 1 {
+		return fmt.Errorf("invalid --io-workers %q: must be a positive number or auto", a.Flags.IOWorkers)
+	}
+	ui.SetIOLimiter(analyze.CreateIOLimiter(workers))
+	log.Printf("I/O workers set to %d", workers)
+	return nil
+}
+
+// setThrottling limits the impact of the scan on the host: the rate of reads,
+// the load of the system and the priority of the process
+func (a *App) setThrottling(ui UI) error {
+	if a.Flags.LowPriority {
+		if err := priority.Lower(); err != nil {
+			if errors.Is(err, errors.ErrUnsupported) {
+				return errors.New("--low-priority is supported only on Linux")
+			}
+			return fmt.Errorf("lowering priority: %w", err)
+		}
+		log.Printf("Priority lowered to nice %d and I/O level %d", priority.Nice, priority.IOLevel)
+	}
+
+	if a.Flags.RateLimit == 0 && a.Flags.MaxLoad == 0 {
+		return nil
+	}
+	throttle, err := analyze.CreateThrottle(a.Flags.RateLimit, a.Flags.MaxLoad)
+	if err != nil {
+		if errors.Is(err, errors.ErrUnsupported) {
+			return errors.New("--max-load is supported only on Linux")
+		}
+		return fmt.Errorf("reading load average: %w", err)
+	}
+	ui.SetThrottle(throttle)
+	if a.Flags.MaxLoad > 0 {
+		log.Printf("Scan paused while load average exceeds %.2f", a.Flags.MaxLoad)
+	}
+	if a.Flags.RateLimit > 0 {
+		log.Printf("Scan limited to %d reads per second", a.Flags.RateLimit)
+	}
+	return nil
+}
+
 func (a *App) setTimeFilters(ui UI) error {
 	loc := time.Local
 	now := time.Now()
@@ -382,6 +744,24 @@ func (a *App) setTimeFilters(ui UI) error {
 	return nil
 }
 
+func (a *App) setFileFilters(ui UI) error {
+	if a.Flags.MaxDepth < 0 {
+		return fmt.Errorf("--max-depth must not be negative")
+	}
+	if a.Flags.MaxDepth > 0 {
+		ui.SetMaxDepth(a.Flags.MaxDepth)
+	}
+
+	fileFilter, err := predicate.New(a.Flags.MinSize, a.Flags.MaxSize, a.Flags.FileModes)
+	if err != nil {
+		return fmt.Errorf("invalid file filter: %w", err)
+	}
+	if fileFilter != nil {
+		ui.SetFileFilter(common.FileFilter(fileFilter))
+	}
+	return nil
+}
+
 func (a *App) createUI(outputAttributes gfs.JSONAttributes) (UI, error) {
 	var ui UI
 	var err error
@@ -572,6 +952,11 @@ func (a *App) getOptions() []tui.Option {
 			ui.SetShowMTime()
 		})
 	}
+	if a.Flags.GitStatus {
+		opts = append(opts, func(ui *tui.UI) {
+			ui.SetShowGitStatus()
+		})
+	}
 	if a.Flags.ShowSymlinkTarget {
 		opts = append(opts, func(ui *tui.UI) {
 			ui.SetShowSymlinkTarget(true)
@@ -612,12 +997,62 @@ func (a *App) getOptions() []tui.Option {
 			ui.SetBrowseParentDirs()
 		})
 	}
+	opts = append(opts, func(ui *tui.UI) {
+		ui.SetShredder(remove.Shredder{
+			Passes:   a.Flags.ShredPasses,
+			Force:    a.Flags.ShredForce,
+			Parallel: a.Flags.DeleteInParallel,
+		})
+	})
+	if a.compressFmt != "" {
+		opts = append(opts, func(ui *tui.UI) {
+			ui.SetCompressFormat(a.compressFmt)
+		})
+	}
+	if a.keys != nil {
+		opts = append(opts, func(ui *tui.UI) {
+			ui.SetKeyBindings(a.keys)
+		})
+	}
+	if a.junk != nil {
+		opts = append(opts, func(ui *tui.UI) {
+			ui.SetJunkClassifier(a.junk)
+		})
+	}
+	if a.auditLog != nil {
+		opts = append(opts, func(ui *tui.UI) {
+			ui.SetAuditLogger(a.auditLog)
+		})
+	}
 	opts = append(opts, func(ui *tui.UI) {
 		ui.SetShowDiskProgressBar(a.Flags.Style.ProgressModal.ShowDiskProgressBar)
 	})
 	return opts
 }
 
+// getInodeCapacity returns the number of inodes of the filesystem containing the paths,
+// zero when unknown or when the paths are on different filesystems
+func (a *App) getInodeCapacity(paths []string) int64 {
+	devices, err := a.Getter.GetDevicesInfo()
+	if err != nil {
+		log.Printf("Loading inode capacity failed: %s", err)
+		return 0
+	}
+
+	var found *device.Device
+	for _, path := range paths {
+		dev := device.GetDeviceForPath(path, devices)
+		if dev == nil || (found != nil && dev != found) {
+			return 0
+		}
+		found = dev
+	}
+	if found == nil {
+		return 0
+	}
+	return found.Inodes
+}
+
 func (a *App) setNoCross(path string) error {
 	if a.Flags.NoCross {
 		mounts, err := a.Getter.GetMounts()
@@ -631,6 +1066,19 @@ func (a *App) setNoCross(path string) error {
 	return nil
 }
 
+func (a *App) setSkipNetwork(path string) error {
+	if a.Flags.SkipNetwork {
+		mounts, err := a.Getter.GetMounts()
+		if err != nil {
+			return fmt.Errorf("loading mount points: %w", err)
+		}
+		paths := device.GetNetworkMountPoints(path, mounts)
+		log.Printf("Ignoring network mount points: %s", strings.Join(paths, ", "))
+		a.Flags.IgnoreDirs = append(a.Flags.IgnoreDirs, paths...)
+	}
+	return nil
+}
+
 func (a *App) runAction(ui UI, path string) error {
 	if a.Flags.Profiling {
 		go func() {
@@ -645,7 +1093,11 @@ func (a *App) runAction(ui UI, path string) error {
 
 	switch {
 	case a.Flags.ShowDisks:
-		if err := ui.ListDevices(a.Getter); err != nil {
+		getter := a.Getter
+		if a.Flags.DevicesLocalOnly {
+			getter = device.LocalDevicesInfoGetter{DevicesInfoGetter: getter}
+		}
+		if err := ui.ListDevices(getter); err != nil {
 			return fmt.Errorf("loading mount points: %w", err)
 		}
 	case a.Flags.InputFile != "":
@@ -671,16 +1123,20 @@ func (a *App) runAction(ui UI, path string) error {
 			log.Printf("Could not prevent dataless materialization: %s", err)
 		}
 
-		if build.RootPathPrefix != "" {
-			path = build.RootPathPrefix + path
-		}
+		if len(a.paths) > 1 {
+			log.Printf("Analyzing paths: %s", strings.Join(a.paths, ", "))
+		} else {
+			if build.RootPathPrefix != "" {
+				path = build.RootPathPrefix + path
+			}
 
-		_, err := a.PathChecker(path)
-		if err != nil {
-			return err
-		}
+			_, err := a.PathChecker(path)
+			if err != nil {
+				return err
+			}
 
-		log.Printf("Analyzing path: %s", path)
+			log.Printf("Analyzing path: %s", path)
+		}
 		if err := ui.AnalyzePath(path, nil); err != nil {
 			return fmt.Errorf("scanning dir: %w", err)
 		}
@@ -151,3 +151,73 @@ func TestAnalyzePathWithSqliteStorageError(t *testing.T) {
 	assert.Empty(t, out)
 	assert.ErrorContains(t, err, "creating sqlite analyzer")
 }
+
+func TestMaxLoad(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	// the load of the host cannot be this high, so the scan is not paused
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", MaxLoad: 10000},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Contains(t, out, "nested")
+	assert.Nil(t, err)
+}
+
+func TestLowPriority(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", LowPriority: true},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Contains(t, out, "nested")
+	assert.Nil(t, err)
+}
+
+func TestPartialResults(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	err := os.Symlink("missing", "test_dir/nested/broken")
+	assert.Nil(t, err)
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", FollowSymlinks: true},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Contains(t, out, "nested")
+	assert.Contains(t, out, "1 item could not be read, results are partial")
+	assert.ErrorIs(t, err, ErrPartialResults)
+	assert.ErrorContains(t, err, "broken could not be read")
+}
+
+func TestPartialResultsExport(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	err := os.Symlink("missing", "test_dir/nested/broken")
+	assert.Nil(t, err)
+
+	output := filepath.Join(t.TempDir(), "out.json")
+	_, err = runApp(
+		&Flags{LogFile: "/dev/null", FollowSymlinks: true, OutputFile: output},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.ErrorIs(t, err, ErrPartialResults)
+
+	data, err := os.ReadFile(output)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"errors":[{"path":`)
+}
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"runtime"
+	"runtime/debug"
 	"strings"
 	"testing"
 	"time"
@@ -17,8 +19,11 @@ import (
 	"github.com/dundee/gdu/v5/internal/testapp"
 	"github.com/dundee/gdu/v5/internal/testdev"
 	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/analyze"
 	"github.com/dundee/gdu/v5/pkg/device"
 	gfs "github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/junk"
+	"github.com/dundee/gdu/v5/pkg/remove"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -83,6 +88,65 @@ func TestAnalyzePath(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestAnalyzeMultiplePaths(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	tmpDir := t.TempDir()
+	nested, _ := filepath.Abs("test_dir/nested")
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", NoProgress: true},
+		[]string{"test_dir/nested/subnested", tmpDir, "test_dir/nested", tmpDir},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Nil(t, err)
+	assert.Contains(t, out, " "+nested+"\n")
+	assert.Contains(t, out, " "+tmpDir)
+	assert.NotContains(t, out, "subnested")
+}
+
+func TestAnalyzeMultiplePathsWithDb(t *testing.T) {
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", DbPath: "test.db"},
+		[]string{"a", "b"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.EqualError(t, err, "--db cannot be used with multiple paths")
+}
+
+func TestAnalyzeMultiplePathsWithBrowseParentDirs(t *testing.T) {
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", BrowseParentDirs: true},
+		[]string{"a", "b"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.EqualError(t, err, "--browse-parent-dirs cannot be used with multiple paths")
+}
+
+func TestAnalyzePathWithShowInodesNonInteractive(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", ShowInodes: true, NoProgress: true},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Nil(t, err)
+	assert.Regexp(t, regexp.MustCompile(`(?m)^\s*4\s+/nested$`), out)
+}
+
 func TestAnalyzePathWithShowItemCountNonInteractive(t *testing.T) {
 	fin := testdir.CreateTestDir()
 	defer fin()
@@ -177,6 +241,77 @@ func TestAnalyzePathWithIgnoring(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestAnalyzePathSkipsSnapshots(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	assert.Nil(t, os.MkdirAll("test_dir/.snapshots/1/snapshot", 0o755))
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Nil(t, err)
+	assert.NotContains(t, out, ".snapshots")
+
+	out, err = runApp(
+		&Flags{LogFile: "/dev/null", IncludeSnapshots: true},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Nil(t, err)
+	assert.Contains(t, out, ".snapshots")
+}
+
+func TestAnalyzePathWithIgnoreFiles(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	ignoreFile := filepath.Join(t.TempDir(), "ignore")
+	assert.Nil(t, os.WriteFile(ignoreFile, []byte("/nested/\n"), 0o600))
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", IgnoreFiles: []string{ignoreFile}},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Nil(t, err)
+	assert.NotContains(t, out, "nested")
+
+	assert.Nil(t, os.WriteFile("test_dir/.gduignore", []byte("nested\n"), 0o600))
+
+	out, err = runApp(
+		&Flags{LogFile: "/dev/null", UseIgnoreFiles: true},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Nil(t, err)
+	assert.NotContains(t, out, "nested")
+	assert.Contains(t, out, ".gduignore")
+}
+
+func TestAnalyzePathWithIgnoreFileNotExisting(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", IgnoreFiles: []string{"missing"}},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Equal(t, out, "")
+	assert.NotNil(t, err)
+}
+
 func TestAnalyzePathWithIgnoringPatternError(t *testing.T) {
 	fin := testdir.CreateTestDir()
 	defer fin()
@@ -483,6 +618,21 @@ func assertOutputAttributes(t *testing.T, value any) {
 	}
 }
 
+func TestAnalyzePathWithSharedUsage(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", SharedUsage: true},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Contains(t, out, "shared")
+	assert.Nil(t, err)
+}
+
 func TestOutputAttributesRequireExport(t *testing.T) {
 	out, err := runApp(
 		&Flags{LogFile: "/dev/null", OutputAttrs: "asize"},
@@ -634,6 +784,76 @@ func TestWrongCombinationOfPrefixes(t *testing.T) {
 	assert.Contains(t, err.Error(), "cannot be used at once")
 }
 
+func TestNegativeShredPasses(t *testing.T) {
+	out, err := runApp(
+		&Flags{ShredPasses: -1},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.ErrorContains(t, err, "--shred-passes must be positive")
+}
+
+func TestZeroShredPasses(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	app := App{
+		Flags:       &Flags{ShredPasses: 0},
+		Args:        []string{"test_dir"},
+		Writer:      buff,
+		TermApp:     testapp.CreateMockedApp(false),
+		Getter:      testdev.DevicesInfoGetterMock{},
+		PathChecker: testdir.MockedPathChecker,
+	}
+	err := app.Run()
+
+	assert.Empty(t, buff.String())
+	assert.ErrorContains(t, err, "--shred-passes must be positive")
+}
+
+func TestWrongCompressFormat(t *testing.T) {
+	out, err := runApp(
+		&Flags{CompressFormat: "rar"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.ErrorContains(t, err, `unknown compression format "rar"`)
+}
+
+func TestCompressFormat(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{CompressFormat: "gzip"},
+		[]string{"test_dir"},
+		true,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+}
+
+func TestShredOptions(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{ShredPasses: 1, ShredForce: true, DeleteInParallel: true},
+		[]string{"test_dir"},
+		true,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+}
+
 func TestReadWrongAnalysisFromNotExistingFile(t *testing.T) {
 	out, err := runApp(
 		&Flags{LogFile: "/dev/null", InputFile: "xxx.json"},
@@ -653,7 +873,7 @@ func TestAnalyzePathWithErr(t *testing.T) {
 	buff := bytes.NewBufferString("")
 
 	app := App{
-		Flags:       &Flags{LogFile: "/dev/null"},
+		Flags:       withDefaultFlags(&Flags{LogFile: "/dev/null"}),
 		Args:        []string{"xxx"},
 		Istty:       false,
 		Writer:      buff,
@@ -682,6 +902,27 @@ func TestNoCross(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestSkipNetwork(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	mountPoint, err := filepath.Abs("test_dir/nested")
+	assert.Nil(t, err)
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", SkipNetwork: true},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{
+			Devices: device.Devices{
+				{Name: "host:/dir", MountPoint: mountPoint, Fstype: "nfs"},
+			},
+		},
+	)
+
+	assert.NotContains(t, out, "nested")
+	assert.Nil(t, err)
+}
+
 func TestListDevices(t *testing.T) {
 	fin := testdir.CreateTestDir()
 	defer fin()
@@ -697,6 +938,24 @@ func TestListDevices(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestListDevicesLocalOnly(t *testing.T) {
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", ShowDisks: true, DevicesLocalOnly: true},
+		[]string{},
+		false,
+		testdev.DevicesInfoGetterMock{
+			Devices: device.Devices{
+				{Name: "/dev/sda1", MountPoint: "/", Fstype: "ext4"},
+				{Name: "host:/dir", MountPoint: "/mnt/nfs", Fstype: "nfs"},
+			},
+		},
+	)
+
+	assert.Nil(t, err)
+	assert.Contains(t, out, "/dev/sda1")
+	assert.NotContains(t, out, "/mnt/nfs")
+}
+
 func TestListDevicesToFile(t *testing.T) {
 	fin := testdir.CreateTestDir()
 	defer fin()
@@ -776,6 +1035,7 @@ func TestMaxCoresLowEdge(t *testing.T) {
 
 type uiTimeFilterMock struct {
 	timeFilter common.TimeFilter
+	ioLimiter  common.IOLimiter
 }
 
 func (m *uiTimeFilterMock) ListDevices(getter device.DevicesInfoGetter) error { return nil }
@@ -786,19 +1046,32 @@ func (m *uiTimeFilterMock) SetIgnoreTypes(types []string)                     {}
 func (m *uiTimeFilterMock) SetIgnoreDirPaths(paths []string)                  {}
 func (m *uiTimeFilterMock) SetIgnoreDirPatterns(paths []string) error         { return nil }
 func (m *uiTimeFilterMock) SetIgnoreFromFile(ignoreFile string) error         { return nil }
-func (m *uiTimeFilterMock) SetIgnoreHidden(value bool)                        {}
-func (m *uiTimeFilterMock) SetIncludeTypes(types []string)                    {}
-func (m *uiTimeFilterMock) SetFollowSymlinks(value bool)                      {}
-func (m *uiTimeFilterMock) SetShowAnnexedSize(value bool)                     {}
-func (m *uiTimeFilterMock) SetAnalyzer(analyzer common.Analyzer)              {}
+func (m *uiTimeFilterMock) SetIgnoreFiles(ignoreFiles []string, useDirFiles bool) error {
+	return nil
+}
+func (m *uiTimeFilterMock) SetIgnoreHidden(value bool)           {}
+func (m *uiTimeFilterMock) SetIgnoreSnapshots(value bool)        {}
+func (m *uiTimeFilterMock) SetIncludeTypes(types []string)       {}
+func (m *uiTimeFilterMock) SetFollowSymlinks(value bool)         {}
+func (m *uiTimeFilterMock) SetShowAnnexedSize(value bool)        {}
+func (m *uiTimeFilterMock) SetAnalyzer(analyzer common.Analyzer) {}
 func (m *uiTimeFilterMock) SetTimeFilter(timeFilter common.TimeFilter) {
 	m.timeFilter = timeFilter
 }
 func (m *uiTimeFilterMock) SetArchiveBrowsing(value bool)   {}
+func (m *uiTimeFilterMock) SetSharedUsage(value bool)       {}
 func (m *uiTimeFilterMock) SetCollapsePath(value bool)      {}
 func (m *uiTimeFilterMock) SetShowSymlinkTarget(value bool) {}
+func (m *uiTimeFilterMock) SetShowInodes(capacity int64)    {}
 func (m *uiTimeFilterMock) StartUILoop() error              { return nil }
 
+func (m *uiTimeFilterMock) SetFileFilter(fileFilter common.FileFilter) {}
+func (m *uiTimeFilterMock) SetMaxDepth(depth int)                      {}
+func (m *uiTimeFilterMock) SetThrottle(throttle common.Throttle)       {}
+func (m *uiTimeFilterMock) SetIOLimiter(limiter common.IOLimiter) {
+	m.ioLimiter = limiter
+}
+
 func TestSetTimeFiltersInvalid(t *testing.T) {
 	a := &App{Flags: &Flags{Since: "not-a-date"}}
 	ui := &uiTimeFilterMock{}
@@ -823,11 +1096,19 @@ func TestSetTimeFiltersSetsFilter(t *testing.T) {
 }
 
 // nolint: unparam // Why: it's used in linux tests
+// withDefaultFlags sets the flags which get their defaults from the command line
+func withDefaultFlags(flags *Flags) *Flags {
+	if flags.ShredPasses == 0 {
+		flags.ShredPasses = remove.DefaultShredPasses
+	}
+	return flags
+}
+
 func runApp(flags *Flags, args []string, istty bool, getter device.DevicesInfoGetter) (output string, err error) {
 	buff := bytes.NewBufferString("")
 
 	app := App{
-		Flags:       flags,
+		Flags:       withDefaultFlags(flags),
 		Args:        args,
 		Istty:       istty,
 		Writer:      buff,
@@ -839,3 +1120,235 @@ func runApp(flags *Flags, args []string, istty bool, getter device.DevicesInfoGe
 
 	return strings.TrimSpace(buff.String()), err
 }
+
+func TestKeyBindings(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{Keys: map[string]string{"delete": "x", "shell": "none"}},
+		[]string{"test_dir"},
+		true,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+}
+
+func TestConflictingKeyBindings(t *testing.T) {
+	out, err := runApp(
+		&Flags{Keys: map[string]string{"delete": "S"}},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.EqualError(t, err, `key "S" is bound to both "delete" and "shred"`)
+}
+
+func TestJunkRules(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{JunkRules: []junk.Rule{{Category: "Logs", Patterns: []string{"*.log"}}}},
+		[]string{"test_dir"},
+		true,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+}
+
+func TestInvalidJunkRules(t *testing.T) {
+	out, err := runApp(
+		&Flags{JunkRules: []junk.Rule{{Patterns: []string{"*.log"}}}},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.EqualError(t, err, "junk rule without category")
+}
+
+func TestFileFilters(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", MinSize: "3", MaxSize: "1K", MaxDepth: 1},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Contains(t, out, "nested")
+	assert.Nil(t, err)
+}
+
+func TestNegativeMaxDepth(t *testing.T) {
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", MaxDepth: -1},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.EqualError(t, err, "--max-depth must not be negative")
+}
+
+func TestInvalidFileMode(t *testing.T) {
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", FileModes: []string{"hidden"}},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.ErrorContains(t, err, `invalid file filter: unknown file mode "hidden"`)
+}
+
+func TestCompact(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", Compact: true},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Contains(t, out, "nested")
+	assert.Nil(t, err)
+}
+
+func TestMaxMemory(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	defer debug.SetMemoryLimit(debug.SetMemoryLimit(-1))
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", MaxMemory: "1G", Depth: 2},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Contains(t, out, "subnested")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1<<30), debug.SetMemoryLimit(-1))
+}
+
+func TestInvalidMaxMemory(t *testing.T) {
+	for flags, expected := range map[*Flags]string{
+		{MaxMemory: "lots"}:                         `invalid --max-memory: invalid size "lots"`,
+		{MaxMemory: "0"}:                            "--max-memory must be positive",
+		{Compact: true, DbPath: "test.sqlite"}:      "--compact and --max-memory cannot be used with --db",
+		{MaxMemory: "1G", SequentialScanning: true}: "--compact and --sequential cannot be used at once",
+	} {
+		flags.LogFile = "/dev/null"
+		out, err := runApp(flags, []string{"test_dir"}, false, testdev.DevicesInfoGetterMock{})
+
+		assert.Empty(t, out)
+		assert.ErrorContains(t, err, expected)
+	}
+}
+
+func TestIOWorkers(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", IOWorkers: "3"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Contains(t, out, "nested")
+	assert.Nil(t, err)
+}
+
+func TestAdaptiveIOWorkers(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", IOWorkers: "auto"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Contains(t, out, "nested")
+	assert.Nil(t, err)
+}
+
+func TestSetIOWorkers(t *testing.T) {
+	ui := &uiTimeFilterMock{}
+	assert.Nil(t, (&App{Flags: &Flags{}}).setIOWorkers(ui))
+	assert.Nil(t, ui.ioLimiter)
+
+	assert.Nil(t, (&App{Flags: &Flags{IOWorkers: "3"}}).setIOWorkers(ui))
+	assert.Equal(t, 3, ui.ioLimiter.Limit())
+	assert.Equal(t, 3, ui.ioLimiter.MaxLimit())
+
+	assert.Nil(t, (&App{Flags: &Flags{IOWorkers: "auto"}}).setIOWorkers(ui))
+	assert.Positive(t, ui.ioLimiter.Limit())
+	assert.Equal(t, analyze.MaxAdaptiveIOWorkers, ui.ioLimiter.MaxLimit())
+}
+
+func TestInvalidIOWorkers(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	for flags, expected := range map[*Flags]string{
+		{IOWorkers: "many"}:                        `invalid --io-workers "many": must be a positive number or auto`,
+		{IOWorkers: "0"}:                           `invalid --io-workers "0": must be a positive number or auto`,
+		{IOWorkers: "4", SequentialScanning: true}: "--io-workers cannot be used with --sequential",
+	} {
+		flags.LogFile = "/dev/null"
+		out, err := runApp(flags, []string{"test_dir"}, false, testdev.DevicesInfoGetterMock{})
+
+		assert.Empty(t, out)
+		assert.ErrorContains(t, err, expected)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", RateLimit: 1000},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Contains(t, out, "nested")
+	assert.Nil(t, err)
+}
+
+func TestInvalidThrottling(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	for flags, expected := range map[*Flags]string{
+		{RateLimit: -1}: "--rate-limit must not be negative",
+		{MaxLoad: -0.5}: "--max-load must not be negative",
+	} {
+		flags.LogFile = "/dev/null"
+		out, err := runApp(flags, []string{"test_dir"}, false, testdev.DevicesInfoGetterMock{})
+
+		assert.Empty(t, out)
+		assert.ErrorContains(t, err, expected)
+	}
+}
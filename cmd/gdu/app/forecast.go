@@ -0,0 +1,118 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/dundee/gdu/v5/pkg/forecast"
+)
+
+// forecastDirsCount is the number of directories listed in the forecast when --top is not given
+const forecastDirsCount = 10
+
+// showForecast prints estimated growth of devices and directories from the history of scans
+func (a *App) showForecast() error {
+	storage, err := a.openHistory("forecast")
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	scans, err := storage.GetHistoryScans()
+	if err != nil {
+		return fmt.Errorf("loading history: %w", err)
+	}
+	entries, err := storage.GetHistory()
+	if err != nil {
+		return fmt.Errorf("loading history: %w", err)
+	}
+	if len(scans) < 2 {
+		return fmt.Errorf(
+			"at least two scans are needed for a forecast, %d found in %s (scan with --db and --history regularly)",
+			len(scans), a.Flags.DbPath,
+		)
+	}
+
+	if a.Flags.NoColor {
+		color.NoColor = true
+	}
+	red := color.New(color.FgRed).Add(color.Bold)
+
+	result := forecast.Build(scans, entries)
+	first, last := scans[0].Time, scans[len(scans)-1].Time
+	fmt.Fprintf(a.Writer, "%d scans from %s to %s\n\n", len(scans), first.Format(time.DateOnly), last.Format(time.DateOnly))
+
+	if len(result.Devices) > 0 {
+		fmt.Fprintf(a.Writer, "%-20s %-20s %10s %12s  %s\n", "Device", "Mounted on", "Free", "Growth/day", "Full in")
+		for _, dev := range result.Devices {
+			fullIn := "never"
+			if days, ok := dev.DaysUntilFull(); ok {
+				fullIn = formatDays(days)
+				if at, ok := dev.FullAt(); ok {
+					fullIn += " (" + at.Format(time.DateOnly) + ")"
+				}
+			}
+			fmt.Fprintf(
+				a.Writer, "%-20s %-20s %10s %12s  %s\n",
+				dev.Device, dev.Path, formatBytes(dev.Free), formatGrowth(dev.Growth), fullIn,
+			)
+		}
+		fmt.Fprintln(a.Writer)
+	}
+
+	top := a.Flags.Top
+	if top <= 0 {
+		top = forecastDirsCount
+	}
+	fmt.Fprintln(a.Writer, "Fastest growing directories:")
+	fmt.Fprintf(a.Writer, "%12s %10s  %s\n", "Growth/day", "Usage", "Path")
+	for i, dir := range result.Dirs {
+		if i >= top {
+			break
+		}
+		growth := fmt.Sprintf("%12s", formatGrowth(dir.Growth))
+		if dir.Growth > 0 {
+			growth = red.Sprint(growth)
+		}
+		fmt.Fprintf(a.Writer, "%s %10s  %s\n", growth, formatBytes(dir.Usage), dir.Path)
+	}
+	return nil
+}
+
+func formatGrowth(growth float64) string {
+	if growth < 0 {
+		return "-" + formatBytes(int64(-growth))
+	}
+	return "+" + formatBytes(int64(growth))
+}
+
+func formatDays(days float64) string {
+	switch {
+	case days < 1:
+		return "less than a day"
+	case days >= 365*100:
+		return "more than 100 years"
+	default:
+		return fmt.Sprintf("%d days", int64(math.Round(days)))
+	}
+}
+
+func formatBytes(size int64) string {
+	fsize := float64(size)
+	switch {
+	case fsize >= common.Ti:
+		return fmt.Sprintf("%.1f TiB", fsize/common.Ti)
+	case fsize >= common.Gi:
+		return fmt.Sprintf("%.1f GiB", fsize/common.Gi)
+	case fsize >= common.Mi:
+		return fmt.Sprintf("%.1f MiB", fsize/common.Mi)
+	case fsize >= common.Ki:
+		return fmt.Sprintf("%.1f KiB", fsize/common.Ki)
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
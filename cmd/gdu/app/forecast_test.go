@@ -0,0 +1,84 @@
+//go:build linux
+
+package app
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/internal/testdev"
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/device"
+)
+
+func TestHistoryRequiresSqlite(t *testing.T) {
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", History: true, DbPath: "test.badger"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.EqualError(t, err, "--history requires SQLite database (--db *.sqlite)")
+}
+
+func TestForecast(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	dbPath := filepath.Join(t.TempDir(), "history.sqlite")
+	getter := testdev.DevicesInfoGetterMock{
+		Devices: []*device.Device{{Name: "/dev/sda1", MountPoint: "/", Size: 1e9, Free: 5e8}},
+	}
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", DbPath: dbPath, History: true},
+		[]string{"test_dir"},
+		false,
+		getter,
+	)
+	assert.Contains(t, out, "nested")
+	assert.Nil(t, err)
+
+	out, err = runApp(&Flags{Forecast: true, DbPath: dbPath}, nil, false, getter)
+	assert.Empty(t, out)
+	assert.ErrorContains(t, err, "at least two scans are needed")
+
+	storage, err := analyze.NewSqliteStorage(dbPath)
+	require.NoError(t, err)
+	scans, err := storage.GetHistoryScans()
+	require.NoError(t, err)
+	assert.Equal(t, "/dev/sda1", scans[0].Device)
+	scan := scans[0]
+	scan.Time = scan.Time.Add(-10 * 24 * time.Hour)
+	scan.DeviceFree = 6e8
+	err = storage.AddHistoryScan(scan, []analyze.HistoryEntry{
+		{Path: filepath.Join(scan.Path, "nested"), Usage: 0},
+	})
+	require.NoError(t, err)
+	storage.Close()
+
+	out, err = runApp(&Flags{Forecast: true, DbPath: dbPath, NoColor: true}, nil, false, getter)
+	assert.Nil(t, err)
+	assert.Contains(t, out, "2 scans from")
+	assert.Contains(t, out, "/dev/sda1")
+	assert.Contains(t, out, "50 days")
+	assert.Contains(t, out, "Fastest growing directories:")
+	assert.Contains(t, out, "test_dir/nested")
+}
+
+func TestForecastWithoutDb(t *testing.T) {
+	out, err := runApp(&Flags{Forecast: true}, nil, false, testdev.DevicesInfoGetterMock{})
+	assert.Empty(t, out)
+	assert.ErrorContains(t, err, "forecast requires SQLite database")
+
+	out, err = runApp(&Flags{Forecast: true, DbPath: "missing.sqlite"}, nil, false, testdev.DevicesInfoGetterMock{})
+	assert.Empty(t, out)
+	assert.ErrorContains(t, err, "opening database")
+}
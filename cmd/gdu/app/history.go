@@ -0,0 +1,107 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/device"
+	"github.com/dundee/gdu/v5/pkg/timefilter"
+)
+
+// getHistoryScan describes the scan of the path recorded into the history of the database
+func (a *App) getHistoryScan(path string) analyze.HistoryScan {
+	scan := analyze.HistoryScan{Time: time.Now(), Path: path}
+
+	devices, err := a.Getter.GetDevicesInfo()
+	if err != nil {
+		log.Printf("Loading devices for history failed: %s", err)
+		return scan
+	}
+	if dev := device.GetDeviceForPath(path, devices); dev != nil {
+		scan.Device = dev.Name
+		scan.MountPoint = dev.MountPoint
+		scan.DeviceSize = dev.Size
+		scan.DeviceFree = dev.Free
+	}
+	return scan
+}
+
+// loadScanAt makes the analyzer load the last scan done before the time given by --at
+func (a *App) loadScanAt(analyzer *analyze.SqliteAnalyzer) error {
+	at, err := timefilter.EndOf(a.Flags.At, time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid --at value: %w", err)
+	}
+	scan, err := analyzer.GetStorage().FindScan(at)
+	if err != nil {
+		return fmt.Errorf("loading scan: %w", err)
+	}
+	log.Printf("Loading scan of %s from %s", scan.Path, scan.Time.Format(time.RFC3339))
+	return analyzer.LoadScan(scan)
+}
+
+// openHistory opens the SQLite database given by --db for reading the history of scans
+func (a *App) openHistory(command string) (*analyze.SqliteStorage, error) {
+	if a.Flags.DbPath == "" {
+		return nil, fmt.Errorf("%s requires SQLite database with history of scans (--db *.sqlite)", command)
+	}
+	if _, err := os.Stat(a.Flags.DbPath); err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+
+	storage, err := analyze.NewSqliteStorage(a.Flags.DbPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	return storage, nil
+}
+
+// listScans prints scans stored in the history of the database
+func (a *App) listScans() error {
+	storage, err := a.openHistory("scans")
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	scans, err := storage.GetHistoryScans()
+	if err != nil {
+		return fmt.Errorf("loading history: %w", err)
+	}
+	entries, err := storage.GetHistory()
+	if err != nil {
+		return fmt.Errorf("loading history: %w", err)
+	}
+	if len(scans) == 0 {
+		fmt.Fprintln(a.Writer, "No scans stored in history")
+		return nil
+	}
+
+	type scanKey struct {
+		time int64
+		path string
+	}
+	roots := make(map[scanKey]analyze.HistoryEntry, len(scans))
+	for _, entry := range entries {
+		roots[scanKey{entry.Time.Unix(), entry.Path}] = entry
+	}
+
+	fmt.Fprintf(a.Writer, "%-19s %10s %12s  %s\n", "Time", "Usage", "Items", "Path")
+	for _, scan := range scans {
+		root := roots[scanKey{scan.Time.Unix(), scan.Path}]
+		path := scan.Path
+		if !scan.HasTree() {
+			path += " (only summary kept)"
+		}
+		fmt.Fprintf(
+			a.Writer, "%-19s %10s %12s  %s\n",
+			scan.Time.Local().Format(time.DateTime), formatBytes(root.Usage), common.FormatNumber(root.ItemCount), path,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+//go:build linux
+
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testdev"
+	"github.com/dundee/gdu/v5/internal/testdir"
+)
+
+func TestAtRequiresReadFromStorage(t *testing.T) {
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", At: "2026-09-01", DbPath: "test.sqlite"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.EqualError(t, err, "--at requires --read-from-storage and SQLite database (--db *.sqlite)")
+}
+
+func TestReadFromStorageAt(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	dbPath := filepath.Join(t.TempDir(), "history.sqlite")
+	for range 2 {
+		_, err := runApp(
+			&Flags{LogFile: "/dev/null", DbPath: dbPath, History: true, KeepScans: 10},
+			[]string{"test_dir"},
+			false,
+			testdev.DevicesInfoGetterMock{},
+		)
+		assert.Nil(t, err)
+	}
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", DbPath: dbPath, ReadFromStorage: true, At: "2100-01-01"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.Nil(t, err)
+	assert.Contains(t, out, "nested")
+
+	out, err = runApp(
+		&Flags{LogFile: "/dev/null", DbPath: dbPath, ReadFromStorage: true, At: "2000-01-01"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.Empty(t, out)
+	assert.ErrorContains(t, err, "no scan done before 2000-01-01")
+
+	_, err = runApp(
+		&Flags{LogFile: "/dev/null", DbPath: dbPath, ReadFromStorage: true, At: "yesterday"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.ErrorContains(t, err, "invalid --at value")
+}
+
+func TestListScans(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	dbPath := filepath.Join(t.TempDir(), "history.sqlite")
+
+	out, err := runApp(&Flags{ListScans: true, DbPath: dbPath}, nil, false, testdev.DevicesInfoGetterMock{})
+	assert.Empty(t, out)
+	assert.ErrorContains(t, err, "opening database")
+
+	for range 3 {
+		_, err := runApp(
+			&Flags{LogFile: "/dev/null", DbPath: dbPath, History: true, KeepScans: 2},
+			[]string{"test_dir"},
+			false,
+			testdev.DevicesInfoGetterMock{},
+		)
+		assert.Nil(t, err)
+	}
+
+	out, err = runApp(&Flags{ListScans: true, DbPath: dbPath}, nil, false, testdev.DevicesInfoGetterMock{})
+	assert.Nil(t, err)
+	assert.Contains(t, out, "Time")
+	assert.Contains(t, out, "test_dir (only summary kept)")
+	assert.Len(t, strings.Split(out, "\n"), 4)
+}
+
+func TestListScansWithoutDb(t *testing.T) {
+	out, err := runApp(&Flags{ListScans: true}, nil, false, testdev.DevicesInfoGetterMock{})
+	assert.Empty(t, out)
+	assert.EqualError(t, err, "scans requires SQLite database with history of scans (--db *.sqlite)")
+}
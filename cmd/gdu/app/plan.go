@@ -0,0 +1,117 @@
+package app
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dundee/gdu/v5/pkg/plan"
+	"github.com/dundee/gdu/v5/tui"
+)
+
+// applyPlan reviews the cleanup plan against the filesystem and, after confirmation,
+// performs the planned actions on the items which have not changed since they were planned
+func (a *App) applyPlan() error {
+	p, err := plan.LoadFile(a.Flags.ApplyPlan)
+	if err != nil {
+		return fmt.Errorf("loading cleanup plan: %w", err)
+	}
+
+	fmt.Fprintf(a.Writer, "Cleanup plan %s created %s", a.Flags.ApplyPlan, p.Created.Format("2006-01-02 15:04:05"))
+	if p.Host != "" {
+		fmt.Fprintf(a.Writer, " on %s", p.Host)
+	}
+	fmt.Fprintln(a.Writer)
+
+	var toApply []plan.Entry
+	for _, reviewed := range p.Review(os.Lstat) {
+		fmt.Fprintf(a.Writer, "%-9s %-6s %12d %s", reviewed.Status, reviewed.Action, reviewed.Usage, reviewed.Path)
+		if reviewed.Reason != "" {
+			fmt.Fprintf(a.Writer, " (%s, skipping)", reviewed.Reason)
+		}
+		fmt.Fprintln(a.Writer)
+
+		if reviewed.Status == plan.StatusUnchanged {
+			toApply = append(toApply, reviewed.Entry)
+		}
+	}
+
+	if len(toApply) == 0 {
+		fmt.Fprintln(a.Writer, "Nothing to apply")
+		return nil
+	}
+
+	if a.auditLog != nil && a.auditLog.IsDryRun() {
+		for _, entry := range toApply {
+			_ = a.applyEntry(entry) // only recorded in dry run
+			fmt.Fprintf(a.Writer, "Dry run: would %s %s\n", entry.Action, entry.Path)
+		}
+		return nil
+	}
+
+	if a.Flags.NoDelete {
+		return errors.New("applying cleanup plan is disabled by --no-delete")
+	}
+
+	if !a.Flags.Yes {
+		confirmed, err := a.confirm(fmt.Sprintf("Apply %d of %d planned actions? [y/N] ", len(toApply), len(p.Entries)))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Fprintln(a.Writer, "Aborted")
+			return nil
+		}
+	}
+
+	failed := 0
+	for _, entry := range toApply {
+		if err := a.applyEntry(entry); err != nil {
+			failed++
+			fmt.Fprintf(a.Writer, "Failed to %s %s: %s\n", entry.Action, entry.Path, err)
+			continue
+		}
+		fmt.Fprintf(a.Writer, "Done: %s %s\n", entry.Action, entry.Path)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d planned actions failed", failed, len(toApply))
+	}
+	return nil
+}
+
+// applyEntry performs the planned action recorded to the audit log
+func (a *App) applyEntry(entry plan.Entry) error {
+	return a.auditLog.Do(string(entry.Action), entry.Path, entry.Size, entry.Usage, entry.IsDir, func() error {
+		return plan.Apply(entry)
+	})
+}
+
+func (a *App) confirm(question string) (bool, error) {
+	if a.Input == nil {
+		return false, errors.New("confirmation is not possible without input, use --yes to skip it")
+	}
+	fmt.Fprint(a.Writer, question)
+
+	answer, err := bufio.NewReader(a.Input).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(a.Writer)
+		return false, nil
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+func (a *App) loadPlan(ui UI) error {
+	p, err := plan.LoadFile(a.Flags.LoadPlan)
+	if err != nil {
+		return fmt.Errorf("loading cleanup plan: %w", err)
+	}
+	tuiUI, ok := ui.(*tui.UI)
+	if !ok {
+		return errors.New("--load-plan can be used only in interactive mode")
+	}
+	tuiUI.SetPlan(p)
+	return nil
+}
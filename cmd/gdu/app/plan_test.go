@@ -0,0 +1,211 @@
+package app
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testapp"
+	"github.com/dundee/gdu/v5/internal/testdev"
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/plan"
+)
+
+func writePlan(t *testing.T, action plan.Action, paths ...string) string {
+	t.Helper()
+	p := plan.New()
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		assert.Nil(t, err)
+		info, err := os.Lstat(abs)
+		assert.Nil(t, err)
+		p.Add(plan.Entry{Path: abs, Size: info.Size(), Mtime: info.ModTime(), IsDir: info.IsDir(), Action: action})
+	}
+	planPath := filepath.Join(t.TempDir(), "plan.json")
+	assert.Nil(t, p.SaveFile(planPath))
+	return planPath
+}
+
+func runAppWithInput(flags *Flags, input io.Reader) (string, error) {
+	buff := bytes.NewBufferString("")
+	app := App{
+		Flags:       withDefaultFlags(flags),
+		Writer:      buff,
+		Input:       input,
+		TermApp:     testapp.CreateMockedApp(false),
+		Getter:      testdev.DevicesInfoGetterMock{},
+		PathChecker: testdir.MockedPathChecker,
+	}
+	err := app.Run()
+	return strings.TrimSpace(buff.String()), err
+}
+
+func TestApplyPlanConfirmed(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	planPath := writePlan(t, plan.ActionDelete, "test_dir/nested/subnested")
+
+	out, err := runAppWithInput(&Flags{ApplyPlan: planPath}, strings.NewReader("y\n"))
+
+	assert.Nil(t, err)
+	assert.Contains(t, out, "unchanged delete")
+	assert.Contains(t, out, "Apply 1 of 1 planned actions?")
+	assert.Contains(t, out, "Done: delete")
+	assert.NoDirExists(t, "test_dir/nested/subnested")
+}
+
+func TestApplyPlanDeclined(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	planPath := writePlan(t, plan.ActionDelete, "test_dir/nested/subnested")
+
+	out, err := runAppWithInput(&Flags{ApplyPlan: planPath}, strings.NewReader("\n"))
+
+	assert.Nil(t, err)
+	assert.Contains(t, out, "Aborted")
+	assert.DirExists(t, "test_dir/nested/subnested")
+
+	out, err = runAppWithInput(&Flags{ApplyPlan: planPath}, strings.NewReader(""))
+	assert.Nil(t, err)
+	assert.Contains(t, out, "Aborted")
+	assert.DirExists(t, "test_dir/nested/subnested")
+}
+
+func TestApplyPlanWithoutInput(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	planPath := writePlan(t, plan.ActionDelete, "test_dir/nested/subnested")
+
+	_, err := runAppWithInput(&Flags{ApplyPlan: planPath}, nil)
+	assert.ErrorContains(t, err, "use --yes")
+	assert.DirExists(t, "test_dir/nested/subnested")
+}
+
+func TestApplyPlanYesSkipsChanged(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	planPath := writePlan(t, plan.ActionEmpty, "test_dir/nested/file2", "test_dir/nested/subnested/file")
+	assert.Nil(t, os.WriteFile("test_dir/nested/subnested/file", []byte("changed content"), 0o600))
+
+	out, err := runAppWithInput(&Flags{ApplyPlan: planPath, Yes: true}, nil)
+
+	assert.Nil(t, err)
+	assert.Contains(t, out, "size changed")
+	info, _ := os.Stat("test_dir/nested/file2")
+	assert.Equal(t, int64(0), info.Size())
+	info, _ = os.Stat("test_dir/nested/subnested/file")
+	assert.Equal(t, int64(15), info.Size())
+}
+
+func TestApplyPlanNoDelete(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	planPath := writePlan(t, plan.ActionDelete, "test_dir/nested/subnested")
+
+	out, err := runAppWithInput(&Flags{ApplyPlan: planPath, NoDelete: true, Yes: true}, nil)
+
+	assert.ErrorContains(t, err, "disabled by --no-delete")
+	assert.Contains(t, out, "unchanged delete")
+	assert.NotContains(t, out, "Done:")
+	assert.DirExists(t, "test_dir/nested/subnested")
+}
+
+func TestApplyPlanNothingToApply(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	planPath := writePlan(t, plan.ActionDelete, "test_dir/nested/file2")
+	assert.Nil(t, os.Remove("test_dir/nested/file2"))
+
+	out, err := runAppWithInput(&Flags{ApplyPlan: planPath}, nil)
+
+	assert.Nil(t, err)
+	assert.Contains(t, out, "missing")
+	assert.Contains(t, out, "Nothing to apply")
+}
+
+func TestApplyPlanWrongFile(t *testing.T) {
+	_, err := runAppWithInput(&Flags{ApplyPlan: "nonexistent.json"}, nil)
+	assert.ErrorContains(t, err, "loading cleanup plan")
+}
+
+func TestLoadPlanNonInteractive(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	planPath := writePlan(t, plan.ActionDelete, "test_dir/nested/file2")
+
+	_, err := runApp(&Flags{LoadPlan: planPath}, []string{"test_dir"}, false, testdev.DevicesInfoGetterMock{})
+	assert.ErrorContains(t, err, "only in interactive mode")
+
+	_, err = runApp(&Flags{LoadPlan: "nonexistent.json"}, []string{"test_dir"}, false, testdev.DevicesInfoGetterMock{})
+	assert.ErrorContains(t, err, "loading cleanup plan")
+}
+
+func TestLoadPlanInteractive(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	planPath := writePlan(t, plan.ActionDelete, "test_dir/nested/file2")
+
+	_, err := runApp(&Flags{LoadPlan: planPath}, []string{"test_dir"}, true, testdev.DevicesInfoGetterMock{})
+	assert.Nil(t, err)
+}
+
+func TestApplyPlanDryRun(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	planPath := writePlan(t, plan.ActionDelete, "test_dir/nested/subnested")
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+
+	out, err := runAppWithInput(&Flags{ApplyPlan: planPath, DryRun: true, AuditFile: auditPath}, nil)
+
+	assert.Nil(t, err)
+	assert.Contains(t, out, "Dry run: would delete")
+	assert.DirExists(t, "test_dir/nested/subnested")
+
+	data, err := os.ReadFile(auditPath)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"result":"dry-run"`)
+}
+
+func TestApplyPlanWithAudit(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	planPath := writePlan(t, plan.ActionDelete, "test_dir/nested/subnested")
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+
+	_, err := runAppWithInput(&Flags{ApplyPlan: planPath, Yes: true, AuditFile: auditPath}, nil)
+
+	assert.Nil(t, err)
+	assert.NoDirExists(t, "test_dir/nested/subnested")
+
+	data, err := os.ReadFile(auditPath)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"action":"delete","result":"ok"`)
+}
+
+func TestAuditFileError(t *testing.T) {
+	_, err := runAppWithInput(&Flags{AuditFile: filepath.Join(t.TempDir(), "nonexistent", "audit.log")}, nil)
+	assert.ErrorContains(t, err, "opening audit file")
+}
+
+func TestDryRunInteractive(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	_, err := runApp(&Flags{DryRun: true}, []string{"test_dir"}, true, testdev.DevicesInfoGetterMock{})
+	assert.Nil(t, err)
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/query"
+)
+
+// filterColumns are the columns printed for filter expressions
+const filterColumns = `path, CASE is_dir WHEN 1 THEN 'dir' ELSE 'file' END AS type, size, usage,
+	item_count AS items, datetime(mtime, 'unixepoch', 'localtime') AS mtime`
+
+// runQuery runs SQL query or filter expression over the analysis stored in SQLite database
+func (a *App) runQuery() error {
+	if a.Flags.DbPath == "" || strings.HasSuffix(a.Flags.DbPath, ".badger") {
+		return fmt.Errorf("query requires SQLite database (--db *.sqlite)")
+	}
+	format := a.Flags.QueryFormat
+	if format == "" {
+		format = "table"
+	}
+	if format != "table" && format != "csv" && format != "json" {
+		return fmt.Errorf("unknown output format %q (use table, csv or json)", format)
+	}
+
+	sqlQuery, args := a.Flags.Query, []any(nil)
+	if !query.IsSQL(sqlQuery) {
+		expr, err := query.Parse(sqlQuery, time.Now(), time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid filter: %w", err)
+		}
+		var where string
+		where, args = expr.SQL()
+		sqlQuery = "SELECT " + filterColumns + " FROM " + analyze.QueryView +
+			" WHERE " + where + " ORDER BY usage DESC"
+		if a.Flags.Top > 0 {
+			sqlQuery += " LIMIT ?"
+			args = append(args, a.Flags.Top)
+		}
+	}
+
+	if _, err := os.Stat(a.Flags.DbPath); err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	storage, err := analyze.OpenSqliteStorageReadOnly(a.Flags.DbPath)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer storage.Close()
+
+	result, err := storage.Query(sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("running query: %w", err)
+	}
+
+	switch format {
+	case "csv":
+		return a.writeQueryCSV(result)
+	case "json":
+		return a.writeQueryJSON(result)
+	default:
+		return a.writeQueryTable(result)
+	}
+}
+
+func (a *App) writeQueryTable(result *analyze.QueryResult) error {
+	w := tabwriter.NewWriter(a.Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(result.Columns, "\t"))
+	for _, row := range result.Rows {
+		fmt.Fprintln(w, strings.Join(formatQueryRow(row), "\t"))
+	}
+	return w.Flush()
+}
+
+func (a *App) writeQueryCSV(result *analyze.QueryResult) error {
+	w := csv.NewWriter(a.Writer)
+	if err := w.Write(result.Columns); err != nil {
+		return err
+	}
+	for _, row := range result.Rows {
+		if err := w.Write(formatQueryRow(row)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func (a *App) writeQueryJSON(result *analyze.QueryResult) error {
+	rows := make([]map[string]any, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		obj := make(map[string]any, len(row))
+		for i, value := range row {
+			obj[result.Columns[i]] = value
+		}
+		rows = append(rows, obj)
+	}
+	encoder := json.NewEncoder(a.Writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(rows)
+}
+
+func formatQueryRow(row []any) []string {
+	values := make([]string, len(row))
+	for i, value := range row {
+		if value != nil {
+			values[i] = fmt.Sprint(value)
+		}
+	}
+	return values
+}
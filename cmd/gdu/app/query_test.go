@@ -0,0 +1,95 @@
+//go:build linux
+
+package app
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/internal/testdev"
+	"github.com/dundee/gdu/v5/internal/testdir"
+)
+
+func createQueryDb(t *testing.T) string {
+	t.Helper()
+	dbPath := filepath.Join(t.TempDir(), "analysis.sqlite")
+	_, err := runApp(
+		&Flags{LogFile: "/dev/null", DbPath: dbPath},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	require.NoError(t, err)
+	return dbPath
+}
+
+func TestQueryFilter(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	dbPath := createQueryDb(t)
+
+	out, err := runApp(
+		&Flags{DbPath: dbPath, Query: "type = file and name ~ 'file*'"},
+		nil, false, testdev.DevicesInfoGetterMock{},
+	)
+	assert.Nil(t, err)
+	lines := strings.Split(out, "\n")
+	require.Len(t, lines, 3)
+	assert.Regexp(t, `^path\s+type\s+size\s+usage\s+items\s+mtime$`, lines[0])
+	assert.Contains(t, out, "test_dir/nested/subnested/file ")
+	assert.Contains(t, out, "test_dir/nested/file2 ")
+
+	out, err = runApp(
+		&Flags{DbPath: dbPath, Query: "type = file", Top: 1, QueryFormat: "csv"},
+		nil, false, testdev.DevicesInfoGetterMock{},
+	)
+	assert.Nil(t, err)
+	assert.Len(t, strings.Split(out, "\n"), 2)
+	assert.True(t, strings.HasPrefix(out, "path,type,size,usage,items,mtime\n"))
+}
+
+func TestQuerySQL(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	dbPath := createQueryDb(t)
+
+	out, err := runApp(
+		&Flags{DbPath: dbPath, Query: "SELECT name, depth, size FROM files WHERE is_dir = 0 ORDER BY name", QueryFormat: "json"},
+		nil, false, testdev.DevicesInfoGetterMock{},
+	)
+	assert.Nil(t, err)
+
+	var rows []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(out), &rows))
+	assert.Equal(t, []map[string]any{
+		{"name": "file", "depth": float64(3), "size": float64(5)},
+		{"name": "file2", "depth": float64(2), "size": float64(2)},
+	}, rows)
+}
+
+func TestQueryErrors(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	dbPath := createQueryDb(t)
+
+	for _, tc := range []struct {
+		flags *Flags
+		err   string
+	}{
+		{&Flags{Query: "size > 1"}, "query requires SQLite database (--db *.sqlite)"},
+		{&Flags{Query: "size > 1", DbPath: "test.badger"}, "query requires SQLite database (--db *.sqlite)"},
+		{&Flags{Query: "size > 1", DbPath: dbPath, QueryFormat: "xml"}, `unknown output format "xml"`},
+		{&Flags{Query: "size >", DbPath: dbPath}, "invalid filter: expected value"},
+		{&Flags{Query: "size > 1", DbPath: "missing.sqlite"}, "opening database"},
+		{&Flags{Query: "SELECT * FROM unknown", DbPath: dbPath}, "running query"},
+	} {
+		out, err := runApp(tc.flags, nil, false, testdev.DevicesInfoGetterMock{})
+		assert.Empty(t, out)
+		assert.ErrorContains(t, err, tc.err)
+	}
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/dundee/gdu/v5/pkg/device"
+	"github.com/dundee/gdu/v5/pkg/remove"
+)
+
+// listTrash prints items stored in the trash directories
+func (a *App) listTrash() error {
+	mounts, err := a.Getter.GetMounts()
+	if err != nil {
+		return fmt.Errorf("loading mount points: %w", err)
+	}
+	items, err := remove.ListTrash(device.GetMountPoints(mounts))
+	if err != nil {
+		return fmt.Errorf("listing trash: %w", err)
+	}
+
+	if len(items) == 0 {
+		fmt.Fprintln(a.Writer, "Trash is empty")
+		return nil
+	}
+
+	var total int64
+	for _, item := range items {
+		total += item.Size
+		path := item.OriginalPath
+		if item.IsDir {
+			path += "/"
+		}
+		fmt.Fprintf(a.Writer, "%s %12d %s\n", item.DeletionDate.Format("2006-01-02 15:04:05"), item.Size, path)
+	}
+	fmt.Fprintf(a.Writer, "%d items, %d bytes in total\n", len(items), total)
+	return nil
+}
@@ -0,0 +1,58 @@
+//go:build !windows && !darwin
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testdev"
+	"github.com/dundee/gdu/v5/pkg/device"
+)
+
+func TestListTrash(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", xdg)
+	mount := t.TempDir()
+
+	homeTrash := filepath.Join(xdg, "Trash")
+	assert.Nil(t, os.MkdirAll(filepath.Join(homeTrash, "files", "dir"), 0o700))
+	assert.Nil(t, os.MkdirAll(filepath.Join(homeTrash, "info"), 0o700))
+	assert.Nil(t, os.WriteFile(filepath.Join(homeTrash, "files", "dir", "file"), []byte("abc"), 0o600))
+	assert.Nil(t, os.WriteFile(
+		filepath.Join(homeTrash, "info", "dir.trashinfo"),
+		[]byte("[Trash Info]\nPath=/home/user/dir\nDeletionDate=2024-01-02T10:00:00\n"),
+		0o600,
+	))
+
+	mountTrash := filepath.Join(mount, ".Trash-"+strconv.Itoa(os.Getuid()))
+	assert.Nil(t, os.MkdirAll(filepath.Join(mountTrash, "files"), 0o700))
+	assert.Nil(t, os.MkdirAll(filepath.Join(mountTrash, "info"), 0o700))
+	assert.Nil(t, os.WriteFile(filepath.Join(mountTrash, "files", "file"), []byte("abcde"), 0o600))
+	assert.Nil(t, os.WriteFile(
+		filepath.Join(mountTrash, "info", "file.trashinfo"),
+		[]byte("[Trash Info]\nPath=data/file\nDeletionDate=2024-02-02T10:00:00\n"),
+		0o600,
+	))
+
+	getter := testdev.DevicesInfoGetterMock{Devices: device.Devices{&device.Device{MountPoint: mount}}}
+	out, err := runApp(&Flags{ShowTrash: true}, []string{}, false, getter)
+
+	assert.Nil(t, err)
+	assert.Contains(t, out, "2024-01-02 10:00:00            3 /home/user/dir/")
+	assert.Contains(t, out, "2024-02-02 10:00:00            5 "+filepath.Join(mount, "data", "file"))
+	assert.Contains(t, out, "2 items, 8 bytes in total")
+}
+
+func TestListTrashEmpty(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	out, err := runApp(&Flags{ShowTrash: true}, []string{}, false, testdev.DevicesInfoGetterMock{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Trash is empty", out)
+}
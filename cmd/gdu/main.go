@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -16,11 +17,15 @@ import (
 
 	"github.com/dundee/gdu/v5/cmd/gdu/app"
 	"github.com/dundee/gdu/v5/pkg/device"
+	"github.com/dundee/gdu/v5/pkg/remove"
 )
 
 const (
 	osWindows = "windows"
 	osPlan9   = "plan9"
+
+	// exitPartialResults is the exit status when some directories or files could not be read
+	exitPartialResults = 2
 )
 
 var (
@@ -29,16 +34,85 @@ var (
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "gdu [directory_to_scan]",
+	Use:   "gdu [directory_to_scan ...]",
 	Short: "Pretty fast disk usage analyzer written in Go",
 	Long: `Pretty fast disk usage analyzer written in Go.
 
 Gdu is intended primarily for SSD disks where it can fully utilize parallel processing.
 However HDDs work as well, but the performance gain is not so huge.
+
+Directories named like a command (forecast, query, scans) have to be given
+as a path, e.g. gdu ./scans, otherwise the command is run.
+
+Exit status is 1 on error and 2 when some directories or files could not be read
+in non-interactive mode, so the results are partial.
+`,
+	Args:          cobra.ArbitraryArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runE,
+}
+
+var forecastCmd = &cobra.Command{
+	Use:   "forecast",
+	Short: "Estimate when disks fill up from the history of scans",
+	Long: `Estimate when disks fill up from the history of scans stored in SQLite database.
+
+Fits growth of used space of each device and of each top-level directory
+recorded by regular scans with --db *.sqlite --history and shows
+the days until the devices are full and the fastest growing directories.
+`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(command *cobra.Command, args []string) error {
+		af.Forecast = true
+		return runE(command, args)
+	},
+}
+
+var scansCmd = &cobra.Command{
+	Use:   "scans",
+	Short: "List scans stored in the history of SQLite database",
+	Long: `List scans stored in the history of SQLite database.
+
+Scans recorded with --db *.sqlite --history can be browsed again
+with --read-from-storage --at WHEN.
 `,
-	Args:         cobra.MaximumNArgs(1),
+	Args:         cobra.NoArgs,
 	SilenceUsage: true,
-	RunE:         runE,
+	RunE: func(command *cobra.Command, args []string) error {
+		af.ListScans = true
+		return runE(command, args)
+	},
+}
+
+var queryCmd = &cobra.Command{
+	Use:   "query [flags] QUERY",
+	Short: "Query the analysis stored in SQLite database",
+	Long: `Query the analysis stored in SQLite database opened read-only.
+
+QUERY is either SQL statement over the view "files" (id, parent_id, name,
+path, depth, is_dir, size, usage, mtime, item_count, mli, flag) holding
+the items of the latest scan, or a filter expression like
+
+  size > 1G and mtime < -1y and name ~ '*.log'
+
+Fields of filter expressions are name, path, size, usage, items, depth,
+mtime and type (file or dir), combined with and, or, not and parentheses.
+Operators are =, !=, <, <=, >, >= and ~, !~ for glob patterns
+or regular expressions delimited by slashes (name ~ /^core\.[0-9]+$/).
+Sizes take binary units (K, M, G, T), mtime takes dates (YYYY-MM-DD, RFC3339)
+or durations back from now (-30d, -6mo, -1y).
+`,
+	Example: `  gdu query --db analysis.sqlite "size > 1G and mtime < -1y and name ~ '*.log'"
+  gdu query --db analysis.sqlite --format csv "type = dir and depth = 1"
+  gdu query --db analysis.sqlite "SELECT depth, SUM(size) FROM files WHERE is_dir = 0 GROUP BY depth"`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(command *cobra.Command, args []string) error {
+		af.Query = args[0]
+		return runE(command, nil)
+	},
 }
 
 // nolint:funlen // a lot of flags to initialize
@@ -48,10 +122,16 @@ func init() {
 	flags.StringVar(&af.CfgFile, "config-file", "", "Read config from file (default is $HOME/.gdu.yaml)")
 	flags.StringVarP(&af.LogFile, "log-file", "l", "/dev/null", "Path to a logfile")
 	flags.StringVarP(&af.OutputFile, "output-file", "o", "", "Export all info into file as JSON")
-	flags.StringVar(&af.OutputAttrs, "output-attrs", "", "Export only selected JSON attributes (name,asize,dsize,items,mtime,notreg)")
+	flags.StringVar(&af.OutputAttrs, "output-attrs", "", "Export only selected JSON attributes (name,asize,dsize,shared,items,mtime,notreg)")
 	flags.StringVarP(&af.InputFile, "input-file", "f", "", "Import analysis from JSON file")
 	flags.IntVarP(&af.MaxCores, "max-cores", "m", runtime.NumCPU(), fmt.Sprintf("Set max cores that Gdu will use. %d cores available", runtime.NumCPU()))
+	flags.StringVar(&af.IOWorkers, "io-workers", "", "Limit number of directories read at once to N, \"auto\" tunes it by the observed latency (intended for network filesystems)")
+	flags.IntVar(&af.RateLimit, "rate-limit", 0, "Limit scanning to N directory reads and file stats per second (0 means unlimited)")
+	flags.Float64Var(&af.MaxLoad, "max-load", 0, "Pause scanning while the 1-minute load average of the system exceeds LOAD (Linux only, 0 means no limit)")
+	flags.BoolVar(&af.LowPriority, "low-priority", false, "Lower CPU and I/O priority of the scan to nice 19 and the lowest best-effort I/O priority (Linux only)")
 	flags.BoolVar(&af.SequentialScanning, "sequential", false, "Use sequential scanning (intended for rotating HDDs)")
+	flags.BoolVar(&af.Compact, "compact", false, "Store the analyzed tree in compact form needing less memory (intended for huge trees)")
+	flags.StringVar(&af.MaxMemory, "max-memory", "", "Limit memory used by the analysis to SIZE (e.g., 4G), only totals of directories over the limit are kept (implies --compact)")
 	flags.BoolVarP(&af.ShowVersion, "version", "v", false, "Print version")
 
 	flags.StringSliceVarP(&af.TypeFilter, "type", "T", []string{}, "File types to include (e.g., --type yaml,json)")
@@ -61,8 +141,13 @@ func init() {
 	flags.StringSliceVarP(&af.IgnoreDirPatterns, "ignore-dirs-pattern", "I", []string{},
 		"Path patterns to ignore (separated by comma)")
 	flags.StringVarP(&af.IgnoreFromFile, "ignore-from", "X", "",
-		"Read path patterns to ignore from file")
+		"Read path patterns (regular expressions) to ignore from file")
+	flags.StringSliceVar(&af.IgnoreFiles, "ignore-file", []string{},
+		"Read gitignore-style patterns of files and dirs to ignore from file (relative to the scanned directory)")
+	flags.BoolVar(&af.UseIgnoreFiles, "use-ignore-files", false,
+		"Apply .gitignore and .gduignore files found in the scanned directories")
 	flags.BoolVarP(&af.NoHidden, "no-hidden", "H", false, "Ignore hidden directories (beginning with dot)")
+	flags.BoolVar(&af.IncludeSnapshots, "include-snapshots", false, "Scan directories holding btrfs or zfs snapshots (.snapshots, .zfs) which are skipped by default")
 	flags.BoolVarP(
 		&af.FollowSymlinks, "follow-symlinks", "L", false,
 		"Follow symlinks for files, i.e. show the size of the file to which symlink points to (symlinks to directories are not followed)",
@@ -72,20 +157,28 @@ func init() {
 		"Use apparent size of git-annex'ed files in case files are not present locally (real usage is zero)",
 	)
 	flags.BoolVarP(&af.NoCross, "no-cross", "x", false, "Do not cross filesystem boundaries")
+	flags.BoolVar(&af.SkipNetwork, "skip-network", false, "Do not scan network filesystems (nfs, cifs, sshfs, ...) mounted in the scanned directory")
 	flags.BoolVar(&af.Profiling, "enable-profiling", false, "Enable collection of profiling data and provide it on http://localhost:6060/debug/pprof/")
 
 	flags.StringVarP(&af.DbPath, "db", "D", "", "Store analysis in database (*.sqlite for SQLite, *.badger for BadgerDB)")
 	flags.BoolVarP(&af.ReadFromStorage, "read-from-storage", "r", false, "Use existing database instead of re-scanning")
+	flags.BoolVar(&af.History, "history", false, "Keep previous scans in the SQLite database (see gdu forecast and gdu scans)")
+	flags.IntVar(&af.KeepScans, "keep-scans", 10, "Number of scans kept in the SQLite database with --history (0 keeps all)")
+	flags.StringVar(&af.At, "at", "", "Browse the last scan stored in the history before WHEN (RFC3339 or YYYY-MM-DD), requires --read-from-storage")
 	flags.BoolVar(&af.ArchiveBrowsing, "archive-browsing", false, "Enable browsing of zip/jar/tar archives (tar, tar.gz, tar.bz2, tar.xz)")
+	flags.BoolVar(&af.SharedUsage, "shared-usage", false, "Show disk usage in extents shared with other files (reflinks, deduplication, snapshots), Linux only")
 	flags.BoolVar(&af.CollapsePath, "collapse-path", false, "Collapse single-child directory chains")
 	flags.BoolVar(&af.ShowSymlinkTarget, "show-symlink-target", false, "Show symlink target (name -> target) in the file list")
 
 	flags.BoolVarP(&af.ShowDisks, "show-disks", "d", false, "Show all mounted disks")
+	flags.BoolVar(&af.DevicesLocalOnly, "devices-local-only", false, "Show only filesystems on local block devices in the list of mounted disks")
 	flags.BoolVarP(&af.ShowApparentSize, "show-apparent-size", "a", false, "Show apparent size")
 	flags.BoolVarP(&af.ShowRelativeSize, "show-relative-size", "B", false, "Show relative size")
 	flags.BoolVarP(&af.NoColor, "no-color", "c", false, "Do not use colorized output")
 	flags.BoolVarP(&af.ShowItemCount, "show-item-count", "C", false, "Show number of items in directory")
 	flags.BoolVarP(&af.ShowMTime, "show-mtime", "M", false, "Show latest mtime of items in directory")
+	flags.BoolVar(&af.ShowInodes, "show-inodes", false, "Rank items by number of used inodes instead of size")
+	flags.BoolVar(&af.GitStatus, "git-status", false, "Show usage of tracked, ignored and untracked files of git repositories (interactive mode)")
 	flags.BoolVarP(&af.NonInteractive, "non-interactive", "n", false, "Do not run in interactive mode")
 	flags.BoolVar(&af.Interactive, "interactive", false, "Force interactive mode even when output is not a TTY")
 	flags.BoolVarP(&af.NoProgress, "no-progress", "p", false, "Do not show progress in non-interactive mode")
@@ -111,12 +204,48 @@ func init() {
 	flags.StringVar(&af.Until, "until", "", "Include files with mtime <= WHEN. WHEN accepts RFC3339 timestamp or date only YYYY-MM-DD")
 	flags.StringVar(&af.MaxAge, "max-age", "", "Include files with mtime no older than DURATION (e.g., 7d, 2h30m, 1y2mo)")
 	flags.StringVar(&af.MinAge, "min-age", "", "Include files with mtime at least DURATION old (e.g., 30d, 1w)")
+	flags.StringVar(&af.MinSize, "min-size", "", "Include only files with apparent size at least SIZE (e.g., 100M, 1.5G)")
+	flags.StringVar(&af.MaxSize, "max-size", "", "Include only files with apparent size at most SIZE (e.g., 4K)")
+	flags.StringSliceVar(
+		&af.FileModes, "file-mode", []string{},
+		"Include only files of any of the modes (executable, setuid, setgid, socket, fifo, symlink, device)",
+	)
+	flags.IntVar(&af.MaxDepth, "max-depth", 0, "Do not scan directories more than N levels below the scanned directory (0 means unlimited)")
+
+	flags.IntVar(&af.ShredPasses, "shred-passes", remove.DefaultShredPasses, "Number of times file contents are overwritten by the shred action")
+	flags.BoolVar(&af.ShredForce, "shred-force", false, "Allow shredding on copy-on-write filesystems where overwriting is not effective and of hard linked files")
+	flags.StringVar(&af.CompressFormat, "compress-format", "", "Format used for compressing items in place in interactive mode (zstd or gzip, default zstd)")
+	flags.BoolVar(&af.ShowTrash, "trash", false, "List items in the trash with their original paths and exit")
+	flags.StringVar(&af.LoadPlan, "load-plan", "", "Load cleanup plan from file and mark the planned items")
+	flags.StringVar(&af.ApplyPlan, "apply-plan", "", "Review cleanup plan from file and apply it after confirmation")
+	flags.BoolVarP(&af.Yes, "yes", "y", false, "Do not ask for confirmation when applying a cleanup plan")
+	flags.StringVar(&af.AuditFile, "audit-file", "", "Append a record of every deleted, emptied or trashed item to file")
+	flags.BoolVar(&af.DryRun, "dry-run", false, "Do not remove anything, only record what would be removed to the audit file")
 
 	flags.BoolVar(&af.Web, "web", false, "Run the web UI (serves a browser interface instead of the terminal UI)")
 	flags.StringVar(&af.WebConfig.Listen, "web-listen", "",
 		"Address for the web UI to listen on (default: localhost with a random free port)")
 	flags.BoolVar(&af.WebConfig.OpenBrowser, "web-open", true, "Open the web UI in the default browser on start")
 
+	forecastFlags := forecastCmd.Flags()
+	forecastFlags.StringVarP(&af.DbPath, "db", "D", "", "SQLite database with history of scans (created by gdu --db *.sqlite --history)")
+	forecastFlags.IntVarP(&af.Top, "top", "t", 0, "Show only top X fastest growing directories (default 10)")
+	forecastFlags.BoolVarP(&af.NoColor, "no-color", "c", false, "Do not use colorized output")
+	forecastFlags.StringVarP(&af.LogFile, "log-file", "l", "/dev/null", "Path to a logfile")
+	rootCmd.AddCommand(forecastCmd)
+
+	scansFlags := scansCmd.Flags()
+	scansFlags.StringVarP(&af.DbPath, "db", "D", "", "SQLite database with history of scans (created by gdu --db *.sqlite --history)")
+	scansFlags.StringVarP(&af.LogFile, "log-file", "l", "/dev/null", "Path to a logfile")
+	rootCmd.AddCommand(scansCmd)
+
+	queryFlags := queryCmd.Flags()
+	queryFlags.StringVarP(&af.DbPath, "db", "D", "", "SQLite database with the analysis (created by gdu --db *.sqlite)")
+	queryFlags.StringVar(&af.QueryFormat, "format", "table", "Output format (table, csv or json)")
+	queryFlags.IntVarP(&af.Top, "top", "t", 0, "Show only top X largest items matching the filter expression")
+	queryFlags.StringVarP(&af.LogFile, "log-file", "l", "/dev/null", "Path to a logfile")
+	rootCmd.AddCommand(queryCmd)
+
 	initConfig()
 	setDefaults()
 }
@@ -280,6 +409,7 @@ func runE(command *cobra.Command, args []string) error {
 		Args:        args,
 		Istty:       istty,
 		Writer:      os.Stdout,
+		Input:       os.Stdin,
 		TermApp:     termApp,
 		Screen:      screen,
 		Getter:      device.Getter,
@@ -290,6 +420,16 @@ func runE(command *cobra.Command, args []string) error {
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
+	}
+}
+
+// exitCode prints the error and returns the exit status for it.
+// Partial results are signaled only by the exit status.
+func exitCode(err error) int {
+	if errors.Is(err, app.ErrPartialResults) {
+		return exitPartialResults
 	}
+	rootCmd.PrintErrln(rootCmd.ErrPrefix(), err.Error())
+	return 1
 }
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -166,3 +169,45 @@ func TestInitConfigMalformedUserConfig(t *testing.T) {
 		t.Fatal("expected configErr to be set for malformed user config, got nil")
 	}
 }
+
+func TestDirNamedLikeCommandGivenAsPath(t *testing.T) {
+	for _, name := range []string{"forecast", "query", "scans"} {
+		cmd, _, err := rootCmd.Find([]string{name})
+		if err != nil {
+			t.Fatalf("finding command %s: %v", name, err)
+		}
+		if cmd.Name() != name {
+			t.Fatalf("expected %s to run the command, got %s", name, cmd.Name())
+		}
+
+		path := "./" + name
+		cmd, args, err := rootCmd.Find([]string{path})
+		if err != nil {
+			t.Fatalf("finding command for %s: %v", path, err)
+		}
+		if cmd != rootCmd || len(args) != 1 || args[0] != path {
+			t.Fatalf("expected %s to be scanned, got command %s with %v", path, cmd.Name(), args)
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	var buff bytes.Buffer
+	rootCmd.SetErr(&buff)
+	defer rootCmd.SetErr(nil)
+
+	partial := fmt.Errorf("%w: 2 items could not be read", app.ErrPartialResults)
+	if code := exitCode(partial); code != exitPartialResults {
+		t.Fatalf("expected exit status %d for partial results, got %d", exitPartialResults, code)
+	}
+	if buff.Len() != 0 {
+		t.Fatalf("expected no output for partial results, got %q", buff.String())
+	}
+
+	if code := exitCode(errors.New("no such dir")); code != 1 {
+		t.Fatalf("expected exit status 1 for error, got %d", code)
+	}
+	if buff.String() != "Error: no such dir\n" {
+		t.Fatalf("expected error to be printed, got %q", buff.String())
+	}
+}
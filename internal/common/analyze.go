@@ -3,9 +3,11 @@
 package common
 
 import (
+	"os"
 	"time"
 
 	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/ignore"
 )
 
 // CurrentProgress struct
@@ -13,6 +15,10 @@ type CurrentProgress struct {
 	CurrentItemName string
 	ItemCount       int64
 	TotalUsage      int64
+	// IOWorkers is the current limit of directories read at once, 0 if not limited
+	IOWorkers int
+	// Throttle describes the current throttling of the scan, empty if not throttled
+	Throttle string
 }
 
 // ShouldDirBeIgnored whether path should be ignored
@@ -28,7 +34,13 @@ type Analyzer interface {
 	SetShowAnnexedSize(bool)
 	SetTimeFilter(timeFilter TimeFilter)
 	SetArchiveBrowsing(bool)
+	SetSharedUsage(bool)
 	SetFileTypeFilter(filter ShouldFileBeIgnored)
+	SetFileFilter(filter FileFilter)
+	SetMaxDepth(depth int)
+	SetIgnorePatterns(patterns []ignore.Pattern, useIgnoreFiles bool)
+	SetThrottle(throttle Throttle)
+	SetIOLimiter(limiter IOLimiter)
 	Cancel()
 	GetDone() SignalGroup
 	GetProgress() CurrentProgress
@@ -37,3 +49,28 @@ type Analyzer interface {
 
 // TimeFilter represents a function that determines if a file should be included based on its mtime
 type TimeFilter func(mtime time.Time) bool
+
+// Throttle limits the impact of the scan on the host
+type Throttle interface {
+	// Wait blocks while the scan should be paused, unless it is cancelled
+	Wait(cancelled func() bool)
+	// Charge counts the stats of files of the read dir
+	Charge(files int)
+	// State describes the current throttling, empty if the scan is not throttled
+	State() string
+}
+
+// IOLimiter limits the number of directories read at once
+type IOLimiter interface {
+	// Acquire blocks until another directory can be read
+	Acquire()
+	// Release ends the read of the directory with the number of items which took latency
+	Release(latency time.Duration, items int)
+	// Limit returns the current number of directories read at once
+	Limit() int
+	// MaxLimit returns the highest number of directories which can be ever read at once
+	MaxLimit() int
+}
+
+// FileFilter represents a function that determines if a file should be included based on its size or mode
+type FileFilter func(info os.FileInfo) bool
@@ -0,0 +1,12 @@
+//go:build windows || plan9
+
+package common
+
+import (
+	"strconv"
+	"syscall"
+)
+
+func errnoName(errno syscall.Errno) string {
+	return "errno " + strconv.FormatUint(uint64(errno), 10)
+}
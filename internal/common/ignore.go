@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/dundee/gdu/v5/pkg/ignore"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -87,6 +88,29 @@ func (ui *UI) SetIgnoreFromFile(ignoreFile string) error {
 	return err
 }
 
+// SetIgnoreFiles sets patterns in gitignore format read from the files to ignore files and dirs.
+// The patterns are applied relative to the scanned directory.
+// If useDirFiles is set, .gitignore and .gduignore files found in the scanned directories are applied as well.
+func (ui *UI) SetIgnoreFiles(ignoreFiles []string, useDirFiles bool) error {
+	var patterns []ignore.Pattern
+	for _, ignoreFile := range ignoreFiles {
+		log.Printf("Reading ignore patterns from file '%s'", ignoreFile)
+		filePatterns, err := ignore.ReadFile(ignoreFile)
+		if err != nil {
+			return err
+		}
+		patterns = append(patterns, filePatterns...)
+	}
+	if useDirFiles {
+		log.Printf("Applying ignore files %s found in scanned dirs", strings.Join(ignore.FileNames, ", "))
+	}
+
+	ui.ignorePatterns = patterns
+	ui.useIgnoreFiles = useDirFiles
+	ui.Analyzer.SetIgnorePatterns(patterns, useDirFiles)
+	return nil
+}
+
 // SetIgnoreTypes sets file types to ignore
 func (ui *UI) SetIgnoreTypes(types []string) {
 	log.Printf("Ignoring file types: %s", strings.Join(types, ", "))
@@ -105,6 +129,14 @@ func (ui *UI) SetIgnoreHidden(value bool) {
 	ui.IgnoreHidden = value
 }
 
+// SetIgnoreSnapshots sets if directories holding btrfs or zfs snapshots should be ignored
+func (ui *UI) SetIgnoreSnapshots(value bool) {
+	if value {
+		log.Printf("Ignoring snapshot dirs")
+	}
+	ui.IgnoreSnapshots = value
+}
+
 // ShouldDirBeIgnored returns true if given path should be ignored
 func (ui *UI) ShouldDirBeIgnored(name, path string) bool {
 	_, shouldIgnore := ui.IgnoreDirPaths[path]
@@ -132,6 +164,15 @@ func (ui *UI) IsHiddenDir(name, path string) bool {
 	return shouldIgnore
 }
 
+// IsSnapshotDir returns if the dir holds snapshots (.snapshots of snapper, .zfs of zfs)
+func (ui *UI) IsSnapshotDir(name, path string) bool {
+	shouldIgnore := name == ".snapshots" || name == ".zfs"
+	if shouldIgnore {
+		log.Printf("Directory %s ignored", path)
+	}
+	return shouldIgnore
+}
+
 // ShouldFileBeIgnoredByType returns true if file should be ignored based on its extension
 func (ui *UI) ShouldFileBeIgnoredByType(name string) bool {
 	if len(ui.IgnoreTypes) == 0 {
@@ -184,8 +225,18 @@ func (ui *UI) ShouldFileBeIncludedByType(name string) bool {
 }
 
 // CreateIgnoreFunc returns function for detecting if dir should be ignored
-// nolint: gocyclo // Why: This function is a switch statement that is not too complex
 func (ui *UI) CreateIgnoreFunc() ShouldDirBeIgnored {
+	ignore := ui.createPathIgnoreFunc()
+	if !ui.IgnoreSnapshots {
+		return ignore
+	}
+	return func(name, path string) bool {
+		return ui.IsSnapshotDir(name, path) || ignore(name, path)
+	}
+}
+
+// nolint: gocyclo // Why: This function is a switch statement that is not too complex
+func (ui *UI) createPathIgnoreFunc() ShouldDirBeIgnored {
 	switch {
 	case len(ui.IgnoreDirPaths) > 0 && ui.IgnoreDirPathPatterns == nil && !ui.IgnoreHidden:
 		return ui.ShouldDirBeIgnored
@@ -117,6 +117,21 @@ func TestIgnoreHidden(t *testing.T) {
 	assert.False(t, shouldBeIgnored("xxx", "/xxx"))
 }
 
+func TestIgnoreSnapshots(t *testing.T) {
+	ui := &common.UI{}
+	ui.SetIgnoreDirPaths([]string{"/abc"})
+	ui.SetIgnoreSnapshots(true)
+	shouldBeIgnored := ui.CreateIgnoreFunc()
+
+	assert.True(t, shouldBeIgnored(".snapshots", "/home/.snapshots"))
+	assert.True(t, shouldBeIgnored(".zfs", "/tank/.zfs"))
+	assert.True(t, shouldBeIgnored("abc", "/abc"))
+	assert.False(t, shouldBeIgnored("snapshots", "/snapshots"))
+
+	ui.SetIgnoreSnapshots(false)
+	assert.False(t, ui.CreateIgnoreFunc()(".snapshots", "/home/.snapshots"))
+}
+
 func TestIgnoreByAbsPathAndHidden(t *testing.T) {
 	ui := &common.UI{}
 	ui.SetIgnoreDirPaths([]string{"/abc"})
@@ -0,0 +1,88 @@
+package common
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"syscall"
+)
+
+// ScanError is an error of reading a directory or a file during the analysis.
+// The item is left out of the analysis (files) or flagged with '!' (directories),
+// so the results of the analysis are partial.
+type ScanError struct {
+	Path string
+	// Op is the operation which failed (e.g. open, readdirent, lstat)
+	Op  string
+	Err error
+}
+
+// ScanErrorReporter is an optional interface of analyzers collecting the errors of the scan
+type ScanErrorReporter interface {
+	GetScanErrors() []ScanError
+}
+
+// NewScanError returns error of reading the path, the operation is taken from the error
+func NewScanError(path string, err error) ScanError {
+	op := "read"
+	var pathErr *os.PathError
+	var syscallErr *os.SyscallError
+	switch {
+	case errors.As(err, &pathErr):
+		op = pathErr.Op
+	case errors.As(err, &syscallErr):
+		op = syscallErr.Syscall
+	}
+	return ScanError{Path: path, Op: op, Err: err}
+}
+
+// Errno returns name of the system error (e.g. EACCES), empty if the error is not a system error
+func (e ScanError) Errno() string {
+	var errno syscall.Errno
+	if !errors.As(e.Err, &errno) {
+		return ""
+	}
+	return errnoName(errno)
+}
+
+// Reason returns the error without the path and the operation (e.g. permission denied)
+func (e ScanError) Reason() string {
+	var errno syscall.Errno
+	if errors.As(e.Err, &errno) {
+		return errno.Error()
+	}
+	var pathErr *os.PathError
+	if errors.As(e.Err, &pathErr) {
+		return pathErr.Err.Error()
+	}
+	return e.Err.Error()
+}
+
+// MarshalJSON encodes the error as an object with the path, the operation, the errno and the reason
+func (e ScanError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Path   string `json:"path"`
+		Op     string `json:"op"`
+		Errno  string `json:"errno,omitempty"`
+		Reason string `json:"error"`
+	}{e.Path, e.Op, e.Errno(), e.Reason()})
+}
+
+// GetScanErrors returns errors of the last scan of the analyzer sorted by path,
+// nil if the analyzer does not collect them
+func (ui *UI) GetScanErrors() []ScanError {
+	reporter, ok := ui.Analyzer.(ScanErrorReporter)
+	if !ok {
+		return nil
+	}
+	return reporter.GetScanErrors()
+}
+
+// SortScanErrors sorts the errors by path
+func SortScanErrors(errs []ScanError) {
+	slices.SortStableFunc(errs, func(a, b ScanError) int {
+		return cmp.Compare(a.Path, b.Path)
+	})
+}
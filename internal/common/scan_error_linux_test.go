@@ -0,0 +1,77 @@
+//go:build linux
+
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type scanErrorsAnalyzer struct {
+	Analyzer
+	errs []ScanError
+}
+
+func (a scanErrorsAnalyzer) GetScanErrors() []ScanError {
+	return a.errs
+}
+
+func TestNewScanError(t *testing.T) {
+	err := &os.PathError{Op: "open", Path: "/a/b", Err: syscall.EACCES}
+	e := NewScanError("/a/b", err)
+
+	assert.Equal(t, "/a/b", e.Path)
+	assert.Equal(t, "open", e.Op)
+	assert.Equal(t, "EACCES", e.Errno())
+	assert.Equal(t, "permission denied", e.Reason())
+}
+
+func TestNewScanErrorFromSyscallError(t *testing.T) {
+	e := NewScanError("/a", os.NewSyscallError("getdents64", syscall.EIO))
+
+	assert.Equal(t, "getdents64", e.Op)
+	assert.Equal(t, "EIO", e.Errno())
+	assert.Equal(t, "input/output error", e.Reason())
+}
+
+func TestNewScanErrorWithoutErrno(t *testing.T) {
+	e := NewScanError("/a", errors.New("broken"))
+
+	assert.Equal(t, "read", e.Op)
+	assert.Empty(t, e.Errno())
+	assert.Equal(t, "broken", e.Reason())
+}
+
+func TestScanErrorMarshalJSON(t *testing.T) {
+	errs := []ScanError{
+		NewScanError("/a", &os.PathError{Op: "lstat", Path: "/a", Err: syscall.ENOENT}),
+		NewScanError("/b", errors.New("broken")),
+	}
+
+	encoded, err := json.Marshal(errs)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"path":"/a","op":"lstat","errno":"ENOENT","error":"no such file or directory"},
+		{"path":"/b","op":"read","error":"broken"}
+	]`, string(encoded))
+}
+
+func TestSortScanErrors(t *testing.T) {
+	errs := []ScanError{{Path: "/b"}, {Path: "/a/c"}, {Path: "/a"}}
+	SortScanErrors(errs)
+
+	assert.Equal(t, []ScanError{{Path: "/a"}, {Path: "/a/c"}, {Path: "/b"}}, errs)
+}
+
+func TestUIGetScanErrors(t *testing.T) {
+	ui := &UI{Analyzer: scanErrorsAnalyzer{errs: []ScanError{{Path: "/a"}}}}
+	assert.Equal(t, []ScanError{{Path: "/a"}}, ui.GetScanErrors())
+
+	ui = &UI{Analyzer: struct{ Analyzer }{}}
+	assert.Nil(t, ui.GetScanErrors())
+}
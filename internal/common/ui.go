@@ -6,6 +6,8 @@ import (
 	"os"
 	"regexp"
 	"strconv"
+
+	"github.com/dundee/gdu/v5/pkg/ignore"
 )
 
 // UI struct
@@ -14,6 +16,7 @@ type UI struct {
 	IgnoreDirPaths        map[string]struct{}
 	IgnoreDirPathPatterns *regexp.Regexp
 	IgnoreHidden          bool
+	IgnoreSnapshots       bool
 	IgnoreTypes           []string
 	IncludeTypes          []string
 	UseColors             bool
@@ -21,9 +24,23 @@ type UI struct {
 	ShowProgress          bool
 	ShowApparentSize      bool
 	ShowRelativeSize      bool
+	ShowInodes            bool
+	ShowSharedUsage       bool
+	InodeCapacity         int64
 	FilteringFiles        bool
 	blockSize             int64
 	blockSuffix           string
+	followSymlinks        bool
+	showAnnexedSize       bool
+	timeFilter            TimeFilter
+	fileFilter            FileFilter
+	maxDepth              int
+	archiveBrowsing       bool
+	sharedUsage           bool
+	ignorePatterns        []ignore.Pattern
+	useIgnoreFiles        bool
+	throttle              Throttle
+	ioLimiter             IOLimiter
 }
 
 // SetAnalyzer sets analyzer instance
@@ -33,25 +50,91 @@ func (ui *UI) SetAnalyzer(a Analyzer) {
 
 // SetFollowSymlinks sets whether symlinks to files should be followed
 func (ui *UI) SetFollowSymlinks(v bool) {
+	ui.followSymlinks = v
 	ui.Analyzer.SetFollowSymlinks(v)
 }
 
 // SetShowAnnexedSize sets whether to use annexed size of git-annex files
 func (ui *UI) SetShowAnnexedSize(v bool) {
+	ui.showAnnexedSize = v
 	ui.Analyzer.SetShowAnnexedSize(v)
 }
 
 // SetTimeFilter sets the time filter function for file inclusion
 func (ui *UI) SetTimeFilter(timeFilter TimeFilter) {
+	ui.timeFilter = timeFilter
 	ui.Analyzer.SetTimeFilter(timeFilter)
 	ui.FilteringFiles = true
 }
 
+// SetFileFilter sets the function selecting files by their size or mode
+func (ui *UI) SetFileFilter(fileFilter FileFilter) {
+	ui.fileFilter = fileFilter
+	ui.Analyzer.SetFileFilter(fileFilter)
+	ui.FilteringFiles = true
+}
+
+// SetMaxDepth sets how many levels of directories below the scanned directory are scanned
+func (ui *UI) SetMaxDepth(depth int) {
+	ui.maxDepth = depth
+	ui.Analyzer.SetMaxDepth(depth)
+}
+
 // SetArchiveBrowsing sets whether browsing of zip/jar archives is enabled
 func (ui *UI) SetArchiveBrowsing(v bool) {
+	ui.archiveBrowsing = v
 	ui.Analyzer.SetArchiveBrowsing(v)
 }
 
+// SetSharedUsage sets whether usage in extents shared with other files (reflinks, dedup) is found out and shown
+func (ui *UI) SetSharedUsage(v bool) {
+	ui.sharedUsage = v
+	ui.ShowSharedUsage = v
+	ui.Analyzer.SetSharedUsage(v)
+}
+
+// SetThrottle sets the limits of the impact of the scan on the host
+func (ui *UI) SetThrottle(throttle Throttle) {
+	ui.throttle = throttle
+	ui.Analyzer.SetThrottle(throttle)
+}
+
+// SetIOLimiter sets the limit of directories read at once
+func (ui *UI) SetIOLimiter(limiter IOLimiter) {
+	ui.ioLimiter = limiter
+	ui.Analyzer.SetIOLimiter(limiter)
+}
+
+// ConfigureAnalyzer applies the analyzer settings of the UI to another analyzer,
+// e.g. one scanning in background
+func (ui *UI) ConfigureAnalyzer(a Analyzer) {
+	a.SetFollowSymlinks(ui.followSymlinks)
+	a.SetShowAnnexedSize(ui.showAnnexedSize)
+	a.SetTimeFilter(ui.timeFilter)
+	a.SetFileFilter(ui.fileFilter)
+	a.SetMaxDepth(ui.maxDepth)
+	a.SetArchiveBrowsing(ui.archiveBrowsing)
+	a.SetSharedUsage(ui.sharedUsage)
+	a.SetIgnorePatterns(ui.ignorePatterns, ui.useIgnoreFiles)
+	a.SetThrottle(ui.throttle)
+	a.SetIOLimiter(ui.ioLimiter)
+}
+
+// SetShowInodes ranks items by the number of inodes they use (item count) instead of size.
+// Capacity is the total number of inodes of the filesystem, zero when unknown.
+func (ui *UI) SetShowInodes(capacity int64) {
+	ui.ShowInodes = true
+	ui.InodeCapacity = capacity
+}
+
+// InodePercent returns part of the inode capacity of the filesystem used by count inodes
+func (ui *UI) InodePercent(count int64) (float64, bool) {
+	if ui.InodeCapacity <= 0 {
+		return 0, false
+	}
+	return float64(count) / float64(ui.InodeCapacity) * 100, true
+}
+
 // SetBlockSizeFromEnvironment applies the BLOCK_SIZE or BLOCKSIZE output format.
 func (ui *UI) SetBlockSizeFromEnvironment() {
 	value, ok := os.LookupEnv("BLOCK_SIZE")
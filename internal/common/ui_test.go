@@ -4,9 +4,12 @@ package common
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/ignore"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -42,6 +45,67 @@ func TestSetEnableArchiveBrowsing(t *testing.T) {
 	assert.Equal(t, true, ui.Analyzer.(*MockedAnalyzer).ArchiveBrowsing)
 }
 
+func TestConfigureAnalyzer(t *testing.T) {
+	ui := UI{
+		Analyzer: &MockedAnalyzer{},
+	}
+	ui.SetFollowSymlinks(true)
+	ui.SetArchiveBrowsing(true)
+	ui.SetSharedUsage(true)
+	ui.SetMaxDepth(3)
+	ui.SetFileFilter(func(os.FileInfo) bool { return true })
+	ui.SetThrottle(mockedThrottle{})
+	ui.SetIOLimiter(mockedIOLimiter{})
+	assert.Nil(t, ui.SetIgnoreFiles(nil, true))
+
+	other := &MockedAnalyzer{}
+	ui.ConfigureAnalyzer(other)
+
+	assert.True(t, other.FollowSymlinks)
+	assert.False(t, other.ShowAnnexedSize)
+	assert.True(t, other.ArchiveBrowsing)
+	assert.True(t, other.SharedUsage)
+	assert.True(t, other.UseIgnoreFiles)
+	assert.Equal(t, 3, other.MaxDepth)
+	assert.NotNil(t, other.FileFilter)
+	assert.Equal(t, mockedThrottle{}, other.Throttle)
+	assert.Equal(t, mockedIOLimiter{}, other.IOLimiter)
+	assert.True(t, ui.ShowSharedUsage)
+	assert.True(t, ui.IsFilteringFiles())
+}
+
+func TestSetIgnoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first")
+	second := filepath.Join(dir, "second")
+	assert.Nil(t, os.WriteFile(first, []byte("# comment\n*.log\n"), 0o600))
+	assert.Nil(t, os.WriteFile(second, []byte("build/\n!keep.log\n"), 0o600))
+
+	ui := UI{
+		Analyzer: &MockedAnalyzer{},
+	}
+	assert.Nil(t, ui.SetIgnoreFiles([]string{first, second}, false))
+
+	analyzer := ui.Analyzer.(*MockedAnalyzer)
+	assert.Len(t, analyzer.IgnorePatterns, 3)
+	assert.False(t, analyzer.UseIgnoreFiles)
+
+	assert.NotNil(t, ui.SetIgnoreFiles([]string{filepath.Join(dir, "missing")}, true))
+}
+
+func TestSetShowInodes(t *testing.T) {
+	ui := UI{}
+	_, ok := ui.InodePercent(10)
+	assert.False(t, ok)
+
+	ui.SetShowInodes(1000)
+	assert.True(t, ui.ShowInodes)
+
+	percent, ok := ui.InodePercent(10)
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, percent)
+}
+
 func TestSetAnalyzer(t *testing.T) {
 	ui := UI{}
 	a := &MockedAnalyzer{}
@@ -56,10 +120,50 @@ func TestSetTimeFilter(t *testing.T) {
 	})
 }
 
+type mockedThrottle struct{}
+
+func (mockedThrottle) Wait(func() bool) {}
+func (mockedThrottle) Charge(int)       {}
+func (mockedThrottle) State() string    { return "" }
+
+type mockedIOLimiter struct{}
+
+func (mockedIOLimiter) Acquire()                   {}
+func (mockedIOLimiter) Release(time.Duration, int) {}
+func (mockedIOLimiter) Limit() int                 { return 1 }
+func (mockedIOLimiter) MaxLimit() int              { return 1 }
+
 type MockedAnalyzer struct {
 	FollowSymlinks  bool
 	ShowAnnexedSize bool
 	ArchiveBrowsing bool
+	SharedUsage     bool
+	IgnorePatterns  []ignore.Pattern
+	UseIgnoreFiles  bool
+	FileFilter      FileFilter
+	MaxDepth        int
+	Throttle        Throttle
+	IOLimiter       IOLimiter
+}
+
+// SetFileFilter sets FileFilter
+func (a *MockedAnalyzer) SetFileFilter(filter FileFilter) {
+	a.FileFilter = filter
+}
+
+// SetMaxDepth sets MaxDepth
+func (a *MockedAnalyzer) SetMaxDepth(depth int) {
+	a.MaxDepth = depth
+}
+
+// SetThrottle sets Throttle
+func (a *MockedAnalyzer) SetThrottle(throttle Throttle) {
+	a.Throttle = throttle
+}
+
+// SetIOLimiter sets IOLimiter
+func (a *MockedAnalyzer) SetIOLimiter(limiter IOLimiter) {
+	a.IOLimiter = limiter
 }
 
 // SetFileTypeFilter sets the file type filter function
@@ -109,6 +213,17 @@ func (a *MockedAnalyzer) SetArchiveBrowsing(v bool) {
 	a.ArchiveBrowsing = v
 }
 
+// SetSharedUsage sets SharedUsage
+func (a *MockedAnalyzer) SetSharedUsage(v bool) {
+	a.SharedUsage = v
+}
+
+// SetIgnorePatterns sets IgnorePatterns and UseIgnoreFiles
+func (a *MockedAnalyzer) SetIgnorePatterns(patterns []ignore.Pattern, useIgnoreFiles bool) {
+	a.IgnorePatterns = patterns
+	a.UseIgnoreFiles = useIgnoreFiles
+}
+
 func TestSetBlockSizeFromEnvironment(t *testing.T) {
 	t.Run("BLOCK_SIZE takes precedence", func(t *testing.T) {
 		t.Setenv("BLOCK_SIZE", "1K")
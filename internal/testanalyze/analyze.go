@@ -8,12 +8,15 @@ import (
 	"github.com/dundee/gdu/v5/internal/common"
 	"github.com/dundee/gdu/v5/pkg/analyze"
 	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/ignore"
 	"github.com/dundee/gdu/v5/pkg/remove"
 )
 
 // MockedAnalyzer returns dir with files with different size exponents
 type MockedAnalyzer struct {
 	cancelled atomic.Bool
+	// ScanErrors are returned as the errors of the scan
+	ScanErrors []common.ScanError
 }
 
 // AnalyzeDir returns dir with files with different size exponents
@@ -74,6 +77,11 @@ func (a *MockedAnalyzer) GetProgress() common.CurrentProgress {
 	return common.CurrentProgress{}
 }
 
+// GetScanErrors returns the errors set in the mock
+func (a *MockedAnalyzer) GetScanErrors() []common.ScanError {
+	return a.ScanErrors
+}
+
 // GetDone returns always Done
 func (a *MockedAnalyzer) GetDone() common.SignalGroup {
 	c := make(common.SignalGroup)
@@ -108,9 +116,27 @@ func (a *MockedAnalyzer) SetTimeFilter(timeFilter common.TimeFilter) {}
 // SetArchiveBrowsing does nothing
 func (a *MockedAnalyzer) SetArchiveBrowsing(v bool) {}
 
+// SetSharedUsage does nothing
+func (a *MockedAnalyzer) SetSharedUsage(v bool) {}
+
+// SetFileFilter does nothing
+func (a *MockedAnalyzer) SetFileFilter(filter common.FileFilter) {}
+
+// SetThrottle does nothing
+func (a *MockedAnalyzer) SetThrottle(throttle common.Throttle) {}
+
+// SetIOLimiter does nothing
+func (a *MockedAnalyzer) SetIOLimiter(limiter common.IOLimiter) {}
+
+// SetMaxDepth does nothing
+func (a *MockedAnalyzer) SetMaxDepth(depth int) {}
+
 // SetFileTypeFilter does nothing
 func (a *MockedAnalyzer) SetFileTypeFilter(fileTypeFilter common.ShouldFileBeIgnored) {}
 
+// SetIgnorePatterns does nothing
+func (a *MockedAnalyzer) SetIgnorePatterns(patterns []ignore.Pattern, useIgnoreFiles bool) {}
+
 // ItemFromDirWithErr returns error
 func ItemFromDirWithErr(dir, file fs.Item) error {
 	return errors.New("Failed")
@@ -1,11 +1,18 @@
 package analyze
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/dundee/gdu/v5/internal/common"
 	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/ignore"
+	log "github.com/sirupsen/logrus"
 )
 
 // BaseAnalyzer provides common logic for all analyzers
@@ -24,8 +31,19 @@ type BaseAnalyzer struct {
 	followSymlinks          bool
 	gitAnnexedSize          bool
 	matchesTimeFilterFn     common.TimeFilter
+	fileFilter              common.FileFilter
+	maxDepth                int
+	rootPath                string
 	archiveBrowsing         bool
+	sharedUsage             bool
+	ignorePatterns          []ignore.Pattern
+	useIgnoreFiles          bool
+	throttle                common.Throttle
+	ioLimiter               common.IOLimiter
+	concurrencyLimit        chan struct{}
 	progressTicker          *time.Ticker
+	scanErrors              []common.ScanError
+	scanErrorsMu            sync.Mutex
 }
 
 // Init initializes the BaseAnalyzer
@@ -40,6 +58,29 @@ func (a *BaseAnalyzer) Init() {
 	a.currentDir.Store((*Dir)(nil))
 	a.cancelled.Store(false)
 	a.progressTicker = time.NewTicker(50 * time.Millisecond)
+	if a.concurrencyLimit == nil {
+		a.concurrencyLimit = make(chan struct{}, defaultConcurrency())
+	}
+	a.scanErrorsMu.Lock()
+	a.scanErrors = nil
+	a.scanErrorsMu.Unlock()
+}
+
+// addScanError logs the error of reading the path and keeps it among the errors of the scan
+func (a *BaseAnalyzer) addScanError(path string, err error) {
+	log.Print(err.Error())
+	a.scanErrorsMu.Lock()
+	a.scanErrors = append(a.scanErrors, common.NewScanError(path, err))
+	a.scanErrorsMu.Unlock()
+}
+
+// GetScanErrors returns the errors of reading directories and files during the last scan sorted by path
+func (a *BaseAnalyzer) GetScanErrors() []common.ScanError {
+	a.scanErrorsMu.Lock()
+	errs := slices.Clone(a.scanErrors)
+	a.scanErrorsMu.Unlock()
+	common.SortScanErrors(errs)
+	return errs
 }
 
 // setCurrentDir stores the root directory currently being analyzed so it can be
@@ -74,16 +115,100 @@ func (a *BaseAnalyzer) SetTimeFilter(matchesTimeFilterFn common.TimeFilter) {
 	a.matchesTimeFilterFn = matchesTimeFilterFn
 }
 
+// SetFileFilter sets the function selecting files by their size or mode
+func (a *BaseAnalyzer) SetFileFilter(filter common.FileFilter) {
+	a.fileFilter = filter
+}
+
+// SetMaxDepth sets how many levels of directories below the scanned directory are scanned, zero means unlimited
+func (a *BaseAnalyzer) SetMaxDepth(depth int) {
+	a.maxDepth = depth
+}
+
+// includeFile returns true if the file matches the time and file filters
+func (a *BaseAnalyzer) includeFile(info os.FileInfo) bool {
+	if a.matchesTimeFilterFn != nil && !a.matchesTimeFilterFn(info.ModTime()) {
+		return false
+	}
+	return a.fileFilter == nil || a.fileFilter(info)
+}
+
+// tooDeep returns true if the directory is more levels below the scanned directory than allowed
+func (a *BaseAnalyzer) tooDeep(path string) bool {
+	if a.maxDepth <= 0 {
+		return false
+	}
+	rel, err := filepath.Rel(a.rootPath, path)
+	if err != nil {
+		return false
+	}
+	return strings.Count(rel, string(filepath.Separator))+1 > a.maxDepth
+}
+
 // SetArchiveBrowsing sets whether browsing of zip/jar/tar archives is enabled
 func (a *BaseAnalyzer) SetArchiveBrowsing(v bool) {
 	a.archiveBrowsing = v
 }
 
+// SetSharedUsage sets whether usage in extents shared with other files should be found out
+func (a *BaseAnalyzer) SetSharedUsage(v bool) {
+	a.sharedUsage = v
+}
+
+// setSharedUsage sets usage of the regular file in extents shared with other files (reflinks, dedup)
+func (a *BaseAnalyzer) setSharedUsage(file *File, info os.FileInfo, path string) {
+	if !a.sharedUsage || !info.Mode().IsRegular() || file.Usage == 0 {
+		return
+	}
+	shared, err := getSharedUsage(path)
+	if err != nil {
+		log.Printf("Reading extents of %s failed: %s", path, err)
+		return
+	}
+	file.Shared = min(shared, file.Usage)
+}
+
+// SetThrottle sets the limits of the impact of the scan on the host, nil means no limits
+func (a *BaseAnalyzer) SetThrottle(throttle common.Throttle) {
+	a.throttle = throttle
+}
+
+func (a *BaseAnalyzer) throttleState() string {
+	if a.throttle == nil {
+		return ""
+	}
+	return a.throttle.State()
+}
+
 // SetFileTypeFilter sets the file type filter function
 func (a *BaseAnalyzer) SetFileTypeFilter(filter common.ShouldFileBeIgnored) {
 	a.ignoreFileType = filter
 }
 
+// SetIgnorePatterns sets patterns in gitignore format applied below the scanned directory
+// and whether ignore files found in the scanned directories (.gitignore, .gduignore) are applied as well
+func (a *BaseAnalyzer) SetIgnorePatterns(patterns []ignore.Pattern, useIgnoreFiles bool) {
+	a.ignorePatterns = patterns
+	a.useIgnoreFiles = useIgnoreFiles
+}
+
+// rootIgnoreRules returns the ignore rules applied to the items of the scanned directory
+func (a *BaseAnalyzer) rootIgnoreRules(path string) *ignore.Rules {
+	return ignore.New(path, a.ignorePatterns)
+}
+
+// dirIgnoreRules adds patterns of the ignore files found among the entries of the directory to the rules
+func (a *BaseAnalyzer) dirIgnoreRules(rules *ignore.Rules, path string, files []os.DirEntry) *ignore.Rules {
+	if !a.useIgnoreFiles {
+		return rules
+	}
+	rules, err := rules.AddDirFiles(path, files)
+	if err != nil {
+		log.Print(err.Error())
+	}
+	return rules
+}
+
 // GetDone returns channel for checking when analysis is done
 func (a *BaseAnalyzer) GetDone() common.SignalGroup {
 	return a.doneChan
@@ -100,8 +225,8 @@ func (a *BaseAnalyzer) IsCancelled() bool {
 	return a.cancelled.Load()
 }
 
-func (a *BaseAnalyzer) shouldSkipDir(name, path string) bool {
-	return a.ignoreDir(name, path) || a.IsCancelled()
+func (a *BaseAnalyzer) shouldSkipDir(rules *ignore.Rules, name, path string) bool {
+	return a.ignoreDir(name, path) || rules.Match(path, true) || a.tooDeep(path) || a.IsCancelled()
 }
 
 // ResetProgress prepares the analyzer for a new scan. Call it only after the
@@ -118,6 +243,8 @@ func (a *BaseAnalyzer) GetProgress() common.CurrentProgress {
 		CurrentItemName: a.progressCurrentItemName.Load().(string),
 		ItemCount:       a.progressItemCount.Load(),
 		TotalUsage:      a.progressTotalUsage.Load(),
+		IOWorkers:       a.ioWorkers(),
+		Throttle:        a.throttleState(),
 	}
 }
 
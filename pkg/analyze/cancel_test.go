@@ -38,25 +38,25 @@ func TestParallelAnalyzersCancelQueuedDirectories(t *testing.T) {
 	root := createCancellationTree(t)
 	tests := []struct {
 		name    string
-		factory func() common.Analyzer
+		factory func() (common.Analyzer, *BaseAnalyzer)
 	}{
-		{name: "parallel", factory: func() common.Analyzer { return CreateAnalyzer() }},
-		{name: "stable parallel", factory: func() common.Analyzer { return CreateStableOrderAnalyzer() }},
+		{name: "parallel", factory: func() (common.Analyzer, *BaseAnalyzer) {
+			a := CreateAnalyzer()
+			return a, &a.BaseAnalyzer
+		}},
+		{name: "stable parallel", factory: func() (common.Analyzer, *BaseAnalyzer) {
+			a := CreateStableOrderAnalyzer()
+			return a, &a.BaseAnalyzer
+		}},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			initialSlots := len(concurrencyLimit)
-			for len(concurrencyLimit) < cap(concurrencyLimit) {
-				concurrencyLimit <- struct{}{}
+			analyzer, base := test.factory()
+			for len(base.concurrencyLimit) < cap(base.concurrencyLimit) {
+				base.concurrencyLimit <- struct{}{}
 			}
-			defer func() {
-				for len(concurrencyLimit) > initialSlots {
-					<-concurrencyLimit
-				}
-			}()
 
-			analyzer := test.factory()
 			result := make(chan fs.Item, 1)
 			go func() {
 				result <- analyzer.AnalyzeDir(root, func(_, _ string) bool { return false }, nil)
@@ -66,7 +66,7 @@ func TestParallelAnalyzersCancelQueuedDirectories(t *testing.T) {
 				return analyzer.GetProgress().CurrentItemName == root
 			}, time.Second, time.Millisecond)
 			analyzer.Cancel()
-			<-concurrencyLimit
+			<-base.concurrencyLimit
 
 			select {
 			case dir := <-result:
@@ -128,7 +128,7 @@ func TestTopDirAnalyzerCancellationStopsActiveSubdirectory(t *testing.T) {
 	analyzer.Cancel()
 	result := &TopDir{}
 
-	analyzer.processSubDir(root, result)
+	analyzer.processSubDir(root, nil, result)
 
 	size, usage, itemCount := result.GetUsage()
 	assert.Zero(t, size)
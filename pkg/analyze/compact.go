@@ -0,0 +1,247 @@
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/ignore"
+)
+
+var _ common.Analyzer = (*CompactAnalyzer)(nil)
+
+// CompactAnalyzer stores the analyzed tree in arena-allocated compact nodes
+// needing about CompactBytesPerItem bytes per item.
+// When the tree reaches the memory limit, directories read later are stored
+// only with their totals (flagged with CollapsedDirFlag), their items are not kept.
+// Archives are not browsed and targets of symlinks and shared usage are not kept in this mode.
+type CompactAnalyzer struct {
+	BaseAnalyzer
+	maxMemory    int64
+	tree         atomic.Pointer[compactTree]
+	linkedInodes *sync.Map
+}
+
+// dirTotals are totals of a dir whose items are not stored
+type dirTotals struct {
+	itemCount int64
+	size      int64
+	usage     int64
+	failed    bool
+}
+
+func (t *dirTotals) add(other dirTotals) {
+	t.itemCount += other.itemCount
+	t.size += other.size
+	t.usage += other.usage
+	t.failed = t.failed || other.failed
+}
+
+// CreateCompactAnalyzer returns Analyzer storing the tree in compact form,
+// maxMemory limits the memory used by the tree (0 means unlimited)
+func CreateCompactAnalyzer(maxMemory int64) *CompactAnalyzer {
+	a := &CompactAnalyzer{maxMemory: maxMemory}
+	a.Init()
+	return a
+}
+
+// MemoryUsage returns estimated number of bytes used by the tree of the last analysis
+func (a *CompactAnalyzer) MemoryUsage() int64 {
+	tree := a.tree.Load()
+	if tree == nil {
+		return 0
+	}
+	return tree.MemoryUsage()
+}
+
+// AnalyzeDir analyzes given path
+func (a *CompactAnalyzer) AnalyzeDir(
+	path string, ignore common.ShouldDirBeIgnored, fileTypeFilter common.ShouldFileBeIgnored,
+) fs.Item {
+	a.ignoreDir = ignore
+	a.rootPath = path
+	a.ignoreFileType = fileTypeFilter
+	a.linkedInodes = &sync.Map{}
+
+	go a.UpdateProgress()
+
+	tree := newCompactTree(filepath.Base(path), filepath.Dir(path), a.maxMemory)
+	a.tree.Store(tree)
+
+	a.wait.Add(1)
+	a.processDir(tree, 0, path, a.rootIgnoreRules(path))
+	a.wait.Done()
+	a.wait.Wait()
+
+	a.progressDoneChan <- struct{}{}
+	a.doneChan.Broadcast()
+
+	return CompactItem{tree: tree}
+}
+
+func (a *CompactAnalyzer) processDir(tree *compactTree, index uint32, path string, rules *ignore.Rules) {
+	entries, flag, mtime, rules := a.readDir(path, rules)
+
+	first, ok := tree.addChildren(index, entries, flag, mtime)
+	if !ok {
+		tree.collapse(index, a.countEntries(path, entries, flag, rules))
+		return
+	}
+
+	for i, entry := range entries {
+		if !entry.dir {
+			continue
+		}
+		child := first + uint32(i)
+		entryPath := filepath.Join(path, entry.name)
+
+		select {
+		case a.concurrencyLimit <- struct{}{}:
+			a.wait.Add(1)
+			go func() {
+				a.processDir(tree, child, entryPath, rules)
+				<-a.concurrencyLimit
+				a.wait.Done()
+			}()
+		default:
+			a.processDir(tree, child, entryPath, rules)
+		}
+	}
+}
+
+// readDir returns the entries of the dir which are not ignored, flag and mtime of the dir
+// and the ignore rules applied to its items
+func (a *CompactAnalyzer) readDir(
+	path string, rules *ignore.Rules,
+) (entries []compactEntry, flag rune, mtime int64, dirRules *ignore.Rules) {
+	files, err := a.readDirLimited(path)
+	if err != nil {
+		a.addScanError(path, err)
+	}
+	dirInfo := statDir(path)
+	flag = getDirFlag(err, len(files), isSubvolume(path, dirInfo))
+
+	dir := &Dir{File: &File{}}
+	setDirPlatformSpecificAttrs(dir, dirInfo)
+	if !dir.Mtime.IsZero() {
+		mtime = dir.Mtime.UnixNano()
+	}
+	rules = a.dirIgnoreRules(rules, path, files)
+
+	var totalUsage int64
+	entries = make([]compactEntry, 0, len(files))
+	for _, f := range files {
+		if a.IsCancelled() {
+			break
+		}
+		name := f.Name()
+		entryPath := filepath.Join(path, name)
+		if f.IsDir() {
+			if a.shouldSkipDir(rules, name, entryPath) {
+				continue
+			}
+			entries = append(entries, compactEntry{name: name, flag: ' ', dir: true})
+			continue
+		}
+
+		// Apply file type filter if set
+		if a.ignoreFileType != nil && a.ignoreFileType(name) {
+			continue // Skip this file
+		}
+		if rules.Match(entryPath, false) {
+			continue
+		}
+
+		info, err := f.Info()
+		if err != nil {
+			a.addScanError(entryPath, err)
+			flag = '!'
+			continue
+		}
+		if a.followSymlinks && info.Mode()&os.ModeSymlink != 0 {
+			infoF, err := followSymlink(entryPath, a.gitAnnexedSize)
+			if err != nil {
+				a.addScanError(entryPath, err)
+				flag = '!'
+				continue
+			}
+			if infoF != nil {
+				info = infoF
+			}
+		}
+
+		// Apply time and file filters if set
+		if !a.includeFile(info) {
+			continue // Skip this file
+		}
+
+		file := CreateFileItem(name, info)
+		entry := compactEntry{
+			name:  name,
+			size:  file.Size,
+			usage: file.Usage,
+			mli:   file.Mli,
+			flag:  file.Flag,
+		}
+		if !file.Mtime.IsZero() {
+			entry.mtime = file.Mtime.UnixNano()
+		}
+		entries = append(entries, entry)
+		totalUsage += file.Usage
+	}
+
+	a.progressCurrentItemName.Store(path)
+	a.progressItemCount.Add(int64(len(files)))
+	a.progressTotalUsage.Add(totalUsage)
+	return entries, flag, mtime, rules
+}
+
+// countEntries returns totals of the dir with the entries and everything below it without storing the items
+func (a *CompactAnalyzer) countEntries(path string, entries []compactEntry, flag rune, rules *ignore.Rules) dirTotals {
+	var (
+		mu   sync.Mutex
+		wait sync.WaitGroup
+	)
+	totals := dirTotals{itemCount: 1, failed: flag == '!'}
+	if len(entries) == 0 {
+		totals.size = EmptyDirSize
+	}
+	addTotals := func(other dirTotals) {
+		mu.Lock()
+		totals.add(other)
+		mu.Unlock()
+	}
+
+	for _, entry := range entries {
+		if !entry.dir {
+			if entry.mli > 0 {
+				if _, loaded := a.linkedInodes.LoadOrStore(entry.mli, struct{}{}); loaded {
+					addTotals(dirTotals{itemCount: 1})
+					continue
+				}
+			}
+			addTotals(dirTotals{itemCount: 1, size: entry.size, usage: entry.usage})
+			continue
+		}
+
+		entryPath := filepath.Join(path, entry.name)
+		select {
+		case a.concurrencyLimit <- struct{}{}:
+			wait.Add(1)
+			go func() {
+				defer wait.Done()
+				subEntries, subFlag, _, subRules := a.readDir(entryPath, rules)
+				addTotals(a.countEntries(entryPath, subEntries, subFlag, subRules))
+				<-a.concurrencyLimit
+			}()
+		default:
+			subEntries, subFlag, _, subRules := a.readDir(entryPath, rules)
+			addTotals(a.countEntries(entryPath, subEntries, subFlag, subRules))
+		}
+	}
+	wait.Wait()
+	return totals
+}
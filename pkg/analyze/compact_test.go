@@ -0,0 +1,239 @@
+package analyze
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func analyzeCompact(t *testing.T, path string, maxMemory int64) CompactItem {
+	t.Helper()
+	analyzer := CreateCompactAnalyzer(maxMemory)
+	dir := analyzer.AnalyzeDir(
+		path, func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	).(CompactItem)
+	analyzer.GetDone().Wait()
+	dir.UpdateStats(make(fs.HardLinkedItems))
+	return dir
+}
+
+func TestAnalyzeDirCompact(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	analyzer := CreateCompactAnalyzer(0)
+	dir := analyzer.AnalyzeDir(
+		"test_dir", func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	)
+	analyzer.GetDone().Wait()
+	dir.UpdateStats(make(fs.HardLinkedItems))
+	assert.Greater(t, analyzer.MemoryUsage(), int64(0))
+
+	// same stats as the parallel analyzer
+	parallel := CreateAnalyzer()
+	expected := parallel.AnalyzeDir(
+		"test_dir", func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	)
+	parallel.GetDone().Wait()
+	expected.UpdateStats(make(fs.HardLinkedItems))
+
+	assert.Equal(t, "test_dir", dir.GetName())
+	assert.Equal(t, expected.GetSize(), dir.GetSize())
+	assert.Equal(t, expected.GetUsage(), dir.GetUsage())
+	assert.Equal(t, expected.GetItemCount(), dir.GetItemCount())
+	assert.Equal(t, expected.GetMtime(), dir.GetMtime())
+	assert.Equal(t, "Directory", dir.GetType())
+	assert.Nil(t, dir.GetParent())
+
+	nested := dir.GetFiles(fs.SortByName, fs.SortAsc)
+	var files []fs.Item
+	for item := range nested {
+		files = append(files, item)
+	}
+	require.Len(t, files, 1)
+	assert.Equal(t, "nested", files[0].GetName())
+	assert.Equal(t, dir, files[0].GetParent())
+	assert.Equal(t, int64(4), files[0].GetItemCount())
+
+	var names []string
+	for item := range files[0].GetFiles(fs.SortBySize, fs.SortDesc) {
+		names = append(names, item.GetName())
+	}
+	assert.Equal(t, []string{"subnested", "file2"}, names)
+
+	file := findItem(t, dir, "nested", "subnested", "file")
+	assert.Equal(t, filepath.Join("test_dir", "nested", "subnested", "file"), file.GetPath())
+	assert.Equal(t, "File", file.GetType())
+	assert.False(t, file.IsDir())
+	assert.Equal(t, int64(5), file.GetSize())
+	assert.Equal(t, int64(1), file.GetItemCount())
+}
+
+// findItem returns the item under the path of names
+func findItem(t *testing.T, dir fs.Item, names ...string) fs.Item {
+	t.Helper()
+	for _, name := range names {
+		var found fs.Item
+		for item := range dir.GetFiles(fs.SortByName, fs.SortAsc) {
+			if item.GetName() == name {
+				found = item
+			}
+		}
+		require.NotNil(t, found, name)
+		dir = found
+	}
+	return dir
+}
+
+func TestCompactMemoryLimit(t *testing.T) {
+	root := t.TempDir()
+	for i := range 10 {
+		path := filepath.Join(root, "big", "sub", strings.Repeat("x", i+1))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, make([]byte, 100), 0o600))
+	}
+
+	// only the root (with interned name) and its items fit into the limit
+	limit := compactChunkSize*compactNodeSize + nameChunkSize + internedNameSize + compactNodeSize + int64(len("big"))
+	dir := analyzeCompact(t, root, limit)
+
+	big := findItem(t, dir, "big")
+	assert.Equal(t, CollapsedDirFlag, big.GetFlag())
+	assert.Equal(t, int64(12), big.GetItemCount())
+	assert.Equal(t, int64(1000), big.GetSize())
+	assert.Empty(t, big.(CompactItem).children())
+
+	assert.Equal(t, int64(13), dir.GetItemCount())
+	assert.Equal(t, int64(1000), dir.GetSize())
+	assert.Equal(t, big.GetUsage(), dir.GetUsage())
+}
+
+func TestCompactHardLinks(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "file"), make([]byte, 100), 0o600))
+	if err := os.Link(filepath.Join(root, "file"), filepath.Join(root, "link")); err != nil {
+		t.Skip("hard links are not supported")
+	}
+
+	dir := analyzeCompact(t, root, 0)
+	assert.Equal(t, int64(100), dir.GetSize())
+	assert.Equal(t, int64(3), dir.GetItemCount())
+
+	file := findItem(t, dir, "file")
+	if file.GetMultiLinkedInode() == 0 {
+		t.Skip("inode numbers are not available")
+	}
+	assert.Equal(t, 'H', file.GetFlag())
+	assert.Equal(t, file.GetMultiLinkedInode(), findItem(t, dir, "link").GetMultiLinkedInode())
+}
+
+func TestCompactRemoveFile(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	dir := analyzeCompact(t, "test_dir", 0)
+	nested := findItem(t, dir, "nested")
+	subnested := findItem(t, nested, "subnested")
+	file := findItem(t, subnested, "file")
+
+	size := dir.GetSize()
+	subnested.RemoveFile(file)
+	assert.Equal(t, size-5, dir.GetSize())
+	assert.Equal(t, int64(4), dir.GetItemCount())
+	assert.Empty(t, subnested.(CompactItem).children())
+
+	nested.RemoveFileByName("file2")
+	assert.Equal(t, size-7, dir.GetSize())
+	assert.Equal(t, int64(3), dir.GetItemCount())
+	nested.RemoveFileByName("missing")
+	assert.Equal(t, int64(3), dir.GetItemCount())
+}
+
+func TestCompactAddFile(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	dir := analyzeCompact(t, "test_dir", 0)
+	nested := findItem(t, dir, "nested")
+
+	// rescanned subdir replaces the old one
+	require.NoError(t, os.WriteFile(filepath.Join("test_dir", "nested", "subnested", "new"), []byte("new"), 0o600))
+	rescanned := analyzeCompact(t, filepath.Join("test_dir", "nested", "subnested"), 0)
+	rescanned.SetParent(nested)
+	nested.RemoveFileByName("subnested")
+	nested.AddFile(rescanned)
+	dir.UpdateStats(make(fs.HardLinkedItems))
+
+	assert.Equal(t, int64(10), dir.GetSize())
+	assert.Equal(t, int64(6), dir.GetItemCount())
+	assert.Equal(t, nested, rescanned.GetParent())
+	newFile := findItem(t, dir, "nested", "subnested", "new")
+	assert.Equal(t, filepath.Join("test_dir", "nested", "subnested", "new"), newFile.GetPath())
+	assert.Equal(t, "subnested", DisplayName(rescanned))
+
+	// removing item of the rescanned subdir updates stats of the whole tree
+	rescanned.RemoveFile(newFile)
+	assert.Equal(t, int64(7), dir.GetSize())
+	assert.Equal(t, int64(5), dir.GetItemCount())
+
+	nested.RemoveFile(rescanned)
+	assert.Equal(t, int64(2), dir.GetSize())
+	assert.Equal(t, int64(3), dir.GetItemCount())
+}
+
+func TestCompactEncodeJSON(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	dir := analyzeCompact(t, "test_dir", 0)
+	var buff bytes.Buffer
+	require.NoError(t, dir.EncodeJSON(&buff, true, nil))
+
+	parallel := CreateAnalyzer()
+	expected := parallel.AnalyzeDir(
+		"test_dir", func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	)
+	parallel.GetDone().Wait()
+	expected.UpdateStats(make(fs.HardLinkedItems))
+	var expectedBuff bytes.Buffer
+	require.NoError(t, expected.EncodeJSON(&expectedBuff, true, nil))
+
+	assert.Equal(t, expectedBuff.String(), buff.String())
+}
+
+func TestCompactInMultiPathAnalyzer(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(first, "a"), []byte("aaa"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(second, "b"), []byte("bb"), 0o600))
+
+	analyzer := CreateMultiPathAnalyzer([]string{first, second}, func() common.Analyzer {
+		return CreateCompactAnalyzer(0)
+	})
+	dir := analyzer.AnalyzeDir(
+		analyzer.RootPath(), func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	)
+	analyzer.GetDone().Wait()
+	dir.UpdateStats(make(fs.HardLinkedItems))
+	assert.Equal(t, int64(5), dir.GetSize())
+
+	var names []string
+	for item := range dir.GetFiles(fs.SortByName, fs.SortAsc) {
+		names = append(names, DisplayName(item))
+	}
+	assert.ElementsMatch(t, []string{first, second}, names)
+
+	// removing an item updates stats of the synthetic root
+	root := findItem(t, dir, filepath.Base(first))
+	root.RemoveFileByName("a")
+	assert.Equal(t, int64(2), dir.GetSize())
+}
@@ -0,0 +1,601 @@
+package analyze
+
+import (
+	"io"
+	"iter"
+	"math"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// CompactBytesPerItem is the target of memory needed for one item of the compact tree
+// including its name (a File of ParallelAnalyzer needs more than 150 bytes)
+const CompactBytesPerItem = 64
+
+const (
+	compactChunkBits = 16
+	compactChunkSize = 1 << compactChunkBits
+	compactChunkMask = compactChunkSize - 1
+
+	nameChunkBits = 20
+	nameChunkSize = 1 << nameChunkBits
+	nameChunkMask = nameChunkSize - 1
+	maxNameChunks = 1 << (32 - nameChunkBits)
+
+	// only short names are interned and only the first ones seen, so that the map stays small
+	maxInternedNames   = 1 << 15
+	maxInternedNameLen = 24
+	internedNameSize   = 64
+	mliEntrySize       = 24
+
+	// CollapsedDirFlag marks directories whose items were not stored because of the memory limit
+	CollapsedDirFlag = '~'
+)
+
+const (
+	nodeDir uint8 = 1 << iota
+	nodeRemoved
+	nodeCollapsed
+)
+
+// compactNode is an item of the compact tree. It holds no pointers,
+// so the garbage collector does not need to scan the arena.
+// Children of a dir are stored next to each other in the arena.
+type compactNode struct {
+	size       int64
+	usage      int64
+	mtime      int64 // unix nanoseconds, zero when unknown
+	name       uint32
+	parent     uint32
+	firstChild uint32
+	childCount uint32
+	itemCount  uint32
+	nameLen    uint16
+	flag       uint8
+	bits       uint8
+}
+
+var compactNodeSize = int64(unsafe.Sizeof(compactNode{}))
+
+// compactEntry is an item read from the directory before it is stored in the tree
+type compactEntry struct {
+	name  string
+	size  int64
+	usage int64
+	mtime int64
+	mli   uint64
+	flag  rune
+	dir   bool
+}
+
+// compactTree stores items in chunks of arena which are never moved, so indexes of the items stay valid
+type compactTree struct {
+	m        sync.RWMutex
+	chunks   []*[compactChunkSize]compactNode
+	count    uint32
+	names    [][]byte
+	interned map[string]uint32
+	mli      map[uint32]uint64
+	extra    map[uint32]fs.Files // items added to the dirs after the scan (e.g. rescanned subdirs)
+	parent   fs.Item
+	basePath string
+	limit    int64
+}
+
+func newCompactTree(name, basePath string, limit int64) *compactTree {
+	t := &compactTree{
+		interned: make(map[string]uint32),
+		mli:      make(map[uint32]uint64),
+		extra:    make(map[uint32]fs.Files),
+		basePath: basePath,
+		limit:    limit,
+	}
+	t.alloc(1)
+	root := t.node(0)
+	root.bits = nodeDir
+	root.flag = ' '
+	root.itemCount = 1
+	root.name, root.nameLen = t.addName(name)
+	return t
+}
+
+func (t *compactTree) node(index uint32) *compactNode {
+	return &t.chunks[index>>compactChunkBits][index&compactChunkMask]
+}
+
+// alloc reserves n nodes following each other and returns index of the first one
+func (t *compactTree) alloc(n int) (uint32, bool) {
+	if uint64(t.count)+uint64(n) > math.MaxUint32 {
+		return 0, false
+	}
+	first := t.count
+	t.count += uint32(n)
+	for int(t.count) > len(t.chunks)*compactChunkSize {
+		t.chunks = append(t.chunks, new([compactChunkSize]compactNode))
+	}
+	return first, true
+}
+
+func (t *compactTree) addName(name string) (ref uint32, length uint16) {
+	name = name[:min(len(name), math.MaxUint16)]
+	short := len(name) <= maxInternedNameLen
+	if short {
+		if ref, ok := t.interned[name]; ok {
+			return ref, uint16(len(name))
+		}
+	}
+
+	last := len(t.names) - 1
+	if last < 0 || len(t.names[last])+len(name) > nameChunkSize {
+		t.names = append(t.names, make([]byte, 0, nameChunkSize))
+		last++
+	}
+	ref = uint32(last)<<nameChunkBits | uint32(len(t.names[last]))
+	t.names[last] = append(t.names[last], name...)
+
+	if short && len(t.interned) < maxInternedNames {
+		t.interned[name] = ref
+	}
+	return ref, uint16(len(name))
+}
+
+func (t *compactTree) name(n *compactNode) string {
+	chunk := t.names[n.name>>nameChunkBits]
+	offset := n.name & nameChunkMask
+	return string(chunk[offset : offset+uint32(n.nameLen)])
+}
+
+// memoryUsage returns estimated number of bytes used by the tree, the lock must be held
+func (t *compactTree) memoryUsage() int64 {
+	return int64(len(t.chunks))*compactChunkSize*compactNodeSize +
+		int64(len(t.names))*nameChunkSize +
+		int64(len(t.interned))*internedNameSize +
+		int64(len(t.mli))*mliEntrySize
+}
+
+// MemoryUsage returns estimated number of bytes used by the tree
+func (t *compactTree) MemoryUsage() int64 {
+	t.m.RLock()
+	defer t.m.RUnlock()
+	return t.memoryUsage()
+}
+
+// addChildren sets the flag and mtime of the dir and stores the entries as its children.
+// It returns index of the first child, false when the entries do not fit into the memory limit.
+func (t *compactTree) addChildren(index uint32, entries []compactEntry, flag rune, mtime int64) (uint32, bool) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	dir := t.node(index)
+	dir.flag = uint8(flag)
+	dir.mtime = mtime
+	if len(entries) == 0 {
+		return 0, true
+	}
+
+	if t.limit > 0 {
+		needed := int64(len(entries)) * compactNodeSize
+		for _, entry := range entries {
+			needed += int64(len(entry.name))
+		}
+		if t.memoryUsage()+needed > t.limit {
+			return 0, false
+		}
+	}
+	if len(t.names) >= maxNameChunks {
+		return 0, false
+	}
+	first, ok := t.alloc(len(entries))
+	if !ok {
+		return 0, false
+	}
+
+	dir.firstChild = first
+	dir.childCount = uint32(len(entries))
+	for i, entry := range entries {
+		child := first + uint32(i)
+		n := t.node(child)
+		n.name, n.nameLen = t.addName(entry.name)
+		n.parent = index
+		n.size = entry.size
+		n.usage = entry.usage
+		n.mtime = entry.mtime
+		n.flag = uint8(entry.flag)
+		n.itemCount = 1
+		if entry.dir {
+			n.bits = nodeDir
+		}
+		if entry.mli > 0 {
+			t.mli[child] = entry.mli
+		}
+	}
+	return first, true
+}
+
+// collapse stores totals of the dir whose items were not stored
+func (t *compactTree) collapse(index uint32, totals dirTotals) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	dir := t.node(index)
+	dir.bits |= nodeCollapsed
+	dir.itemCount = uint32(min(totals.itemCount, math.MaxUint32))
+	dir.size = totals.size
+	dir.usage = totals.usage
+	switch {
+	case dir.flag == '!':
+	case totals.failed:
+		dir.flag = '.'
+	case dir.flag == 'S':
+	default:
+		dir.flag = CollapsedDirFlag
+	}
+}
+
+// updateStats recursively updates size and item count of the dir, the lock must be held
+func (t *compactTree) updateStats(index uint32, linkedItems fs.HardLinkedItems, filteringFiles bool) {
+	dir := t.node(index)
+	if dir.bits&nodeCollapsed != 0 {
+		return
+	}
+
+	var (
+		totalSize  int64
+		totalUsage int64
+		itemCount  int64 = 1
+		hasFiles   bool
+		entries    int
+	)
+	mtime := dir.mtime
+	flag := dir.flag
+	addEntry := func(count, size, usage, entryMtime int64, entryFlag uint8, isDir bool) {
+		entries++
+		totalSize += size
+		totalUsage += usage
+		itemCount += count
+		mtime = max(mtime, entryMtime)
+		if !isDir {
+			hasFiles = true
+		}
+		// errors inside take precedence over the subvolume marker
+		if (entryFlag == '!' || entryFlag == '.') && flag != '!' {
+			flag = '.'
+		}
+	}
+
+	for child := dir.firstChild; child < dir.firstChild+dir.childCount; child++ {
+		n := t.node(child)
+		if n.bits&nodeRemoved != 0 {
+			continue
+		}
+		if n.bits&nodeDir != 0 {
+			t.updateStats(child, linkedItems, filteringFiles)
+			addEntry(int64(n.itemCount), n.size, n.usage, n.mtime, n.flag, true)
+			continue
+		}
+		size, usage := t.fileStats(child, linkedItems)
+		addEntry(1, size, usage, n.mtime, n.flag, false)
+	}
+	for _, item := range t.extra[index] {
+		count, size, usage := item.GetItemStats(linkedItems, filteringFiles)
+		addEntry(count, size, usage, item.GetMtime().UnixNano(), uint8(item.GetFlag()), item.IsDir())
+	}
+
+	dir.mtime = mtime
+	dir.flag = flag
+	// no files, or just empty dirs
+	if entries == 0 || (!hasFiles && filteringFiles && itemCount == int64(entries+1)) {
+		dir.itemCount = 1
+		dir.size = totalSize + EmptyDirSize
+		dir.usage = 0
+	} else {
+		dir.itemCount = uint32(min(itemCount, math.MaxUint32))
+		dir.size = totalSize
+		dir.usage = totalUsage
+	}
+}
+
+// fileStats returns size and usage of the file, zeros if the same file was already counted (hard link)
+func (t *compactTree) fileStats(index uint32, linkedItems fs.HardLinkedItems) (size, usage int64) {
+	n := t.node(index)
+	if mli := t.mli[index]; mli > 0 {
+		n.flag = 'H'
+		_, counted := linkedItems[mli]
+		linkedItems[mli] = append(linkedItems[mli], CompactItem{tree: t, index: index})
+		if counted {
+			return 0, 0
+		}
+	}
+	return n.size, n.usage
+}
+
+// adjustStats adds the differences to the stats of the dir and all its parents in the tree
+// and returns the item the tree is attached to
+func (t *compactTree) adjustStats(index uint32, itemCount, size, usage int64) fs.Item {
+	for {
+		n := t.node(index)
+		n.itemCount = uint32(max(int64(n.itemCount)+itemCount, 0))
+		n.size += size
+		n.usage += usage
+		if index == 0 {
+			return t.parent
+		}
+		index = n.parent
+	}
+}
+
+// statsAdjuster is implemented by dirs which can update their stats when items below them change
+type statsAdjuster interface {
+	adjustStats(itemCount, size, usage int64)
+}
+
+var (
+	_ fs.Item       = CompactItem{}
+	_ statsAdjuster = CompactItem{}
+)
+
+// CompactItem is a file or directory of the tree built by CompactAnalyzer.
+// It only references the item stored in the arena of the tree,
+// so items referencing the same file are equal.
+type CompactItem struct {
+	tree  *compactTree
+	index uint32
+}
+
+// GetPath returns absolute path of the item
+func (i CompactItem) GetPath() string {
+	t := i.tree
+	t.m.RLock()
+	var names []string
+	for index := i.index; index != 0; {
+		n := t.node(index)
+		names = append(names, t.name(n))
+		index = n.parent
+	}
+	root := t.name(t.node(0))
+	parent := t.parent
+	t.m.RUnlock()
+
+	switch {
+	case t.basePath != "":
+		root = filepath.Join(t.basePath, root)
+	case parent != nil:
+		root = filepath.Join(parent.GetPath(), root)
+	}
+	slices.Reverse(names)
+	return filepath.Join(append([]string{root}, names...)...)
+}
+
+// GetName returns name of the item
+func (i CompactItem) GetName() string {
+	i.tree.m.RLock()
+	defer i.tree.m.RUnlock()
+	return i.tree.name(i.tree.node(i.index))
+}
+
+// GetFlag returns flag of the item
+func (i CompactItem) GetFlag() rune {
+	i.tree.m.RLock()
+	defer i.tree.m.RUnlock()
+	return rune(i.tree.node(i.index).flag)
+}
+
+// IsDir returns true for dir
+func (i CompactItem) IsDir() bool {
+	i.tree.m.RLock()
+	defer i.tree.m.RUnlock()
+	return i.tree.node(i.index).bits&nodeDir != 0
+}
+
+// GetSize returns apparent size of the item
+func (i CompactItem) GetSize() int64 {
+	i.tree.m.RLock()
+	defer i.tree.m.RUnlock()
+	return i.tree.node(i.index).size
+}
+
+// GetUsage returns disk usage of the item
+func (i CompactItem) GetUsage() int64 {
+	i.tree.m.RLock()
+	defer i.tree.m.RUnlock()
+	return i.tree.node(i.index).usage
+}
+
+// GetMtime returns mtime of the item
+func (i CompactItem) GetMtime() time.Time {
+	i.tree.m.RLock()
+	defer i.tree.m.RUnlock()
+	mtime := i.tree.node(i.index).mtime
+	if mtime == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, mtime)
+}
+
+// GetType returns name type of item
+func (i CompactItem) GetType() string {
+	i.tree.m.RLock()
+	defer i.tree.m.RUnlock()
+	n := i.tree.node(i.index)
+	switch {
+	case n.bits&nodeDir != 0:
+		return "Directory"
+	case n.flag == '@':
+		return "Other"
+	}
+	return "File"
+}
+
+// GetItemCount returns number of items in dir, 1 for file
+func (i CompactItem) GetItemCount() int64 {
+	i.tree.m.RLock()
+	defer i.tree.m.RUnlock()
+	return int64(i.tree.node(i.index).itemCount)
+}
+
+// GetParent returns parent dir
+func (i CompactItem) GetParent() fs.Item {
+	i.tree.m.RLock()
+	defer i.tree.m.RUnlock()
+	if i.index == 0 {
+		return i.tree.parent
+	}
+	return CompactItem{tree: i.tree, index: i.tree.node(i.index).parent}
+}
+
+// SetParent sets parent of the root of the tree, parents of other items are given by the tree
+func (i CompactItem) SetParent(parent fs.Item) {
+	if i.index != 0 {
+		return
+	}
+	i.tree.m.Lock()
+	defer i.tree.m.Unlock()
+	i.tree.parent = parent
+}
+
+// GetMultiLinkedInode returns inode number of multilinked file
+func (i CompactItem) GetMultiLinkedInode() uint64 {
+	i.tree.m.RLock()
+	defer i.tree.m.RUnlock()
+	return i.tree.mli[i.index]
+}
+
+// EncodeJSON writes JSON representation of the item
+func (i CompactItem) EncodeJSON(writer io.Writer, topLevel bool, attributes fs.JSONAttributes) error {
+	if i.IsDir() {
+		return encodeDir(writer, i, i.children(), topLevel, attributes)
+	}
+	file := &File{
+		Name:  i.GetName(),
+		Flag:  i.GetFlag(),
+		Size:  i.GetSize(),
+		Usage: i.GetUsage(),
+		Mtime: i.GetMtime(),
+		Mli:   i.GetMultiLinkedInode(),
+	}
+	return file.EncodeJSON(writer, topLevel, attributes)
+}
+
+// GetItemStats returns item count, apparent usage and real usage of the item
+func (i CompactItem) GetItemStats(linkedItems fs.HardLinkedItems, filteringFiles bool) (itemCount, size, usage int64) {
+	t := i.tree
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	n := t.node(i.index)
+	if n.bits&nodeDir == 0 {
+		size, usage = t.fileStats(i.index, linkedItems)
+		return 1, size, usage
+	}
+	t.updateStats(i.index, linkedItems, filteringFiles)
+	return int64(n.itemCount), n.size, n.usage
+}
+
+// UpdateStats recursively updates size and item count
+func (i CompactItem) UpdateStats(linkedItems fs.HardLinkedItems) {
+	i.GetItemStats(linkedItems, false)
+}
+
+// UpdateStatsWithFileFiltering recursively updates size and item count, dirs without files are counted as empty
+func (i CompactItem) UpdateStatsWithFileFiltering(linkedItems fs.HardLinkedItems) {
+	i.GetItemStats(linkedItems, true)
+}
+
+// AddFile adds item from outside of the tree to the dir
+func (i CompactItem) AddFile(item fs.Item) {
+	i.tree.m.Lock()
+	defer i.tree.m.Unlock()
+	i.tree.extra[i.index] = append(i.tree.extra[i.index], item)
+}
+
+// children returns the items of the dir
+func (i CompactItem) children() fs.Files {
+	t := i.tree
+	t.m.RLock()
+	defer t.m.RUnlock()
+
+	n := t.node(i.index)
+	files := make(fs.Files, 0, int(n.childCount)+len(t.extra[i.index]))
+	for child := n.firstChild; child < n.firstChild+n.childCount; child++ {
+		if t.node(child).bits&nodeRemoved == 0 {
+			files = append(files, CompactItem{tree: t, index: child})
+		}
+	}
+	return append(files, t.extra[i.index]...)
+}
+
+// GetFiles returns all files in directory as a sorted iterator
+func (i CompactItem) GetFiles(sortBy fs.SortBy, order fs.SortOrder) iter.Seq[fs.Item] {
+	return func(yield func(fs.Item) bool) {
+		files := i.children()
+		sortFiles(files, sortBy, order)
+
+		for _, item := range files {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// GetFilesLocked returns all files in directory as a sorted iterator
+func (i CompactItem) GetFilesLocked(sortBy fs.SortBy, order fs.SortOrder) iter.Seq[fs.Item] {
+	return i.GetFiles(sortBy, order)
+}
+
+// RemoveFile removes item from dir, updates size and item count of the dir and its parents
+func (i CompactItem) RemoveFile(item fs.Item) {
+	itemCount, size, usage := item.GetItemCount(), item.GetSize(), item.GetUsage()
+
+	t := i.tree
+	t.m.Lock()
+	if child, ok := item.(CompactItem); ok && child.tree == t {
+		t.node(child.index).bits |= nodeRemoved
+	} else {
+		t.extra[i.index] = t.extra[i.index].Remove(item)
+	}
+	parent := t.adjustStats(i.index, -itemCount, -size, -usage)
+	t.m.Unlock()
+
+	if adjuster, ok := parent.(statsAdjuster); ok {
+		adjuster.adjustStats(-itemCount, -size, -usage)
+	}
+}
+
+// RemoveFileByName removes item by name from dir
+func (i CompactItem) RemoveFileByName(name string) {
+	for item := range i.GetFiles(fs.SortByName, fs.SortAsc) {
+		if item.GetName() == name {
+			i.RemoveFile(item)
+			return
+		}
+	}
+}
+
+// RLock does not lock anything, every access to the tree is locked on its own
+func (i CompactItem) RLock() func() {
+	return func() {}
+}
+
+func (i CompactItem) adjustStats(itemCount, size, usage int64) {
+	i.tree.m.Lock()
+	parent := i.tree.adjustStats(i.index, itemCount, size, usage)
+	i.tree.m.Unlock()
+
+	if adjuster, ok := parent.(statsAdjuster); ok {
+		adjuster.adjustStats(itemCount, size, usage)
+	}
+}
+
+// basePath returns the path of the dir holding the root of the tree, empty for other items
+func (i CompactItem) basePath() string {
+	if i.index != 0 {
+		return ""
+	}
+	return i.tree.basePath
+}
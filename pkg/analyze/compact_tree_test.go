@@ -0,0 +1,95 @@
+package analyze
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func TestCompactNodeSize(t *testing.T) {
+	assert.Equal(t, int64(48), compactNodeSize)
+}
+
+func TestCompactBytesPerItem(t *testing.T) {
+	const dirs, files = 1023, 1024 // fills 16 chunks of the arena
+
+	tree := newCompactTree("root", "/", 0)
+	entries := make([]compactEntry, dirs)
+	for i := range entries {
+		entries[i] = compactEntry{name: fmt.Sprintf("dir-%06d", i), flag: ' ', dir: true}
+	}
+	first, ok := tree.addChildren(0, entries, ' ', 0)
+	require.True(t, ok)
+
+	for dir := range uint32(dirs) {
+		entries := make([]compactEntry, files)
+		for i := range entries {
+			entries[i] = compactEntry{name: fmt.Sprintf("file-%07d", int(dir)*files+i), size: 100, usage: 4096, flag: ' '}
+		}
+		_, ok := tree.addChildren(first+dir, entries, ' ', 0)
+		require.True(t, ok)
+	}
+
+	items := int64(tree.count)
+	assert.Equal(t, int64(1+dirs+dirs*files), items)
+	assert.LessOrEqual(t, tree.MemoryUsage()/items, int64(CompactBytesPerItem))
+
+	root := CompactItem{tree: tree}
+	root.UpdateStats(make(fs.HardLinkedItems))
+	assert.Equal(t, items, root.GetItemCount())
+	assert.Equal(t, int64(dirs*files*100), root.GetSize())
+	assert.Equal(t, filepath.Join("/", "root", "dir-000001", "file-0001025"), CompactItem{tree: tree, index: first + dirs + files + 1}.GetPath())
+}
+
+func TestCompactNamesInterned(t *testing.T) {
+	tree := newCompactTree("root", "/", 0)
+	long := fmt.Sprintf("%030d", 1)
+	first, ok := tree.addChildren(0, []compactEntry{
+		{name: "index.js"}, {name: "index.js"}, {name: long}, {name: long},
+	}, ' ', 0)
+	require.True(t, ok)
+
+	same := tree.node(first)
+	other := tree.node(first + 1)
+	assert.Equal(t, same.name, other.name)
+	assert.Equal(t, "index.js", tree.name(other))
+
+	// long names are not interned
+	assert.NotEqual(t, tree.node(first+2).name, tree.node(first+3).name)
+	assert.Equal(t, long, tree.name(tree.node(first+3)))
+}
+
+func TestCompactTreeLimit(t *testing.T) {
+	tree := newCompactTree("root", "/", 1)
+	_, ok := tree.addChildren(0, []compactEntry{{name: "file"}}, 'e', 0)
+	assert.False(t, ok)
+	assert.Equal(t, 'e', CompactItem{tree: tree}.GetFlag())
+
+	_, ok = tree.addChildren(0, nil, ' ', 0)
+	assert.True(t, ok)
+}
+
+func TestCompactItemMtime(t *testing.T) {
+	tree := newCompactTree("root", "/", 0)
+	root := CompactItem{tree: tree}
+	assert.True(t, root.GetMtime().IsZero())
+
+	_, ok := tree.addChildren(0, []compactEntry{{name: "file", mtime: 1_700_000_000_000_000_000, flag: '@'}}, ' ', 0)
+	require.True(t, ok)
+	file := CompactItem{tree: tree, index: 1}
+	assert.Equal(t, int64(1_700_000_000), file.GetMtime().Unix())
+	assert.Equal(t, "Other", file.GetType())
+
+	root.UpdateStats(make(fs.HardLinkedItems))
+	assert.Equal(t, file.GetMtime(), root.GetMtime())
+
+	// parents of items other than root are given by the tree
+	file.SetParent(nil)
+	assert.Equal(t, root, file.GetParent())
+	assert.NotPanics(t, func() { root.RLock()() })
+}
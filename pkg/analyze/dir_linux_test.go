@@ -4,8 +4,10 @@ package analyze
 
 import (
 	"os"
+	"syscall"
 	"testing"
 
+	"github.com/dundee/gdu/v5/internal/common"
 	"github.com/dundee/gdu/v5/internal/testdir"
 	"github.com/dundee/gdu/v5/pkg/fs"
 	"github.com/stretchr/testify/assert"
@@ -35,6 +37,12 @@ func TestErr(t *testing.T) {
 
 	assert.Equal(t, "nested", dir.Files[0].GetName())
 	assert.Equal(t, '!', dir.Files[0].GetFlag())
+
+	errs := analyzer.GetScanErrors()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "test_dir/nested", errs[0].Path)
+	assert.Equal(t, "open", errs[0].Op)
+	assert.Equal(t, "EACCES", errs[0].Errno())
 }
 
 func TestSeqErr(t *testing.T) {
@@ -61,4 +69,78 @@ func TestSeqErr(t *testing.T) {
 
 	assert.Equal(t, "nested", dir.Files[0].GetName())
 	assert.Equal(t, '!', dir.Files[0].GetFlag())
+
+	errs := analyzer.GetScanErrors()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "test_dir/nested", errs[0].Path)
+	assert.Equal(t, "open", errs[0].Op)
+	assert.Equal(t, "EACCES", errs[0].Errno())
+}
+
+// failReadingDir makes reading of the dir fail with permission denied
+func failReadingDir(t *testing.T, dir string) {
+	t.Helper()
+	orig := readDirEntries
+	readDirEntries = func(path string) ([]os.DirEntry, error) {
+		if path == dir {
+			return nil, &os.PathError{Op: "open", Path: path, Err: syscall.EACCES}
+		}
+		return orig(path)
+	}
+	t.Cleanup(func() { readDirEntries = orig })
+}
+
+func TestScanErrorsReset(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	orig := readDirEntries
+	failReadingDir(t, "test_dir/nested")
+
+	analyzer := CreateAnalyzer()
+	dir := analyzer.AnalyzeDir(
+		"test_dir", func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	)
+	analyzer.GetDone().Wait()
+	dir.UpdateStats(make(fs.HardLinkedItems))
+
+	assert.Equal(t, '.', dir.GetFlag())
+	errs := analyzer.GetScanErrors()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, common.ScanError{
+		Path: "test_dir/nested",
+		Op:   "open",
+		Err:  &os.PathError{Op: "open", Path: "test_dir/nested", Err: syscall.EACCES},
+	}, errs[0])
+
+	readDirEntries = orig
+	analyzer.ResetProgress()
+	analyzer.AnalyzeDir(
+		"test_dir", func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	)
+	analyzer.GetDone().Wait()
+	assert.Empty(t, analyzer.GetScanErrors())
+}
+
+func TestMultiPathScanErrors(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	failReadingDir(t, "test_dir/nested/subnested")
+
+	analyzer := CreateMultiPathAnalyzer(
+		[]string{"test_dir/nested", "test_dir"},
+		func() common.Analyzer { return CreateAnalyzer() },
+	)
+	analyzer.AnalyzeDir(
+		analyzer.RootPath(), func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	)
+	analyzer.GetDone().Wait()
+
+	errs := analyzer.GetScanErrors()
+	assert.Len(t, errs, 2)
+	for _, e := range errs {
+		assert.Equal(t, "test_dir/nested/subnested", e.Path)
+		assert.Equal(t, "EACCES", e.Errno())
+	}
 }
@@ -28,12 +28,16 @@ func setPlatformSpecificAttrs(file *File, f os.FileInfo) {
 	}
 }
 
-func setDirPlatformSpecificAttrs(dir *Dir, path string) {
-	stat, err := os.Stat(path)
-	if err != nil {
+// getDevice returns ID of the device containing the item, which is not known here
+func getDevice(_ os.FileInfo) (dev uint64, ok bool) {
+	return 0, false
+}
+
+func setDirPlatformSpecificAttrs(dir *Dir, info os.FileInfo) {
+	if info == nil {
 		return
 	}
-	dir.Mtime = stat.ModTime()
+	dir.Mtime = info.ModTime()
 }
 
 // getSyscallStats extracts usage and inode info from os.FileInfo using syscall
@@ -31,13 +31,21 @@ func setPlatformSpecificAttrs(file *File, f os.FileInfo) {
 	}
 }
 
-func setDirPlatformSpecificAttrs(dir *Dir, path string) {
-	var stat syscall.Stat_t
-	if err := syscall.Stat(path, &stat); err != nil {
-		return
+// getDevice returns ID of the device containing the item
+func getDevice(info os.FileInfo) (dev uint64, ok bool) {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Dev), true //nolint:unconvert,gosec // Dev is not uint64 on all platforms
 	}
+	return 0, false
+}
 
-	dir.Mtime = time.Unix(int64(stat.Mtimespec.Sec), int64(stat.Mtimespec.Nsec))
+func setDirPlatformSpecificAttrs(dir *Dir, info os.FileInfo) {
+	if info == nil {
+		return
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		dir.Mtime = time.Unix(int64(stat.Mtimespec.Sec), int64(stat.Mtimespec.Nsec))
+	}
 }
 
 // getSyscallStats extracts usage and inode info from os.FileInfo using syscall
@@ -10,6 +10,11 @@ import (
 
 // EncodeJSON writes JSON representation of dir
 func (f *Dir) EncodeJSON(writer io.Writer, topLevel bool, attributes fs.JSONAttributes) error {
+	return encodeDir(writer, f, f.Files, topLevel, attributes)
+}
+
+// encodeDir writes JSON representation of dir with the given items
+func encodeDir(writer io.Writer, f fs.Item, files fs.Files, topLevel bool, attributes fs.JSONAttributes) error {
 	buff := make([]byte, 0, 20)
 
 	buff = append(buff, []byte(`[{"name":`)...)
@@ -35,6 +40,10 @@ func (f *Dir) EncodeJSON(writer io.Writer, topLevel bool, attributes fs.JSONAttr
 		buff = append(buff, []byte(`,"dsize":`)...)
 		buff = append(buff, []byte(strconv.FormatInt(f.GetUsage(), 10))...)
 	}
+	if attributes.Includes("shared") && SharedUsage(f) > 0 {
+		buff = append(buff, []byte(`,"shared":`)...)
+		buff = append(buff, []byte(strconv.FormatInt(SharedUsage(f), 10))...)
+	}
 	if attributes.Includes("items") {
 		buff = append(buff, []byte(`,"items":`)...)
 		buff = append(buff, []byte(strconv.FormatInt(f.GetItemCount(), 10))...)
@@ -45,7 +54,7 @@ func (f *Dir) EncodeJSON(writer io.Writer, topLevel bool, attributes fs.JSONAttr
 	}
 
 	buff = append(buff, '}')
-	if f.Files.Len() > 0 {
+	if files.Len() > 0 {
 		buff = append(buff, ',')
 	}
 	buff = append(buff, '\n')
@@ -54,13 +63,14 @@ func (f *Dir) EncodeJSON(writer io.Writer, topLevel bool, attributes fs.JSONAttr
 		return err
 	}
 
-	for i, item := range f.Files {
+	for i, item := range files {
 		if i > 0 {
 			if _, err := writer.Write([]byte(",\n")); err != nil {
 				return err
 			}
 		}
-		err := item.EncodeJSON(writer, false, attributes)
+		// roots of a multi-path scan are written with the full path
+		err := item.EncodeJSON(writer, isOutsideOf(item, f), attributes)
 		if err != nil {
 			return err
 		}
@@ -88,6 +98,10 @@ func (f *File) EncodeJSON(writer io.Writer, _ bool, attributes fs.JSONAttributes
 		buff = append(buff, []byte(`,"dsize":`)...)
 		buff = append(buff, []byte(strconv.FormatInt(f.GetUsage(), 10))...)
 	}
+	if attributes.Includes("shared") && f.Shared > 0 {
+		buff = append(buff, []byte(`,"shared":`)...)
+		buff = append(buff, []byte(strconv.FormatInt(f.Shared, 10))...)
+	}
 	if attributes.Includes("mtime") && !f.GetMtime().IsZero() {
 		buff = append(buff, []byte(`,"mtime":`)...)
 		buff = append(buff, []byte(strconv.FormatInt(f.GetMtime().Unix(), 10))...)
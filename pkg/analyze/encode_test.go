@@ -106,3 +106,18 @@ func TestEncodeSelectedAttributes(t *testing.T) {
 	assert.NotContains(t, buff.String(), `"mtime"`)
 	assert.NotContains(t, buff.String(), `"notreg"`)
 }
+
+func TestEncodeRootsOfMultiPathScan(t *testing.T) {
+	root := &Dir{File: &File{Name: "2 paths"}}
+	dir := &Dir{File: &File{Name: "var", Parent: root}, BasePath: "/"}
+	dir2 := &Dir{File: &File{Name: "data", Parent: root}, BasePath: "/mnt/nfs"}
+	root.Files = fs.Files{dir, dir2}
+
+	var buff bytes.Buffer
+	err := root.EncodeJSON(&buff, true, nil)
+
+	assert.Nil(t, err)
+	assert.Contains(t, buff.String(), `[{"name":"2 paths"`)
+	assert.Contains(t, buff.String(), `[{"name":"/var"`)
+	assert.Contains(t, buff.String(), `[{"name":"/mnt/nfs/data"`)
+}
@@ -0,0 +1,79 @@
+//go:build linux && (amd64 || arm64 || 386 || arm || riscv64 || loong64 || s390x)
+
+package analyze
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// fsIocFiemap is _IOWR('f', 11, struct fiemap)
+	fsIocFiemap        = 0xc020660b
+	fiemapExtentLast   = 0x1
+	fiemapExtentShared = 0x2000
+	fiemapExtentCount  = 64
+)
+
+type fiemapExtent struct {
+	Logical    uint64
+	Physical   uint64
+	Length     uint64
+	reserved64 [2]uint64
+	Flags      uint32
+	reserved   [3]uint32
+}
+
+type fiemap struct {
+	Start         uint64
+	Length        uint64
+	Flags         uint32
+	MappedExtents uint32
+	ExtentCount   uint32
+	reserved      uint32
+	Extents       [fiemapExtentCount]fiemapExtent
+}
+
+// getSharedUsage returns number of bytes of the file stored in extents shared with other files
+func getSharedUsage(path string) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var (
+		shared int64
+		req    fiemap
+	)
+	for {
+		req.Length = ^uint64(0) - req.Start
+		// no FIEMAP_FLAG_SYNC, data not written back yet is not worth the writeback of every scanned file
+		req.Flags = 0
+		req.MappedExtents = 0
+		req.ExtentCount = fiemapExtentCount
+
+		_, _, errno := unix.Syscall(
+			unix.SYS_IOCTL, file.Fd(), fsIocFiemap, uintptr(unsafe.Pointer(&req)),
+		)
+		if errno != 0 {
+			return 0, errno
+		}
+		if req.MappedExtents == 0 {
+			return shared, nil
+		}
+
+		for _, extent := range req.Extents[:req.MappedExtents] {
+			if extent.Flags&fiemapExtentShared != 0 {
+				shared += int64(extent.Length) // nolint: gosec // Why: extent lengths fit into int64
+			}
+			if extent.Flags&fiemapExtentLast != 0 {
+				return shared, nil
+			}
+		}
+		last := req.Extents[req.MappedExtents-1]
+		req.Start = last.Logical + last.Length
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux || !(amd64 || arm64 || 386 || arm || riscv64 || loong64 || s390x)
+
+package analyze
+
+import "errors"
+
+// getSharedUsage is not supported on this platform
+func getSharedUsage(_ string) (int64, error) {
+	return 0, errors.ErrUnsupported
+}
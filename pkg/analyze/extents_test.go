@@ -0,0 +1,84 @@
+package analyze
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func TestGetSharedUsage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	err := os.WriteFile(path, bytes.Repeat([]byte("x"), 8192), 0o600)
+	assert.NoError(t, err)
+
+	shared, err := getSharedUsage(path)
+	if err != nil {
+		t.Skipf("FIEMAP not supported here: %s", err)
+	}
+	assert.Equal(t, int64(0), shared)
+}
+
+func TestGetSharedUsageOfMissingFile(t *testing.T) {
+	_, err := getSharedUsage("/non-existent-file")
+	assert.Error(t, err)
+}
+
+func TestUpdateStatsSumsSharedUsage(t *testing.T) {
+	dir := &Dir{File: &File{Name: "root"}, BasePath: "."}
+	subdir := &Dir{File: &File{Name: "sub", Parent: dir}}
+	dir.AddFile(subdir)
+	dir.AddFile(&File{Name: "a", Usage: 8192, Shared: 4096, Parent: dir})
+	subdir.AddFile(&File{Name: "b", Usage: 4096, Shared: 4096, Parent: subdir})
+	subdir.AddFile(&File{Name: "c", Usage: 4096, Parent: subdir})
+
+	dir.UpdateStats(make(fs.HardLinkedItems))
+
+	assert.Equal(t, int64(8192), SharedUsage(dir))
+	assert.Equal(t, int64(4096), SharedUsage(subdir))
+
+	dir.RemoveFile(subdir)
+	assert.Equal(t, int64(4096), SharedUsage(dir))
+}
+
+func TestSetSharedUsageIsClampedToUsage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	err := os.WriteFile(path, []byte("x"), 0o600)
+	assert.NoError(t, err)
+	info, err := os.Lstat(path)
+	assert.NoError(t, err)
+
+	a := CreateAnalyzer()
+	file := &File{Name: "file", Usage: 4096}
+	a.setSharedUsage(file, info, path)
+	assert.Equal(t, int64(0), file.Shared)
+
+	a.SetSharedUsage(true)
+	a.setSharedUsage(file, info, path)
+	assert.LessOrEqual(t, file.Shared, file.Usage)
+}
+
+func TestEncodeSharedUsage(t *testing.T) {
+	dir := &Dir{
+		File:     &File{Name: "test_dir", Size: 10, Usage: 8192, Shared: 4096},
+		BasePath: ".",
+	}
+	dir.AddFile(&File{Name: "file", Size: 10, Usage: 8192, Shared: 4096})
+	dir.AddFile(&File{Name: "other", Size: 10, Usage: 8192})
+
+	var buff bytes.Buffer
+	err := dir.EncodeJSON(&buff, true, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, buff.String(), `"name":"test_dir","asize":10,"dsize":8192,"shared":4096`)
+	assert.Contains(t, buff.String(), `"name":"file","asize":10,"dsize":8192,"shared":4096`)
+	assert.NotContains(t, buff.String(), `"name":"other","asize":10,"dsize":8192,"shared"`)
+
+	buff.Reset()
+	err = dir.EncodeJSON(&buff, true, fs.JSONAttributes{"dsize": {}})
+	assert.NoError(t, err)
+	assert.NotContains(t, buff.String(), `"shared"`)
+}
@@ -26,6 +26,7 @@ type File struct {
 	Symlink string
 	Size    int64
 	Usage   int64
+	Shared  int64
 	Mli     uint64
 	Flag    rune
 }
@@ -72,6 +73,11 @@ func (f *File) GetUsage() int64 {
 	return f.Usage
 }
 
+// GetSharedUsage returns disk usage of the file in extents shared with other files
+func (f *File) GetSharedUsage() int64 {
+	return f.Shared
+}
+
 // GetMtime returns mtime of the file
 func (f *File) GetMtime() time.Time {
 	return f.Mtime
@@ -187,7 +193,12 @@ type Dir struct {
 	Files         fs.Files
 	ItemCount     int64
 	statsFromJSON bool
-	m             sync.RWMutex
+	// synthetic root joining several scanned paths has no path of its own
+	synthetic bool
+	// devices of the items joined under a synthetic root,
+	// hard links are counted once per device as inode numbers are unique only on one device
+	devices map[fs.Item]uint64
+	m       sync.RWMutex
 }
 
 func snapshotDir(source *Dir, parent fs.Item) *Dir {
@@ -199,6 +210,7 @@ func snapshotDir(source *Dir, parent fs.Item) *Dir {
 			Name:   source.Name,
 			Size:   source.Size,
 			Usage:  source.Usage,
+			Shared: source.Shared,
 			Mli:    source.Mli,
 			Flag:   source.Flag,
 		},
@@ -270,6 +282,7 @@ func snapshotFile(source, parent fs.Item) *File {
 		Name:   source.GetName(),
 		Size:   source.GetSize(),
 		Usage:  source.GetUsage(),
+		Shared: SharedUsage(source),
 		Mli:    source.GetMultiLinkedInode(),
 		Flag:   source.GetFlag(),
 	}
@@ -282,6 +295,17 @@ func (f *Dir) AddFile(item fs.Item) {
 	f.Files = append(f.Files, item)
 }
 
+// addFileOnDevice adds the item placed on the given device, its hard links are counted only with other items of the device
+func (f *Dir) addFileOnDevice(item fs.Item, dev uint64) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	f.Files = append(f.Files, item)
+	if f.devices == nil {
+		f.devices = make(map[fs.Item]uint64)
+	}
+	f.devices[item] = dev
+}
+
 // SetFlag updates the directory flag while preserving snapshot consistency.
 func (f *Dir) SetFlag(flag rune) {
 	f.m.Lock()
@@ -369,6 +393,13 @@ func (f *Dir) GetUsage() int64 {
 	return f.Usage
 }
 
+// GetSharedUsage returns the current disk usage in extents shared with other files.
+func (f *Dir) GetSharedUsage() int64 {
+	f.m.RLock()
+	defer f.m.RUnlock()
+	return f.Shared
+}
+
 // GetMtime returns the current modification time.
 func (f *Dir) GetMtime() time.Time {
 	f.m.RLock()
@@ -390,6 +421,9 @@ func (f *Dir) IsDir() bool {
 
 // GetPath returns absolute path of the file
 func (f *Dir) GetPath() string {
+	if f.synthetic {
+		return ""
+	}
 	if f.BasePath != "" {
 		return filepath.Join(f.BasePath, f.Name)
 	}
@@ -399,6 +433,34 @@ func (f *Dir) GetPath() string {
 	return f.Name
 }
 
+// DisplayName returns the name of the item shown in listings.
+// Roots of a multi-path scan are not located inside their synthetic parent
+// so they are shown with the full path.
+func DisplayName(item fs.Item) string {
+	switch item.(type) {
+	case *Dir, CompactItem:
+		if isOutsideOf(item, item.GetParent()) {
+			return item.GetPath()
+		}
+	}
+	return item.GetName()
+}
+
+// isOutsideOf reports whether the item is a dir not located inside the parent
+func isOutsideOf(item fs.Item, parent fs.Item) bool {
+	var basePath string
+	switch dir := item.(type) {
+	case *Dir:
+		basePath = dir.BasePath
+	case CompactItem:
+		basePath = dir.basePath()
+	}
+	if basePath == "" || parent == nil {
+		return false
+	}
+	return filepath.Clean(basePath) != filepath.Clean(parent.GetPath())
+}
+
 // GetItemStats returns item count, apparent usage and real usage of this dir
 func (f *Dir) GetItemStats(linkedItems fs.HardLinkedItems, filteringFiles bool) (itemCount, size, usage int64) {
 	f.updateStats(linkedItems, filteringFiles)
@@ -427,17 +489,33 @@ func (f *Dir) updateStats(linkedItems fs.HardLinkedItems, filteringFiles bool) {
 	copy(files, f.Files)
 	mtime := f.Mtime
 	flag := f.Flag
+	devices := f.devices
 	f.m.RUnlock()
 
+	var deviceLinks map[uint64]fs.HardLinkedItems
 	totalSize := int64(0)
 	totalUsage := int64(0)
+	totalShared := int64(0)
 	var itemCount int64 = 1
 	var hasFiles bool
 	for _, entry := range files {
-		count, size, usage := entry.GetItemStats(linkedItems, filteringFiles)
+		entryLinks := linkedItems
+		if dev, ok := devices[entry]; ok {
+			if deviceLinks == nil {
+				deviceLinks = make(map[uint64]fs.HardLinkedItems)
+			}
+			if deviceLinks[dev] == nil {
+				deviceLinks[dev] = make(fs.HardLinkedItems)
+			}
+			entryLinks = deviceLinks[dev]
+		}
+		count, size, usage := entry.GetItemStats(entryLinks, filteringFiles)
 		totalSize += size
 		totalUsage += usage
 		itemCount += count
+		if usage > 0 {
+			totalShared += SharedUsage(entry)
+		}
 
 		entryMtime := entry.GetMtime()
 		if entryMtime.After(mtime) {
@@ -450,11 +528,18 @@ func (f *Dir) updateStats(linkedItems fs.HardLinkedItems, filteringFiles bool) {
 
 		switch entry.GetFlag() {
 		case '!', '.':
+			// errors inside take precedence over the subvolume marker
 			if flag != '!' {
 				flag = '.'
 			}
 		}
 	}
+	// hard links of all devices are listed together
+	for _, links := range deviceLinks {
+		for ino, items := range links {
+			linkedItems[ino] = append(linkedItems[ino], items...)
+		}
+	}
 
 	f.m.Lock()
 	defer f.m.Unlock()
@@ -466,30 +551,92 @@ func (f *Dir) updateStats(linkedItems fs.HardLinkedItems, filteringFiles bool) {
 		f.ItemCount = 1
 		f.Size = totalSize + EmptyDirSize
 		f.Usage = 0
+		f.Shared = 0
 	} else {
 		f.ItemCount = itemCount
 		f.Size = totalSize
 		f.Usage = totalUsage
+		f.Shared = totalShared
 	}
 }
 
+// SharedUsage returns disk usage of the item in extents shared with other files, zero if the item does not know it
+func SharedUsage(item fs.Item) int64 {
+	if shared, ok := item.(fs.SharedUsageItem); ok {
+		return shared.GetSharedUsage()
+	}
+	return 0
+}
+
 // RemoveFile removes item from dir, updates size and item count
 func (f *Dir) RemoveFile(item fs.Item) {
 	f.m.Lock()
 	defer f.m.Unlock()
 
 	f.Files = f.Files.Remove(item)
+	delete(f.devices, item)
 
 	cur := f
 	for {
 		cur.ItemCount -= item.GetItemCount()
 		cur.Size -= item.GetSize()
 		cur.Usage -= item.GetUsage()
+		cur.Shared -= SharedUsage(item)
+
+		parent, ok := cur.Parent.(*Dir)
+		if !ok {
+			// root is reached or the parent is not a Dir and keeps its stats on its own
+			break
+		}
+		cur = parent
+	}
+}
+
+// adjustStats adds the differences to the stats of the dir and all its parents
+func (f *Dir) adjustStats(itemCount, size, usage int64) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	cur := f
+	for {
+		cur.ItemCount += itemCount
+		cur.Size += size
+		cur.Usage += usage
 
-		if cur.Parent == nil {
+		parent, ok := cur.Parent.(*Dir)
+		if !ok {
+			// root is reached or the parent is not a Dir and keeps its stats on its own
+			break
+		}
+		cur = parent
+	}
+}
+
+// ReplaceFile replaces item with a new one and updates stats of the directory and all its parents
+func (f *Dir) ReplaceFile(oldItem, newItem fs.Item) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if i, ok := f.Files.IndexOf(oldItem); ok {
+		f.Files[i] = newItem
+	} else {
+		f.Files = append(f.Files, newItem)
+	}
+	newItem.SetParent(f)
+
+	cur := f
+	for {
+		cur.ItemCount += newItem.GetItemCount() - oldItem.GetItemCount()
+		cur.Size += newItem.GetSize() - oldItem.GetSize()
+		cur.Usage += newItem.GetUsage() - oldItem.GetUsage()
+		cur.Shared += SharedUsage(newItem) - SharedUsage(oldItem)
+
+		parent, ok := cur.Parent.(*Dir)
+		if !ok {
+			// root is reached or the parent is not a Dir and keeps its stats on its own
 			break
 		}
-		cur = cur.Parent.(*Dir)
+		cur = parent
 	}
 }
 
@@ -540,9 +687,11 @@ func (f *Dir) RemoveFileByName(name string) {
 		cur.Size -= item.GetSize()
 		cur.Usage -= item.GetUsage()
 
-		if cur.Parent == nil {
+		parent, ok := cur.Parent.(*Dir)
+		if !ok {
+			// root is reached or the parent is not a Dir and keeps its stats on its own
 			break
 		}
-		cur = cur.Parent.(*Dir)
+		cur = parent
 	}
 }
@@ -0,0 +1,96 @@
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/dundee/gdu/v5/pkg/predicate"
+)
+
+func TestAnalyzersApplyFileFilterAndMaxDepth(t *testing.T) {
+	// files with at least 10 bytes, directories one level below the root
+	expected := []string{
+		".gitignore", "main.go", "build", "vendor", "vendor/lib.go", "src", "src/.gduignore",
+	}
+
+	analyzers := map[string]func(t *testing.T) common.Analyzer{
+		"parallel":   func(_ *testing.T) common.Analyzer { return CreateAnalyzer() },
+		"sequential": func(_ *testing.T) common.Analyzer { return CreateSeqAnalyzer() },
+		"stable":     func(_ *testing.T) common.Analyzer { return CreateStableOrderAnalyzer() },
+		"stored": func(t *testing.T) common.Analyzer {
+			return CreateStoredAnalyzer(filepath.Join(t.TempDir(), "badger"))
+		},
+		"sqlite": func(t *testing.T) common.Analyzer {
+			analyzer, err := CreateSqliteAnalyzer(filepath.Join(t.TempDir(), "test.db"))
+			require.NoError(t, err)
+			t.Cleanup(func() { analyzer.storage.Close() })
+			return analyzer
+		},
+		"multi": func(_ *testing.T) common.Analyzer {
+			return CreateMultiPathAnalyzer(nil, createParallelAnalyzer)
+		},
+		"compact": func(_ *testing.T) common.Analyzer { return CreateCompactAnalyzer(0) },
+	}
+
+	for name, create := range analyzers {
+		t.Run(name, func(t *testing.T) {
+			root := createIgnoreTestDir(t)
+			analyzer := create(t)
+			analyzer.SetFileFilter(common.FileFilter(predicate.MinSize(10)))
+			analyzer.SetMaxDepth(1)
+
+			dir := analyzer.AnalyzeDir(
+				root, func(_, _ string) bool { return false }, func(_ string) bool { return false },
+			)
+			analyzer.GetDone().Wait()
+
+			var paths []string
+			collectPaths(dir, "", &paths)
+			assert.ElementsMatch(t, expected, paths)
+		})
+	}
+}
+
+func TestAnalyzerFileModeFilter(t *testing.T) {
+	root := createIgnoreTestDir(t)
+	require.NoError(t, os.Chmod(filepath.Join(root, "build", "out"), 0o755))
+
+	analyzer := CreateAnalyzer()
+	analyzer.SetFileFilter(common.FileFilter(predicate.AnyMode(predicate.ModeExecutable)))
+
+	dir := analyzer.AnalyzeDir(
+		root, func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	)
+	analyzer.GetDone().Wait()
+
+	var paths []string
+	collectPaths(dir, "", &paths)
+	assert.Contains(t, paths, "build/out")
+	assert.NotContains(t, paths, "main.go")
+}
+
+func TestTopDirAnalyzerAppliesFileFilterAndMaxDepth(t *testing.T) {
+	root := createIgnoreTestDir(t)
+	analyzer := CreateTopDirAnalyzer()
+	analyzer.SetFileFilter(common.FileFilter(predicate.MinSize(10)))
+	analyzer.SetMaxDepth(1)
+
+	dir := analyzer.AnalyzeDir(
+		root, func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	).(*SimpleDir)
+	analyzer.GetDone().Wait()
+
+	items := make(map[string]int64)
+	for _, file := range dir.Files {
+		items[file.Name] = file.ItemCount
+	}
+	assert.Len(t, items, 5)
+	assert.Equal(t, int64(1), items["src"]) // .gduignore, src/gen is too deep
+	assert.Equal(t, int64(1), items["vendor"])
+	assert.Equal(t, int64(0), items["build"])
+}
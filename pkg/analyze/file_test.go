@@ -109,6 +109,81 @@ func TestRemove(t *testing.T) {
 	assert.Equal(t, file2, dir.Files[0])
 }
 
+func TestUpdateDirWithOtherParent(t *testing.T) {
+	parent := &SimpleDir{}
+	dir := &Dir{
+		File: &File{
+			Name:   "xxx",
+			Size:   10,
+			Usage:  12,
+			Parent: parent,
+		},
+		ItemCount: 3,
+	}
+	file := &File{Name: "yyy", Size: 8, Usage: 8, Parent: dir}
+	file2 := &File{Name: "zzz", Size: 2, Usage: 4, Parent: dir}
+	dir.Files = fs.Files{file, file2}
+
+	dir.ReplaceFile(file, &File{Name: "yyy.zst", Size: 3, Usage: 4})
+	dir.RemoveFile(file2)
+	dir.RemoveFileByName("yyy.zst")
+	dir.adjustStats(1, 1, 1)
+
+	assert.Equal(t, int64(2), dir.ItemCount)
+	assert.Equal(t, int64(1), dir.Size)
+	assert.Equal(t, int64(1), dir.Usage)
+}
+
+func TestReplaceFile(t *testing.T) {
+	top := &Dir{
+		File: &File{
+			Name:  "top",
+			Size:  15,
+			Usage: 20,
+		},
+		ItemCount: 4,
+	}
+	dir := &Dir{
+		File: &File{
+			Name:   "xxx",
+			Size:   10,
+			Usage:  12,
+			Parent: top,
+		},
+		ItemCount: 3,
+	}
+	file := &File{
+		Name:   "yyy",
+		Size:   8,
+		Usage:  8,
+		Parent: dir,
+	}
+	file2 := &File{
+		Name:   "zzz",
+		Size:   2,
+		Usage:  4,
+		Parent: dir,
+	}
+	top.Files = fs.Files{dir}
+	dir.Files = fs.Files{file, file2}
+
+	compressed := &File{
+		Name:  "yyy.zst",
+		Size:  3,
+		Usage: 4,
+	}
+	dir.ReplaceFile(file, compressed)
+
+	assert.Equal(t, fs.Files{compressed, file2}, dir.Files)
+	assert.Equal(t, dir, compressed.GetParent())
+	assert.Equal(t, int64(5), dir.Size)
+	assert.Equal(t, int64(8), dir.Usage)
+	assert.Equal(t, int64(3), dir.ItemCount)
+	assert.Equal(t, int64(10), top.Size)
+	assert.Equal(t, int64(16), top.Usage)
+	assert.Equal(t, int64(4), top.ItemCount)
+}
+
 func TestRemoveByName(t *testing.T) {
 	dir := Dir{
 		File: &File{
@@ -404,3 +479,43 @@ func TestStatsFromJSONArePreserved(t *testing.T) {
 	assert.Equal(t, int64(4096), size)
 	assert.Equal(t, int64(2048), usage)
 }
+
+func TestDisplayName(t *testing.T) {
+	root := &Dir{File: &File{Name: "2 paths"}}
+	dir := &Dir{File: &File{Name: "var", Parent: root}, BasePath: "/"}
+	nested := &Dir{File: &File{Name: "log", Parent: dir}}
+	file := &File{Name: "file", Parent: nested}
+
+	assert.Equal(t, "2 paths", DisplayName(root))
+	assert.Equal(t, "/var", DisplayName(dir))
+	assert.Equal(t, "log", DisplayName(nested))
+	assert.Equal(t, "file", DisplayName(file))
+}
+
+func TestHardLinksCountedPerDevice(t *testing.T) {
+	createRoot := func(firstDev, secondDev uint64) *Dir {
+		root := &Dir{File: &File{Name: "root"}}
+		for i, dev := range []uint64{firstDev, secondDev} {
+			dir := &Dir{File: &File{Name: string(rune('a' + i)), Parent: root}}
+			dir.Files = fs.Files{&File{Name: "file", Size: 10, Usage: 12, Mli: 5, Parent: dir}}
+			root.addFileOnDevice(dir, dev)
+		}
+		return root
+	}
+
+	// same inode number on different devices is not the same file
+	root := createRoot(1, 2)
+	links := make(fs.HardLinkedItems)
+	root.UpdateStats(links)
+	assert.Equal(t, int64(20), root.GetSize())
+	assert.Equal(t, int64(24), root.GetUsage())
+	assert.Len(t, links[5], 2)
+
+	root = createRoot(1, 1)
+	root.UpdateStats(make(fs.HardLinkedItems))
+	assert.Equal(t, int64(10), root.GetSize())
+	assert.Equal(t, int64(12), root.GetUsage())
+
+	root.RemoveFile(root.Files[0])
+	assert.Len(t, root.devices, 1)
+}
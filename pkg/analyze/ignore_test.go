@@ -0,0 +1,137 @@
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/ignore"
+)
+
+// createIgnoreTestDir creates tree with ignore files in the root and in the nested directory
+func createIgnoreTestDir(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		".gitignore":       "*.log\nbuild/\n",
+		"app.log":          "log",
+		"main.go":          "package main",
+		"build/out":        "binary",
+		"vendor/lib.go":    "package lib",
+		"src/.gduignore":   "!debug.log\n/gen\n",
+		"src/debug.log":    "debug",
+		"src/trace.log":    "trace",
+		"src/gen/code.go":  "package gen",
+		"src/x/gen/doc.md": "doc",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	return root
+}
+
+func ignoreTestPatterns(t *testing.T) []ignore.Pattern {
+	t.Helper()
+	patterns, err := ignore.Parse(strings.NewReader("/vendor/\n"))
+	require.NoError(t, err)
+	return patterns
+}
+
+func collectPaths(dir fs.Item, prefix string, paths *[]string) {
+	for item := range dir.GetFiles(fs.SortByName, fs.SortAsc) {
+		path := prefix + item.GetName()
+		*paths = append(*paths, path)
+		if item.IsDir() {
+			collectPaths(item, path+"/", paths)
+		}
+	}
+}
+
+func TestAnalyzersApplyIgnoreFiles(t *testing.T) {
+	expected := []string{
+		".gitignore", "main.go", "src", "src/.gduignore", "src/debug.log",
+		"src/x", "src/x/gen", "src/x/gen/doc.md",
+	}
+
+	analyzers := map[string]func(t *testing.T) common.Analyzer{
+		"parallel":   func(_ *testing.T) common.Analyzer { return CreateAnalyzer() },
+		"sequential": func(_ *testing.T) common.Analyzer { return CreateSeqAnalyzer() },
+		"stable":     func(_ *testing.T) common.Analyzer { return CreateStableOrderAnalyzer() },
+		"stored": func(t *testing.T) common.Analyzer {
+			return CreateStoredAnalyzer(filepath.Join(t.TempDir(), "badger"))
+		},
+		"sqlite": func(t *testing.T) common.Analyzer {
+			analyzer, err := CreateSqliteAnalyzer(filepath.Join(t.TempDir(), "test.db"))
+			require.NoError(t, err)
+			t.Cleanup(func() { analyzer.storage.Close() })
+			return analyzer
+		},
+		"multi": func(_ *testing.T) common.Analyzer {
+			return CreateMultiPathAnalyzer(nil, createParallelAnalyzer)
+		},
+		"compact": func(_ *testing.T) common.Analyzer { return CreateCompactAnalyzer(0) },
+	}
+
+	for name, create := range analyzers {
+		t.Run(name, func(t *testing.T) {
+			root := createIgnoreTestDir(t)
+			analyzer := create(t)
+			analyzer.SetIgnorePatterns(ignoreTestPatterns(t), true)
+
+			dir := analyzer.AnalyzeDir(
+				root, func(_, _ string) bool { return false }, func(_ string) bool { return false },
+			)
+			analyzer.GetDone().Wait()
+
+			var paths []string
+			collectPaths(dir, "", &paths)
+			assert.ElementsMatch(t, expected, paths)
+		})
+	}
+}
+
+func TestAnalyzerIgnoreFilesDisabled(t *testing.T) {
+	root := createIgnoreTestDir(t)
+	analyzer := CreateAnalyzer()
+	analyzer.SetIgnorePatterns(ignoreTestPatterns(t), false)
+
+	dir := analyzer.AnalyzeDir(
+		root, func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	)
+	analyzer.GetDone().Wait()
+
+	var paths []string
+	collectPaths(dir, "", &paths)
+	assert.Contains(t, paths, "app.log")
+	assert.Contains(t, paths, "build/out")
+	assert.NotContains(t, paths, "vendor")
+}
+
+func TestTopDirAnalyzerAppliesIgnoreFiles(t *testing.T) {
+	root := createIgnoreTestDir(t)
+	analyzer := CreateTopDirAnalyzer()
+	analyzer.SetIgnorePatterns(ignoreTestPatterns(t), true)
+
+	dir := analyzer.AnalyzeDir(
+		root, func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	).(*SimpleDir)
+	analyzer.GetDone().Wait()
+
+	items := make(map[string]int64)
+	for _, file := range dir.Files {
+		items[file.Name] = file.ItemCount
+	}
+	assert.Len(t, items, 3)
+	assert.Contains(t, items, ".gitignore")
+	assert.Contains(t, items, "main.go")
+	// .gduignore, debug.log, x, gen, doc.md
+	assert.Equal(t, int64(5), items["src"])
+}
@@ -0,0 +1,189 @@
+package analyze
+
+import (
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/dundee/gdu/v5/internal/common"
+)
+
+const (
+	// MaxAdaptiveIOWorkers is the upper bound of the adaptive number of I/O workers
+	MaxAdaptiveIOWorkers = 128
+
+	// minAdaptiveWindow is the minimal number of reads after which the adaptive limit is tuned
+	minAdaptiveWindow = 16
+	// the limit is lowered when the latency per item exceeds the baseline by overloadedRatio
+	// and raised when it stays below the baseline multiplied by idleRatio
+	overloadedRatio = 2
+	idleRatio       = 1.5
+)
+
+// CreateIOLimiter limits the number of directories read at once (including stats of their files) to n
+func CreateIOLimiter(n int) *IOLimiter {
+	return newIOLimiter(n, n, false)
+}
+
+// CreateAdaptiveIOLimiter creates the limit of directories read at once tuned by the observed latency.
+// The limit grows while the latency stays low (e.g. latency-bound network filesystems)
+// and shrinks when the storage gets overloaded.
+func CreateAdaptiveIOLimiter() *IOLimiter {
+	return newIOLimiter(defaultConcurrency(), MaxAdaptiveIOWorkers, true)
+}
+
+func defaultConcurrency() int {
+	return 2 * runtime.GOMAXPROCS(0)
+}
+
+// SetIOLimiter sets the limit of directories read at once, nil means no limit.
+// It must be called before the analysis is started.
+func (a *BaseAnalyzer) SetIOLimiter(limiter common.IOLimiter) {
+	a.ioLimiter = limiter
+
+	// the number of directories processed at once has to be
+	// at least the number of I/O workers so that they can be all busy
+	n := defaultConcurrency()
+	if limiter != nil {
+		n = max(n, limiter.MaxLimit())
+	}
+	if cap(a.concurrencyLimit) != n {
+		a.concurrencyLimit = make(chan struct{}, n)
+	}
+}
+
+// ioWorkers returns the current limit of directories read at once, 0 means no limit
+func (a *BaseAnalyzer) ioWorkers() int {
+	if a.ioLimiter == nil {
+		return 0
+	}
+	return a.ioLimiter.Limit()
+}
+
+// readDirLimited reads the entries of the dir within the limit of I/O workers and the throttling of the scan
+func (a *BaseAnalyzer) readDirLimited(path string) ([]os.DirEntry, error) {
+	if a.throttle != nil {
+		a.throttle.Wait(a.IsCancelled)
+	}
+
+	var (
+		files []os.DirEntry
+		err   error
+	)
+	if a.ioLimiter != nil {
+		a.ioLimiter.Acquire()
+		start := time.Now()
+		files, err = readDirEntries(path)
+		a.ioLimiter.Release(time.Since(start), len(files))
+	} else {
+		files, err = readDirEntries(path)
+	}
+
+	if a.throttle != nil {
+		a.throttle.Charge(len(files))
+	}
+	return files, err
+}
+
+// IOLimiter is a semaphore with optionally adaptive number of permits
+type IOLimiter struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	limit    int
+	maxLimit int
+	inFlight int
+	adaptive bool
+
+	// lowest latency per item observed, taken as the latency of idle storage
+	baseline time.Duration
+	// reads of the current window
+	windowReads   int
+	windowItems   int
+	windowLatency time.Duration
+	// whether all permits were taken during the window
+	saturated bool
+}
+
+func newIOLimiter(limit, maxLimit int, adaptive bool) *IOLimiter {
+	l := &IOLimiter{
+		limit:    min(limit, maxLimit),
+		maxLimit: maxLimit,
+		adaptive: adaptive,
+	}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// Limit returns current number of permits
+func (l *IOLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// MaxLimit returns the highest number of permits
+func (l *IOLimiter) MaxLimit() int {
+	return l.maxLimit
+}
+
+// Acquire blocks until a permit is available
+func (l *IOLimiter) Acquire() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for l.inFlight >= l.limit {
+		l.cond.Wait()
+	}
+	l.inFlight++
+	if l.inFlight == l.limit {
+		l.saturated = true
+	}
+}
+
+// Release returns the permit, latency of the read of the dir with items is taken into account
+func (l *IOLimiter) Release(latency time.Duration, items int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	if l.adaptive && l.observe(latency, items) {
+		l.cond.Broadcast()
+		return
+	}
+	l.cond.Signal()
+}
+
+// observe tunes the limit after each window of reads, it returns true if the limit was raised.
+// Reads of the dirs take time proportional to the number of their items (files are stated),
+// so latency per item is compared.
+func (l *IOLimiter) observe(latency time.Duration, items int) bool {
+	l.windowReads++
+	l.windowItems += items + 1
+	l.windowLatency += latency
+	if l.windowReads < max(l.limit, minAdaptiveWindow) {
+		return false
+	}
+
+	perItem := l.windowLatency / time.Duration(l.windowItems)
+	baseline := l.baseline
+	saturated := l.saturated
+	l.windowReads, l.windowItems, l.windowLatency, l.saturated = 0, 0, 0, false
+
+	if baseline == 0 || perItem < baseline {
+		l.baseline = perItem
+		baseline = perItem
+	} else {
+		// follow slowly changing conditions (e.g. other clients of the server)
+		l.baseline += (perItem - baseline) / 16
+	}
+
+	switch {
+	case perItem > baseline*overloadedRatio:
+		l.limit = max(1, l.limit*3/4)
+	case saturated && float64(perItem) <= float64(baseline)*idleRatio && l.limit < l.maxLimit:
+		l.limit = min(l.maxLimit, l.limit+max(1, l.limit/8))
+		return true
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package analyze
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// simulateReads takes all permits of the limiter and returns them with the given latency, rounds times
+func simulateReads(l *IOLimiter, rounds int, latency time.Duration) {
+	for range rounds {
+		n := l.Limit()
+		for range n {
+			l.Acquire()
+		}
+		for range n {
+			l.Release(latency, 9)
+		}
+	}
+}
+
+func TestIOLimiterFixed(t *testing.T) {
+	l := newIOLimiter(2, 2, false)
+
+	var inFlight, maxInFlight atomic.Int32
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Acquire()
+			current := inFlight.Add(1)
+			for {
+				prev := maxInFlight.Load()
+				if current <= prev || maxInFlight.CompareAndSwap(prev, current) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			inFlight.Add(-1)
+			l.Release(time.Millisecond, 0)
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+	assert.Equal(t, 2, l.Limit())
+}
+
+func TestIOLimiterAdaptiveRaise(t *testing.T) {
+	l := newIOLimiter(2, 8, true)
+
+	// latency does not grow with the number of reads in flight
+	simulateReads(l, 200, time.Millisecond)
+	assert.Equal(t, 8, l.Limit())
+}
+
+func TestIOLimiterAdaptiveLower(t *testing.T) {
+	l := newIOLimiter(8, 8, true)
+	simulateReads(l, 10, time.Millisecond)
+	assert.Equal(t, 8, l.Limit())
+
+	// storage is overloaded
+	simulateReads(l, 10, 5*time.Millisecond)
+	assert.Less(t, l.Limit(), 8)
+	assert.GreaterOrEqual(t, l.Limit(), 1)
+}
+
+func TestIOLimiterAdaptiveNotSaturated(t *testing.T) {
+	l := newIOLimiter(4, 8, true)
+
+	// only one read in flight, there is no reason to raise the limit
+	for range 100 {
+		l.Acquire()
+		l.Release(time.Millisecond, 9)
+	}
+	assert.Equal(t, 4, l.Limit())
+}
+
+func TestSetIOLimiter(t *testing.T) {
+	analyzer := CreateAnalyzer()
+	assert.Equal(t, 0, analyzer.GetProgress().IOWorkers)
+	assert.Equal(t, defaultConcurrency(), cap(analyzer.concurrencyLimit))
+
+	analyzer.SetIOLimiter(CreateIOLimiter(300))
+	assert.Equal(t, 300, analyzer.GetProgress().IOWorkers)
+	assert.Equal(t, 300, cap(analyzer.concurrencyLimit))
+
+	analyzer.SetIOLimiter(CreateAdaptiveIOLimiter())
+	assert.Equal(t, defaultConcurrency(), analyzer.GetProgress().IOWorkers)
+	assert.Equal(t, MaxAdaptiveIOWorkers, cap(analyzer.concurrencyLimit))
+
+	analyzer.SetIOLimiter(nil)
+	assert.Equal(t, 0, analyzer.GetProgress().IOWorkers)
+	assert.Equal(t, defaultConcurrency(), cap(analyzer.concurrencyLimit))
+
+	// other analyzers are not affected
+	assert.Equal(t, defaultConcurrency(), cap(CreateAnalyzer().concurrencyLimit))
+}
+
+func TestAnalyzeDirWithIOWorkers(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	for _, limiter := range []*IOLimiter{CreateIOLimiter(1), CreateAdaptiveIOLimiter()} {
+		analyzer := CreateAnalyzer()
+		analyzer.SetIOLimiter(limiter)
+		dir := analyzer.AnalyzeDir(
+			"test_dir", func(_, _ string) bool { return false }, func(_ string) bool { return false },
+		)
+		analyzer.GetDone().Wait()
+		dir.UpdateStats(make(fs.HardLinkedItems))
+
+		assert.Equal(t, int64(7), dir.GetSize())
+		assert.Equal(t, int64(5), dir.GetItemCount())
+		assert.Equal(t, limiter.Limit(), analyzer.GetProgress().IOWorkers)
+		assert.Positive(t, analyzer.GetProgress().IOWorkers)
+	}
+}
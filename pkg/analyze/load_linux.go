@@ -0,0 +1,15 @@
+package analyze
+
+import "golang.org/x/sys/unix"
+
+// siLoadShift is the fixed point shift of the load averages returned by sysinfo
+const siLoadShift = 16
+
+// getLoadAverage returns 1-minute load average of the system
+func getLoadAverage() (float64, error) {
+	var info unix.Sysinfo_t
+	if err := unix.Sysinfo(&info); err != nil {
+		return 0, err
+	}
+	return float64(info.Loads[0]) / (1 << siLoadShift), nil
+}
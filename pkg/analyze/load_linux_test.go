@@ -0,0 +1,13 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLoadAverage(t *testing.T) {
+	load, err := getLoadAverage()
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, load, 0.0)
+}
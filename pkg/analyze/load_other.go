@@ -0,0 +1,10 @@
+//go:build !linux
+
+package analyze
+
+import "errors"
+
+// getLoadAverage is not supported on this platform
+func getLoadAverage() (float64, error) {
+	return 0, errors.ErrUnsupported
+}
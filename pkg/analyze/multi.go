@@ -0,0 +1,276 @@
+package analyze
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/ignore"
+)
+
+var _ common.Analyzer = (*MultiPathAnalyzer)(nil)
+
+// MultiPathAnalyzer analyzes several paths concurrently and joins them under a synthetic root dir.
+// Every path is scanned by its own analyzer returned by the create function.
+type MultiPathAnalyzer struct {
+	paths    []string
+	rootName string
+	create   func() common.Analyzer
+
+	analyzers []common.Analyzer
+	mu        sync.Mutex
+	doneChan  common.SignalGroup
+	cancelled atomic.Bool
+
+	followSymlinks  bool
+	gitAnnexedSize  bool
+	timeFilter      common.TimeFilter
+	archiveBrowsing bool
+	sharedUsage     bool
+	fileTypeFilter  common.ShouldFileBeIgnored
+	fileFilter      common.FileFilter
+	maxDepth        int
+	ignorePatterns  []ignore.Pattern
+	useIgnoreFiles  bool
+	throttle        common.Throttle
+	ioLimiter       common.IOLimiter
+}
+
+// CreateMultiPathAnalyzer returns analyzer scanning all given paths using analyzers from the create function
+func CreateMultiPathAnalyzer(paths []string, create func() common.Analyzer) *MultiPathAnalyzer {
+	return &MultiPathAnalyzer{
+		paths:    paths,
+		rootName: fmt.Sprintf("%d paths", len(paths)),
+		create:   create,
+		doneChan: make(common.SignalGroup),
+	}
+}
+
+// RootPath returns the path of the synthetic root dir, which is empty so the root can not be acted on as a file.
+// Analyzing this path scans all paths, any other path is analyzed on its own (e.g. when rescanning a subdir).
+func (a *MultiPathAnalyzer) RootPath() string {
+	return ""
+}
+
+// AnalyzeDir analyzes given path
+func (a *MultiPathAnalyzer) AnalyzeDir(
+	path string, ignore common.ShouldDirBeIgnored, fileTypeFilter common.ShouldFileBeIgnored,
+) fs.Item {
+	done := a.GetDone()
+	defer done.Broadcast()
+
+	if path != a.RootPath() {
+		return a.newAnalyzer().AnalyzeDir(path, ignore, fileTypeFilter)
+	}
+
+	analyzers := make([]common.Analyzer, len(a.paths))
+	for i := range a.paths {
+		analyzers[i] = a.newAnalyzer()
+	}
+
+	dirs := make([]fs.Item, len(a.paths))
+	var wait sync.WaitGroup
+	for i, p := range a.paths {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			dirs[i] = analyzers[i].AnalyzeDir(p, ignore, fileTypeFilter)
+		}()
+	}
+	wait.Wait()
+
+	root := a.createRoot()
+	for _, dir := range dirs {
+		addToRoot(root, dir)
+	}
+	return root
+}
+
+// GetCurrentDir returns snapshot of all paths analyzed so far joined under the synthetic root
+func (a *MultiPathAnalyzer) GetCurrentDir() fs.Item {
+	a.mu.Lock()
+	analyzers := append([]common.Analyzer(nil), a.analyzers...)
+	a.mu.Unlock()
+
+	root := a.createRoot()
+	for _, analyzer := range analyzers {
+		current, ok := analyzer.(interface{ GetCurrentDir() fs.Item })
+		if !ok {
+			continue
+		}
+		if dir := current.GetCurrentDir(); dir != nil {
+			addToRoot(root, dir)
+		}
+	}
+	return root
+}
+
+// addToRoot adds the scanned dir to the root, hard links are counted per device of the dir
+func addToRoot(root *Dir, dir fs.Item) {
+	dir.SetParent(root)
+	if info, err := os.Stat(dir.GetPath()); err == nil {
+		if dev, ok := getDevice(info); ok {
+			root.addFileOnDevice(dir, dev)
+			return
+		}
+	}
+	root.AddFile(dir)
+}
+
+func (a *MultiPathAnalyzer) createRoot() *Dir {
+	return &Dir{
+		File: &File{
+			Name: a.rootName,
+			Flag: ' ',
+		},
+		ItemCount: 1,
+		Files:     make(fs.Files, 0, len(a.paths)),
+		synthetic: true,
+	}
+}
+
+func (a *MultiPathAnalyzer) newAnalyzer() common.Analyzer {
+	analyzer := a.create()
+	analyzer.SetFollowSymlinks(a.followSymlinks)
+	analyzer.SetShowAnnexedSize(a.gitAnnexedSize)
+	analyzer.SetTimeFilter(a.timeFilter)
+	analyzer.SetArchiveBrowsing(a.archiveBrowsing)
+	analyzer.SetSharedUsage(a.sharedUsage)
+	analyzer.SetFileTypeFilter(a.fileTypeFilter)
+	analyzer.SetFileFilter(a.fileFilter)
+	analyzer.SetMaxDepth(a.maxDepth)
+	analyzer.SetIgnorePatterns(a.ignorePatterns, a.useIgnoreFiles)
+	analyzer.SetThrottle(a.throttle)
+	analyzer.SetIOLimiter(a.ioLimiter)
+	if a.cancelled.Load() {
+		analyzer.Cancel()
+	}
+
+	a.mu.Lock()
+	a.analyzers = append(a.analyzers, analyzer)
+	a.mu.Unlock()
+	return analyzer
+}
+
+// SetFollowSymlinks sets whether symlink to files should be followed
+func (a *MultiPathAnalyzer) SetFollowSymlinks(v bool) {
+	a.followSymlinks = v
+}
+
+// SetShowAnnexedSize sets whether to use annexed size of git-annex files
+func (a *MultiPathAnalyzer) SetShowAnnexedSize(v bool) {
+	a.gitAnnexedSize = v
+}
+
+// SetTimeFilter sets the time filter function for file inclusion
+func (a *MultiPathAnalyzer) SetTimeFilter(timeFilter common.TimeFilter) {
+	a.timeFilter = timeFilter
+}
+
+// SetArchiveBrowsing sets whether browsing of zip/jar/tar archives is enabled
+func (a *MultiPathAnalyzer) SetArchiveBrowsing(v bool) {
+	a.archiveBrowsing = v
+}
+
+// SetSharedUsage sets whether usage in extents shared with other files should be found out
+func (a *MultiPathAnalyzer) SetSharedUsage(v bool) {
+	a.sharedUsage = v
+}
+
+// SetFileTypeFilter sets the file type filter function
+func (a *MultiPathAnalyzer) SetFileTypeFilter(filter common.ShouldFileBeIgnored) {
+	a.fileTypeFilter = filter
+}
+
+// SetFileFilter sets the function selecting files by their size or mode
+func (a *MultiPathAnalyzer) SetFileFilter(filter common.FileFilter) {
+	a.fileFilter = filter
+}
+
+// SetMaxDepth sets how many levels of directories below each scanned path are scanned
+func (a *MultiPathAnalyzer) SetMaxDepth(depth int) {
+	a.maxDepth = depth
+}
+
+// SetIgnorePatterns sets patterns in gitignore format and whether ignore files found in the scanned directories are applied
+func (a *MultiPathAnalyzer) SetIgnorePatterns(patterns []ignore.Pattern, useIgnoreFiles bool) {
+	a.ignorePatterns = patterns
+	a.useIgnoreFiles = useIgnoreFiles
+}
+
+// SetThrottle sets the limits of the impact of the scans on the host, shared by the scans of all paths
+func (a *MultiPathAnalyzer) SetThrottle(throttle common.Throttle) {
+	a.throttle = throttle
+}
+
+// SetIOLimiter sets the limit of directories read at once, shared by the scans of all paths
+func (a *MultiPathAnalyzer) SetIOLimiter(limiter common.IOLimiter) {
+	a.ioLimiter = limiter
+}
+
+// Cancel stops all running scans
+func (a *MultiPathAnalyzer) Cancel() {
+	a.cancelled.Store(true)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, analyzer := range a.analyzers {
+		analyzer.Cancel()
+	}
+}
+
+// GetDone returns channel for checking when analysis of all paths is done
+func (a *MultiPathAnalyzer) GetDone() common.SignalGroup {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.doneChan
+}
+
+// GetProgress returns progress summed over all running scans
+func (a *MultiPathAnalyzer) GetProgress() common.CurrentProgress {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var progress common.CurrentProgress
+	for _, analyzer := range a.analyzers {
+		p := analyzer.GetProgress()
+		progress.ItemCount += p.ItemCount
+		progress.TotalUsage += p.TotalUsage
+		progress.IOWorkers = max(progress.IOWorkers, p.IOWorkers)
+		if p.Throttle != "" {
+			progress.Throttle = p.Throttle
+		}
+		if p.CurrentItemName != "" {
+			progress.CurrentItemName = p.CurrentItemName
+		}
+	}
+	return progress
+}
+
+// GetScanErrors returns the errors of all scans sorted by path
+func (a *MultiPathAnalyzer) GetScanErrors() []common.ScanError {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var errs []common.ScanError
+	for _, analyzer := range a.analyzers {
+		if reporter, ok := analyzer.(common.ScanErrorReporter); ok {
+			errs = append(errs, reporter.GetScanErrors()...)
+		}
+	}
+	common.SortScanErrors(errs)
+	return errs
+}
+
+// ResetProgress prepares the analyzer for a new scan
+func (a *MultiPathAnalyzer) ResetProgress() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.analyzers = nil
+	a.doneChan = make(common.SignalGroup)
+	a.cancelled.Store(false)
+}
@@ -0,0 +1,98 @@
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func createParallelAnalyzer() common.Analyzer {
+	return CreateAnalyzer()
+}
+
+func TestMultiPathAnalyzeDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	first := filepath.Join(tmpDir, "first")
+	second := filepath.Join(tmpDir, "second")
+	assert.Nil(t, os.MkdirAll(first, 0o755))
+	assert.Nil(t, os.MkdirAll(second, 0o755))
+	assert.Nil(t, os.WriteFile(filepath.Join(first, "file"), []byte("hello"), 0o600))
+	assert.Nil(t, os.WriteFile(filepath.Join(second, "file"), []byte("go"), 0o600))
+
+	analyzer := CreateMultiPathAnalyzer([]string{first, second}, createParallelAnalyzer)
+	assert.Equal(t, "", analyzer.RootPath())
+
+	dir := analyzer.AnalyzeDir(
+		analyzer.RootPath(), func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	).(*Dir)
+	analyzer.GetDone().Wait()
+	dir.UpdateStats(make(fs.HardLinkedItems))
+
+	// the synthetic root has no path so it can not be acted on
+	assert.Equal(t, "", dir.GetPath())
+	assert.Equal(t, "2 paths", dir.GetName())
+	assert.Equal(t, 2, len(dir.Files))
+	assert.Equal(t, dir.Files[0].GetSize()+dir.Files[1].GetSize(), dir.GetSize())
+	assert.Equal(t, int64(5), dir.GetItemCount())
+
+	paths := []string{dir.Files[0].GetPath(), dir.Files[1].GetPath()}
+	assert.ElementsMatch(t, []string{first, second}, paths)
+	assert.Equal(t, dir, dir.Files[0].GetParent())
+}
+
+func TestMultiPathAnalyzeDirCountsHardLinksOnce(t *testing.T) {
+	tmpDir := t.TempDir()
+	first := filepath.Join(tmpDir, "first")
+	second := filepath.Join(tmpDir, "second")
+	assert.Nil(t, os.MkdirAll(first, 0o755))
+	assert.Nil(t, os.MkdirAll(second, 0o755))
+	assert.Nil(t, os.WriteFile(filepath.Join(first, "file"), make([]byte, 10000), 0o600))
+	if err := os.Link(filepath.Join(first, "file"), filepath.Join(second, "link")); err != nil {
+		t.Skip("hard links not supported")
+	}
+
+	analyzer := CreateMultiPathAnalyzer([]string{first, second}, createParallelAnalyzer)
+	dir := analyzer.AnalyzeDir(
+		analyzer.RootPath(), func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	)
+	analyzer.GetDone().Wait()
+	dir.UpdateStats(make(fs.HardLinkedItems))
+
+	assert.Equal(t, int64(10000), dir.GetSize())
+}
+
+func TestMultiPathAnalyzeSingleDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(tmpDir, "file"), []byte("hello"), 0o600))
+
+	analyzer := CreateMultiPathAnalyzer([]string{tmpDir, "/nonexistent"}, createParallelAnalyzer)
+	analyzer.SetFollowSymlinks(true)
+
+	dir := analyzer.AnalyzeDir(
+		tmpDir, func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	)
+	analyzer.GetDone().Wait()
+
+	assert.Equal(t, tmpDir, dir.GetPath())
+
+	analyzer.ResetProgress()
+	assert.Equal(t, common.CurrentProgress{}, analyzer.GetProgress())
+}
+
+func TestMultiPathCancel(t *testing.T) {
+	analyzer := CreateMultiPathAnalyzer([]string{t.TempDir()}, createParallelAnalyzer)
+	analyzer.Cancel()
+
+	dir := analyzer.AnalyzeDir(
+		analyzer.RootPath(), func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	).(*Dir)
+	analyzer.GetDone().Wait()
+
+	assert.Equal(t, 1, len(dir.Files))
+	assert.NotNil(t, analyzer.GetCurrentDir())
+}
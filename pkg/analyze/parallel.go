@@ -3,15 +3,13 @@ package analyze
 import (
 	"os"
 	"path/filepath"
-	"runtime"
 
 	"github.com/dundee/gdu/v5/internal/common"
 	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/ignore"
 	log "github.com/sirupsen/logrus"
 )
 
-var concurrencyLimit = make(chan struct{}, 2*runtime.GOMAXPROCS(0))
-
 var _ common.Analyzer = (*ParallelAnalyzer)(nil)
 
 // ParallelAnalyzer implements Analyzer
@@ -31,10 +29,11 @@ func (a *ParallelAnalyzer) AnalyzeDir(
 	path string, ignore common.ShouldDirBeIgnored, fileTypeFilter common.ShouldFileBeIgnored,
 ) fs.Item {
 	a.ignoreDir = ignore
+	a.rootPath = path
 	a.ignoreFileType = fileTypeFilter
 
 	go a.UpdateProgress()
-	dir := a.processDir(path)
+	dir := a.processDir(path, a.rootIgnoreRules(path))
 
 	dir.BasePath = filepath.Dir(path)
 	a.setCurrentDir(dir)
@@ -46,17 +45,17 @@ func (a *ParallelAnalyzer) AnalyzeDir(
 	return dir
 }
 
-func (a *ParallelAnalyzer) processQueuedDir(path string, parent *Dir, result chan<- *Dir) {
-	concurrencyLimit <- struct{}{}
+func (a *ParallelAnalyzer) processQueuedDir(path string, rules *ignore.Rules, parent *Dir, result chan<- *Dir) {
+	a.concurrencyLimit <- struct{}{}
 	if a.IsCancelled() {
-		<-concurrencyLimit
+		<-a.concurrencyLimit
 		result <- nil
 		return
 	}
 
-	subdir := a.processDir(path)
+	subdir := a.processDir(path, rules)
 	subdir.Parent = parent
-	<-concurrencyLimit
+	<-a.concurrencyLimit
 	result <- subdir
 }
 
@@ -66,7 +65,7 @@ func addSubDir(parent, child *Dir) {
 	}
 }
 
-func (a *ParallelAnalyzer) processDir(path string) *Dir {
+func (a *ParallelAnalyzer) processDir(path string, rules *ignore.Rules) *Dir {
 	var (
 		file       fs.Item
 		err        error
@@ -78,20 +77,22 @@ func (a *ParallelAnalyzer) processDir(path string) *Dir {
 
 	a.wait.Add(1)
 
-	files, err := os.ReadDir(path)
+	files, err := a.readDirLimited(path)
 	if err != nil {
-		log.Print(err.Error())
+		a.addScanError(path, err)
 	}
+	dirInfo := statDir(path)
 
 	dir := &Dir{
 		File: &File{
 			Name: filepath.Base(path),
-			Flag: getDirFlag(err, len(files)),
+			Flag: getDirFlag(err, len(files), isSubvolume(path, dirInfo)),
 		},
 		ItemCount: 1,
 		Files:     make(fs.Files, 0, len(files)),
 	}
-	setDirPlatformSpecificAttrs(dir, path)
+	setDirPlatformSpecificAttrs(dir, dirInfo)
+	rules = a.dirIgnoreRules(rules, path, files)
 
 	for _, f := range files {
 		if a.IsCancelled() {
@@ -100,21 +101,24 @@ func (a *ParallelAnalyzer) processDir(path string) *Dir {
 		name := f.Name()
 		entryPath := filepath.Join(path, name)
 		if f.IsDir() {
-			if a.shouldSkipDir(name, entryPath) {
+			if a.shouldSkipDir(rules, name, entryPath) {
 				continue
 			}
 			dirCount++
 
-			go a.processQueuedDir(entryPath, dir, subDirChan)
+			go a.processQueuedDir(entryPath, rules, dir, subDirChan)
 		} else {
 			// Apply file type filter if set
 			if a.ignoreFileType != nil && a.ignoreFileType(name) {
 				continue // Skip this file
 			}
+			if rules.Match(entryPath, false) {
+				continue
+			}
 
 			info, err = f.Info()
 			if err != nil {
-				log.Print(err.Error())
+				a.addScanError(entryPath, err)
 				dir.SetFlag('!')
 				continue
 			}
@@ -122,7 +126,7 @@ func (a *ParallelAnalyzer) processDir(path string) *Dir {
 			if a.followSymlinks && info.Mode()&os.ModeSymlink != 0 {
 				infoF, err := followSymlink(entryPath, a.gitAnnexedSize)
 				if err != nil {
-					log.Print(err.Error())
+					a.addScanError(entryPath, err)
 					dir.SetFlag('!')
 					continue
 				}
@@ -133,8 +137,8 @@ func (a *ParallelAnalyzer) processDir(path string) *Dir {
 
 			symlinkTarget := readSymlinkTarget(f.Type(), entryPath)
 
-			// Apply time filter if set
-			if a.matchesTimeFilterFn != nil && !a.matchesTimeFilterFn(info.ModTime()) {
+			// Apply time and file filters if set
+			if !a.includeFile(info) {
 				continue // Skip this file
 			}
 
@@ -186,6 +190,7 @@ func (a *ParallelAnalyzer) processDir(path string) *Dir {
 				// Only set platform-specific attributes for regular files
 				if regularFile, ok := file.(*File); ok {
 					setPlatformSpecificAttrs(regularFile, info)
+					a.setSharedUsage(regularFile, info, entryPath)
 				}
 				totalUsage += file.GetUsage()
 				dir.AddFile(file)
@@ -210,10 +215,12 @@ func (a *ParallelAnalyzer) processDir(path string) *Dir {
 	return dir
 }
 
-func getDirFlag(err error, items int) rune {
+func getDirFlag(err error, items int, subvolume bool) rune {
 	switch {
 	case err != nil:
 		return '!'
+	case subvolume:
+		return 'S'
 	case items == 0:
 		return 'e'
 	default:
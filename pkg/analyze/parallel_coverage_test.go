@@ -29,20 +29,25 @@ func TestParallelAnalyzerSetShowAnnexedSize(t *testing.T) {
 }
 
 func TestGetDirFlagWithError(t *testing.T) {
-	flag := getDirFlag(os.ErrNotExist, 5)
+	flag := getDirFlag(os.ErrNotExist, 5, true)
 	assert.Equal(t, '!', flag)
 }
 
 func TestGetDirFlagWithEmptyDir(t *testing.T) {
-	flag := getDirFlag(nil, 0)
+	flag := getDirFlag(nil, 0, false)
 	assert.Equal(t, 'e', flag)
 }
 
 func TestGetDirFlagWithNormalDir(t *testing.T) {
-	flag := getDirFlag(nil, 5)
+	flag := getDirFlag(nil, 5, false)
 	assert.Equal(t, ' ', flag)
 }
 
+func TestGetDirFlagWithSubvolume(t *testing.T) {
+	flag := getDirFlag(nil, 0, true)
+	assert.Equal(t, 'S', flag)
+}
+
 func TestGetFlagWithSymlink(t *testing.T) {
 	// Create a temporary symlink
 	symlinkPath := "/tmp/test_symlink"
@@ -153,8 +158,14 @@ func TestAnalyzersMarkFilesystemErrors(t *testing.T) {
 				filePath := filepath.Join(root, "removed-during-scan")
 				require.NoError(t, os.WriteFile(filePath, []byte("data"), 0o600))
 
+				// os.ReadDir stats the files lazily, the fast reader on Linux
+				// stats them before the file type filter is called
+				defer func(orig func(string) ([]os.DirEntry, error)) { readDirEntries = orig }(readDirEntries)
+				readDirEntries = os.ReadDir
+
 				var removeErr error
-				dir := factory.new().AnalyzeDir(
+				analyzer := factory.new()
+				dir := analyzer.AnalyzeDir(
 					root,
 					func(_, _ string) bool { return false },
 					func(string) bool {
@@ -165,6 +176,12 @@ func TestAnalyzersMarkFilesystemErrors(t *testing.T) {
 
 				require.NoError(t, removeErr)
 				assert.Equal(t, '!', dir.GetFlag())
+
+				errs := analyzer.(common.ScanErrorReporter).GetScanErrors()
+				require.Len(t, errs, 1)
+				assert.Equal(t, filePath, errs[0].Path)
+				assert.Equal(t, "lstat", errs[0].Op)
+				assert.ErrorIs(t, errs[0].Err, os.ErrNotExist)
 			})
 
 			t.Run("broken symlink", func(t *testing.T) {
@@ -183,6 +200,10 @@ func TestAnalyzersMarkFilesystemErrors(t *testing.T) {
 				)
 
 				assert.Equal(t, '!', dir.GetFlag())
+
+				errs := analyzer.(common.ScanErrorReporter).GetScanErrors()
+				require.Len(t, errs, 1)
+				assert.Equal(t, filepath.Join(root, "broken-link"), errs[0].Path)
 			})
 		})
 	}
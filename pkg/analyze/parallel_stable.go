@@ -6,7 +6,7 @@ import (
 
 	"github.com/dundee/gdu/v5/internal/common"
 	"github.com/dundee/gdu/v5/pkg/fs"
-	log "github.com/sirupsen/logrus"
+	"github.com/dundee/gdu/v5/pkg/ignore"
 )
 
 // ParallelStableOrderAnalyzer implements Analyzer
@@ -26,10 +26,11 @@ func (a *ParallelStableOrderAnalyzer) AnalyzeDir(
 	path string, ignore common.ShouldDirBeIgnored, fileTypeFilter common.ShouldFileBeIgnored,
 ) fs.Item {
 	a.ignoreDir = ignore
+	a.rootPath = path
 	a.ignoreFileType = fileTypeFilter
 
 	go a.UpdateProgress()
-	dir := a.processDir(path)
+	dir := a.processDir(path, a.rootIgnoreRules(path))
 
 	dir.BasePath = filepath.Dir(path)
 	a.wait.Wait()
@@ -40,7 +41,7 @@ func (a *ParallelStableOrderAnalyzer) AnalyzeDir(
 	return dir
 }
 
-func (a *ParallelStableOrderAnalyzer) processDir(path string) *Dir {
+func (a *ParallelStableOrderAnalyzer) processDir(path string, rules *ignore.Rules) *Dir {
 	type indexedItem struct {
 		index int
 		item  fs.Item
@@ -57,20 +58,22 @@ func (a *ParallelStableOrderAnalyzer) processDir(path string) *Dir {
 
 	a.wait.Add(1)
 
-	files, err := os.ReadDir(path)
+	files, err := a.readDirLimited(path)
 	if err != nil {
-		log.Print(err.Error())
+		a.addScanError(path, err)
 	}
+	dirInfo := statDir(path)
 
 	dir := &Dir{
 		File: &File{
 			Name: filepath.Base(path),
-			Flag: getDirFlag(err, len(files)),
+			Flag: getDirFlag(err, len(files), isSubvolume(path, dirInfo)),
 		},
 		ItemCount: 1,
 		Files:     make(fs.Files, 0, len(files)),
 	}
-	setDirPlatformSpecificAttrs(dir, path)
+	setDirPlatformSpecificAttrs(dir, dirInfo)
+	rules = a.dirIgnoreRules(rules, path, files)
 
 	// Buffer channel to prevent deadlock when sending files synchronously
 	itemChan := make(chan indexedItem, len(files))
@@ -83,7 +86,7 @@ func (a *ParallelStableOrderAnalyzer) processDir(path string) *Dir {
 		entryPath := filepath.Join(path, name)
 
 		if f.IsDir() {
-			if a.shouldSkipDir(name, entryPath) {
+			if a.shouldSkipDir(rules, name, entryPath) {
 				continue
 			}
 			currentIndex := itemCount
@@ -91,16 +94,16 @@ func (a *ParallelStableOrderAnalyzer) processDir(path string) *Dir {
 			dirCount++
 
 			go func(entryPath string, idx int) {
-				concurrencyLimit <- struct{}{}
+				a.concurrencyLimit <- struct{}{}
 				if a.IsCancelled() {
-					<-concurrencyLimit
+					<-a.concurrencyLimit
 					itemChan <- indexedItem{idx, nil}
 					return
 				}
-				subdir := a.processDir(entryPath)
+				subdir := a.processDir(entryPath, rules)
 				subdir.Parent = dir
 
-				<-concurrencyLimit
+				<-a.concurrencyLimit
 				itemChan <- indexedItem{idx, subdir}
 			}(entryPath, currentIndex)
 		} else {
@@ -108,10 +111,13 @@ func (a *ParallelStableOrderAnalyzer) processDir(path string) *Dir {
 			if a.ignoreFileType != nil && a.ignoreFileType(name) {
 				continue // Skip this file
 			}
+			if rules.Match(entryPath, false) {
+				continue
+			}
 
 			info, err = f.Info()
 			if err != nil {
-				log.Print(err.Error())
+				a.addScanError(entryPath, err)
 				dir.SetFlag('!')
 				continue
 			}
@@ -119,7 +125,7 @@ func (a *ParallelStableOrderAnalyzer) processDir(path string) *Dir {
 			if a.followSymlinks && info.Mode()&os.ModeSymlink != 0 {
 				infoF, err := followSymlink(entryPath, a.gitAnnexedSize)
 				if err != nil {
-					log.Print(err.Error())
+					a.addScanError(entryPath, err)
 					dir.SetFlag('!')
 					continue
 				}
@@ -128,8 +134,8 @@ func (a *ParallelStableOrderAnalyzer) processDir(path string) *Dir {
 				}
 			}
 
-			// Apply time filter if set
-			if a.matchesTimeFilterFn != nil && !a.matchesTimeFilterFn(info.ModTime()) {
+			// Apply time and file filters if set
+			if !a.includeFile(info) {
 				continue // Skip this file
 			}
 
@@ -141,6 +147,7 @@ func (a *ParallelStableOrderAnalyzer) processDir(path string) *Dir {
 				Symlink: readSymlinkTarget(f.Type(), entryPath),
 			}
 			setPlatformSpecificAttrs(file, info)
+			a.setSharedUsage(file, info, entryPath)
 
 			totalSize += file.Usage
 
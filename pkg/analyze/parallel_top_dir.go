@@ -8,7 +8,7 @@ import (
 
 	"github.com/dundee/gdu/v5/internal/common"
 	"github.com/dundee/gdu/v5/pkg/fs"
-	log "github.com/sirupsen/logrus"
+	"github.com/dundee/gdu/v5/pkg/ignore"
 )
 
 var pathSep = string(os.PathSeparator)
@@ -41,6 +41,7 @@ func (a *TopDirAnalyzer) AnalyzeDir(
 	path string, ignore common.ShouldDirBeIgnored, fileTypeFilter common.ShouldFileBeIgnored,
 ) fs.Item {
 	a.ignoreDir = ignore
+	a.rootPath = path
 	if fileTypeFilter != nil {
 		a.ignoreFileType = fileTypeFilter
 	}
@@ -49,15 +50,17 @@ func (a *TopDirAnalyzer) AnalyzeDir(
 
 	go a.UpdateProgress()
 
-	files, err := os.ReadDir(path)
+	files, err := a.readDirLimited(path)
 	if err != nil {
-		log.Print(err.Error())
+		a.addScanError(path, err)
 	}
+	dirInfo := statDir(path)
+	rules := a.dirIgnoreRules(a.rootIgnoreRules(path), path, files)
 
 	dir := SimpleDir{
 		SimpleFile: SimpleFile{
 			Name:      filepath.Base(path),
-			Flag:      getDirFlag(err, len(files)),
+			Flag:      getDirFlag(err, len(files), isSubvolume(path, dirInfo)),
 			IsDir:     true,
 			ItemCount: 1,
 		},
@@ -73,7 +76,7 @@ func (a *TopDirAnalyzer) AnalyzeDir(
 		name := f.Name()
 		entryPath := filepath.Join(path, name)
 		if f.IsDir() {
-			if a.shouldSkipDir(name, entryPath) {
+			if a.shouldSkipDir(rules, name, entryPath) {
 				continue
 			}
 			topDir := &TopDir{
@@ -82,7 +85,7 @@ func (a *TopDirAnalyzer) AnalyzeDir(
 			}
 			topDirs = append(topDirs, topDir)
 			go func(entryPath string) {
-				a.processSubDir(entryPath, topDir)
+				a.processSubDir(entryPath, rules, topDir)
 				subDirChan <- struct{}{}
 			}(entryPath)
 		} else {
@@ -91,23 +94,26 @@ func (a *TopDirAnalyzer) AnalyzeDir(
 			if a.ignoreFileType(name) {
 				continue // Skip this file
 			}
+			if rules.Match(entryPath, false) {
+				continue
+			}
 
 			info, err = f.Info()
 			if err != nil {
-				log.Print(err.Error())
+				a.addScanError(entryPath, err)
 				dir.Flag = '!'
 				continue
 			}
 
-			// Apply time filter if set
-			if !a.matchesTimeFilterFn(info.ModTime()) {
+			// Apply time and file filters if set
+			if !a.includeFile(info) {
 				continue // Skip this file
 			}
 
 			if a.followSymlinks && info.Mode()&os.ModeSymlink != 0 {
 				infoF, err := followSymlink(entryPath, a.gitAnnexedSize)
 				if err != nil {
-					log.Print(err.Error())
+					a.addScanError(entryPath, err)
 					dir.Flag = '!'
 					continue
 				}
@@ -160,7 +166,7 @@ func (a *TopDirAnalyzer) AnalyzeDir(
 	return &dir
 }
 
-func (a *TopDirAnalyzer) processSubDir(path string, topDir *TopDir) {
+func (a *TopDirAnalyzer) processSubDir(path string, rules *ignore.Rules, topDir *TopDir) {
 	var (
 		err        error
 		totalSize  int64
@@ -169,11 +175,12 @@ func (a *TopDirAnalyzer) processSubDir(path string, topDir *TopDir) {
 		info       os.FileInfo
 	)
 
-	files, err := os.ReadDir(path)
+	files, err := a.readDirLimited(path)
 	if err != nil {
-		log.Print(err.Error())
+		a.addScanError(path, err)
 		topDir.SetFlag('.')
 	}
+	rules = a.dirIgnoreRules(rules, path, files)
 
 	for _, f := range files {
 		if a.IsCancelled() {
@@ -182,38 +189,41 @@ func (a *TopDirAnalyzer) processSubDir(path string, topDir *TopDir) {
 		name := f.Name()
 		entryPath := path + pathSep + name
 		if f.IsDir() {
-			if a.shouldSkipDir(name, entryPath) {
+			if a.shouldSkipDir(rules, name, entryPath) {
 				continue
 			}
 
 			totalCount++
 
 			select {
-			case concurrencyLimit <- struct{}{}:
+			case a.concurrencyLimit <- struct{}{}:
 				a.wait.Add(1)
 				go func(entryPath string) {
-					a.processSubDir(entryPath, topDir)
-					<-concurrencyLimit
+					a.processSubDir(entryPath, rules, topDir)
+					<-a.concurrencyLimit
 					a.wait.Done()
 				}(entryPath)
 			default:
-				a.processSubDir(entryPath, topDir)
+				a.processSubDir(entryPath, rules, topDir)
 			}
 		} else {
 			// Apply file type filter if set
 			if a.ignoreFileType(name) {
 				continue // Skip this file
 			}
+			if rules.Match(entryPath, false) {
+				continue
+			}
 
 			info, err = f.Info()
 			if err != nil {
-				log.Print(err.Error())
+				a.addScanError(entryPath, err)
 				topDir.SetFlag('.')
 				continue
 			}
 
-			// Apply time filter if set
-			if !a.matchesTimeFilterFn(info.ModTime()) {
+			// Apply time and file filters if set
+			if !a.includeFile(info) {
 				continue // Skip this file
 			}
 
@@ -222,7 +232,7 @@ func (a *TopDirAnalyzer) processSubDir(path string, topDir *TopDir) {
 			if a.followSymlinks && info.Mode()&os.ModeSymlink != 0 {
 				infoF, err := followSymlink(entryPath, a.gitAnnexedSize)
 				if err != nil {
-					log.Print(err.Error())
+					a.addScanError(entryPath, err)
 					topDir.SetFlag('.')
 					continue
 				}
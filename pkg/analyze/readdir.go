@@ -0,0 +1,33 @@
+package analyze
+
+import (
+	"io/fs"
+	"os"
+)
+
+// statedEntry is a dir entry with the info fetched when the dir was read
+type statedEntry struct {
+	os.DirEntry
+	info fs.FileInfo
+	err  error
+}
+
+// Info returns the info of the entry fetched when the dir was read
+func (e *statedEntry) Info() (fs.FileInfo, error) {
+	return e.info, e.err
+}
+
+// readDirStated reads the entries of the dir sorted by name as os.ReadDir
+// and stats the non-dir entries right away, so that the stats are done within the I/O limit
+func readDirStated(path string) ([]os.DirEntry, error) {
+	files, err := os.ReadDir(path)
+	for i, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		entry := &statedEntry{DirEntry: f}
+		entry.info, entry.err = f.Info()
+		files[i] = entry
+	}
+	return files, err
+}
@@ -0,0 +1,310 @@
+package analyze
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// direntBufSize is the size of the buffer filled by one getdents64 call
+	direntBufSize = 64 * 1024
+
+	// offsets of the fields of struct linux_dirent64
+	direntReclenOff = 16
+	direntTypeOff   = 18
+	direntNameOff   = 19
+
+	// statxMask contains only the fields used by the analyzers
+	statxMask = unix.STATX_TYPE | unix.STATX_MODE | unix.STATX_NLINK | unix.STATX_INO |
+		unix.STATX_SIZE | unix.STATX_BLOCKS | unix.STATX_MTIME
+	// statxFlags do not follow symlinks (as lstat)
+	statxFlags = unix.AT_SYMLINK_NOFOLLOW
+)
+
+// readDirEntries reads the entries of the dir sorted by name the same way as os.ReadDir
+var readDirEntries = readDirFast
+
+// fastReadDirUnsupported is set when getdents64 or statx is not available (old kernel, seccomp)
+var fastReadDirUnsupported atomic.Bool
+
+// readDirFast reads the dir with batched getdents64 calls and stats the non-dir entries
+// with statx relative to the dir fd, asking only for the needed fields.
+// It falls back to os.ReadDir (with the entries stated) when the syscalls are not available.
+func readDirFast(path string) ([]os.DirEntry, error) {
+	if fastReadDirUnsupported.Load() {
+		return readDirStated(path)
+	}
+
+	fd, err := openDir(path)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	defer unix.Close(fd)
+
+	entries, err := readDirents(fd, path)
+	if statErr := statEntries(fd, entries); statErr != nil {
+		err = statErr
+	}
+	if isUnsupported(err) {
+		fastReadDirUnsupported.Store(true)
+		return readDirStated(path)
+	}
+
+	res := make([]os.DirEntry, 0, len(entries))
+	for i := range entries {
+		if !entries[i].gone {
+			res = append(res, &entries[i])
+		}
+	}
+	slices.SortFunc(res, func(a, b os.DirEntry) int {
+		return cmp.Compare(a.Name(), b.Name())
+	})
+
+	if err != nil {
+		return res, &os.PathError{Op: "readdirent", Path: path, Err: err}
+	}
+	return res, nil
+}
+
+func openDir(path string) (int, error) {
+	for {
+		fd, err := unix.Open(path, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+		if err != unix.EINTR {
+			return fd, err
+		}
+	}
+}
+
+// readDirents returns all entries of the dir except "." and ".."
+func readDirents(fd int, path string) ([]dirEntry, error) {
+	type dirent struct {
+		name string
+		typ  byte
+	}
+	var (
+		buf     = make([]byte, direntBufSize)
+		dirents []dirent
+		readErr error
+	)
+
+	for {
+		n, err := unix.Getdents(fd, buf)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+		if n <= 0 {
+			break
+		}
+
+		for rec := buf[:n]; len(rec) > direntNameOff; {
+			reclen := int(binary.NativeEndian.Uint16(rec[direntReclenOff:]))
+			if reclen <= direntNameOff || reclen > len(rec) {
+				break
+			}
+			name := rec[direntNameOff:reclen]
+			if i := bytes.IndexByte(name, 0); i >= 0 {
+				name = name[:i]
+			}
+			typ := rec[direntTypeOff]
+			rec = rec[reclen:]
+
+			if string(name) == "." || string(name) == ".." {
+				continue
+			}
+			dirents = append(dirents, dirent{name: string(name), typ: typ})
+		}
+	}
+
+	// entries are allocated at once as they are much bigger than dirents
+	entries := make([]dirEntry, len(dirents))
+	for i, d := range dirents {
+		entries[i] = dirEntry{dir: path, name: d.name, typ: direntType(d.typ)}
+	}
+	return entries, readErr
+}
+
+// statEntries fills the attributes of all entries other than dirs
+// (and of entries with unknown type).
+// Big batches are submitted to io_uring if available.
+func statEntries(fd int, entries []dirEntry) error {
+	toStat := make([]*dirEntry, 0, len(entries))
+	for i := range entries {
+		if !entries[i].typ.IsDir() {
+			toStat = append(toStat, &entries[i])
+		}
+	}
+
+	if len(toStat) >= uringMinBatch {
+		if ring := getUring(); ring != nil {
+			err := ring.statEntries(fd, toStat)
+			putUring(ring, err)
+			if err == nil {
+				return nil
+			}
+		}
+	}
+
+	var stx unix.Statx_t
+	for _, e := range toStat {
+		err := statxEntry(fd, e.name, &stx)
+		if isUnsupported(err) {
+			return err
+		}
+		e.setStatx(&stx, err)
+	}
+	return nil
+}
+
+func statxEntry(fd int, name string, stx *unix.Statx_t) error {
+	for {
+		err := unix.Statx(fd, name, statxFlags, statxMask, stx)
+		if err != unix.EINTR {
+			return err
+		}
+	}
+}
+
+// isUnsupported reports whether the error means the syscall is not available at all.
+// Other errors (e.g. EPERM or EACCES) are errors of the single entry.
+func isUnsupported(err error) bool {
+	return errors.Is(err, unix.ENOSYS) || errors.Is(err, unix.EOPNOTSUPP)
+}
+
+func direntType(typ byte) os.FileMode {
+	switch typ {
+	case unix.DT_BLK:
+		return os.ModeDevice
+	case unix.DT_CHR:
+		return os.ModeDevice | os.ModeCharDevice
+	case unix.DT_DIR:
+		return os.ModeDir
+	case unix.DT_FIFO:
+		return os.ModeNamedPipe
+	case unix.DT_LNK:
+		return os.ModeSymlink
+	case unix.DT_REG:
+		return 0
+	case unix.DT_SOCK:
+		return os.ModeSocket
+	}
+	return os.ModeIrregular // unknown, given by statx
+}
+
+// fileMode converts st_mode to os.FileMode the same way as package os
+func fileMode(mode uint32) os.FileMode {
+	res := os.FileMode(mode & 0o777)
+	switch mode & unix.S_IFMT {
+	case unix.S_IFBLK:
+		res |= os.ModeDevice
+	case unix.S_IFCHR:
+		res |= os.ModeDevice | os.ModeCharDevice
+	case unix.S_IFDIR:
+		res |= os.ModeDir
+	case unix.S_IFIFO:
+		res |= os.ModeNamedPipe
+	case unix.S_IFLNK:
+		res |= os.ModeSymlink
+	case unix.S_IFSOCK:
+		res |= os.ModeSocket
+	}
+	if mode&unix.S_ISGID != 0 {
+		res |= os.ModeSetgid
+	}
+	if mode&unix.S_ISUID != 0 {
+		res |= os.ModeSetuid
+	}
+	if mode&unix.S_ISVTX != 0 {
+		res |= os.ModeSticky
+	}
+	return res
+}
+
+// dirEntry implements os.DirEntry and os.FileInfo of entry read by readDirFast
+type dirEntry struct {
+	dir  string
+	name string
+	typ  os.FileMode
+	mode os.FileMode
+	stat syscall.Stat_t
+	err  error
+	gone bool
+	ok   bool
+}
+
+// setStatx fills the attributes of the entry from the result of statx
+func (e *dirEntry) setStatx(stx *unix.Statx_t, err error) {
+	if err == unix.ENOENT {
+		// removed since it was read
+		e.gone = true
+		return
+	}
+	if err != nil {
+		e.err = &os.PathError{Op: "statx", Path: filepath.Join(e.dir, e.name), Err: err}
+		return
+	}
+
+	e.mode = fileMode(uint32(stx.Mode))
+	e.typ = e.mode.Type()
+	e.ok = true
+
+	setUint(&e.stat.Dev, unix.Mkdev(stx.Dev_major, stx.Dev_minor))
+	e.stat.Ino = stx.Ino
+	e.stat.Mode = uint32(stx.Mode)
+	setUint(&e.stat.Nlink, stx.Nlink)
+	e.stat.Size = int64(stx.Size)     // nolint: gosec // Why: sizes fit into int64
+	e.stat.Blocks = int64(stx.Blocks) // nolint: gosec // Why: block counts fit into int64
+	e.stat.Mtim = syscall.NsecToTimespec(stx.Mtime.Sec*int64(time.Second) + int64(stx.Mtime.Nsec))
+}
+
+// setUint sets field of syscall.Stat_t whose type differs between architectures
+func setUint[T ~uint32 | ~uint64, V ~uint32 | ~uint64](dst *T, v V) {
+	*dst = T(v)
+}
+
+// Name returns name of the entry
+func (e *dirEntry) Name() string { return e.name }
+
+// IsDir returns true for directories
+func (e *dirEntry) IsDir() bool { return e.typ.IsDir() }
+
+// Type returns type bits of the entry
+func (e *dirEntry) Type() os.FileMode { return e.typ }
+
+// Info returns attributes of the entry, dirs are not stated when read so they are stated now
+func (e *dirEntry) Info() (os.FileInfo, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	if !e.ok {
+		return os.Lstat(filepath.Join(e.dir, e.name))
+	}
+	return e, nil
+}
+
+// Size returns apparent size of the entry
+func (e *dirEntry) Size() int64 { return e.stat.Size }
+
+// Mode returns mode of the entry
+func (e *dirEntry) Mode() os.FileMode { return e.mode }
+
+// ModTime returns modification time of the entry
+func (e *dirEntry) ModTime() time.Time {
+	return time.Unix(int64(e.stat.Mtim.Sec), int64(e.stat.Mtim.Nsec))
+}
+
+// Sys returns *syscall.Stat_t with the fields filled by statx
+func (e *dirEntry) Sys() any { return &e.stat }
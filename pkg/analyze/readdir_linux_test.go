@@ -0,0 +1,279 @@
+//go:build linux
+
+package analyze
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func createReadDirTestDir(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "dir"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "file"), make([]byte, 5000), 0o640))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "empty"), nil, 0o600))
+	require.NoError(t, os.Link(filepath.Join(root, "file"), filepath.Join(root, "link")))
+	require.NoError(t, os.Symlink("file", filepath.Join(root, "symlink")))
+	require.NoError(t, syscall.Mkfifo(filepath.Join(root, "fifo"), 0o600))
+	require.NoError(t, os.Chmod(filepath.Join(root, "empty"), 0o600|os.ModeSetuid|os.ModeSticky))
+	if l, err := net.Listen("unix", filepath.Join(root, "socket")); err == nil {
+		defer l.Close()
+		if ul, ok := l.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+	return root
+}
+
+func TestReadDirFast(t *testing.T) {
+	root := createReadDirTestDir(t)
+
+	expected, err := os.ReadDir(root)
+	require.NoError(t, err)
+	entries, err := readDirFast(root)
+	require.NoError(t, err)
+	require.Len(t, entries, len(expected))
+
+	for i, e := range expected {
+		entry := entries[i]
+		assert.Equal(t, e.Name(), entry.Name())
+		assert.Equal(t, e.IsDir(), entry.IsDir(), e.Name())
+		assert.Equal(t, e.Type(), entry.Type(), e.Name())
+
+		expectedInfo, err := e.Info()
+		require.NoError(t, err)
+		info, err := entry.Info()
+		require.NoError(t, err)
+		assert.Equal(t, expectedInfo.Name(), info.Name())
+		assert.Equal(t, expectedInfo.Mode(), info.Mode(), e.Name())
+		assert.Equal(t, expectedInfo.ModTime(), info.ModTime(), e.Name())
+		if !e.IsDir() {
+			assert.Equal(t, expectedInfo.Size(), info.Size(), e.Name())
+		}
+
+		expectedStat := expectedInfo.Sys().(*syscall.Stat_t)
+		stat := info.Sys().(*syscall.Stat_t)
+		assert.Equal(t, expectedStat.Dev, stat.Dev, e.Name())
+		assert.Equal(t, expectedStat.Ino, stat.Ino, e.Name())
+		assert.Equal(t, expectedStat.Nlink, stat.Nlink, e.Name())
+		assert.Equal(t, expectedStat.Blocks, stat.Blocks, e.Name())
+		assert.Equal(t, expectedStat.Mode, stat.Mode, e.Name())
+		assert.Equal(t, expectedStat.Mtim, stat.Mtim, e.Name())
+
+		assert.Equal(t, *CreateFileItem(e.Name(), expectedInfo), *CreateFileItem(entry.Name(), info), e.Name())
+	}
+}
+
+func TestReadDirFastManyEntries(t *testing.T) {
+	root := t.TempDir()
+	// more entries than fit into one getdents64 buffer
+	for i := range 3000 {
+		require.NoError(t, os.WriteFile(filepath.Join(root, fmt.Sprintf("file-with-longer-name-%04d", i)), nil, 0o600))
+	}
+
+	expected, err := os.ReadDir(root)
+	require.NoError(t, err)
+	entries, err := readDirFast(root)
+	require.NoError(t, err)
+	require.Len(t, entries, len(expected))
+	for i := range expected {
+		assert.Equal(t, expected[i].Name(), entries[i].Name())
+	}
+}
+
+func TestReadDirFastErrors(t *testing.T) {
+	entries, err := readDirFast("/nonexistent")
+	assert.Empty(t, entries)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.Equal(t, "open /nonexistent: no such file or directory", err.Error())
+
+	root := t.TempDir()
+	file := filepath.Join(root, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+	_, err = readDirFast(file)
+	assert.ErrorIs(t, err, syscall.ENOTDIR)
+}
+
+func TestReadDirFastStatError(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can stat everything")
+	}
+	root := t.TempDir()
+	dir := filepath.Join(root, "dir")
+	require.NoError(t, os.Mkdir(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file"), nil, 0o600))
+	// dir can be listed but its entries cannot be stated
+	require.NoError(t, os.Chmod(dir, 0o444))
+	defer func() { require.NoError(t, os.Chmod(dir, 0o755)) }()
+
+	entries, err := readDirFast(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	_, err = entries[0].Info()
+	assert.ErrorIs(t, err, os.ErrPermission)
+}
+
+func TestReadDirFastEntryPermissionError(t *testing.T) {
+	e := dirEntry{dir: "/dir", name: "file"}
+	e.setStatx(&unix.Statx_t{}, syscall.EPERM)
+
+	_, err := e.Info()
+	assert.ErrorIs(t, err, os.ErrPermission)
+	assert.False(t, e.gone)
+	assert.False(t, fastReadDirUnsupported.Load())
+}
+
+func TestReadDirFastUnsupported(t *testing.T) {
+	root := createReadDirTestDir(t)
+	defer fastReadDirUnsupported.Store(false)
+	fastReadDirUnsupported.Store(true)
+
+	entries, err := readDirFast(root)
+	require.NoError(t, err)
+	expected, err := readDirStated(root)
+	require.NoError(t, err)
+	assert.Equal(t, expected, entries)
+
+	assert.True(t, isUnsupported(&os.PathError{Op: "statx", Err: syscall.ENOSYS}))
+	assert.True(t, isUnsupported(&os.PathError{Op: "statx", Err: syscall.EOPNOTSUPP}))
+	assert.False(t, isUnsupported(&os.PathError{Op: "statx", Err: syscall.EACCES}))
+	assert.False(t, isUnsupported(&os.PathError{Op: "statx", Err: syscall.EPERM}))
+}
+
+func TestAnalyzeDirWithOsReadDir(t *testing.T) {
+	root := createReadDirTestDir(t)
+	for i := range 10 {
+		sub := filepath.Join(root, "dir", fmt.Sprintf("sub%d", i))
+		require.NoError(t, os.Mkdir(sub, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(sub, "file"), make([]byte, i*1000), 0o600))
+	}
+
+	// stable order analyzer keeps the order of dirs in the encoded tree
+	fast := analyzeWithReader(root, readDirFast, CreateStableOrderAnalyzer())
+	expected := analyzeWithReader(root, os.ReadDir, CreateStableOrderAnalyzer())
+
+	var buff, expectedBuff bytes.Buffer
+	require.NoError(t, fast.EncodeJSON(&buff, true, nil))
+	require.NoError(t, expected.EncodeJSON(&expectedBuff, true, nil))
+	assert.Equal(t, expectedBuff.String(), buff.String())
+}
+
+func analyzeWithReader(path string, reader func(string) ([]os.DirEntry, error), analyzer common.Analyzer) fs.Item {
+	defer func(orig func(string) ([]os.DirEntry, error)) { readDirEntries = orig }(readDirEntries)
+	readDirEntries = reader
+
+	dir := analyzer.AnalyzeDir(
+		path, func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	)
+	analyzer.GetDone().Wait()
+	dir.UpdateStats(make(fs.HardLinkedItems))
+	return dir
+}
+
+// createLargeTestDir creates a tree of dirs*files files in the temp dir of the benchmark
+func createLargeTestDir(b *testing.B, dirs, files int) string {
+	b.Helper()
+	root := b.TempDir()
+	for d := range dirs {
+		dir := filepath.Join(root, fmt.Sprintf("dir%03d", d/10), fmt.Sprintf("sub%03d", d))
+		require.NoError(b, os.MkdirAll(dir, 0o755))
+		for f := range files {
+			require.NoError(b, os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%05d", f)), nil, 0o600))
+		}
+	}
+	return root
+}
+
+func benchmarkAnalyzeLargeDir(b *testing.B, reader func(string) ([]os.DirEntry, error)) {
+	root := createLargeTestDir(b, 100, 200)
+	b.ResetTimer()
+	for range b.N {
+		analyzeWithReader(root, reader, CreateAnalyzer())
+	}
+}
+
+func BenchmarkAnalyzeLargeDirFastReadDir(b *testing.B) {
+	benchmarkAnalyzeLargeDir(b, readDirFast)
+}
+
+func BenchmarkAnalyzeLargeDirOsReadDir(b *testing.B) {
+	benchmarkAnalyzeLargeDir(b, os.ReadDir)
+}
+
+func benchmarkReadLargeDir(b *testing.B, reader func(string) ([]os.DirEntry, error)) {
+	root := createLargeTestDir(b, 1, 10000)
+	dir := filepath.Join(root, "dir000", "sub000")
+	b.ResetTimer()
+	for range b.N {
+		entries, err := reader(dir)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, e := range entries {
+			if _, err := e.Info(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkReadLargeDirFastReadDir(b *testing.B) {
+	benchmarkReadLargeDir(b, readDirFast)
+}
+
+func BenchmarkReadLargeDirOsReadDir(b *testing.B) {
+	benchmarkReadLargeDir(b, os.ReadDir)
+}
+
+func TestUringStatEntries(t *testing.T) {
+	ring := getUring()
+	if ring == nil {
+		t.Skip("io_uring is not supported")
+	}
+	defer putUring(ring, nil)
+
+	root := t.TempDir()
+	// more entries than fit into one batch
+	for i := range uringEntries + 10 {
+		require.NoError(t, os.WriteFile(filepath.Join(root, fmt.Sprintf("file%03d", i)), make([]byte, i), 0o600))
+	}
+	fd, err := openDir(root)
+	require.NoError(t, err)
+	defer syscall.Close(fd)
+
+	entries, err := readDirents(fd, root)
+	require.NoError(t, err)
+	entries = append(entries, dirEntry{dir: root, name: "missing"}, dirEntry{dir: root, name: "file000/x"})
+	toStat := make([]*dirEntry, len(entries))
+	for i := range entries {
+		toStat[i] = &entries[i]
+	}
+	require.NoError(t, ring.statEntries(fd, toStat))
+
+	for _, e := range entries[:len(entries)-2] {
+		info, err := os.Lstat(filepath.Join(root, e.name))
+		require.NoError(t, err)
+		assert.True(t, e.ok)
+		assert.Equal(t, info.Size(), e.Size())
+		assert.Equal(t, info.Mode(), e.Mode())
+		assert.Equal(t, info.ModTime(), e.ModTime())
+		assert.Equal(t, info.Sys().(*syscall.Stat_t).Ino, e.stat.Ino)
+		assert.Equal(t, info.Sys().(*syscall.Stat_t).Dev, e.stat.Dev)
+	}
+	assert.True(t, entries[len(entries)-2].gone)
+	_, err = entries[len(entries)-1].Info()
+	assert.ErrorIs(t, err, syscall.ENOTDIR)
+}
@@ -0,0 +1,6 @@
+//go:build !linux
+
+package analyze
+
+// readDirEntries reads the entries of the dir sorted by name
+var readDirEntries = readDirStated
@@ -0,0 +1,30 @@
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadDirStated(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "dir"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "file"), make([]byte, 10), 0o600))
+
+	entries, err := readDirStated(root)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "dir", entries[0].Name())
+	assert.True(t, entries[0].IsDir())
+	assert.Equal(t, "file", entries[1].Name())
+
+	// the file was stated when the dir was read
+	require.NoError(t, os.Remove(filepath.Join(root, "file")))
+	info, err := entries[1].Info()
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), info.Size())
+	assert.False(t, entries[1].IsDir())
+}
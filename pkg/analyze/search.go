@@ -0,0 +1,40 @@
+package analyze
+
+import (
+	"sort"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/query"
+)
+
+// searcher is implemented by items able to evaluate the filter expression themselves (e.g. in the database)
+type searcher interface {
+	search(expr *query.Expr, limit int) (fs.Files, error)
+}
+
+// Search returns items below the directory matching the filter expression sorted by disk usage.
+// At most limit items are returned, limit <= 0 means no limit.
+func Search(dir fs.Item, expr *query.Expr, limit int) (fs.Files, error) {
+	if s, ok := dir.(searcher); ok {
+		return s.search(expr, limit)
+	}
+
+	var found fs.Files
+	searchDir(dir, expr, 1, &found)
+	sort.Sort(sort.Reverse(found))
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+func searchDir(dir fs.Item, expr *query.Expr, depth int, found *fs.Files) {
+	for item := range dir.GetFiles(fs.SortBySize, fs.SortDesc) {
+		if expr.Match(item, depth) {
+			*found = append(*found, item)
+		}
+		if item.IsDir() {
+			searchDir(item, expr, depth+1, found)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package analyze
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/query"
+)
+
+func searchPaths(t *testing.T, dir fs.Item, expr string, limit int) []string {
+	t.Helper()
+	parsed, err := query.Parse(expr, time.Now(), time.Local)
+	require.NoError(t, err)
+	found, err := Search(dir, parsed, limit)
+	require.NoError(t, err)
+	paths := []string{}
+	for _, item := range found {
+		paths = append(paths, item.GetPath())
+	}
+	return paths
+}
+
+func TestSearch(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	dir := CreateAnalyzer().AnalyzeDir(
+		"test_dir", func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	)
+
+	assert.ElementsMatch(t, []string{"test_dir/nested/subnested/file", "test_dir/nested/file2"},
+		searchPaths(t, dir, "type = file and size > 0", 0))
+	assert.Len(t, searchPaths(t, dir, "type = file", 1), 1)
+	assert.Equal(t, []string{"test_dir/nested/file2"}, searchPaths(t, dir, "name ~ /^file\\d$/", 0))
+	assert.Equal(t, []string{"test_dir/nested"}, searchPaths(t, dir, "depth = 1", 0))
+	assert.Equal(t, []string{}, searchPaths(t, dir, "name = 'test_dir'", 0))
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/dundee/gdu/v5/internal/common"
 	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/ignore"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -26,10 +27,11 @@ func (a *SequentialAnalyzer) AnalyzeDir(
 	path string, ignore common.ShouldDirBeIgnored, fileTypeFilter common.ShouldFileBeIgnored,
 ) fs.Item {
 	a.ignoreDir = ignore
+	a.rootPath = path
 	a.ignoreFileType = fileTypeFilter
 
 	go a.UpdateProgress()
-	dir := a.processDir(path)
+	dir := a.processDir(path, a.rootIgnoreRules(path))
 
 	dir.BasePath = filepath.Dir(path)
 	a.setCurrentDir(dir)
@@ -40,7 +42,7 @@ func (a *SequentialAnalyzer) AnalyzeDir(
 	return dir
 }
 
-func (a *SequentialAnalyzer) processDir(path string) *Dir {
+func (a *SequentialAnalyzer) processDir(path string, rules *ignore.Rules) *Dir {
 	var (
 		file      fs.Item
 		err       error
@@ -49,20 +51,22 @@ func (a *SequentialAnalyzer) processDir(path string) *Dir {
 		dirCount  int
 	)
 
-	files, err := os.ReadDir(path)
+	files, err := a.readDirLimited(path)
 	if err != nil {
-		log.Print(err.Error())
+		a.addScanError(path, err)
 	}
+	dirInfo := statDir(path)
 
 	dir := &Dir{
 		File: &File{
 			Name: filepath.Base(path),
-			Flag: getDirFlag(err, len(files)),
+			Flag: getDirFlag(err, len(files), isSubvolume(path, dirInfo)),
 		},
 		ItemCount: 1,
 		Files:     make(fs.Files, 0, len(files)),
 	}
-	setDirPlatformSpecificAttrs(dir, path)
+	setDirPlatformSpecificAttrs(dir, dirInfo)
+	rules = a.dirIgnoreRules(rules, path, files)
 
 	for _, f := range files {
 		if a.IsCancelled() {
@@ -71,12 +75,12 @@ func (a *SequentialAnalyzer) processDir(path string) *Dir {
 		name := f.Name()
 		entryPath := filepath.Join(path, name)
 		if f.IsDir() {
-			if a.shouldSkipDir(name, entryPath) {
+			if a.shouldSkipDir(rules, name, entryPath) {
 				continue
 			}
 			dirCount++
 
-			subdir := a.processDir(entryPath)
+			subdir := a.processDir(entryPath, rules)
 			subdir.Parent = dir
 			dir.AddFile(subdir)
 		} else {
@@ -84,10 +88,13 @@ func (a *SequentialAnalyzer) processDir(path string) *Dir {
 			if a.ignoreFileType != nil && a.ignoreFileType(name) {
 				continue // Skip this file
 			}
+			if rules.Match(entryPath, false) {
+				continue
+			}
 
 			info, err = f.Info()
 			if err != nil {
-				log.Print(err.Error())
+				a.addScanError(entryPath, err)
 				dir.SetFlag('!')
 				continue
 			}
@@ -95,7 +102,7 @@ func (a *SequentialAnalyzer) processDir(path string) *Dir {
 			if a.followSymlinks && info.Mode()&os.ModeSymlink != 0 {
 				infoF, err := followSymlink(entryPath, a.gitAnnexedSize)
 				if err != nil {
-					log.Print(err.Error())
+					a.addScanError(entryPath, err)
 					dir.SetFlag('!')
 					continue
 				}
@@ -106,8 +113,8 @@ func (a *SequentialAnalyzer) processDir(path string) *Dir {
 
 			symlinkTarget := readSymlinkTarget(f.Type(), entryPath)
 
-			// Apply time filter if set
-			if a.matchesTimeFilterFn != nil && !a.matchesTimeFilterFn(info.ModTime()) {
+			// Apply time and file filters if set
+			if !a.includeFile(info) {
 				continue // Skip this file
 			}
 
@@ -159,6 +166,7 @@ func (a *SequentialAnalyzer) processDir(path string) *Dir {
 				// Only set platform-specific attributes for regular files
 				if regularFile, ok := file.(*File); ok {
 					setPlatformSpecificAttrs(regularFile, info)
+					a.setSharedUsage(regularFile, info, entryPath)
 				}
 				totalSize += file.GetUsage()
 				dir.AddFile(file)
@@ -13,6 +13,7 @@ import (
 
 	"github.com/dundee/gdu/v5/internal/common"
 	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/ignore"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
@@ -26,6 +27,9 @@ type SqliteStorage struct {
 	insertStmt   *sql.Stmt
 	updateStmt   *sql.Stmt
 	hasInodeStmt *sql.Stmt
+	// scanStartID is the highest item ID stored before the current scan began,
+	// items of older scans kept in the history are not taken as hard links
+	scanStartID int64
 }
 
 // NewSqliteStorage creates a new SQLite storage and initializes the schema
@@ -77,7 +81,9 @@ func (s *SqliteStorage) createTables() error {
 		mtime       INTEGER NOT NULL,
 		item_count  INTEGER NOT NULL DEFAULT 1,
 		mli         INTEGER NOT NULL DEFAULT 0,
-		flag        TEXT NOT NULL DEFAULT ' '
+		flag        TEXT NOT NULL DEFAULT ' ',
+		hash        INTEGER NOT NULL DEFAULT 0,
+		ref_id      INTEGER REFERENCES items(id)
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_items_parent_id ON items(parent_id);
@@ -88,12 +94,61 @@ func (s *SqliteStorage) createTables() error {
 		key   TEXT PRIMARY KEY,
 		value TEXT
 	);
+
+	CREATE TABLE IF NOT EXISTS history (
+		scan_time   INTEGER NOT NULL,
+		path        TEXT NOT NULL,
+		size        INTEGER NOT NULL,
+		usage       INTEGER NOT NULL,
+		item_count  INTEGER NOT NULL,
+		PRIMARY KEY (scan_time, path)
+	);
 	`
 
-	_, err := s.db.Exec(schema)
+	if _, err := s.db.Exec(schema); err != nil {
+		return err
+	}
+	if err := s.migrateItems(); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_items_ref_id ON items(ref_id) WHERE ref_id IS NOT NULL`)
 	return err
 }
 
+// migrateItems adds columns missing in databases created by older versions
+func (s *SqliteStorage) migrateItems() error {
+	rows, err := s.db.Query(`SELECT name FROM pragma_table_info('items')`)
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]struct{})
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		columns[name] = struct{}{}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := columns["hash"]; !ok {
+		if _, err := s.db.Exec(`ALTER TABLE items ADD COLUMN hash INTEGER NOT NULL DEFAULT 0`); err != nil {
+			return err
+		}
+	}
+	if _, ok := columns["ref_id"]; !ok {
+		if _, err := s.db.Exec(`ALTER TABLE items ADD COLUMN ref_id INTEGER REFERENCES items(id)`); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Close closes the database connection
 func (s *SqliteStorage) Close() error {
 	s.m.Lock()
@@ -134,7 +189,7 @@ func (s *SqliteStorage) BeginBulkInsert() error {
 	}
 
 	s.updateStmt, err = tx.Prepare(
-		`UPDATE items SET size = ?, usage = ?, item_count = ?, flag = ? WHERE id = ?`,
+		`UPDATE items SET size = ?, usage = ?, item_count = ?, flag = ?, hash = ? WHERE id = ?`,
 	)
 	if err != nil {
 		s.insertStmt.Close()
@@ -146,7 +201,7 @@ func (s *SqliteStorage) BeginBulkInsert() error {
 	}
 
 	s.hasInodeStmt, err = tx.Prepare(
-		`SELECT 1 FROM items WHERE mli = ? LIMIT 1`,
+		`SELECT 1 FROM items WHERE mli = ? AND id > ? LIMIT 1`,
 	)
 	if err != nil {
 		s.insertStmt.Close()
@@ -205,10 +260,10 @@ func (s *SqliteStorage) HasInode(mli uint64) bool {
 	var err error
 
 	if s.hasInodeStmt != nil {
-		err = s.hasInodeStmt.QueryRow(mli).Scan(&exists)
+		err = s.hasInodeStmt.QueryRow(mli, s.scanStartID).Scan(&exists)
 	} else {
 		s.m.RLock()
-		err = s.db.QueryRow(`SELECT 1 FROM items WHERE mli = ? LIMIT 1`, mli).Scan(&exists)
+		err = s.db.QueryRow(`SELECT 1 FROM items WHERE mli = ? AND id > ? LIMIT 1`, mli, s.scanStartID).Scan(&exists)
 		s.m.RUnlock()
 	}
 
@@ -228,7 +283,7 @@ func (s *SqliteStorage) GetRootItem() (*SqliteItem, error) {
 
 	err := s.db.QueryRow(
 		`SELECT id, parent_id, name, is_dir, size, usage, mtime, item_count, mli, flag
-		 FROM items WHERE parent_id IS NULL LIMIT 1`,
+		 FROM items WHERE parent_id IS NULL ORDER BY id DESC LIMIT 1`,
 	).Scan(
 		&item.id, &parentID, &item.name, &isDirInt,
 		&item.size, &item.usage, &mtimeUnix, &item.itemCount,
@@ -282,16 +337,22 @@ func (s *SqliteStorage) InsertItem(
 
 // UpdateItem updates an existing item's stats
 func (s *SqliteStorage) UpdateItem(id, size, usage, itemCount int64, flag rune) error {
+	return s.UpdateItemWithHash(id, size, usage, itemCount, flag, 0)
+}
+
+// UpdateItemWithHash updates an existing item's stats together with the hash of its subtree
+// used for deduplication of unchanged directories between scans
+func (s *SqliteStorage) UpdateItemWithHash(id, size, usage, itemCount int64, flag rune, hash uint64) error {
 	var err error
 
 	// Use prepared statement if in bulk mode, otherwise use direct exec
 	if s.updateStmt != nil {
-		_, err = s.updateStmt.Exec(size, usage, itemCount, string(flag), id)
+		_, err = s.updateStmt.Exec(size, usage, itemCount, string(flag), int64(hash), id) // nolint:gosec // Why: hash is stored as bits
 	} else {
 		s.m.Lock()
 		_, err = s.db.Exec(
-			`UPDATE items SET size = ?, usage = ?, item_count = ?, flag = ? WHERE id = ?`,
-			size, usage, itemCount, string(flag), id,
+			`UPDATE items SET size = ?, usage = ?, item_count = ?, flag = ?, hash = ? WHERE id = ?`,
+			size, usage, itemCount, string(flag), int64(hash), id, // nolint:gosec // Why: hash is stored as bits
 		)
 		s.m.Unlock()
 	}
@@ -322,6 +383,12 @@ func (s *SqliteStorage) RemoveItemAndUpdateStats(id, size, usage, itemCount int6
 		return err
 	}
 
+	// 0. Keep the content for older scans in the history sharing the item or its ancestors
+	if err = s.unshare(tx, id); err != nil {
+		rollback(tx)
+		return err
+	}
+
 	// 1. Update all ancestors using recursive CTE
 	updateAncestorsQuery := `
 	WITH RECURSIVE ancestors(id, parent_id) AS (
@@ -368,7 +435,7 @@ func (s *SqliteStorage) GetChildByName(parentID int64, name string) (*SqliteItem
 	err := s.db.QueryRow(
 		`SELECT id, parent_id, name, is_dir, size, usage, mtime, item_count, mli, flag
 		 FROM items WHERE parent_id = ? AND name = ? LIMIT 1`,
-		parentID, name,
+		s.resolveRef(parentID), name,
 	).Scan(
 		&item.id, &pID, &item.name, &isDirInt,
 		&item.size, &item.usage, &mtimeUnix, &item.itemCount,
@@ -379,7 +446,8 @@ func (s *SqliteStorage) GetChildByName(parentID int64, name string) (*SqliteItem
 	}
 
 	if pID.Valid {
-		item.parentID = &pID.Int64
+		// children of a deduplicated directory belong to the directory they were read through
+		item.parentID = &parentID
 	}
 	item.isDir = isDirInt == 1
 	item.mtime = time.Unix(mtimeUnix, 0)
@@ -400,7 +468,7 @@ func (s *SqliteStorage) GetChildren(parentID int64) ([]*SqliteItem, error) {
 	rows, err := s.db.Query(
 		`SELECT id, parent_id, name, is_dir, size, usage, mtime, item_count, mli, flag
 		 FROM items WHERE parent_id = ?`,
-		parentID,
+		s.resolveRef(parentID),
 	)
 	if err != nil {
 		return nil, err
@@ -410,13 +478,13 @@ func (s *SqliteStorage) GetChildren(parentID int64) ([]*SqliteItem, error) {
 	var items []*SqliteItem
 	for rows.Next() {
 		item := &SqliteItem{storage: s}
-		var parentID sql.NullInt64
+		var pID sql.NullInt64
 		var isDirInt int
 		var mtimeUnix int64
 		var flag string
 
 		err := rows.Scan(
-			&item.id, &parentID, &item.name, &isDirInt,
+			&item.id, &pID, &item.name, &isDirInt,
 			&item.size, &item.usage, &mtimeUnix, &item.itemCount,
 			&item.mli, &flag,
 		)
@@ -424,8 +492,9 @@ func (s *SqliteStorage) GetChildren(parentID int64) ([]*SqliteItem, error) {
 			return nil, err
 		}
 
-		if parentID.Valid {
-			item.parentID = &parentID.Int64
+		if pID.Valid {
+			// children of a deduplicated directory belong to the directory they were read through
+			item.parentID = &parentID
 		}
 		item.isDir = isDirInt == 1
 		item.mtime = time.Unix(mtimeUnix, 0)
@@ -514,6 +583,8 @@ type SqliteItem struct {
 	mli       uint64
 	flag      rune
 	parent    fs.Item
+	rootPath  string // path of the scan for root items loaded from the history
+	hash      uint64 // hash of the subtree, set only during the scan
 	m         sync.RWMutex
 }
 
@@ -523,6 +594,9 @@ func (i *SqliteItem) GetPath() string {
 	if parent != nil {
 		return filepath.Join(parent.GetPath(), i.name)
 	}
+	if i.rootPath != "" {
+		return i.rootPath
+	}
 	// For root item, get basePath from metadata
 	basePath, err := i.storage.GetMetadata("top_dir_path")
 	if err != nil {
@@ -845,6 +919,9 @@ type SqliteAnalyzer struct {
 	BaseAnalyzer
 	storage   *SqliteStorage
 	dbWriteMu sync.Mutex
+	history   *HistoryScan
+	keepScans int
+	scanRoot  *SqliteItem
 }
 
 // insertItemLocked is a serialized wrapper around storage.InsertItem.
@@ -860,10 +937,10 @@ func (a *SqliteAnalyzer) insertItemLocked(
 	return a.storage.InsertItem(parentID, name, isDir, size, usage, mtime, itemCount, mli, flag)
 }
 
-func (a *SqliteAnalyzer) updateDirLocked(id, size, usage, itemCount int64, flag rune) error {
+func (a *SqliteAnalyzer) updateDirLocked(id, size, usage, itemCount int64, flag rune, hash uint64) error {
 	a.dbWriteMu.Lock()
 	defer a.dbWriteMu.Unlock()
-	return a.storage.UpdateItem(id, size, usage, itemCount, flag)
+	return a.storage.UpdateItemWithHash(id, size, usage, itemCount, flag, hash)
 }
 
 // hasInodeLocked is a serialized wrapper around storage.HasInode.
@@ -896,8 +973,14 @@ func CreateSqliteAnalyzer(dbPath string) (*SqliteAnalyzer, error) {
 func (a *SqliteAnalyzer) AnalyzeDir(
 	path string, ignore common.ShouldDirBeIgnored, fileTypeFilter common.ShouldFileBeIgnored,
 ) fs.Item {
+	if a.scanRoot != nil {
+		log.Printf("Loading scan from history of SQLite database")
+		a.doneChan.Broadcast()
+		return a.scanRoot
+	}
+
 	// Check if database already has data
-	if a.storage.HasData() {
+	if a.history == nil && a.storage.HasData() {
 		log.Printf("Loading analysis from existing SQLite database")
 		rootItem, err := a.storage.GetRootItem()
 		if err != nil {
@@ -910,14 +993,17 @@ func (a *SqliteAnalyzer) AnalyzeDir(
 	}
 
 	a.ignoreDir = ignore
+	a.rootPath = path
 	a.ignoreFileType = fileTypeFilter
 
-	// Clear existing data and store metadata
-	err := a.storage.ClearItems()
-	if err != nil {
-		log.Printf("Error clearing items: %v", err)
+	// Clear existing data unless previous scans are kept and store metadata
+	if a.history == nil {
+		if err := a.storage.ClearItems(); err != nil {
+			log.Printf("Error clearing items: %v", err)
+		}
 	}
-	err = a.storage.SetMetadata("top_dir_path", path)
+	a.storage.scanStartID = a.storage.maxItemID()
+	err := a.storage.SetMetadata("top_dir_path", path)
 	if err != nil {
 		log.Printf("Error setting metadata: %v", err)
 	}
@@ -930,7 +1016,7 @@ func (a *SqliteAnalyzer) AnalyzeDir(
 	go a.UpdateProgress()
 
 	// Process directory and get the root item
-	rootItem := a.processDir(path, nil)
+	rootItem := a.processDir(path, nil, a.rootIgnoreRules(path))
 
 	a.wait.Wait()
 
@@ -939,6 +1025,10 @@ func (a *SqliteAnalyzer) AnalyzeDir(
 		log.Printf("Error committing bulk insert: %v", err)
 	}
 
+	if a.history != nil {
+		a.recordHistory(rootItem, path)
+	}
+
 	a.progressDoneChan <- struct{}{}
 	a.doneChan.Broadcast()
 
@@ -987,14 +1077,14 @@ func (a *SqliteAnalyzer) processFile(entryPath, name string, f os.DirEntry) (sta
 
 	info, err := f.Info()
 	if err != nil {
-		log.Print(err.Error())
+		a.addScanError(entryPath, err)
 		return stat
 	}
 
 	if a.followSymlinks && info.Mode()&os.ModeSymlink != 0 {
 		infoF, err := followSymlink(entryPath, a.gitAnnexedSize)
 		if err != nil {
-			log.Print(err.Error())
+			a.addScanError(entryPath, err)
 			return stat
 		}
 		if infoF != nil {
@@ -1002,7 +1092,7 @@ func (a *SqliteAnalyzer) processFile(entryPath, name string, f os.DirEntry) (sta
 		}
 	}
 
-	if a.matchesTimeFilterFn != nil && !a.matchesTimeFilterFn(info.ModTime()) {
+	if !a.includeFile(info) {
 		return stat
 	}
 
@@ -1040,7 +1130,7 @@ func (a *SqliteAnalyzer) processFile(entryPath, name string, f os.DirEntry) (sta
 }
 
 // nolint:funlen
-func (a *SqliteAnalyzer) processDir(path string, parentID *int64) *SqliteItem {
+func (a *SqliteAnalyzer) processDir(path string, parentID *int64, rules *ignore.Rules) *SqliteItem {
 	// Start with 4096 for directory's own size/usage, matching Dir.UpdateStats behavior
 	var (
 		totalSize  int64 = 4096
@@ -1049,6 +1139,7 @@ func (a *SqliteAnalyzer) processDir(path string, parentID *int64) *SqliteItem {
 		itemCount  int64 = 1
 		subDirChan       = make(chan *SqliteItem)
 		dirCount   int
+		hashes     []uint64 // hashes of the items, the dir hash is made of them
 	)
 
 	a.wait.Add(1)
@@ -1057,13 +1148,13 @@ func (a *SqliteAnalyzer) processDir(path string, parentID *int64) *SqliteItem {
 	// Hold a concurrency slot only during the scan/insert phase. We must
 	// release it before draining subDirChan, otherwise child goroutines
 	// (which need to acquire a slot themselves) would deadlock once the
-	// global concurrencyLimit is saturated by ancestors waiting on their
+	// concurrencyLimit of the analyzer is saturated by ancestors waiting on their
 	// own children.
-	concurrencyLimit <- struct{}{}
+	a.concurrencyLimit <- struct{}{}
 	slotReleased := false
 	releaseSlot := func() {
 		if !slotReleased {
-			<-concurrencyLimit
+			<-a.concurrencyLimit
 			slotReleased = true
 		}
 	}
@@ -1073,19 +1164,20 @@ func (a *SqliteAnalyzer) processDir(path string, parentID *int64) *SqliteItem {
 		return nil
 	}
 
-	files, err := os.ReadDir(path)
+	files, err := a.readDirLimited(path)
 	if err != nil {
-		log.Print(err.Error())
+		a.addScanError(path, err)
 	}
+	rules = a.dirIgnoreRules(rules, path, files)
 
 	// Get directory info for mtime
-	dirInfo, statErr := os.Stat(path)
+	dirInfo := statDir(path)
 	var dirMtime time.Time
-	if statErr == nil {
+	if dirInfo != nil {
 		dirMtime = dirInfo.ModTime()
 	}
 
-	dirFlag := getDirFlag(err, len(files))
+	dirFlag := getDirFlag(err, len(files), isSubvolume(path, dirInfo))
 
 	// Insert directory into database (size/usage will be updated later)
 	dirID, err := a.insertItemLocked(
@@ -1114,21 +1206,24 @@ func (a *SqliteAnalyzer) processDir(path string, parentID *int64) *SqliteItem {
 		entryPath := filepath.Join(path, name)
 
 		if f.IsDir() {
-			if a.shouldSkipDir(name, entryPath) {
+			if a.shouldSkipDir(rules, name, entryPath) {
 				continue
 			}
 			dirCount++
 
 			go func(entryPath string) {
-				sub := a.processDir(entryPath, &dirID)
+				sub := a.processDir(entryPath, &dirID, rules)
 				subDirChan <- sub
 			}(entryPath)
 			continue
 		}
+		if rules.Match(entryPath, false) {
+			continue
+		}
 
 		info, err := f.Info()
 		if err != nil {
-			log.Print(err.Error())
+			a.addScanError(entryPath, err)
 			continue
 		}
 
@@ -1155,6 +1250,7 @@ func (a *SqliteAnalyzer) processDir(path string, parentID *int64) *SqliteItem {
 			}
 			a.persistArchive(stat.archiveDir, archiveID)
 
+			hashes = append(hashes, hashItem(name, true, stat.archiveDir.Size, stat.archiveDir.Usage, info.ModTime(), stat.archiveDir.Flag))
 			totalSize += stat.size
 			totalUsage += stat.usage
 			filesSize += stat.usage
@@ -1178,6 +1274,7 @@ func (a *SqliteAnalyzer) processDir(path string, parentID *int64) *SqliteItem {
 			continue
 		}
 
+		hashes = append(hashes, hashItem(name, false, stat.size, stat.usage, info.ModTime(), stat.flag))
 		totalSize += stat.size
 		totalUsage += stat.usage
 		filesSize += stat.usage
@@ -1192,6 +1289,7 @@ func (a *SqliteAnalyzer) processDir(path string, parentID *int64) *SqliteItem {
 	for i := 0; i < dirCount; i++ {
 		sub := <-subDirChan
 		if sub != nil {
+			hashes = append(hashes, sub.hash)
 			totalSize += sub.size
 			totalUsage += sub.usage
 			itemCount += sub.itemCount
@@ -1205,7 +1303,8 @@ func (a *SqliteAnalyzer) processDir(path string, parentID *int64) *SqliteItem {
 	}
 
 	// Update directory with computed stats
-	if err := a.updateDirLocked(dirID, totalSize, totalUsage, itemCount, dirFlag); err != nil {
+	hash := hashDir(filepath.Base(path), dirMtime, dirFlag, hashes)
+	if err := a.updateDirLocked(dirID, totalSize, totalUsage, itemCount, dirFlag, hash); err != nil {
 		log.Printf("Error updating item: %v", err)
 	}
 
@@ -1226,6 +1325,7 @@ func (a *SqliteAnalyzer) processDir(path string, parentID *int64) *SqliteItem {
 		mtime:     dirMtime,
 		itemCount: itemCount,
 		flag:      dirFlag,
+		hash:      hash,
 	}
 }
 
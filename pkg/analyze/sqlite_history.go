@@ -0,0 +1,731 @@
+package analyze
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"hash/fnv"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// historyScanKeyPrefix prefixes metadata keys describing scans recorded in the history
+const historyScanKeyPrefix = "scan:"
+
+// maxRefDepth limits following of references between deduplicated directories
+const maxRefDepth = 64
+
+// ErrNoScan is returned when no scan stored in the history matches
+var ErrNoScan = errors.New("no scan found in history")
+
+// HistoryScan describes one scan recorded in the history of a SQLite database
+type HistoryScan struct {
+	Time       time.Time `json:"-"`
+	Path       string    `json:"path"`
+	RootID     int64     `json:"root_id,omitempty"`
+	Device     string    `json:"device,omitempty"`
+	MountPoint string    `json:"mount_point,omitempty"`
+	DeviceSize int64     `json:"device_size,omitempty"`
+	DeviceFree int64     `json:"device_free,omitempty"`
+}
+
+// HasTree returns true if the whole tree of the scan is kept in the database
+func (s HistoryScan) HasTree() bool {
+	return s.RootID != 0
+}
+
+// HistoryEntry holds usage of one directory in one recorded scan
+type HistoryEntry struct {
+	Time      time.Time
+	Path      string
+	Size      int64
+	Usage     int64
+	ItemCount int64
+}
+
+// AddHistoryScan records the scan and usage of the given directories into the history.
+// The scan is keyed by its timestamp in the metadata table.
+func (s *SqliteStorage) AddHistoryScan(scan HistoryScan, entries []HistoryEntry) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err = setHistoryScan(tx, scan); err != nil {
+		rollback(tx)
+		return err
+	}
+	scanTime := scan.Time.Unix()
+	for _, entry := range entries {
+		if _, err = tx.Exec(
+			`INSERT OR REPLACE INTO history (scan_time, path, size, usage, item_count) VALUES (?, ?, ?, ?, ?)`,
+			scanTime, entry.Path, entry.Size, entry.Usage, entry.ItemCount,
+		); err != nil {
+			rollback(tx)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func setHistoryScan(tx *sql.Tx, scan HistoryScan) error {
+	value, err := json.Marshal(scan)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`INSERT OR REPLACE INTO metadata (key, value) VALUES (?, ?)`,
+		historyScanKeyPrefix+strconv.FormatInt(scan.Time.Unix(), 10), string(value),
+	)
+	return err
+}
+
+// GetHistoryScans returns all scans recorded in the history ordered by time
+func (s *SqliteStorage) GetHistoryScans() ([]HistoryScan, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	rows, err := s.db.Query(
+		`SELECT key, value FROM metadata WHERE key LIKE ?`, historyScanKeyPrefix+"%",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scans []HistoryScan
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		scanTime, err := strconv.ParseInt(strings.TrimPrefix(key, historyScanKeyPrefix), 10, 64)
+		if err != nil {
+			log.Printf("Ignoring history scan with invalid key %s", key)
+			continue
+		}
+		scan := HistoryScan{}
+		if err := json.Unmarshal([]byte(value), &scan); err != nil {
+			return nil, err
+		}
+		scan.Time = time.Unix(scanTime, 0)
+		scans = append(scans, scan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(scans, func(a, b HistoryScan) int {
+		return a.Time.Compare(b.Time)
+	})
+	return scans, nil
+}
+
+// GetHistory returns usage of all directories recorded in the history ordered by time
+func (s *SqliteStorage) GetHistory() ([]HistoryEntry, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	rows, err := s.db.Query(
+		`SELECT scan_time, path, size, usage, item_count FROM history ORDER BY scan_time, path`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []HistoryEntry
+	for rows.Next() {
+		var (
+			entry    HistoryEntry
+			scanTime int64
+		)
+		if err := rows.Scan(&scanTime, &entry.Path, &entry.Size, &entry.Usage, &entry.ItemCount); err != nil {
+			return nil, err
+		}
+		entry.Time = time.Unix(scanTime, 0)
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// FindScan returns the last scan with the whole tree kept in the history done before the given time
+func (s *SqliteStorage) FindScan(at time.Time) (HistoryScan, error) {
+	scans, err := s.GetHistoryScans()
+	if err != nil {
+		return HistoryScan{}, err
+	}
+	for _, scan := range slices.Backward(scans) {
+		if scan.HasTree() && !scan.Time.After(at) {
+			return scan, nil
+		}
+	}
+	return HistoryScan{}, errors.Wrapf(ErrNoScan, "no scan done before %s", at.Format(time.RFC3339))
+}
+
+// GetScanRootItem returns the root item of the scan stored in the history
+func (s *SqliteStorage) GetScanRootItem(scan HistoryScan) (*SqliteItem, error) {
+	if !scan.HasTree() {
+		return nil, ErrNoScan
+	}
+	item, err := s.GetItemByID(scan.RootID)
+	if err != nil {
+		return nil, err
+	}
+	item.parentID = nil
+	item.rootPath = scan.Path
+	return item, nil
+}
+
+// GetUsageHistory returns usage of the item in all scans kept in the history.
+// Scans which do not contain the item are left out.
+func (s *SqliteStorage) GetUsageHistory(item *SqliteItem) ([]HistoryEntry, error) {
+	var names []string
+	var cur fs.Item = item
+	for cur.GetParent() != nil {
+		names = append(names, cur.GetName())
+		cur = cur.GetParent()
+	}
+	slices.Reverse(names)
+
+	path := item.GetPath()
+	scans, err := s.GetHistoryScans()
+	if err != nil {
+		return nil, err
+	}
+
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	var entries []HistoryEntry
+	for _, scan := range scans {
+		if !scan.HasTree() {
+			continue
+		}
+		id, err := findItemByNames(s.db, scan.RootID, names)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entry := HistoryEntry{Time: scan.Time, Path: path}
+		if err := s.db.QueryRow(
+			`SELECT size, usage, item_count FROM items WHERE id = ?`, id,
+		).Scan(&entry.Size, &entry.Usage, &entry.ItemCount); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// resolveRef returns the ID of the directory holding the children of the given directory.
+// Directories unchanged since the previous scan reference the directory of the newer scan.
+func (s *SqliteStorage) resolveRef(id int64) int64 {
+	return resolveRef(s.db, id)
+}
+
+func resolveRef(q querier, id int64) int64 {
+	for range maxRefDepth {
+		var ref sql.NullInt64
+		if err := q.QueryRow(`SELECT ref_id FROM items WHERE id = ?`, id).Scan(&ref); err != nil || !ref.Valid {
+			return id
+		}
+		id = ref.Int64
+	}
+	return id
+}
+
+// findItemByNames walks the tree from the given directory following the names of items
+func findItemByNames(q querier, id int64, names []string) (int64, error) {
+	for _, name := range names {
+		if err := q.QueryRow(
+			`SELECT id FROM items WHERE parent_id = ? AND name = ?`, resolveRef(q, id), name,
+		).Scan(&id); err != nil {
+			return 0, err
+		}
+	}
+	return id, nil
+}
+
+// getHistoryEntries returns usage of the root item and of its direct subdirectories
+func (s *SqliteStorage) getHistoryEntries(root *SqliteItem, rootPath string) ([]HistoryEntry, error) {
+	entries := []HistoryEntry{{
+		Path:      rootPath,
+		Size:      root.size,
+		Usage:     root.usage,
+		ItemCount: root.itemCount,
+	}}
+
+	children, err := s.GetChildren(root.id)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		if !child.isDir {
+			continue
+		}
+		entries = append(entries, HistoryEntry{
+			Path:      filepath.Join(rootPath, child.name),
+			Size:      child.size,
+			Usage:     child.usage,
+			ItemCount: child.itemCount,
+		})
+	}
+	return entries, nil
+}
+
+// maxItemID returns the highest ID of stored items
+func (s *SqliteStorage) maxItemID() int64 {
+	var id sql.NullInt64
+	if err := s.db.QueryRow(`SELECT MAX(id) FROM items`).Scan(&id); err != nil {
+		log.Printf("Error getting max item ID: %v", err)
+	}
+	return id.Int64
+}
+
+// Deduplicate replaces subtrees of the older scan which are the same in the newer scan
+// with references to the newer scan, so that unchanged directories are stored only once.
+// The newer scan is kept complete so it can be modified without affecting the older scans.
+func (s *SqliteStorage) Deduplicate(olderRootID, newerRootID int64) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := deduplicateDir(tx, olderRootID, newerRootID); err != nil {
+		rollback(tx)
+		return err
+	}
+	return tx.Commit()
+}
+
+// dirStats are the stats of a stored dir compared when looking for unchanged dirs
+type dirStats struct {
+	hash      int64
+	size      int64
+	usage     int64
+	itemCount int64
+}
+
+// same reports whether the dirs have the same content
+func (d dirStats) same(other dirStats) bool {
+	return d.hash != 0 && d == other
+}
+
+func getDirStats(tx *sql.Tx, id int64) (dirStats, error) {
+	var stats dirStats
+	err := tx.QueryRow(`SELECT hash, size, usage, item_count FROM items WHERE id = ?`, id).Scan(
+		&stats.hash, &stats.size, &stats.usage, &stats.itemCount,
+	)
+	return stats, err
+}
+
+type dirHash struct {
+	id   int64
+	hash int64
+}
+
+func getDirHashes(tx *sql.Tx, parentID int64) (map[string]dirHash, error) {
+	rows, err := tx.Query(
+		`SELECT id, name, hash FROM items WHERE parent_id = ? AND is_dir = 1 AND ref_id IS NULL`, parentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dirs := make(map[string]dirHash)
+	for rows.Next() {
+		var (
+			name string
+			dir  dirHash
+		)
+		if err := rows.Scan(&dir.id, &name, &dir.hash); err != nil {
+			return nil, err
+		}
+		dirs[name] = dir
+	}
+	return dirs, rows.Err()
+}
+
+func deduplicateDir(tx *sql.Tx, olderID, newerID int64) error {
+	olderStats, err := getDirStats(tx, olderID)
+	if err != nil {
+		return err
+	}
+	newerStats, err := getDirStats(tx, newerID)
+	if err != nil {
+		return err
+	}
+	// hashes can collide, so the totals have to match too
+	if olderStats.same(newerStats) {
+		return replaceWithRef(tx, olderID, newerID)
+	}
+
+	olderDirs, err := getDirHashes(tx, olderID)
+	if err != nil {
+		return err
+	}
+	newerDirs, err := getDirHashes(tx, newerID)
+	if err != nil {
+		return err
+	}
+	for name, older := range olderDirs {
+		if newer, ok := newerDirs[name]; ok {
+			if err := deduplicateDir(tx, older.id, newer.id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// replaceWithRef removes the content of the older directory and makes it reference the same newer one
+func replaceWithRef(tx *sql.Tx, olderID, newerID int64) error {
+	// references from even older scans into the removed subtree are moved to the newer one
+	rows, err := tx.Query(`
+	WITH RECURSIVE tree(id, rel) AS (
+		SELECT ?, ''
+		UNION ALL
+		SELECT items.id, tree.rel || '/' || items.name FROM items JOIN tree ON items.parent_id = tree.id
+	)
+	SELECT items.id, tree.rel FROM items JOIN tree ON items.ref_id = tree.id`, olderID)
+	if err != nil {
+		return err
+	}
+	refs := make(map[int64]string)
+	for rows.Next() {
+		var (
+			id  int64
+			rel string
+		)
+		if err := rows.Scan(&id, &rel); err != nil {
+			rows.Close()
+			return err
+		}
+		refs[id] = rel
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, rel := range refs {
+		var names []string
+		if rel != "" {
+			names = strings.Split(rel[1:], "/")
+		}
+		target, err := findItemByNames(tx, newerID, names)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE items SET ref_id = ? WHERE id = ?`, target, id); err != nil {
+			return err
+		}
+	}
+
+	if err := deleteDescendants(tx, olderID); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE items SET ref_id = ? WHERE id = ?`, newerID, olderID)
+	return err
+}
+
+func deleteDescendants(tx *sql.Tx, id int64) error {
+	_, err := tx.Exec(`
+	WITH RECURSIVE descendants(id) AS (
+		SELECT id FROM items WHERE parent_id = ?
+		UNION ALL
+		SELECT items.id FROM items JOIN descendants ON items.parent_id = descendants.id
+	)
+	DELETE FROM items WHERE id IN (SELECT id FROM descendants)`, id)
+	return err
+}
+
+// rescueSubtree hands over the parts of the subtree referenced from other scans
+// to the referencing directories before the subtree is removed
+func rescueSubtree(tx *sql.Tx, id int64) error {
+	rows, err := tx.Query(`
+	WITH RECURSIVE tree(id, depth) AS (
+		SELECT ?, 0
+		UNION ALL
+		SELECT items.id, tree.depth + 1 FROM items JOIN tree ON items.parent_id = tree.id
+	)
+	SELECT items.id, items.ref_id FROM items JOIN tree ON items.ref_id = tree.id ORDER BY tree.depth`, id)
+	if err != nil {
+		return err
+	}
+	type ref struct{ from, to int64 }
+	var refs []ref
+	for rows.Next() {
+		var r ref
+		if err := rows.Scan(&r.from, &r.to); err != nil {
+			rows.Close()
+			return err
+		}
+		refs = append(refs, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, r := range refs {
+		// the target could have been handed over together with its ancestor already
+		var inside int
+		if err := tx.QueryRow(`
+		WITH RECURSIVE ancestors(id) AS (
+			SELECT ?
+			UNION ALL
+			SELECT items.parent_id FROM items JOIN ancestors ON items.id = ancestors.id WHERE items.parent_id IS NOT NULL
+		)
+		SELECT COUNT(*) FROM ancestors WHERE id = ?`, r.to, id).Scan(&inside); err != nil {
+			return err
+		}
+		if inside == 0 {
+			continue
+		}
+		if _, err := tx.Exec(`UPDATE items SET parent_id = ? WHERE parent_id = ?`, r.from, r.to); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE items SET ref_id = NULL WHERE id = ?`, r.from); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE items SET ref_id = ? WHERE ref_id = ?`, r.from, r.to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// materialize copies the children of the referenced directory into the referencing one.
+// Copied subdirectories reference the original ones.
+func materialize(tx *sql.Tx, id, refID int64) error {
+	if _, err := tx.Exec(`
+	INSERT INTO items (parent_id, name, is_dir, size, usage, mtime, item_count, mli, flag, hash, ref_id)
+	SELECT ?, name, is_dir, size, usage, mtime, item_count, mli, flag, hash,
+		CASE WHEN is_dir = 1 THEN COALESCE(ref_id, id) END
+	FROM items WHERE parent_id = ?`, id, refID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`UPDATE items SET ref_id = NULL WHERE id = ?`, id)
+	return err
+}
+
+// unshare keeps the content seen by other scans in the history unchanged
+// before the item is removed and the stats of its ancestors are updated
+func (s *SqliteStorage) unshare(tx *sql.Tx, id int64) error {
+	for {
+		// directories of other scans referencing the ancestors get their own copy of the changed level
+		rows, err := tx.Query(`
+		WITH RECURSIVE ancestors(id) AS (
+			SELECT parent_id FROM items WHERE id = ?
+			UNION ALL
+			SELECT items.parent_id FROM items JOIN ancestors ON items.id = ancestors.id WHERE items.parent_id IS NOT NULL
+		)
+		SELECT items.id, items.ref_id FROM items JOIN ancestors ON items.ref_id = ancestors.id`, id)
+		if err != nil {
+			return err
+		}
+		refs := make(map[int64]int64)
+		for rows.Next() {
+			var from, to int64
+			if err := rows.Scan(&from, &to); err != nil {
+				rows.Close()
+				return err
+			}
+			refs[from] = to
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(refs) == 0 {
+			break
+		}
+		for from, to := range refs {
+			if err := materialize(tx, from, to); err != nil {
+				return err
+			}
+		}
+	}
+
+	return rescueSubtree(tx, id)
+}
+
+// PruneScans removes trees of the oldest scans so that at most keep scans are kept in the history.
+// Usage of top-level directories of the removed scans stays in the history.
+func (s *SqliteStorage) PruneScans(keep int) error {
+	scans, err := s.GetHistoryScans()
+	if err != nil {
+		return err
+	}
+	stored := slices.DeleteFunc(scans, func(scan HistoryScan) bool { return !scan.HasTree() })
+	if len(stored) <= keep {
+		return nil
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, scan := range stored[:len(stored)-keep] {
+		log.Printf("Removing scan from %s from history", scan.Time.Format(time.RFC3339))
+		if err := rescueSubtree(tx, scan.RootID); err != nil {
+			rollback(tx)
+			return err
+		}
+		if err := deleteDescendants(tx, scan.RootID); err != nil {
+			rollback(tx)
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM items WHERE id = ?`, scan.RootID); err != nil {
+			rollback(tx)
+			return err
+		}
+		scan.RootID = 0
+		if err := setHistoryScan(tx, scan); err != nil {
+			rollback(tx)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// recordHistory stores the finished scan into the history,
+// deduplicates it with the previous scan and removes the oldest scans
+func (a *SqliteAnalyzer) recordHistory(root *SqliteItem, path string) {
+	if root == nil {
+		return
+	}
+	scan := *a.history
+	scan.Path = path
+	scan.RootID = root.id
+	if scan.Time.IsZero() {
+		scan.Time = time.Now()
+	}
+	// scans are keyed by time in seconds
+	scan.Time = scan.Time.Truncate(time.Second)
+
+	scans, err := a.storage.GetHistoryScans()
+	if err != nil {
+		log.Printf("Error loading history: %v", err)
+		return
+	}
+	var previous *HistoryScan
+	if len(scans) > 0 {
+		previous = &scans[len(scans)-1]
+		if !scan.Time.After(previous.Time) {
+			scan.Time = previous.Time.Add(time.Second)
+		}
+	}
+
+	entries, err := a.storage.getHistoryEntries(root, path)
+	if err == nil {
+		for i := range entries {
+			entries[i].Time = scan.Time
+		}
+		err = a.storage.AddHistoryScan(scan, entries)
+	}
+	if err != nil {
+		log.Printf("Error recording scan into history: %v", err)
+		return
+	}
+
+	if previous != nil && previous.HasTree() && previous.Path == path {
+		if err := a.storage.Deduplicate(previous.RootID, root.id); err != nil {
+			log.Printf("Error deduplicating scans: %v", err)
+		}
+	}
+	if a.keepScans > 0 {
+		if err := a.storage.PruneScans(a.keepScans); err != nil {
+			log.Printf("Error removing old scans: %v", err)
+		}
+	}
+}
+
+// KeepHistory makes the analyzer keep the previous scans in the database,
+// store the new one next to them and keep at most keep scans (all when zero).
+// Directories unchanged since the previous scan are stored only once.
+// The device fields of the scan describe the filesystem holding the scanned path.
+func (a *SqliteAnalyzer) KeepHistory(scan HistoryScan, keep int) {
+	a.history = &scan
+	a.keepScans = keep
+}
+
+// LoadScan makes the analyzer load the given scan from the history instead of scanning
+func (a *SqliteAnalyzer) LoadScan(scan HistoryScan) error {
+	root, err := a.storage.GetScanRootItem(scan)
+	if err != nil {
+		return err
+	}
+	a.scanRoot = root
+	return nil
+}
+
+// GetStorage returns the storage used by the analyzer
+func (a *SqliteAnalyzer) GetStorage() *SqliteStorage {
+	return a.storage
+}
+
+func rollback(tx *sql.Tx) {
+	if err := tx.Rollback(); err != nil {
+		log.Errorf("failed to rollback transaction: %v", err)
+	}
+}
+
+// hashItem returns hash of the attributes of the item,
+// hash of a directory is the sum of the hash of its own attributes and of hashes of its items
+func hashItem(name string, isDir bool, size, usage int64, mtime time.Time, flag rune) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	if isDir {
+		h.Write([]byte{'/'})
+	}
+	h.Write([]byte(strconv.FormatInt(size, 10) + ":" + strconv.FormatInt(usage, 10) + ":" +
+		strconv.FormatInt(mtime.UnixNano(), 10) + ":" + string(flag)))
+	return h.Sum64()
+}
+
+// hashDir returns hash of the dir made of the hashes of its items.
+// The hashes are sorted so the result does not depend on the order the items were read in.
+func hashDir(name string, mtime time.Time, flag rune, itemHashes []uint64) uint64 {
+	slices.Sort(itemHashes)
+	h := fnv.New64a()
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, hashItem(name, true, 0, 0, mtime, flag))
+	h.Write(buf)
+	for _, itemHash := range itemHashes {
+		binary.LittleEndian.PutUint64(buf, itemHash)
+		h.Write(buf)
+	}
+	return h.Sum64()
+}
+
+// GetUsageHistory returns usage of the item in the scans stored in the history of the database
+func (i *SqliteItem) GetUsageHistory() ([]HistoryEntry, error) {
+	return i.storage.GetUsageHistory(i)
+}
@@ -0,0 +1,350 @@
+//go:build (linux && !mips64 && !mipsle && !mips && !mips64le && !ppc64) || darwin || windows || (freebsd && !arm && !386) || (openbsd && !386) || (netbsd && !arm && !386 && !amd64)
+
+package analyze
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func TestSqliteStorageHistory(t *testing.T) {
+	storage, err := NewSqliteStorage(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer storage.Close()
+
+	later := time.Date(2026, 9, 2, 0, 0, 0, 0, time.UTC)
+	earlier := later.Add(-24 * time.Hour)
+	err = storage.AddHistoryScan(
+		HistoryScan{Time: later, Path: "/home", MountPoint: "/", DeviceSize: 100, DeviceFree: 40},
+		[]HistoryEntry{{Path: "/home", Usage: 60}, {Path: "/home/user", Usage: 50}},
+	)
+	assert.NoError(t, err)
+	err = storage.AddHistoryScan(
+		HistoryScan{Time: earlier, Path: "/home", MountPoint: "/", DeviceSize: 100, DeviceFree: 50},
+		[]HistoryEntry{{Path: "/home", Usage: 50}},
+	)
+	assert.NoError(t, err)
+
+	scans, err := storage.GetHistoryScans()
+	assert.NoError(t, err)
+	require.Len(t, scans, 2)
+	assert.True(t, scans[0].Time.Equal(earlier))
+	assert.Equal(t, int64(40), scans[1].DeviceFree)
+	assert.Equal(t, "/", scans[1].MountPoint)
+
+	entries, err := storage.GetHistory()
+	assert.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.True(t, entries[0].Time.Equal(earlier))
+	assert.Equal(t, "/home/user", entries[2].Path)
+	assert.Equal(t, int64(50), entries[2].Usage)
+
+	value, err := storage.GetMetadata("scan:" + "1788307200")
+	assert.NoError(t, err)
+	assert.Contains(t, value, `"path":"/home"`)
+}
+
+func TestSqliteAnalyzerKeepHistory(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	for _, scanTime := range []time.Time{time.Unix(1000, 0), time.Unix(2000, 0)} {
+		analyzer, err := CreateSqliteAnalyzer(dbPath)
+		require.NoError(t, err)
+		analyzer.KeepHistory(HistoryScan{Time: scanTime, MountPoint: "/"}, 0)
+		dir := analyzer.AnalyzeDir("test_dir", func(_, _ string) bool { return false }, nil)
+		assert.Equal(t, "test_dir", dir.GetName())
+		analyzer.storage.Close()
+	}
+
+	storage, err := NewSqliteStorage(dbPath)
+	require.NoError(t, err)
+	defer storage.Close()
+
+	scans, err := storage.GetHistoryScans()
+	assert.NoError(t, err)
+	require.Len(t, scans, 2)
+	assert.Equal(t, "test_dir", scans[1].Path)
+
+	entries, err := storage.GetHistory()
+	assert.NoError(t, err)
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		paths = append(paths, entry.Path)
+	}
+	assert.Equal(t, []string{"test_dir", "test_dir/nested", "test_dir", "test_dir/nested"}, paths)
+}
+
+// scanHistory scans test_dir into the database once for each of the given times
+func scanHistory(t *testing.T, dbPath string, keep int, times ...time.Time) {
+	t.Helper()
+	for _, scanTime := range times {
+		analyzer, err := CreateSqliteAnalyzer(dbPath)
+		require.NoError(t, err)
+		analyzer.KeepHistory(HistoryScan{Time: scanTime}, keep)
+		analyzer.AnalyzeDir("test_dir", func(_, _ string) bool { return false }, nil)
+		analyzer.storage.Close()
+	}
+}
+
+func getChildNames(t *testing.T, item fs.Item) []string {
+	t.Helper()
+	var names []string
+	for child := range item.GetFiles(fs.SortByName, fs.SortAsc) {
+		names = append(names, child.GetName())
+	}
+	return names
+}
+
+func getChild(t *testing.T, item fs.Item, name string) fs.Item {
+	t.Helper()
+	for child := range item.GetFiles(fs.SortByName, fs.SortAsc) {
+		if child.GetName() == name {
+			return child
+		}
+	}
+	require.Failf(t, "child not found", "%s not found in %s", name, item.GetPath())
+	return nil
+}
+
+func TestSqliteAnalyzerHistoryDeduplicates(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	scanHistory(t, dbPath, 0, time.Unix(1000, 0), time.Unix(2000, 0))
+
+	storage, err := NewSqliteStorage(dbPath)
+	require.NoError(t, err)
+	defer storage.Close()
+
+	var refs int
+	require.NoError(t, storage.db.QueryRow(`SELECT COUNT(*) FROM items WHERE ref_id IS NOT NULL`).Scan(&refs))
+	assert.Positive(t, refs)
+
+	scans, err := storage.GetHistoryScans()
+	require.NoError(t, err)
+	require.Len(t, scans, 2)
+
+	for _, scan := range scans {
+		root, err := storage.GetScanRootItem(scan)
+		require.NoError(t, err)
+		assert.Equal(t, "test_dir", root.GetPath())
+		nested := getChild(t, root, "nested")
+		assert.Equal(t, []string{"file2", "subnested"}, getChildNames(t, nested))
+		assert.Equal(t, []string{"file"}, getChildNames(t, getChild(t, nested, "subnested")))
+		assert.Equal(t, "test_dir/nested/subnested", getChild(t, nested, "subnested").GetPath())
+	}
+}
+
+func TestSqliteStorageFindScan(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	scanHistory(t, dbPath, 0, time.Unix(1000, 0), time.Unix(2000, 0))
+
+	storage, err := NewSqliteStorage(dbPath)
+	require.NoError(t, err)
+	defer storage.Close()
+
+	scan, err := storage.FindScan(time.Unix(1500, 0))
+	assert.NoError(t, err)
+	assert.True(t, scan.Time.Equal(time.Unix(1000, 0)))
+
+	scan, err = storage.FindScan(time.Unix(2000, 0))
+	assert.NoError(t, err)
+	assert.True(t, scan.Time.Equal(time.Unix(2000, 0)))
+
+	_, err = storage.FindScan(time.Unix(500, 0))
+	assert.ErrorIs(t, err, ErrNoScan)
+}
+
+func TestSqliteAnalyzerLoadScan(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	scanHistory(t, dbPath, 0, time.Unix(1000, 0))
+	require.NoError(t, os.WriteFile("test_dir/nested/file3", []byte("new"), 0o600))
+	scanHistory(t, dbPath, 0, time.Unix(2000, 0))
+
+	analyzer, err := CreateSqliteAnalyzer(dbPath)
+	require.NoError(t, err)
+	defer analyzer.storage.Close()
+
+	scan, err := analyzer.GetStorage().FindScan(time.Unix(1500, 0))
+	require.NoError(t, err)
+	require.NoError(t, analyzer.LoadScan(scan))
+
+	dir := analyzer.AnalyzeDir("test_dir", func(_, _ string) bool { return false }, nil)
+	assert.Equal(t, []string{"file2", "subnested"}, getChildNames(t, getChild(t, dir, "nested")))
+}
+
+func TestSqliteItemRemoveFileKeepsHistory(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	scanHistory(t, dbPath, 0, time.Unix(1000, 0), time.Unix(2000, 0))
+
+	storage, err := NewSqliteStorage(dbPath)
+	require.NoError(t, err)
+	defer storage.Close()
+
+	scans, err := storage.GetHistoryScans()
+	require.NoError(t, err)
+	require.Len(t, scans, 2)
+
+	latest, err := storage.GetScanRootItem(scans[1])
+	require.NoError(t, err)
+	nested := getChild(t, latest, "nested")
+	nested.RemoveFile(getChild(t, nested, "subnested"))
+	assert.Equal(t, []string{"file2"}, getChildNames(t, nested))
+
+	older, err := storage.GetScanRootItem(scans[0])
+	require.NoError(t, err)
+	olderNested := getChild(t, older, "nested")
+	assert.Equal(t, []string{"file2", "subnested"}, getChildNames(t, olderNested))
+	assert.Equal(t, []string{"file"}, getChildNames(t, getChild(t, olderNested, "subnested")))
+}
+
+func TestSqliteStoragePruneScans(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	scanHistory(t, dbPath, 2, time.Unix(1000, 0), time.Unix(2000, 0), time.Unix(3000, 0))
+
+	storage, err := NewSqliteStorage(dbPath)
+	require.NoError(t, err)
+	defer storage.Close()
+
+	scans, err := storage.GetHistoryScans()
+	require.NoError(t, err)
+	require.Len(t, scans, 3)
+	assert.False(t, scans[0].HasTree())
+	assert.True(t, scans[1].HasTree())
+
+	_, err = storage.GetScanRootItem(scans[0])
+	assert.ErrorIs(t, err, ErrNoScan)
+
+	for _, scan := range scans[1:] {
+		root, err := storage.GetScanRootItem(scan)
+		require.NoError(t, err)
+		nested := getChild(t, root, "nested")
+		assert.Equal(t, []string{"file2", "subnested"}, getChildNames(t, nested))
+		assert.Equal(t, []string{"file"}, getChildNames(t, getChild(t, nested, "subnested")))
+	}
+
+	entries, err := storage.GetHistory()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 6)
+}
+
+func TestSqliteItemGetUsageHistory(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	scanHistory(t, dbPath, 0, time.Unix(1000, 0))
+	require.NoError(t, os.WriteFile("test_dir/nested/subnested/file", make([]byte, 10000), 0o600))
+	scanHistory(t, dbPath, 0, time.Unix(2000, 0))
+
+	storage, err := NewSqliteStorage(dbPath)
+	require.NoError(t, err)
+	defer storage.Close()
+
+	root, err := storage.GetRootItem()
+	require.NoError(t, err)
+	subnested := getChild(t, getChild(t, root, "nested"), "subnested").(*SqliteItem)
+
+	entries, err := subnested.GetUsageHistory()
+	assert.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "test_dir/nested/subnested", entries[0].Path)
+	assert.True(t, entries[0].Time.Equal(time.Unix(1000, 0)))
+	assert.Equal(t, int64(10000-5), entries[1].Size-entries[0].Size)
+}
+
+func TestSqliteStorageMigratesItems(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", dbPath)
+	require.NoError(t, err)
+	// schema of databases created before the history of scans was kept
+	_, err = db.Exec(`CREATE TABLE items (
+		id          INTEGER PRIMARY KEY,
+		parent_id   INTEGER REFERENCES items(id),
+		name        TEXT NOT NULL,
+		is_dir      INTEGER NOT NULL,
+		size        INTEGER NOT NULL,
+		usage       INTEGER NOT NULL,
+		mtime       INTEGER NOT NULL,
+		item_count  INTEGER NOT NULL DEFAULT 1,
+		mli         INTEGER NOT NULL DEFAULT 0,
+		flag        TEXT NOT NULL DEFAULT ' '
+	)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO items (name, is_dir, size, usage, mtime, item_count, mli, flag)
+		VALUES ('root', 1, 0, 0, 0, 1, 0, ' ')`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	storage, err := NewSqliteStorage(dbPath)
+	require.NoError(t, err)
+	defer storage.Close()
+
+	root, err := storage.GetRootItem()
+	require.NoError(t, err)
+	assert.Equal(t, "root", root.GetName())
+	assert.Empty(t, getChildNames(t, root))
+}
+
+func TestHashDir(t *testing.T) {
+	mtime := time.Unix(1000, 0)
+
+	assert.Equal(t, hashDir("dir", mtime, ' ', []uint64{1, 4}), hashDir("dir", mtime, ' ', []uint64{4, 1}))
+	// items with the same sum of hashes make a different dir
+	assert.NotEqual(t, hashDir("dir", mtime, ' ', []uint64{1, 4}), hashDir("dir", mtime, ' ', []uint64{2, 3}))
+	assert.NotEqual(t, hashDir("dir", mtime, ' ', nil), hashDir("other", mtime, ' ', nil))
+}
+
+func TestDeduplicateDirWithDifferentStats(t *testing.T) {
+	storage, err := NewSqliteStorage(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer storage.Close()
+
+	mtime := time.Unix(1000, 0)
+	olderID, err := storage.InsertItem(nil, "dir", true, 10, 10, mtime, 2, 0, ' ')
+	require.NoError(t, err)
+	_, err = storage.InsertItem(&olderID, "file", false, 10, 10, mtime, 1, 0, ' ')
+	require.NoError(t, err)
+	newerID, err := storage.InsertItem(nil, "dir", true, 20, 20, mtime, 2, 0, ' ')
+	require.NoError(t, err)
+	_, err = storage.InsertItem(&newerID, "file", false, 20, 20, mtime, 1, 0, ' ')
+	require.NoError(t, err)
+	// colliding hashes of dirs with different content
+	require.NoError(t, storage.UpdateItemWithHash(olderID, 10, 10, 2, ' ', 42))
+	require.NoError(t, storage.UpdateItemWithHash(newerID, 20, 20, 2, ' ', 42))
+
+	require.NoError(t, storage.Deduplicate(olderID, newerID))
+
+	var refs int
+	require.NoError(t, storage.db.QueryRow(`SELECT COUNT(*) FROM items WHERE ref_id IS NOT NULL`).Scan(&refs))
+	assert.Zero(t, refs)
+
+	require.NoError(t, storage.UpdateItemWithHash(newerID, 10, 10, 2, ' ', 42))
+	require.NoError(t, storage.Deduplicate(olderID, newerID))
+	require.NoError(t, storage.db.QueryRow(`SELECT COUNT(*) FROM items WHERE ref_id IS NOT NULL`).Scan(&refs))
+	assert.Equal(t, 1, refs)
+}
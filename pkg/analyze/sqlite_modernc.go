@@ -3,10 +3,48 @@
 package analyze
 
 import (
-	// nolint:revive // Why: importing SQLite driver for side effects
-	_ "modernc.org/sqlite"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"modernc.org/sqlite"
 )
 
+// regexpCache holds regular expressions compiled by the REGEXP operator
+var regexpCache sync.Map
+
+func init() {
+	// X REGEXP Y calls regexp(Y, X)
+	sqlite.MustRegisterDeterministicScalarFunction("regexp", 2, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		pattern, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("regexp: pattern must be a string")
+		}
+		re, ok := regexpCache.Load(pattern)
+		if !ok {
+			compiled, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, err
+			}
+			re, _ = regexpCache.LoadOrStore(pattern, compiled)
+		}
+
+		var value string
+		switch v := args[1].(type) {
+		case nil:
+			return false, nil
+		case string:
+			value = v
+		case []byte:
+			value = string(v)
+		default:
+			value = fmt.Sprint(v)
+		}
+		return re.(*regexp.Regexp).MatchString(value), nil
+	})
+}
+
 // checkAvailable checks if the modernc SQLite driver is available
 func checkAvailable() error {
 	return nil
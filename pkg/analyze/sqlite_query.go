@@ -0,0 +1,227 @@
+package analyze
+
+import (
+	"database/sql"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/query"
+)
+
+// QueryView is the name of the view with full paths and depth of the items of the latest scan
+const QueryView = "files"
+
+// QueryResult holds the columns and rows returned by a query
+type QueryResult struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// OpenSqliteStorageReadOnly opens existing SQLite database for querying.
+// The database is never modified, the view of items with full paths is created in the temporary schema.
+func OpenSqliteStorageReadOnly(dbPath string) (*SqliteStorage, error) {
+	if err := checkAvailable(); err != nil {
+		return nil, err
+	}
+
+	dsn := (&url.URL{Scheme: "file", OmitHost: true, Path: filepath.ToSlash(dbPath), RawQuery: "mode=ro"}).String()
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// the temporary view exists only in the connection which created it
+	db.SetMaxOpenConns(1)
+
+	storage := &SqliteStorage{
+		db:     db,
+		dbPath: dbPath,
+	}
+	if err := storage.createQueryView(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return storage, nil
+}
+
+// createQueryView creates the view of the items of the latest scan with their full paths and depth
+func (s *SqliteStorage) createQueryView() error {
+	var count int
+	if err := s.db.QueryRow(
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'items'`,
+	).Scan(&count); err != nil {
+		return errors.Wrap(err, "opening database")
+	}
+	if count == 0 {
+		return errors.New("no analysis stored in database")
+	}
+
+	rootPath, err := s.GetMetadata("top_dir_path")
+	if err != nil {
+		rootPath = ""
+	}
+
+	// the latest scan is always stored completely, older scans of the history are left out
+	_, err = s.db.Exec(`
+	CREATE TEMP VIEW ` + QueryView + ` AS
+	WITH RECURSIVE tree(id, parent_id, name, path, depth, is_dir, size, usage, mtime, item_count, mli, flag) AS (
+		SELECT id, parent_id, name, COALESCE(NULLIF(` + quoteSQL(rootPath) + `, ''), name), 0,
+			is_dir, size, usage, mtime, item_count, mli, flag
+		FROM items WHERE id = (SELECT MAX(id) FROM items WHERE parent_id IS NULL)
+		UNION ALL
+		SELECT i.id, i.parent_id, i.name, t.path || ` + quoteSQL(string(filepath.Separator)) + ` || i.name, t.depth + 1,
+			i.is_dir, i.size, i.usage, i.mtime, i.item_count, i.mli, i.flag
+		FROM items i JOIN tree t ON i.parent_id = t.id
+	)
+	SELECT * FROM tree`)
+	return err
+}
+
+// Query runs the SQL query and returns all its rows
+func (s *SqliteStorage) Query(query string, args ...any) (*QueryResult, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := &QueryResult{Columns: columns}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			if b, ok := value.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	return result, rows.Err()
+}
+
+func quoteSQL(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// search evaluates the filter expression in the database.
+// The items of older scans referencing unchanged directories of newer scans are followed to the referenced rows
+// so the results are the same as when walking the tree item by item.
+func (i *SqliteItem) search(expr *query.Expr, limit int) (fs.Files, error) {
+	where, args := expr.SQL()
+	sep := quoteSQL(string(filepath.Separator))
+	sqlQuery := `
+	WITH RECURSIVE tree(id, src, ref, hop, ancestors, name, path, depth, is_dir, size, usage, mtime, item_count, mli, flag) AS (
+		SELECT id, id, ref_id, 1, '', name, ?, 0, is_dir, size, usage, mtime, item_count, mli, flag
+		FROM items WHERE id = ?
+		UNION ALL
+		SELECT t.id, r.id, r.ref_id, 1, t.ancestors, t.name, t.path, t.depth,
+			t.is_dir, t.size, t.usage, t.mtime, t.item_count, t.mli, t.flag
+		FROM items r JOIN tree t ON r.id = t.ref
+		UNION ALL
+		SELECT c.id, c.id, c.ref_id, 0, t.ancestors || ',' || t.id, c.name, t.path || ` + sep + ` || c.name, t.depth + 1,
+			c.is_dir, c.size, c.usage, c.mtime, c.item_count, c.mli, c.flag
+		FROM items c JOIN tree t ON c.parent_id = t.src AND t.ref IS NULL
+	)
+	SELECT id, ancestors, name, is_dir, size, usage, mtime, item_count, mli, flag
+	FROM tree WHERE hop = 0 AND (` + where + `) ORDER BY usage DESC, name DESC LIMIT ?`
+	if limit <= 0 {
+		limit = -1
+	}
+	args = append([]any{i.GetPath(), i.id}, args...)
+	args = append(args, limit)
+
+	type match struct {
+		item      *SqliteItem
+		ancestors string
+	}
+	var matches []match
+
+	i.storage.m.RLock()
+	rows, err := i.storage.db.Query(sqlQuery, args...)
+	if err != nil {
+		i.storage.m.RUnlock()
+		return nil, err
+	}
+	for rows.Next() {
+		item := &SqliteItem{storage: i.storage}
+		var ancestors, flag string
+		var isDirInt int
+		var mtimeUnix int64
+		if err = rows.Scan(
+			&item.id, &ancestors, &item.name, &isDirInt,
+			&item.size, &item.usage, &mtimeUnix, &item.itemCount,
+			&item.mli, &flag,
+		); err != nil {
+			break
+		}
+		item.isDir = isDirInt == 1
+		item.mtime = time.Unix(mtimeUnix, 0)
+		item.flag = ' '
+		if flag != "" {
+			item.flag = rune(flag[0])
+		}
+		matches = append(matches, match{item: item, ancestors: ancestors})
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	rows.Close()
+	i.storage.m.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// connect the found items to the browsed tree so their paths and parents are the same as when walking the tree
+	items := map[int64]*SqliteItem{i.id: i}
+	found := make(fs.Files, 0, len(matches))
+	for _, m := range matches {
+		parent := i
+		// the first ancestor is the searched directory itself
+		ids := strings.Split(m.ancestors, ",")[2:]
+		for _, value := range ids {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, errors.Wrap(err, "parsing ancestors")
+			}
+			dir, ok := items[id]
+			if !ok {
+				dir, err = i.storage.GetItemByID(id)
+				if err != nil {
+					return nil, err
+				}
+				dir.parentID = &parent.id
+				dir.SetParent(parent)
+				items[id] = dir
+			}
+			parent = dir
+		}
+		if item, ok := items[m.item.id]; ok {
+			// the item has been loaded already as an ancestor of another found item
+			found = append(found, item)
+			continue
+		}
+		m.item.parentID = &parent.id
+		m.item.SetParent(parent)
+		items[m.item.id] = m.item
+		found = append(found, m.item)
+	}
+	return found, nil
+}
@@ -0,0 +1,136 @@
+//go:build (linux && !mips64 && !mipsle && !mips && !mips64le && !ppc64) || darwin || windows || (freebsd && !arm && !386) || (openbsd && !386) || (netbsd && !arm && !386 && !amd64)
+
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/query"
+)
+
+func TestSqliteStorageQuery(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	analyzer, err := CreateSqliteAnalyzer(dbPath)
+	require.NoError(t, err)
+	analyzer.AnalyzeDir("test_dir", func(_, _ string) bool { return false }, nil)
+	analyzer.storage.Close()
+
+	storage, err := OpenSqliteStorageReadOnly(dbPath)
+	require.NoError(t, err)
+	defer storage.Close()
+
+	result, err := storage.Query(`SELECT path, depth, is_dir, size FROM files ORDER BY path`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"path", "depth", "is_dir", "size"}, result.Columns)
+	require.Len(t, result.Rows, 5)
+	assert.Equal(t, []any{"test_dir", int64(0), int64(1)}, result.Rows[0][:3])
+	assert.Equal(t, []any{"test_dir/nested/subnested/file", int64(3), int64(0), int64(5)}, result.Rows[4])
+
+	result, err = storage.Query(`SELECT name FROM files WHERE size > ? AND is_dir = 0`, 4)
+	require.NoError(t, err)
+	assert.Equal(t, [][]any{{"file"}}, result.Rows)
+
+	_, err = storage.Query(`DELETE FROM items`)
+	assert.ErrorContains(t, err, "readonly")
+
+	_, err = storage.Query(`SELECT * FROM unknown`)
+	assert.Error(t, err)
+}
+
+func TestSqliteStorageQueryLatestScan(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	scanHistory(t, dbPath, 0, time.Unix(1000, 0), time.Unix(2000, 0))
+
+	storage, err := OpenSqliteStorageReadOnly(dbPath)
+	require.NoError(t, err)
+	defer storage.Close()
+
+	result, err := storage.Query(`SELECT COUNT(*) FROM files`)
+	require.NoError(t, err)
+	assert.Equal(t, [][]any{{int64(5)}}, result.Rows)
+}
+
+func TestOpenSqliteStorageReadOnlyErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := OpenSqliteStorageReadOnly(filepath.Join(dir, "missing.db"))
+	assert.Error(t, err)
+
+	storage, err := NewSqliteStorage(filepath.Join(dir, "empty.db"))
+	require.NoError(t, err)
+	_, err = storage.db.Exec(`DROP TABLE items`)
+	require.NoError(t, err)
+	storage.Close()
+
+	_, err = OpenSqliteStorageReadOnly(filepath.Join(dir, "empty.db"))
+	assert.EqualError(t, err, "no analysis stored in database")
+}
+
+func TestSqliteItemSearch(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	analyzer, err := CreateSqliteAnalyzer(dbPath)
+	require.NoError(t, err)
+	defer analyzer.storage.Close()
+	dir := analyzer.AnalyzeDir("test_dir", func(_, _ string) bool { return false }, nil)
+
+	assert.ElementsMatch(t, []string{"test_dir/nested/subnested/file", "test_dir/nested/file2"},
+		searchPaths(t, dir, "type = file and size > 0", 0))
+	assert.Len(t, searchPaths(t, dir, "type = file", 1), 1)
+	assert.Equal(t, []string{"test_dir/nested/file2"}, searchPaths(t, dir, "name ~ /^file\\d$/", 0))
+	assert.Equal(t, []string{"test_dir/nested"}, searchPaths(t, dir, "depth = 1", 0))
+	assert.Equal(t, []string{"test_dir/nested/subnested"}, searchPaths(t, dir, "path ~ '*/subnested'", 0))
+
+	// the found items are connected to the searched tree
+	nested := getChild(t, dir, "nested")
+	parsed, err := query.Parse("name = 'file'", time.Now(), time.Local)
+	require.NoError(t, err)
+	found, err := Search(nested, parsed, 0)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "subnested", found[0].GetParent().GetName())
+	assert.Same(t, nested, found[0].GetParent().GetParent())
+}
+
+func TestSqliteItemSearchHistory(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	scanHistory(t, dbPath, 0, time.Unix(1000, 0))
+	require.NoError(t, os.WriteFile("test_dir/nested/file3", []byte("new"), 0o600))
+	scanHistory(t, dbPath, 0, time.Unix(2000, 0))
+
+	storage, err := NewSqliteStorage(dbPath)
+	require.NoError(t, err)
+	defer storage.Close()
+	scans, err := storage.GetHistoryScans()
+	require.NoError(t, err)
+	require.Len(t, scans, 2)
+
+	// the unchanged subnested dir of the older scan references the latest scan
+	older, err := storage.GetScanRootItem(scans[0])
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"test_dir/nested/subnested/file", "test_dir/nested/file2"},
+		searchPaths(t, older, "type = file", 0))
+
+	latest, err := storage.GetScanRootItem(scans[1])
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"test_dir/nested/subnested/file", "test_dir/nested/file3", "test_dir/nested/file2"},
+		searchPaths(t, latest, "type = file", 0))
+}
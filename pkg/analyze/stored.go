@@ -10,6 +10,7 @@ import (
 
 	"github.com/dundee/gdu/v5/internal/common"
 	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/ignore"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -34,6 +35,7 @@ func (a *StoredAnalyzer) AnalyzeDir(
 	path string, ignore common.ShouldDirBeIgnored, fileTypeFilter common.ShouldFileBeIgnored,
 ) fs.Item {
 	a.ignoreDir = ignore
+	a.rootPath = path
 	a.ignoreFileType = fileTypeFilter
 
 	a.storage = NewStorage(a.storagePath, path)
@@ -49,7 +51,7 @@ func (a *StoredAnalyzer) AnalyzeDir(
 	a.ignoreDir = ignore
 
 	go a.UpdateProgress()
-	dir := a.processDir(path)
+	dir := a.processDir(path, a.rootIgnoreRules(path))
 
 	a.wait.Wait()
 
@@ -59,7 +61,7 @@ func (a *StoredAnalyzer) AnalyzeDir(
 	return dir
 }
 
-func (a *StoredAnalyzer) processDir(path string) *StoredDir {
+func (a *StoredAnalyzer) processDir(path string, rules *ignore.Rules) *StoredDir {
 	var (
 		file       fs.Item
 		err        error
@@ -70,16 +72,17 @@ func (a *StoredAnalyzer) processDir(path string) *StoredDir {
 
 	a.wait.Add(1)
 
-	files, err := os.ReadDir(path)
+	files, err := a.readDirLimited(path)
 	if err != nil {
-		log.Print(err.Error())
+		a.addScanError(path, err)
 	}
+	dirInfo := statDir(path)
 
 	dir := &StoredDir{
 		Dir: &Dir{
 			File: &File{
 				Name: filepath.Base(path),
-				Flag: getDirFlag(err, len(files)),
+				Flag: getDirFlag(err, len(files), isSubvolume(path, dirInfo)),
 			},
 			BasePath:  filepath.Dir(path),
 			ItemCount: 1,
@@ -88,7 +91,8 @@ func (a *StoredAnalyzer) processDir(path string) *StoredDir {
 	}
 	parent := &ParentDir{Path: path}
 
-	setDirPlatformSpecificAttrs(dir.Dir, path)
+	setDirPlatformSpecificAttrs(dir.Dir, dirInfo)
+	rules = a.dirIgnoreRules(rules, path, files)
 
 	for _, f := range files {
 		if a.IsCancelled() {
@@ -97,7 +101,7 @@ func (a *StoredAnalyzer) processDir(path string) *StoredDir {
 		name := f.Name()
 		entryPath := filepath.Join(path, name)
 		if f.IsDir() {
-			if a.shouldSkipDir(name, entryPath) {
+			if a.shouldSkipDir(rules, name, entryPath) {
 				continue
 			}
 			dirCount++
@@ -115,26 +119,29 @@ func (a *StoredAnalyzer) processDir(path string) *StoredDir {
 			dir.AddFile(subdir)
 
 			go func(entryPath string) {
-				concurrencyLimit <- struct{}{}
-				a.processDir(entryPath)
-				<-concurrencyLimit
+				a.concurrencyLimit <- struct{}{}
+				a.processDir(entryPath, rules)
+				<-a.concurrencyLimit
 			}(entryPath)
 		} else {
 			// Apply file type filter if set
 			if a.ignoreFileType != nil && a.ignoreFileType(name) {
 				continue // Skip this file
 			}
+			if rules.Match(entryPath, false) {
+				continue
+			}
 
 			info, err = f.Info()
 			if err != nil {
-				log.Print(err.Error())
+				a.addScanError(entryPath, err)
 				continue
 			}
 
 			if a.followSymlinks && info.Mode()&os.ModeSymlink != 0 {
 				infoF, err := followSymlink(entryPath, a.gitAnnexedSize)
 				if err != nil {
-					log.Print(err.Error())
+					a.addScanError(entryPath, err)
 					continue
 				}
 				if infoF != nil {
@@ -144,8 +151,8 @@ func (a *StoredAnalyzer) processDir(path string) *StoredDir {
 
 			symlinkTarget := readSymlinkTarget(f.Type(), entryPath)
 
-			// Apply time filter if set
-			if a.matchesTimeFilterFn != nil && !a.matchesTimeFilterFn(info.ModTime()) {
+			// Apply time and file filters if set
+			if !a.includeFile(info) {
 				continue // Skip this file
 			}
 
@@ -185,6 +192,7 @@ func (a *StoredAnalyzer) processDir(path string) *StoredDir {
 				// Only set platform-specific attributes for regular files
 				if regularFile, ok := file.(*File); ok {
 					setPlatformSpecificAttrs(regularFile, info)
+					a.setSharedUsage(regularFile, info, entryPath)
 				}
 				totalUsage += file.GetUsage()
 				dir.AddFile(file)
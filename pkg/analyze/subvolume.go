@@ -0,0 +1,27 @@
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// isSubvolume returns true if the dir is a root of btrfs subvolume (or snapshot) or zfs snapshot,
+// info is the already read info of the dir (nil if it could not be read)
+func isSubvolume(path string, info os.FileInfo) bool {
+	return isZfsSnapshot(path) || info != nil && isBtrfsSubvolume(path, info)
+}
+
+// isZfsSnapshot returns true for dirs inside of the .zfs/snapshot control dir
+func isZfsSnapshot(path string) bool {
+	parent := filepath.Dir(path)
+	return filepath.Base(parent) == "snapshot" && filepath.Base(filepath.Dir(parent)) == ".zfs"
+}
+
+// statDir returns the info of the dir or nil if it could not be read
+func statDir(path string) os.FileInfo {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	return info
+}
@@ -0,0 +1,36 @@
+package analyze
+
+import (
+	"os"
+	"sync"
+	"syscall"
+)
+
+const (
+	btrfsSuperMagic = 0x9123683e
+	// btrfsSubvolumeIno is the inode number of the root dir of every btrfs subvolume
+	btrfsSubvolumeIno = 256
+)
+
+// btrfsDevices caches whether the device (by its ID) holds btrfs filesystem
+var btrfsDevices sync.Map
+
+// isBtrfsSubvolume checks the inode number from the info first,
+// so the filesystem type is looked up only for dirs which can be subvolumes, once per device
+func isBtrfsSubvolume(path string, info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || stat.Ino != btrfsSubvolumeIno {
+		return false
+	}
+	if btrfs, ok := btrfsDevices.Load(stat.Dev); ok {
+		return btrfs.(bool)
+	}
+
+	var fsStat syscall.Statfs_t
+	if err := syscall.Statfs(path, &fsStat); err != nil {
+		return false
+	}
+	btrfs := uint32(fsStat.Type) == btrfsSuperMagic // nolint: gosec // Why: magic numbers fit into 32 bits
+	btrfsDevices.Store(stat.Dev, btrfs)
+	return btrfs
+}
@@ -0,0 +1,58 @@
+package analyze
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type subvolumeInfo struct {
+	stat syscall.Stat_t
+}
+
+func (i subvolumeInfo) Name() string       { return "subvol" }
+func (i subvolumeInfo) Size() int64        { return 0 }
+func (i subvolumeInfo) Mode() os.FileMode  { return os.ModeDir }
+func (i subvolumeInfo) ModTime() time.Time { return time.Time{} }
+func (i subvolumeInfo) IsDir() bool        { return true }
+func (i subvolumeInfo) Sys() any           { return &i.stat }
+
+func TestIsBtrfsSubvolumeCachesDevice(t *testing.T) {
+	const dev = 0xfffffff0
+	t.Cleanup(func() { btrfsDevices.Delete(uint64(dev)) })
+
+	info := subvolumeInfo{stat: syscall.Stat_t{Ino: btrfsSubvolumeIno, Dev: dev}}
+
+	// the type of the filesystem is cached for the device and not looked up again
+	btrfsDevices.Store(uint64(dev), true)
+	assert.True(t, isBtrfsSubvolume("/nonexistent", info))
+	btrfsDevices.Store(uint64(dev), false)
+	assert.False(t, isBtrfsSubvolume("/nonexistent", info))
+}
+
+func TestIsBtrfsSubvolumeOtherInode(t *testing.T) {
+	info := subvolumeInfo{stat: syscall.Stat_t{Ino: btrfsSubvolumeIno + 1}}
+	assert.False(t, isBtrfsSubvolume("/nonexistent", info))
+}
+
+func TestIsBtrfsSubvolumeOfNonBtrfs(t *testing.T) {
+	dir := t.TempDir()
+	info := statDir(dir)
+	stat := *info.Sys().(*syscall.Stat_t)
+	t.Cleanup(func() { btrfsDevices.Delete(stat.Dev) })
+
+	var fsStat syscall.Statfs_t
+	assert.NoError(t, syscall.Statfs(dir, &fsStat))
+	if uint32(fsStat.Type) == btrfsSuperMagic { // nolint: gosec // Why: magic numbers fit into 32 bits
+		t.Skip("temp dir is on btrfs")
+	}
+
+	stat.Ino = btrfsSubvolumeIno
+	assert.False(t, isBtrfsSubvolume(dir, subvolumeInfo{stat: stat}))
+	btrfs, ok := btrfsDevices.Load(stat.Dev)
+	assert.True(t, ok)
+	assert.Equal(t, false, btrfs)
+}
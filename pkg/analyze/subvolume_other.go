@@ -0,0 +1,9 @@
+//go:build !linux
+
+package analyze
+
+import "os"
+
+func isBtrfsSubvolume(_ string, _ os.FileInfo) bool {
+	return false
+}
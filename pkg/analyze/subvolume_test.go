@@ -0,0 +1,75 @@
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func TestIsZfsSnapshot(t *testing.T) {
+	assert.True(t, isZfsSnapshot("/tank/.zfs/snapshot/daily-1"))
+	assert.False(t, isZfsSnapshot("/tank/.zfs/snapshot"))
+	assert.False(t, isZfsSnapshot("/tank/snapshot/daily-1"))
+	assert.False(t, isZfsSnapshot("/tank/.zfs/snapshot/daily-1/etc"))
+}
+
+func TestIsSubvolumeOfOrdinaryDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.False(t, isSubvolume(dir, statDir(dir)))
+}
+
+func TestIsSubvolumeWithoutInfo(t *testing.T) {
+	assert.True(t, isSubvolume("/tank/.zfs/snapshot/daily-1", nil))
+	assert.False(t, isSubvolume("/nonexistent", statDir("/nonexistent")))
+}
+
+func TestZfsSnapshotFlagged(t *testing.T) {
+	tmpDir := t.TempDir()
+	snapshot := filepath.Join(tmpDir, ".zfs", "snapshot", "daily-1")
+	assert.Nil(t, os.MkdirAll(snapshot, 0o755))
+	assert.Nil(t, os.WriteFile(filepath.Join(snapshot, "file"), []byte("hello"), 0o600))
+
+	analyzer := CreateAnalyzer()
+	dir := analyzer.AnalyzeDir(
+		tmpDir, func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	).(*Dir)
+	analyzer.GetDone().Wait()
+	dir.UpdateStats(make(fs.HardLinkedItems))
+
+	zfsDir := dir.Files[0].(*Dir)
+	snapshotDir := zfsDir.Files[0].(*Dir)
+	assert.Equal(t, 'S', snapshotDir.Files[0].GetFlag())
+	assert.Equal(t, ' ', snapshotDir.GetFlag())
+}
+
+func TestErrorsInsideSubvolumeFlagged(t *testing.T) {
+	dir := &Dir{
+		File:      &File{Name: "subvol", Flag: 'S'},
+		ItemCount: 1,
+	}
+	sub := &Dir{
+		File:      &File{Name: "unreadable", Flag: '!', Parent: dir},
+		ItemCount: 1,
+	}
+	dir.AddFile(sub)
+
+	dir.UpdateStats(make(fs.HardLinkedItems))
+
+	assert.Equal(t, '.', dir.GetFlag())
+}
+
+func TestSubvolumeFlagKeptWithoutErrorsInside(t *testing.T) {
+	dir := &Dir{
+		File:      &File{Name: "subvol", Flag: 'S'},
+		ItemCount: 1,
+	}
+	dir.AddFile(&File{Name: "file", Flag: ' ', Parent: dir})
+
+	dir.UpdateStats(make(fs.HardLinkedItems))
+
+	assert.Equal(t, 'S', dir.GetFlag())
+}
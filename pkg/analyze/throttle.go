@@ -0,0 +1,166 @@
+package analyze
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dundee/gdu/v5/internal/common"
+)
+
+const (
+	// loadCheckInterval is how often the load average is read while the scan is throttled by the load
+	loadCheckInterval = time.Second
+	// throttleSleep is the longest sleep of throttled scan between checks whether it was cancelled
+	throttleSleep = 100 * time.Millisecond
+)
+
+// loadAverage returns 1-minute load average of the system
+var loadAverage = getLoadAverage
+
+var _ common.Throttle = (*Throttle)(nil)
+
+// Throttle limits the impact of the scan on the host by the rate of reads and the load of the system.
+// It can be shared by several analyzers, e.g. the ones scanning multiple paths, which then share the limits.
+type Throttle struct {
+	// rate limits the number of directory reads and file stats per second, nil means no limit
+	rate *rateLimiter
+	// load pauses the scan while the load of the system is too high, nil means no limit
+	load *loadGuard
+}
+
+// CreateThrottle returns throttle limiting the number of directory reads and file stats to perSecond
+// and pausing reading of directories while the 1-minute load average of the system exceeds maxLoad,
+// 0 means no limit for both. Error is returned if the load average cannot be read on this platform.
+func CreateThrottle(perSecond int, maxLoad float64) (*Throttle, error) {
+	t := &Throttle{}
+	if maxLoad > 0 {
+		if _, err := loadAverage(); err != nil {
+			return nil, err
+		}
+		t.load = &loadGuard{max: maxLoad}
+	}
+	if perSecond > 0 {
+		t.rate = newRateLimiter(perSecond)
+	}
+	return t, nil
+}
+
+// State returns description of the current throttling of the scan, empty if the scan is not throttled
+func (t *Throttle) State() string {
+	var state []string
+	if t.load != nil {
+		if load, overloaded := t.load.state(); overloaded {
+			state = append(state, fmt.Sprintf("paused, load %.2f > %.2f", load, t.load.max))
+		}
+	}
+	if t.rate != nil && t.rate.waiting.Load() > 0 {
+		state = append(state, fmt.Sprintf("limited to %.0f reads/s", t.rate.rate))
+	}
+	return strings.Join(state, ", ")
+}
+
+// Wait blocks while the scan is paused for high load of the system
+// or the rate limit is exceeded, cancelled scans are not blocked
+func (t *Throttle) Wait(cancelled func() bool) {
+	if t.load != nil {
+		t.load.wait(cancelled)
+	}
+	if t.rate != nil {
+		t.rate.wait(1, cancelled)
+	}
+}
+
+// Charge counts the stats of the files of the read dir into the rate limit
+func (t *Throttle) Charge(files int) {
+	if t.rate != nil && files > 0 {
+		t.rate.take(files)
+	}
+}
+
+// rateLimiter is a token bucket refilled with rate tokens per second holding at most rate tokens
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	tokens  float64
+	last    time.Time
+	waiting atomic.Int32
+}
+
+func newRateLimiter(perSecond int) *rateLimiter {
+	return &rateLimiter{
+		rate:   float64(perSecond),
+		tokens: float64(perSecond),
+		last:   time.Now(),
+	}
+}
+
+// take takes n tokens and returns how long to wait until they are available,
+// tokens are taken even if they are not available yet so that the following callers wait longer
+func (r *rateLimiter) take(n int) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.tokens = min(r.rate, r.tokens+now.Sub(r.last).Seconds()*r.rate)
+	r.last = now
+	r.tokens -= float64(n)
+	if r.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-r.tokens / r.rate * float64(time.Second))
+}
+
+func (r *rateLimiter) wait(n int, cancelled func() bool) {
+	delay := r.take(n)
+	if delay <= 0 {
+		return
+	}
+
+	r.waiting.Add(1)
+	defer r.waiting.Add(-1)
+	for deadline := time.Now().Add(delay); !cancelled(); {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return
+		}
+		time.Sleep(min(remaining, throttleSleep))
+	}
+}
+
+// loadGuard pauses the scan while the load average of the system exceeds max
+type loadGuard struct {
+	mu      sync.Mutex
+	max     float64
+	current float64
+	checked time.Time
+}
+
+// overloaded reads the load average (at most once per loadCheckInterval) and compares it with max
+func (g *loadGuard) overloaded() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if now := time.Now(); now.Sub(g.checked) >= loadCheckInterval {
+		if load, err := loadAverage(); err == nil {
+			g.current = load
+		}
+		g.checked = now
+	}
+	return g.current > g.max
+}
+
+// state returns the last read load average and whether it exceeds max
+func (g *loadGuard) state() (float64, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.current, g.current > g.max
+}
+
+func (g *loadGuard) wait(cancelled func() bool) {
+	for g.overloaded() && !cancelled() {
+		time.Sleep(throttleSleep)
+	}
+}
@@ -0,0 +1,215 @@
+package analyze
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func notCancelled() bool { return false }
+
+// setLoadAverage replaces the load average of the system for the test
+func setLoadAverage(t *testing.T, load *atomic.Int64) {
+	t.Helper()
+	orig := loadAverage
+	loadAverage = func() (float64, error) { return float64(load.Load()), nil }
+	t.Cleanup(func() { loadAverage = orig })
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+	r := newRateLimiter(100)
+
+	assert.Zero(t, r.take(100))
+	assert.Positive(t, r.take(1))
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	r := newRateLimiter(100)
+	r.take(105)
+
+	start := time.Now()
+	r.wait(5, notCancelled)
+
+	// 10 tokens are missing
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	assert.Zero(t, r.waiting.Load())
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	r := newRateLimiter(1)
+	r.take(100)
+
+	start := time.Now()
+	r.wait(1, func() bool { return true })
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestCreateThrottleWithoutLimits(t *testing.T) {
+	throttle, err := CreateThrottle(0, 0)
+	assert.NoError(t, err)
+	assert.Nil(t, throttle.rate)
+	assert.Nil(t, throttle.load)
+
+	throttle.Wait(notCancelled)
+	throttle.Charge(100)
+	assert.Empty(t, throttle.State())
+}
+
+func TestRateLimitState(t *testing.T) {
+	throttle, err := CreateThrottle(10, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, throttle.State())
+
+	throttle.Charge(20)
+	done := make(chan struct{})
+	go func() {
+		throttle.Wait(notCancelled)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return throttle.State() == "limited to 10 reads/s"
+	}, time.Second, time.Millisecond)
+	<-done
+	assert.Empty(t, throttle.State())
+}
+
+func TestCreateThrottleWithMaxLoad(t *testing.T) {
+	var load atomic.Int64
+	setLoadAverage(t, &load)
+
+	throttle, err := CreateThrottle(0, 2)
+	assert.NoError(t, err)
+	assert.NotNil(t, throttle.load)
+	assert.Nil(t, throttle.rate)
+}
+
+func TestCreateThrottleUnsupported(t *testing.T) {
+	orig := loadAverage
+	defer func() { loadAverage = orig }()
+	loadAverage = func() (float64, error) { return 0, errors.ErrUnsupported }
+
+	_, err := CreateThrottle(0, 2)
+	assert.ErrorIs(t, err, errors.ErrUnsupported)
+
+	// the load average is not needed without the limit
+	_, err = CreateThrottle(10, 0)
+	assert.NoError(t, err)
+}
+
+func TestMaxLoadPausesScan(t *testing.T) {
+	var load atomic.Int64
+	load.Store(5)
+	setLoadAverage(t, &load)
+	throttle, err := CreateThrottle(0, 2)
+	assert.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		throttle.Wait(notCancelled)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return throttle.State() == "paused, load 5.00 > 2.00"
+	}, time.Second, time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("scan was not paused")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	load.Store(1)
+	select {
+	case <-done:
+	case <-time.After(3 * loadCheckInterval):
+		t.Fatal("scan was not resumed")
+	}
+	assert.Empty(t, throttle.State())
+}
+
+func TestMaxLoadCancelled(t *testing.T) {
+	var load atomic.Int64
+	load.Store(5)
+	setLoadAverage(t, &load)
+	throttle, err := CreateThrottle(0, 2)
+	assert.NoError(t, err)
+
+	start := time.Now()
+	throttle.Wait(func() bool { return true })
+	assert.Less(t, time.Since(start), loadCheckInterval)
+}
+
+func TestAnalyzeDirWithRateLimit(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	// the test dir has 3 dirs and 2 files, the burst covers all of them
+	throttle, err := CreateThrottle(100, 0)
+	assert.NoError(t, err)
+
+	analyzer := CreateAnalyzer()
+	analyzer.SetThrottle(throttle)
+	dir := analyzer.AnalyzeDir(
+		"test_dir", func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	)
+	analyzer.GetDone().Wait()
+	dir.UpdateStats(make(fs.HardLinkedItems))
+
+	assert.Equal(t, int64(7), dir.GetSize())
+	assert.Equal(t, int64(5), dir.GetItemCount())
+	assert.Empty(t, analyzer.GetProgress().Throttle)
+
+	// all reads were charged to the throttle of the analyzer
+	assert.Less(t, throttle.rate.tokens, 100.0)
+}
+
+func TestThrottleNotSharedByOtherAnalyzers(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	throttle, err := CreateThrottle(100, 0)
+	assert.NoError(t, err)
+	throttled := CreateAnalyzer()
+	throttled.SetThrottle(throttle)
+
+	analyzer := CreateAnalyzer()
+	analyzer.AnalyzeDir(
+		"test_dir", func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	)
+	analyzer.GetDone().Wait()
+
+	assert.Equal(t, 100.0, throttle.rate.tokens)
+}
+
+func TestMultiPathAnalyzerSharesThrottle(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	throttle, err := CreateThrottle(100, 0)
+	assert.NoError(t, err)
+
+	var created []*ParallelAnalyzer
+	analyzer := CreateMultiPathAnalyzer([]string{"test_dir/nested", "test_dir/nested/subnested"}, func() common.Analyzer {
+		a := CreateAnalyzer()
+		created = append(created, a)
+		return a
+	})
+	analyzer.SetThrottle(throttle)
+	analyzer.AnalyzeDir(
+		analyzer.RootPath(), func(_, _ string) bool { return false }, func(_ string) bool { return false },
+	)
+	analyzer.GetDone().Wait()
+
+	assert.Len(t, created, 2)
+	for _, a := range created {
+		assert.Same(t, throttle, a.throttle)
+	}
+}
@@ -0,0 +1,280 @@
+package analyze
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// constants of the io_uring ABI (include/uapi/linux/io_uring.h)
+const (
+	uringEntries        = 256
+	uringOpStatx        = 21
+	uringEnterGetEvents = 1 << 0
+	uringFeatSingleMmap = 1 << 0
+	uringOffSqRing      = 0
+	uringOffSqes        = 0x10000000
+	uringSqeSize        = 64
+	uringCqeSize        = 16
+
+	// uringMinBatch is the minimal number of entries of a dir stated with io_uring,
+	// smaller dirs are stated directly as the batching would not pay off
+	uringMinBatch = 32
+	// maxIdleUrings is the number of rings kept for reuse
+	maxIdleUrings = 16
+)
+
+var (
+	errUringUnsupported = errors.New("io_uring statx is not supported")
+
+	// uringUnsupported is set when io_uring cannot be used (old kernel, disabled by sysctl or seccomp)
+	uringUnsupported atomic.Bool
+	idleUrings       = make(chan *uring, maxIdleUrings)
+
+	// failedUrings keep the rings with requests possibly still in flight,
+	// their buffers must not be freed
+	failedUrings   []*uring
+	failedUringsMu sync.Mutex
+)
+
+type uringSqringOffsets struct {
+	head, tail, ringMask, ringEntries, flags, dropped, array, resv1 uint32
+	userAddr                                                        uint64
+}
+
+type uringCqringOffsets struct {
+	head, tail, ringMask, ringEntries, overflow, cqes, flags, resv1 uint32
+	userAddr                                                        uint64
+}
+
+type uringParams struct {
+	sqEntries, cqEntries, flags, sqThreadCPU, sqThreadIdle, features, wqFd uint32
+	resv                                                                   [3]uint32
+	sqOff                                                                  uringSqringOffsets
+	cqOff                                                                  uringCqringOffsets
+}
+
+type uringSqe struct {
+	opcode      uint8
+	flags       uint8
+	ioprio      uint16
+	fd          int32
+	off         uint64 // addr2, the statx buffer
+	addr        uint64 // the path
+	len         uint32 // the statx mask
+	opFlags     uint32 // the statx flags
+	userData    uint64
+	bufIndex    uint16
+	personality uint16
+	spliceFdIn  int32
+	addr3       uint64
+	_           uint64
+}
+
+type uringCqe struct {
+	userData uint64
+	res      int32
+	flags    uint32
+}
+
+// uring is io_uring instance submitting batches of statx requests.
+// It is used by one goroutine at a time.
+type uring struct {
+	fd      int
+	ring    []byte
+	sqesMem []byte
+
+	sqTail  *uint32
+	sqMask  uint32
+	sqArray []uint32
+	sqes    []uringSqe
+	cqHead  *uint32
+	cqTail  *uint32
+	cqMask  uint32
+	cqes    []uringCqe
+
+	stx      [uringEntries]unix.Statx_t
+	res      [uringEntries]int32
+	names    []byte
+	inFlight bool
+}
+
+func newUring() (*uring, error) {
+	var p uringParams
+	fd, _, errno := unix.Syscall(unix.SYS_IO_URING_SETUP, uringEntries, uintptr(unsafe.Pointer(&p)), 0)
+	if errno != 0 {
+		return nil, errno
+	}
+	r := &uring{fd: int(fd)}
+
+	// kernels without single mmap (< 5.4) do not support statx either
+	if p.features&uringFeatSingleMmap == 0 || p.sqEntries < uringEntries {
+		r.close()
+		return nil, errUringUnsupported
+	}
+
+	size := max(p.sqOff.array+p.sqEntries*4, p.cqOff.cqes+p.cqEntries*uringCqeSize)
+	var err error
+	r.ring, err = unix.Mmap(r.fd, uringOffSqRing, int(size), unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED|unix.MAP_POPULATE)
+	if err != nil {
+		r.close()
+		return nil, err
+	}
+	r.sqesMem, err = unix.Mmap(
+		r.fd, uringOffSqes, int(p.sqEntries)*uringSqeSize, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED|unix.MAP_POPULATE,
+	)
+	if err != nil {
+		r.close()
+		return nil, err
+	}
+
+	r.sqTail = r.uint32At(p.sqOff.tail)
+	r.sqMask = *r.uint32At(p.sqOff.ringMask)
+	r.sqArray = unsafe.Slice(r.uint32At(p.sqOff.array), p.sqEntries)
+	r.sqes = unsafe.Slice((*uringSqe)(unsafe.Pointer(&r.sqesMem[0])), p.sqEntries)
+	r.cqHead = r.uint32At(p.cqOff.head)
+	r.cqTail = r.uint32At(p.cqOff.tail)
+	r.cqMask = *r.uint32At(p.cqOff.ringMask)
+	r.cqes = unsafe.Slice((*uringCqe)(unsafe.Pointer(&r.ring[p.cqOff.cqes])), p.cqEntries)
+	return r, nil
+}
+
+func (r *uring) uint32At(offset uint32) *uint32 {
+	return (*uint32)(unsafe.Pointer(&r.ring[offset]))
+}
+
+func (r *uring) close() {
+	if r.sqesMem != nil {
+		_ = unix.Munmap(r.sqesMem)
+	}
+	if r.ring != nil {
+		_ = unix.Munmap(r.ring)
+	}
+	_ = unix.Close(r.fd)
+}
+
+// getUring returns idle ring or creates new one, nil is returned if io_uring is not supported
+func getUring() *uring {
+	select {
+	case r := <-idleUrings:
+		return r
+	default:
+	}
+
+	if uringUnsupported.Load() {
+		return nil
+	}
+	r, err := newUring()
+	if err != nil {
+		uringUnsupported.Store(true)
+		return nil
+	}
+	return r
+}
+
+// putUring returns the ring for reuse, rings which failed are not used anymore
+func putUring(r *uring, err error) {
+	if err != nil {
+		uringUnsupported.Store(true)
+		if !r.inFlight {
+			r.close()
+		}
+		return
+	}
+	select {
+	case idleUrings <- r:
+	default:
+		r.close()
+	}
+}
+
+// statEntries stats the entries relative to dirfd with batches of statx requests
+func (r *uring) statEntries(dirfd int, entries []*dirEntry) error {
+	for len(entries) > 0 {
+		batch := entries[:min(len(entries), len(r.stx))]
+		entries = entries[len(batch):]
+		if err := r.statBatch(dirfd, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *uring) statBatch(dirfd int, entries []*dirEntry) error {
+	size := 0
+	for _, e := range entries {
+		size += len(e.name) + 1
+	}
+	// the names are kept by the ring as the kernel may read them until the requests are completed
+	r.names = make([]byte, 0, size)
+
+	tail := atomic.LoadUint32(r.sqTail)
+	for i, e := range entries {
+		start := len(r.names)
+		r.names = append(r.names, e.name...)
+		r.names = append(r.names, 0)
+
+		idx := (tail + uint32(i)) & r.sqMask // nolint: gosec // Why: batch is smaller than the ring
+		r.sqes[idx] = uringSqe{
+			opcode:   uringOpStatx,
+			fd:       int32(dirfd), // nolint: gosec // Why: fds fit into int32
+			addr:     uint64(uintptr(unsafe.Pointer(&r.names[start]))),
+			off:      uint64(uintptr(unsafe.Pointer(&r.stx[i]))),
+			len:      statxMask,
+			opFlags:  statxFlags,
+			userData: uint64(i), // nolint: gosec // Why: index is not negative
+		}
+		r.sqArray[idx] = idx
+	}
+	atomic.StoreUint32(r.sqTail, tail+uint32(len(entries))) // nolint: gosec // Why: batch is smaller than the ring
+
+	toSubmit, done := len(entries), 0
+	for done < len(entries) {
+		n, _, errno := unix.Syscall6(
+			unix.SYS_IO_URING_ENTER, uintptr(r.fd), uintptr(toSubmit), uintptr(len(entries)-done), uringEnterGetEvents, 0, 0,
+		)
+		if errno == unix.EINTR {
+			continue
+		}
+		if errno != 0 {
+			if toSubmit < len(entries) {
+				// some requests are in flight, the ring cannot be closed
+				r.inFlight = true
+				failedUringsMu.Lock()
+				failedUrings = append(failedUrings, r)
+				failedUringsMu.Unlock()
+			}
+			return errno
+		}
+		toSubmit -= int(n)
+
+		head := atomic.LoadUint32(r.cqHead)
+		for cqTail := atomic.LoadUint32(r.cqTail); head != cqTail; head++ {
+			cqe := &r.cqes[head&r.cqMask]
+			r.res[cqe.userData] = cqe.res
+			done++
+		}
+		atomic.StoreUint32(r.cqHead, head)
+	}
+	runtime.KeepAlive(r)
+
+	for i := range entries {
+		// unknown opcode of kernels older than 5.6
+		if r.res[i] == -int32(unix.EINVAL) {
+			return errUringUnsupported
+		}
+	}
+	for i, e := range entries {
+		var err error
+		if r.res[i] < 0 {
+			err = syscall.Errno(-r.res[i])
+		}
+		e.setStatx(&r.stx[i], err)
+	}
+	return nil
+}
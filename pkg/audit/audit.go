@@ -0,0 +1,156 @@
+// Package audit records destructive actions (deleting, emptying, shredding,
+// compressing, moving items to trash and restoring or purging them) as JSON lines
+// appended to an audit file.
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+const (
+	// ResultOK is recorded when the action succeeded
+	ResultOK = "ok"
+	// ResultDryRun is recorded when the action was skipped in dry-run mode
+	ResultDryRun = "dry-run"
+)
+
+// Record is one audited action
+type Record struct {
+	Time   time.Time `json:"time"`
+	User   string    `json:"user"`
+	Path   string    `json:"path"`
+	Size   int64     `json:"size"`
+	Usage  int64     `json:"usage"`
+	IsDir  bool      `json:"isDir"`
+	Action string    `json:"action"`
+	Result string    `json:"result"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// Logger appends audit records to a writer
+type Logger struct {
+	output io.Writer
+	closer io.Closer
+	user   string
+	dryRun bool
+	now    func() time.Time
+	mut    sync.Mutex
+}
+
+// New creates audit logger writing to the given writer
+func New(output io.Writer, dryRun bool) *Logger {
+	return &Logger{
+		output: output,
+		user:   currentUser(),
+		dryRun: dryRun,
+		now:    time.Now,
+	}
+}
+
+// Open creates audit logger appending to the file at the given path.
+// Without a path the records are written only to the log.
+func Open(path string, dryRun bool) (*Logger, error) {
+	if path == "" {
+		return New(io.Discard, dryRun), nil
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	l := New(file, dryRun)
+	l.closer = file
+	return l, nil
+}
+
+// Close closes the underlying audit file
+func (l *Logger) Close() error {
+	if l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
+
+// IsDryRun returns true if the actions are only recorded, not performed
+func (l *Logger) IsDryRun() bool {
+	return l.dryRun
+}
+
+// Record appends a record about the action performed on the item
+func (l *Logger) Record(action string, item fs.Item, actionErr error) {
+	l.RecordPath(action, item.GetPath(), item.GetSize(), item.GetUsage(), item.IsDir(), actionErr)
+}
+
+// RecordPath appends a record about the action performed on the path
+func (l *Logger) RecordPath(action, path string, size, usage int64, isDir bool, actionErr error) {
+	record := Record{
+		Time:   l.now(),
+		User:   l.user,
+		Path:   path,
+		Size:   size,
+		Usage:  usage,
+		IsDir:  isDir,
+		Action: action,
+		Result: ResultOK,
+	}
+	switch {
+	case l.dryRun:
+		record.Result = ResultDryRun
+	case actionErr != nil:
+		record.Result = "error"
+		record.Error = actionErr.Error()
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		log.Printf("audit: encoding record: %s", err)
+		return
+	}
+	log.Printf("audit: %s", data)
+
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	if _, err := l.output.Write(append(data, '\n')); err != nil {
+		log.Printf("audit: writing record: %s", err)
+	}
+}
+
+// Wrap returns remove function which records every call.
+// In dry-run mode the wrapped function is not called at all.
+func (l *Logger) Wrap(action string, fn func(fs.Item, fs.Item) error) func(fs.Item, fs.Item) error {
+	return func(dir, item fs.Item) error {
+		return l.Do(action, item.GetPath(), item.GetSize(), item.GetUsage(), item.IsDir(), func() error {
+			return fn(dir, item)
+		})
+	}
+}
+
+// Do performs the action on the path by calling fn and records it.
+// In dry-run mode fn is not called at all, nil logger only calls fn.
+func (l *Logger) Do(action, path string, size, usage int64, isDir bool, fn func() error) error {
+	if l == nil {
+		return fn()
+	}
+	if l.dryRun {
+		l.RecordPath(action, path, size, usage, isDir, nil)
+		return nil
+	}
+	err := fn()
+	l.RecordPath(action, path, size, usage, isDir, err)
+	return err
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
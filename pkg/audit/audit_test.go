@@ -0,0 +1,156 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func testItem() fs.Item {
+	dir := &analyze.Dir{
+		File:     &analyze.File{Name: "dir"},
+		BasePath: "/tmp",
+	}
+	return &analyze.File{
+		Name:   "file",
+		Size:   10,
+		Usage:  4096,
+		Parent: dir,
+	}
+}
+
+func readRecords(t *testing.T, data string) []Record {
+	t.Helper()
+	var records []Record
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		var r Record
+		assert.Nil(t, json.Unmarshal([]byte(line), &r))
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestWrap(t *testing.T) {
+	var buff bytes.Buffer
+	l := New(&buff, false)
+	l.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	called := 0
+	fn := l.Wrap("delete", func(dir, item fs.Item) error {
+		called++
+		return nil
+	})
+	failing := l.Wrap("trash", func(dir, item fs.Item) error {
+		return errors.New("permission denied")
+	})
+
+	assert.Nil(t, fn(nil, testItem()))
+	assert.Error(t, failing(nil, testItem()))
+	assert.Equal(t, 1, called)
+	assert.False(t, l.IsDryRun())
+
+	records := readRecords(t, buff.String())
+	assert.Len(t, records, 2)
+	assert.Equal(t, "/tmp/dir/file", records[0].Path)
+	assert.Equal(t, int64(10), records[0].Size)
+	assert.Equal(t, int64(4096), records[0].Usage)
+	assert.Equal(t, "delete", records[0].Action)
+	assert.Equal(t, ResultOK, records[0].Result)
+	assert.Equal(t, 2024, records[0].Time.Year())
+	assert.NotEmpty(t, records[0].User)
+	assert.Equal(t, "trash", records[1].Action)
+	assert.Equal(t, "error", records[1].Result)
+	assert.Equal(t, "permission denied", records[1].Error)
+}
+
+func TestWrapDryRun(t *testing.T) {
+	var buff bytes.Buffer
+	l := New(&buff, true)
+
+	fn := l.Wrap("empty", func(dir, item fs.Item) error {
+		t.Fatal("should not be called")
+		return nil
+	})
+
+	assert.Nil(t, fn(nil, testItem()))
+	assert.True(t, l.IsDryRun())
+
+	records := readRecords(t, buff.String())
+	assert.Equal(t, ResultDryRun, records[0].Result)
+	assert.Equal(t, "empty", records[0].Action)
+}
+
+func TestDo(t *testing.T) {
+	var buff bytes.Buffer
+	l := New(&buff, false)
+
+	err := l.Do("purge", "/trash/file", 5, 8, false, func() error { return errors.New("busy") })
+	assert.EqualError(t, err, "busy")
+
+	records := readRecords(t, buff.String())
+	assert.Len(t, records, 1)
+	assert.Equal(t, "purge", records[0].Action)
+	assert.Equal(t, "/trash/file", records[0].Path)
+	assert.Equal(t, "busy", records[0].Error)
+}
+
+func TestDoDryRun(t *testing.T) {
+	var buff bytes.Buffer
+	l := New(&buff, true)
+
+	err := l.Do("restore", "/home/file", 5, 8, false, func() error {
+		t.Fatal("should not be called")
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, ResultDryRun, readRecords(t, buff.String())[0].Result)
+}
+
+func TestDoWithoutLogger(t *testing.T) {
+	var l *Logger
+	called := false
+	assert.Nil(t, l.Do("delete", "/file", 0, 0, false, func() error {
+		called = true
+		return nil
+	}))
+	assert.True(t, called)
+}
+
+func TestOpenAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	for range 2 {
+		l, err := Open(path, false)
+		assert.Nil(t, err)
+		l.RecordPath("delete", "/a", 1, 2, true, nil)
+		assert.Nil(t, l.Close())
+	}
+
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	records := readRecords(t, string(data))
+	assert.Len(t, records, 2)
+	assert.True(t, records[1].IsDir)
+}
+
+func TestOpenWithoutPath(t *testing.T) {
+	l, err := Open("", true)
+	assert.Nil(t, err)
+	l.RecordPath("delete", "/a", 1, 2, false, nil)
+	assert.Nil(t, l.Close())
+}
+
+func TestOpenError(t *testing.T) {
+	_, err := Open(filepath.Join(t.TempDir(), "nonexistent", "audit.log"), false)
+	assert.Error(t, err)
+}
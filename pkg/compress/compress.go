@@ -0,0 +1,239 @@
+// Package compress replaces files and directories with their compressed versions
+package compress
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	gfs "github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/remove"
+)
+
+// Format is the compression format
+type Format string
+
+const (
+	// FormatZstd compresses with Zstandard
+	FormatZstd Format = "zstd"
+	// FormatGzip compresses with gzip
+	FormatGzip Format = "gzip"
+)
+
+// ParseFormat returns the compression format of the given name
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case FormatZstd, FormatGzip:
+		return Format(name), nil
+	case "zst":
+		return FormatZstd, nil
+	case "gz":
+		return FormatGzip, nil
+	}
+	return "", fmt.Errorf("unknown compression format %q, use zstd or gzip", name)
+}
+
+// Extension returns the file name extension of the format
+func (f Format) Extension() string {
+	if f == FormatGzip {
+		return ".gz"
+	}
+	return ".zst"
+}
+
+// TargetPath returns path of the compressed file created from the given path,
+// directories are compressed into a tarball
+func TargetPath(path string, isDir bool, format Format) string {
+	if isDir {
+		return path + ".tar" + format.Extension()
+	}
+	return path + format.Extension()
+}
+
+// Path compresses the file or directory and removes the original one on success.
+// The path of the compressed file is returned.
+func Path(path string, format Format) (string, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() && !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file or directory", path)
+	}
+
+	target := TargetPath(path, info.IsDir(), format)
+	if _, err := os.Lstat(target); err == nil {
+		return "", fmt.Errorf("%s already exists", target)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(target)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	tmpPath := tmp.Name()
+
+	mode := info.Mode().Perm()
+	if info.IsDir() {
+		// the tarball keeps the permissions of the directory readable but not executable
+		mode &^= 0o111
+	}
+	if err := writeFile(tmp, path, info, format, mode); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	if err := os.Chtimes(tmpPath, info.ModTime(), info.ModTime()); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	// existing target is kept even when it was created in the meantime
+	if err := remove.RenameNoReplace(tmpPath, target); err != nil {
+		os.Remove(tmpPath)
+		if os.IsExist(err) {
+			return "", fmt.Errorf("%s already exists", target)
+		}
+		return "", err
+	}
+	// the compressed file must be stored before the original one is removed
+	if err := syncDir(filepath.Dir(target)); err != nil {
+		return target, err
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		return target, err
+	}
+	return target, nil
+}
+
+// writeFile writes the compressed path to the file, sets its permissions,
+// flushes it to the disk and closes it
+func writeFile(file *os.File, path string, info os.FileInfo, format Format, mode os.FileMode) error {
+	if err := writeCompressed(file, path, info, format); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Chmod(mode); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ItemInDir compresses the item and replaces it in dir with the compressed file
+func ItemInDir(dir, item gfs.Item, format Format) (gfs.Item, error) {
+	target, err := Path(item.GetPath(), format)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Lstat(target)
+	if err != nil {
+		return nil, err
+	}
+	file := analyze.CreateFileItem(filepath.Base(target), info)
+	file.Parent = dir
+
+	if d, ok := dir.(*analyze.Dir); ok {
+		d.ReplaceFile(item, file)
+	} else {
+		dir.RemoveFile(item)
+		dir.AddFile(file)
+	}
+	return file, nil
+}
+
+func writeCompressed(output io.Writer, path string, info os.FileInfo, format Format) error {
+	var (
+		w   io.WriteCloser
+		err error
+	)
+	switch format {
+	case FormatGzip:
+		w = gzip.NewWriter(output)
+	default:
+		w, err = zstd.NewWriter(output)
+		if err != nil {
+			return err
+		}
+	}
+
+	if info.IsDir() {
+		err = writeTar(w, path)
+	} else {
+		err = copyFile(w, path)
+	}
+	if err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func copyFile(w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// writeTar writes the directory as a tar archive, the entries are prefixed with name of the directory
+func writeTar(w io.Writer, root string) error {
+	tw := tar.NewWriter(w)
+	base := filepath.Dir(root)
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(base, path)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			return copyFile(tw, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
@@ -0,0 +1,191 @@
+package compress
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+var content = strings.Repeat("compressible log line\n", 1000)
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"zstd": FormatZstd, "zst": FormatZstd, "gzip": FormatGzip, "gz": FormatGzip} {
+		format, err := ParseFormat(name)
+		assert.NoError(t, err)
+		assert.Equal(t, want, format)
+	}
+
+	_, err := ParseFormat("rar")
+	assert.EqualError(t, err, `unknown compression format "rar", use zstd or gzip`)
+}
+
+func TestTargetPath(t *testing.T) {
+	assert.Equal(t, "/a/log.zst", TargetPath("/a/log", false, FormatZstd))
+	assert.Equal(t, "/a/log.gz", TargetPath("/a/log", false, FormatGzip))
+	assert.Equal(t, "/a/dir.tar.zst", TargetPath("/a/dir", true, FormatZstd))
+	assert.Equal(t, "/a/dir.tar.gz", TargetPath("/a/dir", true, FormatGzip))
+}
+
+func TestCompressFileZstd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o640))
+
+	target, err := Path(path, FormatZstd)
+	require.NoError(t, err)
+
+	assert.Equal(t, path+".zst", target)
+	assert.NoFileExists(t, path)
+
+	file, err := os.Open(target)
+	require.NoError(t, err)
+	defer file.Close()
+	r, err := zstd.NewReader(file)
+	require.NoError(t, err)
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+
+	info, err := os.Stat(target)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+	assert.Less(t, info.Size(), int64(len(content)))
+}
+
+func TestCompressFileGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.sql")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	target, err := Path(path, FormatGzip)
+	require.NoError(t, err)
+
+	file, err := os.Open(target)
+	require.NoError(t, err)
+	defer file.Close()
+	r, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+}
+
+func TestCompressDir(t *testing.T) {
+	base := t.TempDir()
+	dir := filepath.Join(base, "logs")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "old"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.log"), []byte(content), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "old", "b.log"), []byte("b"), 0o600))
+	require.NoError(t, os.Symlink("a.log", filepath.Join(dir, "link")))
+
+	target, err := Path(dir, FormatGzip)
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(base, "logs.tar.gz"), target)
+	assert.NoDirExists(t, dir)
+
+	file, err := os.Open(target)
+	require.NoError(t, err)
+	defer file.Close()
+	gr, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, header.Name)
+		if header.Name == "logs/a.log" {
+			data, err := io.ReadAll(tr)
+			require.NoError(t, err)
+			assert.Equal(t, content, string(data))
+		}
+		if header.Name == "logs/link" {
+			assert.Equal(t, "a.log", header.Linkname)
+		}
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"logs/", "logs/a.log", "logs/link", "logs/old/", "logs/old/b.log"}, names)
+}
+
+func TestCompressTargetExists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	require.NoError(t, os.WriteFile(path+".zst", nil, 0o600))
+
+	_, err := Path(path, FormatZstd)
+	assert.ErrorContains(t, err, "already exists")
+	assert.FileExists(t, path)
+}
+
+func TestCompressNonExisting(t *testing.T) {
+	_, err := Path(filepath.Join(t.TempDir(), "missing"), FormatZstd)
+	assert.Error(t, err)
+}
+
+func TestCompressSymlink(t *testing.T) {
+	base := t.TempDir()
+	require.NoError(t, os.Symlink("target", filepath.Join(base, "link")))
+
+	_, err := Path(filepath.Join(base, "link"), FormatZstd)
+	assert.ErrorContains(t, err, "is not a regular file or directory")
+}
+
+func TestItemInDir(t *testing.T) {
+	base := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(base, "app.log"), []byte(content), 0o600))
+
+	dir := &analyze.Dir{
+		File: &analyze.File{
+			Name:  filepath.Base(base),
+			Size:  int64(len(content)),
+			Usage: 24576,
+		},
+		BasePath:  filepath.Dir(base),
+		ItemCount: 2,
+	}
+	file := &analyze.File{
+		Name:   "app.log",
+		Size:   int64(len(content)),
+		Usage:  24576,
+		Parent: dir,
+	}
+	dir.Files = fs.Files{file}
+
+	compressed, err := ItemInDir(dir, file, FormatZstd)
+	require.NoError(t, err)
+
+	assert.Equal(t, "app.log.zst", compressed.GetName())
+	assert.Equal(t, fs.Files{compressed}, dir.Files)
+	assert.Equal(t, compressed.GetSize(), dir.Size)
+	assert.Less(t, dir.Size, int64(len(content)))
+	assert.Equal(t, int64(2), dir.ItemCount)
+}
+
+func TestItemInDirWithErr(t *testing.T) {
+	dir := &analyze.Dir{
+		File:     &analyze.File{Name: "missing"},
+		BasePath: t.TempDir(),
+	}
+	file := &analyze.File{Name: "app.log", Parent: dir}
+	dir.Files = fs.Files{file}
+
+	_, err := ItemInDir(dir, file, FormatZstd)
+	assert.Error(t, err)
+	assert.Equal(t, fs.Files{file}, dir.Files)
+}
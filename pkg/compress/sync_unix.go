@@ -0,0 +1,18 @@
+//go:build !windows
+
+package compress
+
+import "os"
+
+// syncDir flushes entries of the directory to the disk
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	if err := dir.Sync(); err != nil {
+		dir.Close()
+		return err
+	}
+	return dir.Close()
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package compress
+
+// syncDir does nothing as directories cannot be flushed on Windows
+func syncDir(_ string) error {
+	return nil
+}
@@ -9,6 +9,79 @@ type Device struct {
 	Fstype     string
 	Size       int64
 	Free       int64
+	Inodes     int64
+	FreeInodes int64
+	Bind       bool // mount of a subtree of another filesystem (detected on Linux only)
+}
+
+// Class is a kind of mounted filesystem
+type Class string
+
+const (
+	// ClassLocal is a filesystem on a local block device
+	ClassLocal Class = "local"
+	// ClassBind is a bind mount of a subtree of another filesystem
+	ClassBind Class = "bind"
+	// ClassNetwork is a filesystem accessed over network (nfs, cifs, sshfs, ...)
+	ClassNetwork Class = "network"
+	// ClassVirtual is a filesystem not backed by a local block device (tmpfs, overlay, squashfs, ...)
+	ClassVirtual Class = "virtual"
+	// ClassOther is a pseudo filesystem like proc or sysfs
+	ClassOther Class = "other"
+)
+
+// Classes lists the classes in the order in which the devices are grouped
+var Classes = []Class{ClassLocal, ClassBind, ClassNetwork, ClassVirtual, ClassOther}
+
+var networkFstypes = map[string]struct{}{
+	"nfs":            {},
+	"nfs4":           {},
+	"cifs":           {},
+	"smb3":           {},
+	"smbfs":          {},
+	"sshfs":          {},
+	"fuse.sshfs":     {},
+	"afs":            {},
+	"ceph":           {},
+	"glusterfs":      {},
+	"fuse.glusterfs": {},
+	"davfs":          {},
+	"fuse.rclone":    {},
+}
+
+var virtualFstypes = map[string]struct{}{
+	"tmpfs":    {},
+	"ramfs":    {},
+	"overlay":  {},
+	"squashfs": {},
+}
+
+// IsNetworkFstype returns true if the filesystem type is accessed over network
+func IsNetworkFstype(fstype string) bool {
+	_, ok := networkFstypes[fstype]
+	return ok
+}
+
+// Classify returns class of filesystem mounted from the device name with the filesystem type
+func Classify(name, fstype string) Class {
+	if IsNetworkFstype(fstype) {
+		return ClassNetwork
+	}
+	if _, ok := virtualFstypes[fstype]; ok {
+		return ClassVirtual
+	}
+	if strings.HasPrefix(name, "/dev") || fstype == "zfs" {
+		return ClassLocal
+	}
+	return ClassOther
+}
+
+// GetClass returns class of the device
+func (d Device) GetClass() Class {
+	if d.Bind {
+		return ClassBind
+	}
+	return Classify(d.Name, d.Fstype)
 }
 
 // GetUsage returns used size of device
@@ -16,6 +89,11 @@ func (d Device) GetUsage() int64 {
 	return d.Size - d.Free
 }
 
+// GetUsedInodes returns number of used inodes of device
+func (d Device) GetUsedInodes() int64 {
+	return d.Inodes - d.FreeInodes
+}
+
 // DevicesInfoGetter is type for GetDevicesInfo function
 type DevicesInfoGetter interface {
 	GetMounts() (Devices, error)
@@ -25,6 +103,43 @@ type DevicesInfoGetter interface {
 // Devices if slice of Device items
 type Devices []*Device
 
+// LocalDevicesInfoGetter returns only devices of local filesystems listed by the wrapped getter.
+// Mounts are returned unfiltered.
+type LocalDevicesInfoGetter struct {
+	DevicesInfoGetter
+}
+
+// GetDevicesInfo returns local devices with usage info
+func (t LocalDevicesInfoGetter) GetDevicesInfo() (Devices, error) {
+	devices, err := t.DevicesInfoGetter.GetDevicesInfo()
+	if err != nil {
+		return nil, err
+	}
+	return FilterByClass(devices, ClassLocal), nil
+}
+
+// FilterByClass returns devices of the given class
+func FilterByClass(devices Devices, class Class) Devices {
+	filtered := make(Devices, 0, len(devices))
+	for _, dev := range devices {
+		if dev.GetClass() == class {
+			filtered = append(filtered, dev)
+		}
+	}
+	return filtered
+}
+
+// GetNetworkMountPoints returns mount points of network filesystems nested in the path
+func GetNetworkMountPoints(path string, mounts Devices) []string {
+	paths := make([]string, 0)
+	for _, mount := range mounts {
+		if IsNetworkFstype(mount.Fstype) && mount.MountPoint != path && isInMountPoint(mount.MountPoint, path) {
+			paths = append(paths, mount.MountPoint)
+		}
+	}
+	return paths
+}
+
 // ByUsedSize sorts devices by used size
 type ByUsedSize Devices
 
@@ -34,6 +149,15 @@ func (f ByUsedSize) Less(i, j int) bool {
 	return f[i].GetUsage() < f[j].GetUsage()
 }
 
+// ByUsedInodes sorts devices by number of used inodes
+type ByUsedInodes Devices
+
+func (f ByUsedInodes) Len() int      { return len(f) }
+func (f ByUsedInodes) Swap(i, j int) { f[i], f[j] = f[j], f[i] }
+func (f ByUsedInodes) Less(i, j int) bool {
+	return f[i].GetUsedInodes() < f[j].GetUsedInodes()
+}
+
 // ByName sorts devices by device name
 type ByName Devices
 
@@ -54,3 +178,33 @@ func GetNestedMountpointsPaths(path string, mounts Devices) []string {
 	}
 	return paths
 }
+
+// GetMountPoints returns mount points of the devices
+func GetMountPoints(mounts Devices) []string {
+	paths := make([]string, 0, len(mounts))
+	for _, mount := range mounts {
+		paths = append(paths, mount.MountPoint)
+	}
+	return paths
+}
+
+// GetDeviceForPath returns the device with the longest mount point containing the path
+func GetDeviceForPath(path string, mounts Devices) *Device {
+	var found *Device
+	for _, mount := range mounts {
+		if !isInMountPoint(path, mount.MountPoint) {
+			continue
+		}
+		if found == nil || len(mount.MountPoint) > len(found.MountPoint) {
+			found = mount
+		}
+	}
+	return found
+}
+
+func isInMountPoint(path, mountPoint string) bool {
+	if path == mountPoint || mountPoint == "/" {
+		return true
+	}
+	return strings.HasPrefix(path, strings.TrimSuffix(mountPoint, "/")+"/")
+}
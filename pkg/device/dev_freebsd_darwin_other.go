@@ -89,6 +89,8 @@ func processMounts(mounts Devices, ignoreErrors bool) (devices Devices, err erro
 
 		mount.Size = int64(info.Bsize) * int64(info.Blocks)
 		mount.Free = int64(info.Bsize) * int64(info.Bavail)
+		mount.Inodes = int64(info.Files)
+		mount.FreeInodes = int64(info.Ffree)
 
 		devices = append(devices, mount)
 	}
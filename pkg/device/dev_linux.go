@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 
 	"golang.org/x/sys/unix"
@@ -13,10 +14,15 @@ import (
 // LinuxDevicesInfoGetter returns info for Linux devices
 type LinuxDevicesInfoGetter struct {
 	MountsPath string
+	// MountInfoPath is used for detection of bind mounts, detection is skipped when empty or not readable
+	MountInfoPath string
 }
 
 // Getter is current instance of DevicesInfoGetter
-var Getter DevicesInfoGetter = LinuxDevicesInfoGetter{MountsPath: "/proc/mounts"}
+var Getter DevicesInfoGetter = LinuxDevicesInfoGetter{
+	MountsPath:    "/proc/mounts",
+	MountInfoPath: "/proc/self/mountinfo",
+}
 
 // GetMounts returns all mounted filesystems from /proc/mounts
 func (t LinuxDevicesInfoGetter) GetMounts() (devices Devices, err error) {
@@ -35,9 +41,67 @@ func (t LinuxDevicesInfoGetter) GetMounts() (devices Devices, err error) {
 	if err := file.Close(); err != nil {
 		return nil, err
 	}
+
+	if t.MountInfoPath != "" {
+		markBindMounts(devices, t.MountInfoPath)
+	}
 	return devices, nil
 }
 
+func markBindMounts(devices Devices, mountInfoPath string) {
+	file, err := os.Open(mountInfoPath)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	binds, err := readBindMounts(file)
+	if err != nil {
+		return
+	}
+	for _, dev := range devices {
+		dev.Bind = binds[dev.MountPoint]
+	}
+}
+
+// readBindMounts returns mount points from mountinfo file which mount a subtree of a filesystem
+func readBindMounts(file io.Reader) (map[string]bool, error) {
+	binds := make(map[string]bool)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+		fields := strings.Fields(scanner.Text())
+		sep := slices.Index(fields, "-")
+		if sep < 5 || len(fields) < sep+4 {
+			continue
+		}
+
+		root := unescapeString(fields[3])
+		mountPoint := unescapeString(fields[4])
+		fstype := fields[sep+1]
+		binds[mountPoint] = root != "/" && !isBtrfsSubvolume(fstype, root, fields[sep+3])
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return binds, nil
+}
+
+// isBtrfsSubvolume returns true if the root of the mount is the mounted btrfs subvolume
+func isBtrfsSubvolume(fstype, root, superOptions string) bool {
+	if fstype != "btrfs" {
+		return false
+	}
+	for opt := range strings.SplitSeq(superOptions, ",") {
+		if subvol, ok := strings.CutPrefix(opt, "subvol="); ok {
+			return subvol == root
+		}
+	}
+	return false
+}
+
 // GetDevicesInfo returns result of GetMounts with usage info about mounted devices (by calling Statfs syscall)
 func (t LinuxDevicesInfoGetter) GetDevicesInfo() (devices Devices, err error) {
 	mounts, err := t.GetMounts()
@@ -84,10 +148,7 @@ func processMounts(mounts Devices, ignoreErrors bool) (devices Devices, err erro
 			continue
 		}
 
-		if strings.HasPrefix(mount.Name, "/dev") ||
-			mount.Fstype == "zfs" ||
-			mount.Fstype == "nfs" ||
-			mount.Fstype == "nfs4" {
+		if Classify(mount.Name, mount.Fstype) != ClassOther {
 			info := &unix.Statfs_t{}
 			err = unix.Statfs(mount.MountPoint, info)
 			if err != nil && !ignoreErrors {
@@ -96,6 +157,8 @@ func processMounts(mounts Devices, ignoreErrors bool) (devices Devices, err erro
 
 			mount.Size = int64(info.Bsize) * int64(info.Blocks)
 			mount.Free = int64(info.Bsize) * int64(info.Bavail)
+			mount.Inodes = int64(info.Files)
+			mount.FreeInodes = int64(info.Ffree)
 
 			devices = append(devices, mount)
 		}
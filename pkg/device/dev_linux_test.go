@@ -3,6 +3,8 @@
 package device
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -82,3 +84,52 @@ host2:/dir2/ /mnt/dir2 nfs rw,relatime,vers=3,rsize=524288,wsize=524288,namlen=2
 	assert.Equal(t, "/mnt/dir with spaces", devices[0].MountPoint)
 	assert.Nil(t, err)
 }
+
+func TestNetworkAndVirtualMountsShown(t *testing.T) {
+	mounts, _ := readMountsFile(strings.NewReader(`//host/share /mnt/share cifs rw,relatime 0 0
+user@host: /mnt/ssh fuse.sshfs rw,nosuid,nodev 0 0
+tmpfs /tmp tmpfs rw,nosuid,nodev 0 0
+proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0
+sysfs /sys sysfs rw,nosuid,nodev,noexec,relatime 0 0`))
+
+	devices, err := processMounts(mounts, true)
+	assert.Nil(t, err)
+	assert.Len(t, devices, 3)
+	assert.Equal(t, ClassNetwork, devices[0].GetClass())
+	assert.Equal(t, ClassNetwork, devices[1].GetClass())
+	assert.Equal(t, ClassVirtual, devices[2].GetClass())
+}
+
+func TestReadBindMounts(t *testing.T) {
+	// nolint: lll // Why: Test data
+	binds, err := readBindMounts(strings.NewReader(`29 1 8:2 / / rw,relatime shared:1 - ext4 /dev/sda2 rw
+30 29 8:2 /srv/data /mnt/data rw,relatime shared:1 - ext4 /dev/sda2 rw
+31 29 0:40 /@home /home rw,relatime shared:2 - btrfs /dev/sda3 rw,space_cache=v2,subvolid=257,subvol=/@home
+32 29 0:40 /@home/user/share /mnt/with\040space rw,relatime shared:2 - btrfs /dev/sda3 rw,space_cache=v2,subvolid=257,subvol=/@home
+malformed line`))
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]bool{
+		"/":               false,
+		"/mnt/data":       true,
+		"/home":           false,
+		"/mnt/with space": true,
+	}, binds)
+}
+
+func TestGetMountsMarksBindMounts(t *testing.T) {
+	dir := t.TempDir()
+	mountsPath := filepath.Join(dir, "mounts")
+	mountInfoPath := filepath.Join(dir, "mountinfo")
+	assert.Nil(t, os.WriteFile(mountsPath, []byte("/dev/sda2 / ext4 rw 0 0\n/dev/sda2 /mnt/data ext4 rw 0 0\n"), 0o600))
+	assert.Nil(t, os.WriteFile(mountInfoPath, []byte(
+		"29 1 8:2 / / rw - ext4 /dev/sda2 rw\n30 29 8:2 /srv/data /mnt/data rw - ext4 /dev/sda2 rw\n",
+	), 0o600))
+
+	getter := LinuxDevicesInfoGetter{MountsPath: mountsPath, MountInfoPath: mountInfoPath}
+	mounts, err := getter.GetMounts()
+
+	assert.Nil(t, err)
+	assert.Equal(t, ClassLocal, mounts[0].GetClass())
+	assert.Equal(t, ClassBind, mounts[1].GetClass())
+}
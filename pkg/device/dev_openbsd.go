@@ -20,6 +20,8 @@ func processMounts(mounts Devices, ignoreErrors bool) (devices Devices, err erro
 
 			mount.Size = int64(info.F_bsize) * int64(info.F_blocks)
 			mount.Free = int64(info.F_bsize) * int64(info.F_bavail)
+			mount.Inodes = int64(info.F_files)
+			mount.FreeInodes = int64(info.F_ffree)
 
 			devices = append(devices, mount)
 		}
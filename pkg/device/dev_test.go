@@ -26,6 +26,12 @@ func TestNested(t *testing.T) {
 	assert.Equal(t, "/xxx/yyy", mountsNested[0])
 }
 
+func TestGetMountPoints(t *testing.T) {
+	mounts := Devices{&Device{MountPoint: "/"}, &Device{MountPoint: "/home"}}
+
+	assert.Equal(t, []string{"/", "/home"}, GetMountPoints(mounts))
+}
+
 func TestSortByName(t *testing.T) {
 	item := &Device{
 		Name: "/xxx",
@@ -71,3 +77,77 @@ func TestSortByUsedSize(t *testing.T) {
 	assert.Equal(t, "yyy", devices[1].Name)
 	assert.Equal(t, "xxx", devices[2].Name)
 }
+
+func TestSortByUsedInodes(t *testing.T) {
+	full := &Device{Name: "full", Inodes: 100, FreeInodes: 1}
+	empty := &Device{Name: "empty", Inodes: 1000, FreeInodes: 990}
+
+	devices := Devices{empty, full}
+	sort.Sort(sort.Reverse(ByUsedInodes(devices)))
+
+	assert.Equal(t, "full", devices[0].Name)
+	assert.Equal(t, int64(99), devices[0].GetUsedInodes())
+}
+
+func TestGetDeviceForPath(t *testing.T) {
+	root := &Device{MountPoint: "/"}
+	home := &Device{MountPoint: "/home"}
+	homeData := &Device{MountPoint: "/home/data"}
+	mounts := Devices{root, homeData, home}
+
+	assert.Equal(t, homeData, GetDeviceForPath("/home/data/x", mounts))
+	assert.Equal(t, home, GetDeviceForPath("/home", mounts))
+	assert.Equal(t, root, GetDeviceForPath("/homework", mounts))
+	assert.Nil(t, GetDeviceForPath("/home", Devices{homeData}))
+}
+
+func TestClassify(t *testing.T) {
+	assert.Equal(t, ClassLocal, Classify("/dev/sda1", "ext4"))
+	assert.Equal(t, ClassLocal, Classify("rootpool/home", "zfs"))
+	assert.Equal(t, ClassNetwork, Classify("host:/dir", "nfs4"))
+	assert.Equal(t, ClassNetwork, Classify("//host/share", "cifs"))
+	assert.Equal(t, ClassNetwork, Classify("user@host:", "fuse.sshfs"))
+	assert.Equal(t, ClassVirtual, Classify("tmpfs", "tmpfs"))
+	assert.Equal(t, ClassVirtual, Classify("overlay", "overlay"))
+	assert.Equal(t, ClassVirtual, Classify("/dev/loop1", "squashfs"))
+	assert.Equal(t, ClassOther, Classify("proc", "proc"))
+}
+
+func TestGetClassOfBindMount(t *testing.T) {
+	dev := Device{Name: "/dev/sda1", Fstype: "ext4", Bind: true}
+	assert.Equal(t, ClassBind, dev.GetClass())
+}
+
+type devicesGetterStub struct {
+	devices Devices
+}
+
+func (g devicesGetterStub) GetDevicesInfo() (Devices, error) { return g.devices, nil }
+func (g devicesGetterStub) GetMounts() (Devices, error)      { return g.devices, nil }
+
+func TestLocalDevicesInfoGetter(t *testing.T) {
+	local := &Device{Name: "/dev/sda1", MountPoint: "/", Fstype: "ext4"}
+	bind := &Device{Name: "/dev/sda1", MountPoint: "/srv", Fstype: "ext4", Bind: true}
+	nfs := &Device{Name: "host:/dir", MountPoint: "/mnt", Fstype: "nfs"}
+	getter := LocalDevicesInfoGetter{devicesGetterStub{Devices{local, bind, nfs}}}
+
+	devices, err := getter.GetDevicesInfo()
+	assert.Nil(t, err)
+	assert.Equal(t, Devices{local}, devices)
+
+	mounts, err := getter.GetMounts()
+	assert.Nil(t, err)
+	assert.Len(t, mounts, 3)
+}
+
+func TestGetNetworkMountPoints(t *testing.T) {
+	mounts := Devices{
+		&Device{Name: "/dev/sda1", MountPoint: "/", Fstype: "ext4"},
+		&Device{Name: "host:/dir", MountPoint: "/home/nfs", Fstype: "nfs4"},
+		&Device{Name: "//host/share", MountPoint: "/mnt/share", Fstype: "cifs"},
+	}
+
+	assert.Equal(t, []string{"/home/nfs", "/mnt/share"}, GetNetworkMountPoints("/", mounts))
+	assert.Equal(t, []string{"/home/nfs"}, GetNetworkMountPoints("/home", mounts))
+	assert.Empty(t, GetNetworkMountPoints("/home/nfs", mounts))
+}
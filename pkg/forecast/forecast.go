@@ -0,0 +1,170 @@
+// Package forecast estimates growth of disk usage from the history of scans
+package forecast
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+)
+
+const day = 24 * time.Hour
+
+// Point is usage measured at given time
+type Point struct {
+	Time  time.Time
+	Usage int64
+}
+
+// Trend is a linear growth of usage fitted to the measured points
+type Trend struct {
+	Path    string
+	Usage   int64   // last measured usage
+	Growth  float64 // bytes per day
+	Samples int
+	Last    time.Time
+}
+
+// DeviceTrend is a trend of used space of a device
+type DeviceTrend struct {
+	Trend
+	Device string
+	Size   int64
+	Free   int64 // last measured free space
+}
+
+// Forecast holds trends of devices and directories found in the history of scans
+type Forecast struct {
+	Devices []DeviceTrend
+	Dirs    []Trend
+}
+
+// Fit fits a line to the points using least squares and returns its slope in bytes per day.
+// It returns false when the points do not span more than one moment in time.
+func Fit(points []Point) (float64, bool) {
+	if len(points) < 2 {
+		return 0, false
+	}
+
+	start := points[0].Time
+	var sumX, sumY float64
+	for _, p := range points {
+		sumX += float64(p.Time.Sub(start)) / float64(day)
+		sumY += float64(p.Usage)
+	}
+	n := float64(len(points))
+	meanX, meanY := sumX/n, sumY/n
+
+	var cov, variance float64
+	for _, p := range points {
+		dx := float64(p.Time.Sub(start))/float64(day) - meanX
+		cov += dx * (float64(p.Usage) - meanY)
+		variance += dx * dx
+	}
+	if variance == 0 {
+		return 0, false
+	}
+	return cov / variance, true
+}
+
+// NewTrend fits a trend to the points, the points must be ordered by time
+func NewTrend(path string, points []Point) (Trend, bool) {
+	growth, ok := Fit(points)
+	if !ok {
+		return Trend{}, false
+	}
+	last := points[len(points)-1]
+	return Trend{
+		Path:    path,
+		Usage:   last.Usage,
+		Growth:  growth,
+		Samples: len(points),
+		Last:    last.Time,
+	}, true
+}
+
+// DaysUntil returns number of days after the last measurement until the trend uses up the free space.
+// It returns false when the usage is not growing.
+func (t Trend) DaysUntil(free int64) (float64, bool) {
+	if t.Growth <= 0 {
+		return math.Inf(1), false
+	}
+	return float64(free) / t.Growth, true
+}
+
+// DaysUntilFull returns number of days after the last scan until the device is full
+func (t DeviceTrend) DaysUntilFull() (float64, bool) {
+	return t.DaysUntil(t.Free)
+}
+
+// FullAt returns the estimated time when the device is full
+func (t DeviceTrend) FullAt() (time.Time, bool) {
+	days, ok := t.DaysUntilFull()
+	if !ok || days > float64(math.MaxInt64/day) {
+		return time.Time{}, false
+	}
+	return t.Last.Add(time.Duration(days * float64(day))), true
+}
+
+// Build fits trends of devices and directories to the history of scans.
+// Directories are sorted from the fastest growing, the scanned paths themselves are left out.
+func Build(scans []analyze.HistoryScan, entries []analyze.HistoryEntry) Forecast {
+	var result Forecast
+
+	devicePoints := make(map[string][]Point)
+	lastScans := make(map[string]analyze.HistoryScan)
+	roots := make(map[string]struct{})
+	for _, scan := range scans {
+		roots[scan.Path] = struct{}{}
+		if scan.MountPoint == "" || scan.DeviceSize == 0 {
+			continue
+		}
+		devicePoints[scan.MountPoint] = append(devicePoints[scan.MountPoint], Point{
+			Time:  scan.Time,
+			Usage: scan.DeviceSize - scan.DeviceFree,
+		})
+		lastScans[scan.MountPoint] = scan
+	}
+	for mountPoint, points := range devicePoints {
+		trend, ok := NewTrend(mountPoint, points)
+		if !ok {
+			continue
+		}
+		last := lastScans[mountPoint]
+		result.Devices = append(result.Devices, DeviceTrend{
+			Trend:  trend,
+			Device: last.Device,
+			Size:   last.DeviceSize,
+			Free:   last.DeviceFree,
+		})
+	}
+	slices.SortFunc(result.Devices, func(a, b DeviceTrend) int {
+		return cmp.Compare(a.Path, b.Path)
+	})
+
+	dirPoints := make(map[string][]Point)
+	for _, entry := range entries {
+		if _, ok := roots[entry.Path]; ok {
+			continue
+		}
+		dirPoints[entry.Path] = append(dirPoints[entry.Path], Point{Time: entry.Time, Usage: entry.Usage})
+	}
+	for path, points := range dirPoints {
+		slices.SortFunc(points, func(a, b Point) int {
+			return a.Time.Compare(b.Time)
+		})
+		if trend, ok := NewTrend(path, points); ok {
+			result.Dirs = append(result.Dirs, trend)
+		}
+	}
+	slices.SortFunc(result.Dirs, func(a, b Trend) int {
+		if c := cmp.Compare(b.Growth, a.Growth); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Path, b.Path)
+	})
+
+	return result
+}
@@ -0,0 +1,78 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+)
+
+var start = time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFit(t *testing.T) {
+	growth, ok := Fit([]Point{
+		{Time: start, Usage: 100},
+		{Time: start.Add(day), Usage: 110},
+		{Time: start.Add(2 * day), Usage: 120},
+	})
+	assert.True(t, ok)
+	assert.InDelta(t, 10, growth, 1e-9)
+}
+
+func TestFitWithoutEnoughPoints(t *testing.T) {
+	_, ok := Fit([]Point{{Time: start, Usage: 100}})
+	assert.False(t, ok)
+
+	_, ok = Fit([]Point{{Time: start, Usage: 100}, {Time: start, Usage: 200}})
+	assert.False(t, ok)
+}
+
+func TestDaysUntil(t *testing.T) {
+	days, ok := Trend{Growth: 10}.DaysUntil(100)
+	assert.True(t, ok)
+	assert.Equal(t, 10.0, days)
+
+	days, ok = Trend{Growth: -1}.DaysUntil(100)
+	assert.False(t, ok)
+	assert.True(t, math.IsInf(days, 1))
+}
+
+func TestBuild(t *testing.T) {
+	scans := []analyze.HistoryScan{
+		{Time: start, Path: "/srv", Device: "/dev/sda1", MountPoint: "/", DeviceSize: 1000, DeviceFree: 500},
+		{Time: start.Add(day), Path: "/srv", Device: "/dev/sda1", MountPoint: "/", DeviceSize: 1000, DeviceFree: 400},
+		{Time: start.Add(day), Path: "/mnt"},
+	}
+	entries := []analyze.HistoryEntry{
+		{Time: start, Path: "/srv", Usage: 300},
+		{Time: start, Path: "/srv/logs", Usage: 100},
+		{Time: start, Path: "/srv/db", Usage: 200},
+		{Time: start.Add(day), Path: "/srv", Usage: 400},
+		{Time: start.Add(day), Path: "/srv/logs", Usage: 190},
+		{Time: start.Add(day), Path: "/srv/db", Usage: 210},
+		{Time: start.Add(day), Path: "/srv/new", Usage: 10},
+	}
+
+	result := Build(scans, entries)
+
+	assert.Len(t, result.Devices, 1)
+	dev := result.Devices[0]
+	assert.Equal(t, "/dev/sda1", dev.Device)
+	assert.Equal(t, "/", dev.Path)
+	assert.Equal(t, int64(400), dev.Free)
+	assert.InDelta(t, 100, dev.Growth, 1e-9)
+	days, ok := dev.DaysUntilFull()
+	assert.True(t, ok)
+	assert.InDelta(t, 4, days, 1e-9)
+	at, ok := dev.FullAt()
+	assert.True(t, ok)
+	assert.Equal(t, start.Add(5*day), at)
+
+	assert.Len(t, result.Dirs, 2)
+	assert.Equal(t, "/srv/logs", result.Dirs[0].Path)
+	assert.InDelta(t, 90, result.Dirs[0].Growth, 1e-9)
+	assert.Equal(t, "/srv/db", result.Dirs[1].Path)
+}
@@ -60,6 +60,13 @@ type SymlinkItem interface {
 	GetSymlinkTarget() string
 }
 
+// SharedUsageItem is an optional interface implemented by items which know
+// how much of their disk usage is in extents shared with other files
+// (reflinked copies, deduplicated data, snapshots).
+type SharedUsageItem interface {
+	GetSharedUsage() int64
+}
+
 // Files - slice of pointers to File
 type Files []Item
 
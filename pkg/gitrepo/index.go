@@ -0,0 +1,124 @@
+// Package gitrepo classifies files in git repositories as tracked, ignored or untracked
+package gitrepo
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	indexSignature = "DIRC"
+
+	// size of ctime, mtime, dev, ino, mode, uid, gid and size fields of an index entry
+	entryStatSize = 40
+	modeOffset    = 24
+
+	flagExtended = 0x4000
+	flagNameMask = 0x0fff
+
+	modeTypeMask = 0o170000
+	modeDir      = 0o040000
+)
+
+// ErrInvalidIndex is returned when the index file is not in a supported format
+var ErrInvalidIndex = errors.New("invalid git index")
+
+// ReadIndex reads paths of the entries of the git index (versions 2 to 4).
+// Paths use slash as separator, sparse directory entries are returned without the trailing slash.
+func ReadIndex(r io.Reader, hashSize int) (map[string]struct{}, error) {
+	reader := bufio.NewReader(r)
+
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIndex, err)
+	}
+	if string(header[:4]) != indexSignature {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidIndex)
+	}
+	version := binary.BigEndian.Uint32(header[4:8])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidIndex, version)
+	}
+	count := binary.BigEndian.Uint32(header[8:12])
+
+	paths := make(map[string]struct{}, count)
+	fixed := make([]byte, entryStatSize+hashSize+2)
+	var previous string
+
+	for range count {
+		if _, err := io.ReadFull(reader, fixed); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidIndex, err)
+		}
+		mode := binary.BigEndian.Uint32(fixed[modeOffset : modeOffset+4])
+		flags := binary.BigEndian.Uint16(fixed[len(fixed)-2:])
+		entrySize := len(fixed)
+		if version >= 3 && flags&flagExtended != 0 {
+			if _, err := reader.Discard(2); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidIndex, err)
+			}
+			entrySize += 2
+		}
+
+		var name string
+		if version == 4 {
+			strip, err := readOffset(reader)
+			if err != nil || strip > len(previous) {
+				return nil, fmt.Errorf("%w: bad path prefix", ErrInvalidIndex)
+			}
+			suffix, err := readName(reader)
+			if err != nil {
+				return nil, err
+			}
+			name = previous[:len(previous)-strip] + suffix
+		} else {
+			var err error
+			if name, err = readName(reader); err != nil {
+				return nil, err
+			}
+			if int(flags&flagNameMask) < flagNameMask && len(name) != int(flags&flagNameMask) {
+				return nil, fmt.Errorf("%w: bad path length", ErrInvalidIndex)
+			}
+			// entries are padded with 1-8 NUL bytes to multiple of 8, one was read with the name
+			padding := (entrySize+len(name)+8)&^7 - entrySize - len(name) - 1
+			if _, err := reader.Discard(padding); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidIndex, err)
+			}
+		}
+		previous = name
+
+		if mode&modeTypeMask == modeDir {
+			name = strings.TrimSuffix(name, "/")
+		}
+		paths[name] = struct{}{}
+	}
+	return paths, nil
+}
+
+// readName reads the NUL terminated path
+func readName(reader *bufio.Reader) (string, error) {
+	name, err := reader.ReadString(0)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidIndex, err)
+	}
+	return name[:len(name)-1], nil
+}
+
+// readOffset reads the variable length number used for prefix compression of paths in index version 4
+func readOffset(reader *bufio.Reader) (int, error) {
+	c, err := reader.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	value := int(c & 0x7f)
+	for c&0x80 != 0 {
+		if c, err = reader.ReadByte(); err != nil {
+			return 0, err
+		}
+		value = ((value + 1) << 7) | int(c&0x7f)
+	}
+	return value, nil
+}
@@ -0,0 +1,141 @@
+package gitrepo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const regularFileMode = 0o100644
+
+// encodeIndex creates the git index with given entries in the given version
+func encodeIndex(version uint32, hashSize int, names ...string) []byte {
+	sort.Strings(names)
+	b := &bytes.Buffer{}
+	b.WriteString(indexSignature)
+	_ = binary.Write(b, binary.BigEndian, version)
+	_ = binary.Write(b, binary.BigEndian, uint32(len(names)))
+
+	var previous string
+	for i, name := range names {
+		start := b.Len()
+		stat := make([]byte, entryStatSize)
+		mode := uint32(regularFileMode)
+		if name[len(name)-1] == '/' {
+			mode = modeDir
+		}
+		binary.BigEndian.PutUint32(stat[modeOffset:], mode)
+		b.Write(stat)
+		b.Write(make([]byte, hashSize))
+
+		flags := uint16(min(len(name), flagNameMask))
+		extended := version == 3 && i%2 == 0
+		if extended {
+			flags |= flagExtended
+		}
+		_ = binary.Write(b, binary.BigEndian, flags)
+		if extended {
+			b.Write([]byte{0, 0})
+		}
+
+		if version == 4 {
+			common := 0
+			for common < len(previous) && common < len(name) && previous[common] == name[common] {
+				common++
+			}
+			b.Write(encodeOffset(len(previous) - common))
+			b.WriteString(name[common:])
+			b.WriteByte(0)
+			previous = name
+			continue
+		}
+		b.WriteString(name)
+		size := b.Len() - start
+		b.Write(make([]byte, (size+8)&^7-size))
+	}
+	return b.Bytes()
+}
+
+func encodeOffset(value int) []byte {
+	buf := []byte{byte(value & 0x7f)}
+	for value >>= 7; value > 0; value >>= 7 {
+		value--
+		buf = append([]byte{byte(0x80 | (value & 0x7f))}, buf...)
+	}
+	return buf
+}
+
+func TestReadIndex(t *testing.T) {
+	long := string(bytes.Repeat([]byte("x"), 5000))
+	names := []string{"README.md", "src/main.go", "src/main_test.go", "vendor/", "a/" + long}
+
+	for _, version := range []uint32{2, 3, 4} {
+		for _, hashSize := range []int{sha1Size, sha256Size} {
+			paths, err := ReadIndex(bytes.NewReader(encodeIndex(version, hashSize, names...)), hashSize)
+			require.NoError(t, err, version)
+			assert.Len(t, paths, len(names))
+			assert.Contains(t, paths, "src/main_test.go")
+			assert.Contains(t, paths, "a/"+long)
+			// sparse directory entries are stored without the slash
+			assert.Contains(t, paths, "vendor")
+		}
+	}
+}
+
+func TestReadIndexOffset(t *testing.T) {
+	for _, value := range []int{0, 1, 127, 128, 255, 16511, 16512, 1 << 20} {
+		paths, err := ReadIndex(bytes.NewReader(encodeIndex(4, sha1Size, string(bytes.Repeat([]byte("a"), value+1)), "b")), sha1Size)
+		require.NoError(t, err, value)
+		assert.Len(t, paths, 2)
+	}
+}
+
+func TestReadIndexErrors(t *testing.T) {
+	valid := encodeIndex(2, sha1Size, "file")
+
+	for name, content := range map[string][]byte{
+		"empty":     nil,
+		"signature": append([]byte("XXXX"), valid[4:]...),
+		"version":   append([]byte("DIRC\x00\x00\x00\x05"), valid[8:]...),
+		"truncated": valid[:len(valid)-10],
+		"entry":     valid[:30],
+	} {
+		_, err := ReadIndex(bytes.NewReader(content), sha1Size)
+		assert.ErrorIs(t, err, ErrInvalidIndex, name)
+	}
+}
+
+func TestReadIndexOfGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src", "pkg"), 0o755))
+	for _, name := range []string{"README.md", "src/main.go", "src/pkg/a.go", "src/pkg/b.go"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(name), 0o600))
+	}
+	git("add", ".")
+
+	for _, version := range []string{"2", "3", "4"} {
+		git("update-index", "--index-version", version)
+		repo, err := Open(dir)
+		require.NoError(t, err, version)
+		assert.Len(t, repo.tracked, 4, version)
+		assert.True(t, repo.IsTracked(filepath.Join(dir, "src", "pkg", "b.go")), version)
+	}
+}
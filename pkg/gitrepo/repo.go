@@ -0,0 +1,143 @@
+package gitrepo
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dundee/gdu/v5/pkg/ignore"
+)
+
+// DirName is the name of the directory holding the git repository
+const DirName = ".git"
+
+const (
+	sha1Size   = 20
+	sha256Size = 32
+)
+
+// Repo is a git repository with the paths tracked in its index
+type Repo struct {
+	root    string
+	tracked map[string]struct{}
+	exclude []ignore.Pattern
+}
+
+// Open reads the index and the exclude file of the repository with the working tree in root.
+// Worktrees and submodules with .git file pointing to the git directory are supported.
+func Open(root string) (*Repo, error) {
+	gitDir, err := resolveGitDir(root)
+	if err != nil {
+		return nil, err
+	}
+	commonDir := resolveCommonDir(gitDir)
+
+	repo := &Repo{
+		root:    filepath.Clean(root),
+		tracked: make(map[string]struct{}),
+	}
+
+	file, err := os.Open(filepath.Join(gitDir, "index"))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		// no file has been added yet
+	case err != nil:
+		return nil, err
+	default:
+		defer file.Close()
+		if repo.tracked, err = ReadIndex(file, hashSize(commonDir)); err != nil {
+			return nil, err
+		}
+	}
+
+	repo.exclude, err = ignore.ReadFile(filepath.Join(commonDir, "info", "exclude"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// Root returns the path of the working tree of the repository
+func (r *Repo) Root() string {
+	return r.root
+}
+
+// IsTracked returns true if the path is in the index
+func (r *Repo) IsTracked(path string) bool {
+	_, ok := r.tracked[r.rel(path)]
+	return ok
+}
+
+// rules returns the patterns of the exclude file applied to the whole working tree
+func (r *Repo) rules() *ignore.Rules {
+	return ignore.New(r.root, r.exclude)
+}
+
+// rel returns the path relative to the root of the working tree with slashes as separators
+func (r *Repo) rel(path string) string {
+	rel, err := filepath.Rel(r.root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// resolveGitDir returns the git directory of the working tree, following the gitdir link in .git file
+func resolveGitDir(root string) (string, error) {
+	gitDir := filepath.Join(root, DirName)
+	info, err := os.Stat(gitDir)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return gitDir, nil
+	}
+
+	content, err := os.ReadFile(gitDir)
+	if err != nil {
+		return "", err
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+	if !ok {
+		return "", errors.New("invalid gitdir file " + gitDir)
+	}
+	target = strings.TrimSpace(target)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(root, target)
+	}
+	return target, nil
+}
+
+// resolveCommonDir returns the directory shared by all worktrees of the repository
+func resolveCommonDir(gitDir string) string {
+	content, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+	commonDir := strings.TrimSpace(string(content))
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(gitDir, commonDir)
+	}
+	return commonDir
+}
+
+// hashSize returns the size of object names of the repository, which is larger for repositories using SHA-256
+func hashSize(commonDir string) int {
+	file, err := os.Open(filepath.Join(commonDir, "config"))
+	if err != nil {
+		return sha1Size
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "objectformat") &&
+			strings.EqualFold(strings.TrimSpace(value), "sha256") {
+			return sha256Size
+		}
+	}
+	return sha1Size
+}
@@ -0,0 +1,78 @@
+package gitrepo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createRepo creates the git directory with the index holding the given paths
+func createRepo(t *testing.T, root string, tracked ...string) {
+	t.Helper()
+	gitDir := filepath.Join(root, DirName)
+	require.NoError(t, os.MkdirAll(filepath.Join(gitDir, "info"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "index"), encodeIndex(2, sha1Size, tracked...), 0o600))
+}
+
+func TestOpen(t *testing.T) {
+	root := t.TempDir()
+	createRepo(t, root, "main.go", "doc/index.md")
+	require.NoError(t, os.WriteFile(filepath.Join(root, DirName, "info", "exclude"), []byte("*.swp\n"), 0o600))
+
+	repo, err := Open(root)
+	require.NoError(t, err)
+	assert.Equal(t, root, repo.Root())
+	assert.True(t, repo.IsTracked(filepath.Join(root, "doc", "index.md")))
+	assert.False(t, repo.IsTracked(filepath.Join(root, "doc")))
+	assert.True(t, repo.rules().Match(filepath.Join(root, "doc", ".main.go.swp"), false))
+}
+
+func TestOpenWithoutIndex(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, DirName), 0o755))
+
+	repo, err := Open(root)
+	require.NoError(t, err)
+	assert.False(t, repo.IsTracked(filepath.Join(root, "main.go")))
+	assert.Nil(t, repo.rules())
+}
+
+func TestOpenWorktree(t *testing.T) {
+	dir := t.TempDir()
+	common := filepath.Join(dir, "repo.git")
+	gitDir := filepath.Join(common, "worktrees", "wt")
+	root := filepath.Join(dir, "wt")
+	require.NoError(t, os.MkdirAll(gitDir, 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(common, "info"), 0o755))
+	require.NoError(t, os.MkdirAll(root, 0o755))
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, DirName), []byte("gitdir: ../repo.git/worktrees/wt\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "commondir"), []byte("../..\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(common, "config"), []byte("[extensions]\n\tobjectformat = sha256\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(common, "info", "exclude"), []byte("build/\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "index"), encodeIndex(2, sha256Size, "main.go"), 0o600))
+
+	repo, err := Open(root)
+	require.NoError(t, err)
+	assert.True(t, repo.IsTracked(filepath.Join(root, "main.go")))
+	assert.True(t, repo.rules().Match(filepath.Join(root, "build"), true))
+}
+
+func TestOpenErrors(t *testing.T) {
+	_, err := Open(t.TempDir())
+	assert.Error(t, err)
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, DirName), []byte("nonsense"), 0o600))
+	_, err = Open(root)
+	assert.Error(t, err)
+
+	root = t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, DirName), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, DirName, "index"), []byte("DIRC"), 0o600))
+	_, err = Open(root)
+	assert.ErrorIs(t, err, ErrInvalidIndex)
+}
@@ -0,0 +1,231 @@
+package gitrepo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/ignore"
+)
+
+// Status is the state of the file in git repository
+type Status int
+
+const (
+	// Tracked files are in the index
+	Tracked Status = iota
+	// Ignored files are not in the index and match the patterns of .gitignore or info/exclude
+	Ignored
+	// Untracked files are neither in the index nor ignored
+	Untracked
+
+	statusCount
+)
+
+// String returns the name of the status
+func (s Status) String() string {
+	switch s {
+	case Tracked:
+		return "tracked"
+	case Ignored:
+		return "ignored"
+	default:
+		return "untracked"
+	}
+}
+
+// Stats are disk usage and apparent size of files of each status
+type Stats struct {
+	Usage [statusCount]int64
+	Size  [statusCount]int64
+}
+
+func (s *Stats) add(other Stats) {
+	for i := range statusCount {
+		s.Usage[i] += other.Usage[i]
+		s.Size[i] += other.Size[i]
+	}
+}
+
+func (s *Stats) subtract(other Stats) {
+	for i := range statusCount {
+		s.Usage[i] -= other.Usage[i]
+		s.Size[i] -= other.Size[i]
+	}
+}
+
+// IsZero returns true if there are no files of any status
+func (s Stats) IsZero() bool {
+	return s == Stats{}
+}
+
+// Usage holds stats of items in the git repositories found in the analyzed tree.
+// Directories containing a repository have the stats summed as well.
+type Usage struct {
+	mu    sync.RWMutex
+	items map[string]Stats
+	repos map[string]struct{}
+}
+
+// Classify finds git repositories in the analyzed tree and sums usage of their tracked, ignored and untracked files.
+// Patterns of .gitignore files are read from the disk, the .git directories themselves are not counted.
+func Classify(dir fs.Item) *Usage {
+	u := &Usage{
+		items: make(map[string]Stats),
+		repos: make(map[string]struct{}),
+	}
+	absPath, err := filepath.Abs(dir.GetPath())
+	if err != nil {
+		log.Print(err.Error())
+		return u
+	}
+	repo, rules, ignored := enclosingRepo(absPath)
+	if stats := u.walk(dir, absPath, repo, rules, ignored); repo != nil || !stats.IsZero() {
+		u.items[dir.GetPath()] = stats
+	}
+	return u
+}
+
+// Get returns the stats of the item, false if the item is not in any repository
+func (u *Usage) Get(path string) (Stats, bool) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	stats, ok := u.items[path]
+	return stats, ok
+}
+
+// IsRepo returns true if the directory is the root of working tree of a repository
+func (u *Usage) IsRepo(path string) bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	_, ok := u.repos[path]
+	return ok
+}
+
+// Remove subtracts the stats of the removed item from its parent directories
+func (u *Usage) Remove(path string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	stats, ok := u.items[path]
+	if !ok {
+		return
+	}
+	delete(u.items, path)
+	delete(u.repos, path)
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if parent, ok := u.items[dir]; ok {
+			parent.subtract(stats)
+			u.items[dir] = parent
+		}
+		if dir == filepath.Dir(dir) {
+			return
+		}
+	}
+}
+
+// walk sums the stats of the items in the directory.
+// Repositories and ignore rules work with absolute paths, the stats are stored by paths of the items.
+func (u *Usage) walk(dir fs.Item, path string, repo *Repo, rules *ignore.Rules, ignored bool) Stats {
+	files := slices.Collect(dir.GetFiles(fs.SortByName, fs.SortAsc))
+
+	if hasFile(files, DirName) {
+		if found, err := Open(path); err != nil {
+			log.Printf("reading git repository %s: %s", path, err)
+		} else {
+			repo, rules, ignored = found, found.rules(), false
+			u.repos[dir.GetPath()] = struct{}{}
+		}
+	}
+	if repo != nil && hasFile(files, ".gitignore") {
+		rules = addGitignore(rules, path)
+	}
+
+	var total Stats
+	for _, item := range files {
+		if repo != nil && item.GetName() == DirName {
+			continue
+		}
+		itemPath := filepath.Join(path, item.GetName())
+
+		var stats Stats
+		switch {
+		case repo != nil && repo.IsTracked(itemPath):
+			// browsed archives and submodules are tracked as a whole
+			stats.Usage[Tracked], stats.Size[Tracked] = item.GetUsage(), item.GetSize()
+		case item.IsDir():
+			stats = u.walk(item, itemPath, repo, rules, ignored || rules.Match(itemPath, true))
+			if stats.IsZero() && repo == nil {
+				continue
+			}
+		case repo == nil:
+			continue
+		default:
+			status := Untracked
+			if ignored || rules.Match(itemPath, false) {
+				status = Ignored
+			}
+			stats.Usage[status], stats.Size[status] = item.GetUsage(), item.GetSize()
+		}
+
+		u.items[item.GetPath()] = stats
+		total.add(stats)
+	}
+	return total
+}
+
+// enclosingRepo finds the repository containing the analyzed directory
+// and the rules of .gitignore files of the directories above it
+func enclosingRepo(absPath string) (*Repo, *ignore.Rules, bool) {
+	var dirs []string
+	for dir := filepath.Dir(absPath); ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+		if _, err := os.Lstat(filepath.Join(dir, DirName)); err == nil {
+			break
+		}
+		if dir == filepath.Dir(dir) {
+			return nil, nil, false
+		}
+	}
+
+	repo, err := Open(dirs[len(dirs)-1])
+	if err != nil {
+		log.Printf("reading git repository %s: %s", dirs[len(dirs)-1], err)
+		return nil, nil, false
+	}
+
+	rules, ignored := repo.rules(), false
+	current := repo.Root()
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if i < len(dirs)-1 {
+			current = filepath.Join(current, filepath.Base(dirs[i]))
+			ignored = ignored || rules.Match(current, true)
+		}
+		rules = addGitignore(rules, current)
+	}
+	current = filepath.Join(current, filepath.Base(absPath))
+	return repo, rules, ignored || rules.Match(current, true)
+}
+
+// addGitignore adds the patterns of the .gitignore file of the directory if it exists
+func addGitignore(rules *ignore.Rules, dir string) *ignore.Rules {
+	patterns, err := ignore.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Print(err.Error())
+	}
+	return rules.Add(dir, patterns)
+}
+
+func hasFile(files []fs.Item, name string) bool {
+	for _, item := range files {
+		if item.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package gitrepo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// createTree creates the files in the directory, each with 100 bytes
+func createTree(t *testing.T, root string, files ...string) {
+	t.Helper()
+	for _, name := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, make([]byte, 100), 0o600))
+	}
+}
+
+func analyzeDir(t *testing.T, path string) fs.Item {
+	t.Helper()
+	analyzer := analyze.CreateAnalyzer()
+	dir := analyzer.AnalyzeDir(path, func(_, _ string) bool { return false }, nil)
+	analyzer.GetDone().Wait()
+	dir.UpdateStats(make(fs.HardLinkedItems))
+	return dir
+}
+
+func TestClassify(t *testing.T) {
+	root := t.TempDir()
+	repoDir := filepath.Join(root, "repo")
+	createTree(t, repoDir,
+		".gitignore", "main.go", "main_test.go", "notes.txt",
+		"build/app", "build/keep.txt", "src/.gitignore", "src/gen.go", "src/gen.pb.go", "vendor.zip",
+	)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, ".gitignore"), []byte("build/\n*.txt\n!notes.txt\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "src", ".gitignore"), []byte("*.pb.go\n"), 0o600))
+	createRepo(t, repoDir, ".gitignore", "main.go", "build/keep.txt", "src/.gitignore", "src/gen.go", "vendor.zip")
+	createTree(t, root, "outside.bin", "plain/data")
+
+	dir := analyzeDir(t, root)
+	usage := Classify(dir)
+
+	stats, ok := usage.Get(filepath.Join(root, "repo"))
+	require.True(t, ok)
+	assert.True(t, usage.IsRepo(filepath.Join(root, "repo")))
+	assert.Equal(t, int64(400+24+8), stats.Size[Tracked]) // four files and both .gitignore files
+	assert.Equal(t, int64(200), stats.Size[Ignored])      // build/app, src/gen.pb.go
+	assert.Equal(t, int64(200), stats.Size[Untracked])    // main_test.go, notes.txt
+
+	check := func(name string, status Status) {
+		t.Helper()
+		stats, ok := usage.Get(filepath.Join(repoDir, filepath.FromSlash(name)))
+		require.True(t, ok, name)
+		assert.Equal(t, int64(100), stats.Size[status], name)
+	}
+	check("build/keep.txt", Tracked)
+	check("build/app", Ignored)
+	check("src/gen.pb.go", Ignored)
+	check("notes.txt", Untracked)
+	check("vendor.zip", Tracked)
+
+	_, ok = usage.Get(filepath.Join(repoDir, DirName))
+	assert.False(t, ok)
+	_, ok = usage.Get(filepath.Join(root, "outside.bin"))
+	assert.False(t, ok)
+	_, ok = usage.Get(filepath.Join(root, "plain"))
+	assert.False(t, ok)
+
+	// the analyzed dir sums the stats of the repositories inside of it
+	stats, ok = usage.Get(root)
+	require.True(t, ok)
+	assert.Equal(t, int64(200), stats.Size[Ignored])
+	assert.Greater(t, stats.Usage[Tracked], int64(0))
+}
+
+func TestClassifyInsideRepo(t *testing.T) {
+	root := t.TempDir()
+	createTree(t, root, ".gitignore", "out/lib/a.o", "out/lib/b.o", "src/main.c")
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".gitignore"), []byte("/out\n"), 0o600))
+	createRepo(t, root, ".gitignore", "src/main.c", "out/lib/b.o")
+
+	usage := Classify(analyzeDir(t, filepath.Join(root, "out", "lib")))
+
+	stats, ok := usage.Get(filepath.Join(root, "out", "lib"))
+	require.True(t, ok)
+	assert.Equal(t, int64(100), stats.Size[Ignored])
+	assert.Equal(t, int64(100), stats.Size[Tracked])
+	assert.False(t, usage.IsRepo(root))
+}
+
+func TestClassifyRelativePath(t *testing.T) {
+	root := t.TempDir()
+	createTree(t, root, "repo/.gitignore", "repo/tmp/x", "repo/main.go")
+	require.NoError(t, os.WriteFile(filepath.Join(root, "repo", ".gitignore"), []byte("tmp\n"), 0o600))
+	createRepo(t, filepath.Join(root, "repo"), ".gitignore", "main.go")
+	t.Chdir(filepath.Join(root, "repo"))
+
+	usage := Classify(analyzeDir(t, "."))
+
+	stats, ok := usage.Get("tmp")
+	require.True(t, ok)
+	assert.Equal(t, int64(100), stats.Size[Ignored])
+	stats, ok = usage.Get("main.go")
+	require.True(t, ok)
+	assert.Equal(t, int64(100), stats.Size[Tracked])
+}
+
+func TestUsageRemove(t *testing.T) {
+	root := t.TempDir()
+	createTree(t, root, ".gitignore", "build/a", "build/b", "main.go")
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".gitignore"), []byte("build/\n"), 0o600))
+	createRepo(t, root, ".gitignore", "main.go")
+
+	usage := Classify(analyzeDir(t, root))
+	usage.Remove(filepath.Join(root, "build", "a"))
+
+	stats, _ := usage.Get(filepath.Join(root, "build"))
+	assert.Equal(t, int64(100), stats.Size[Ignored])
+	stats, _ = usage.Get(root)
+	assert.Equal(t, int64(100), stats.Size[Ignored])
+	_, ok := usage.Get(filepath.Join(root, "build", "a"))
+	assert.False(t, ok)
+
+	usage.Remove(filepath.Join(root, "missing"))
+	usage.Remove(root)
+	assert.False(t, usage.IsRepo(root))
+}
+
+func TestStatusString(t *testing.T) {
+	assert.Equal(t, "tracked", Tracked.String())
+	assert.Equal(t, "ignored", Ignored.String())
+	assert.Equal(t, "untracked", Untracked.String())
+}
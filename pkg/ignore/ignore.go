@@ -0,0 +1,242 @@
+// Package ignore matches paths against patterns in the format of .gitignore files
+package ignore
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FileNames are names of the ignore files read from the scanned directories.
+// Patterns of the later files take precedence.
+var FileNames = []string{".gitignore", ".gduignore"}
+
+// Pattern is one line of an ignore file
+type Pattern struct {
+	re       *regexp.Regexp
+	negate   bool
+	dirOnly  bool
+	basename bool
+}
+
+// ParsePattern parses the line of an ignore file.
+// It returns false for blank lines, comments and invalid patterns.
+func ParsePattern(line string) (Pattern, bool) {
+	var p Pattern
+
+	line = strings.TrimSuffix(line, "\r")
+	// trailing spaces are ignored unless they are escaped with backslash
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	if line == "" || line[0] == '#' {
+		return p, false
+	}
+
+	if line[0] == '!' {
+		p.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return p, false
+	}
+
+	// patterns without slash match the name at any level,
+	// others match the path relative to the directory of the ignore file
+	p.basename = !strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	re, err := regexp.Compile(globToRegexp(line))
+	if err != nil {
+		return p, false
+	}
+	p.re = re
+	return p, true
+}
+
+// Parse reads the patterns from the ignore file content
+func Parse(r io.Reader) ([]Pattern, error) {
+	var patterns []Pattern
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if p, ok := ParsePattern(scanner.Text()); ok {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns, scanner.Err()
+}
+
+// ReadFile reads the patterns from the ignore file
+func ReadFile(path string) ([]Pattern, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Match returns true if the path relative to the directory of the pattern matches it
+func (p Pattern) Match(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	if p.basename {
+		rel = rel[strings.LastIndexByte(rel, '/')+1:]
+	}
+	return p.re.MatchString(rel)
+}
+
+// Rules are patterns applied to the paths below a directory together with the rules of the parent directories.
+// The nil value has no patterns.
+type Rules struct {
+	parent   *Rules
+	base     string
+	patterns []Pattern
+}
+
+// New returns rules with the patterns applied below the base directory
+func New(base string, patterns []Pattern) *Rules {
+	return (*Rules)(nil).Add(base, patterns)
+}
+
+// Add returns the rules extended by patterns applied below the base directory.
+// The patterns take precedence over the current rules.
+func (r *Rules) Add(base string, patterns []Pattern) *Rules {
+	if len(patterns) == 0 {
+		return r
+	}
+	return &Rules{
+		parent:   r,
+		base:     filepath.Clean(base),
+		patterns: patterns,
+	}
+}
+
+// AddDirFiles adds patterns from the ignore files of the directory.
+// Only the ignore files found among the entries of the directory are read.
+func (r *Rules) AddDirFiles(dir string, entries []os.DirEntry) (*Rules, error) {
+	var err error
+	for _, fileName := range FileNames {
+		found := false
+		for _, entry := range entries {
+			if entry.Name() == fileName && !entry.IsDir() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		patterns, readErr := ReadFile(filepath.Join(dir, fileName))
+		if readErr != nil {
+			err = readErr
+			continue
+		}
+		r = r.Add(dir, patterns)
+	}
+	return r, err
+}
+
+// Match returns true if the path should be ignored.
+// The last matching pattern of the deepest directory decides, negated patterns include the path again.
+func (r *Rules) Match(path string, isDir bool) bool {
+	for rules := r; rules != nil; rules = rules.parent {
+		rel, ok := relPath(rules.base, path)
+		if !ok {
+			continue
+		}
+		for i := len(rules.patterns) - 1; i >= 0; i-- {
+			if rules.patterns[i].Match(rel, isDir) {
+				return !rules.patterns[i].negate
+			}
+		}
+	}
+	return false
+}
+
+// relPath returns the path relative to base with slashes as separators
+func relPath(base, path string) (string, bool) {
+	if !strings.HasPrefix(path, base) {
+		return "", false
+	}
+	rel := path[len(base):]
+	if rel == "" || (rel[0] != filepath.Separator && !strings.HasSuffix(base, string(filepath.Separator))) {
+		return "", false
+	}
+	return filepath.ToSlash(strings.TrimPrefix(rel, string(filepath.Separator))), true
+}
+
+// globToRegexp converts the glob pattern to regular expression.
+// * and ? do not match slash, leading **/ matches in all directories, trailing /** matches everything inside.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+
+	runes := []rune(glob)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(runes[i])))
+			}
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' && (i == 0 || runes[i-1] == '/') {
+				switch {
+				case i+2 == len(runes):
+					b.WriteString(".*")
+					i++
+					continue
+				case runes[i+2] == '/':
+					b.WriteString("(?:.*/)?")
+					i += 2
+					continue
+				}
+			}
+			for i+1 < len(runes) && runes[i+1] == '*' {
+				i++
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := i + 1
+			if end < len(runes) && (runes[end] == '!' || runes[end] == '^') {
+				end++
+			}
+			if end < len(runes) && runes[end] == ']' {
+				end++
+			}
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := runes[i+1 : end]
+			b.WriteString("[")
+			if class[0] == '!' {
+				b.WriteString("^")
+				class = class[1:]
+			}
+			b.WriteString(strings.ReplaceAll(string(class), `\`, `\\`))
+			b.WriteString("]")
+			i = end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	b.WriteString("$")
+	return b.String()
+}
@@ -0,0 +1,132 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, content string) []Pattern {
+	t.Helper()
+	patterns, err := Parse(strings.NewReader(content))
+	require.NoError(t, err)
+	return patterns
+}
+
+func TestParsePatternSkipsComments(t *testing.T) {
+	for _, line := range []string{"", "   ", "# comment", "!", "/", "\r"} {
+		_, ok := ParsePattern(line)
+		assert.False(t, ok, line)
+	}
+}
+
+func TestPatternMatch(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		rel     string
+		isDir   bool
+		match   bool
+	}{
+		{"*.log", "app.log", false, true},
+		{"*.log", "logs/app.log", false, true},
+		{"*.log", "app.log.gz", false, false},
+		{"build/", "build", true, true},
+		{"build/", "build", false, false},
+		{"build/", "src/build", true, true},
+		{"/build", "build", true, true},
+		{"/build", "src/build", true, false},
+		{"doc/*.txt", "doc/a.txt", false, true},
+		{"doc/*.txt", "doc/sub/a.txt", false, false},
+		{"doc/*.txt", "x/doc/a.txt", false, false},
+		{"**/cache", "cache", true, true},
+		{"**/cache", "a/b/cache", true, true},
+		{"a/**/b", "a/b", true, true},
+		{"a/**/b", "a/x/y/b", true, true},
+		{"a/**", "a/x/y", false, true},
+		{"a/**", "a", true, false},
+		{"file?.[ch]", "file1.c", false, true},
+		{"file?.[ch]", "file12.c", false, false},
+		{"[!a]*", "abc", false, false},
+		{"[!a]*", "bcd", false, true},
+		{`\#hash`, "#hash", false, true},
+		{`\!bang`, "!bang", false, true},
+		{`space\ `, "space ", false, true},
+		{"trailing   ", "trailing", false, true},
+		{"[unterminated", "[unterminated", false, true},
+		{"a+b(c)", "a+b(c)", false, true},
+		{"žluť*", "žluťoučký", false, true},
+	} {
+		p, ok := ParsePattern(tc.pattern)
+		require.True(t, ok, tc.pattern)
+		assert.Equal(t, tc.match, p.Match(tc.rel, tc.isDir), "%s ~ %s", tc.pattern, tc.rel)
+	}
+}
+
+func TestRulesMatch(t *testing.T) {
+	rules := New("/srv", parse(t, "*.log\n!keep.log\n/tmp/\n"))
+
+	assert.True(t, rules.Match("/srv/app.log", false))
+	assert.True(t, rules.Match("/srv/a/b/app.log", false))
+	assert.False(t, rules.Match("/srv/keep.log", false))
+	assert.True(t, rules.Match("/srv/tmp", true))
+	assert.False(t, rules.Match("/srv/a/tmp", true))
+	assert.False(t, rules.Match("/srv", true))
+	assert.False(t, rules.Match("/srvx/app.log", false))
+	assert.False(t, rules.Match("/other/app.log", false))
+
+	// patterns of deeper directories take precedence
+	nested := rules.Add("/srv/a", parse(t, "!*.log\nkeep.log\n"))
+	assert.False(t, nested.Match("/srv/a/app.log", false))
+	assert.True(t, nested.Match("/srv/a/keep.log", false))
+	assert.True(t, nested.Match("/srv/b/app.log", false))
+	assert.False(t, nested.Match("/srv/keep.log", false))
+
+	assert.Same(t, rules, rules.Add("/srv/b", nil))
+}
+
+func TestNilRules(t *testing.T) {
+	var rules *Rules
+	assert.False(t, rules.Match("/srv/app.log", false))
+	assert.Nil(t, New("/srv", nil))
+}
+
+func TestRulesRootBase(t *testing.T) {
+	rules := New(string(filepath.Separator), parse(t, "/tmp\n"))
+	assert.True(t, rules.Match(filepath.Join(string(filepath.Separator), "tmp"), true))
+}
+
+func TestAddDirFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.o\n*.tmp\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".gduignore"), []byte("!*.tmp\n"), 0o600))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.c"), nil, 0o600))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	rules, err := (*Rules)(nil).AddDirFiles(dir, entries)
+	require.NoError(t, err)
+	assert.True(t, rules.Match(filepath.Join(dir, "main.o"), false))
+	assert.False(t, rules.Match(filepath.Join(dir, "x.tmp"), false))
+	assert.False(t, rules.Match(filepath.Join(dir, "main.c"), false))
+
+	// files not listed in the entries are not read
+	rules, err = (*Rules)(nil).AddDirFiles(dir, entries[2:])
+	require.NoError(t, err)
+	assert.Nil(t, rules)
+
+	// unreadable files are reported, the others are applied
+	require.NoError(t, os.Remove(filepath.Join(dir, ".gitignore")))
+	rules, err = (*Rules)(nil).AddDirFiles(dir, entries)
+	assert.Error(t, err)
+	assert.NotNil(t, rules)
+}
+
+func TestReadFileError(t *testing.T) {
+	_, err := ReadFile(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,147 @@
+package junk
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/ignore"
+)
+
+// Category sums the items tagged by one rule
+type Category struct {
+	Name       string
+	Suggestion string
+	Items      fs.Files
+	Usage      int64
+	Size       int64
+}
+
+// Result holds the categories of junk found in the analyzed tree
+type Result struct {
+	mu         sync.RWMutex
+	categories []*Category
+	tags       map[string]*Category
+}
+
+// Classify tags the items of the analyzed tree.
+// Items inside a tagged directory are not tagged again, they are removed together with it.
+func (c *Classifier) Classify(dir fs.Item) *Result {
+	r := &Result{tags: make(map[string]*Category)}
+
+	absPath, err := filepath.Abs(dir.GetPath())
+	if err != nil {
+		log.Print(err.Error())
+		return r
+	}
+	// patterns containing a slash match the paths relative to the root of the volume
+	root := filepath.VolumeName(absPath) + string(filepath.Separator)
+	rules := make([]*ignore.Rules, len(c.rules))
+	for i, rule := range c.rules {
+		rules[i] = ignore.New(root, rule.patterns)
+	}
+
+	byName := make(map[string]*Category, len(c.rules))
+	r.walk(dir, absPath, func(item fs.Item, path string, siblings []fs.Item) bool {
+		for i, rule := range c.rules {
+			if !rules[i].Match(path, item.IsDir()) {
+				continue
+			}
+			if rule.Marker != "" && !hasFile(siblings, rule.Marker) {
+				continue
+			}
+
+			category, ok := byName[rule.Category]
+			if !ok {
+				category = &Category{Name: rule.Category, Suggestion: rule.Suggestion}
+				byName[rule.Category] = category
+				r.categories = append(r.categories, category)
+			}
+			category.Items = append(category.Items, item)
+			category.Usage += item.GetUsage()
+			category.Size += item.GetSize()
+			r.tags[item.GetPath()] = category
+			return true
+		}
+		return false
+	})
+
+	return r
+}
+
+// walk calls tag for the items of the directory and descends to the directories which were not tagged
+func (r *Result) walk(dir fs.Item, path string, tag func(fs.Item, string, []fs.Item) bool) {
+	files := slices.Collect(dir.GetFiles(fs.SortByName, fs.SortAsc))
+	for _, item := range files {
+		itemPath := filepath.Join(path, item.GetName())
+		if tag(item, itemPath, files) || !item.IsDir() {
+			continue
+		}
+		r.walk(item, itemPath, tag)
+	}
+}
+
+// Categories returns copies of the categories with tagged items from the one with the biggest usage
+func (r *Result) Categories() []Category {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	categories := make([]Category, 0, len(r.categories))
+	for _, category := range r.categories {
+		copied := *category
+		copied.Items = slices.Clone(category.Items)
+		categories = append(categories, copied)
+	}
+	slices.SortStableFunc(categories, func(a, b Category) int {
+		return cmp.Compare(b.Usage, a.Usage)
+	})
+	return categories
+}
+
+// Get returns the name of the category of the item, false if the item is not tagged
+func (r *Result) Get(path string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if category, ok := r.tags[path]; ok {
+		return category.Name, true
+	}
+	return "", false
+}
+
+// Remove removes the deleted item and the tagged items inside of it from their categories
+func (r *Result) Remove(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prefix := path + string(filepath.Separator)
+	for tagged, category := range r.tags {
+		if tagged != path && !strings.HasPrefix(tagged, prefix) {
+			continue
+		}
+		delete(r.tags, tagged)
+		category.Items = slices.DeleteFunc(category.Items, func(item fs.Item) bool {
+			if item.GetPath() != tagged {
+				return false
+			}
+			category.Usage -= item.GetUsage()
+			category.Size -= item.GetSize()
+			return true
+		})
+		if len(category.Items) == 0 {
+			r.categories = slices.DeleteFunc(r.categories, func(c *Category) bool { return c == category })
+		}
+	}
+}
+
+func hasFile(files []fs.Item, name string) bool {
+	for _, item := range files {
+		if item.GetName() == name && !item.IsDir() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package junk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// createTree creates the files in the directory, each with 100 bytes
+func createTree(t *testing.T, root string, files ...string) {
+	t.Helper()
+	for _, name := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, make([]byte, 100), 0o600))
+	}
+}
+
+func analyzeDir(t *testing.T, path string) fs.Item {
+	t.Helper()
+	analyzer := analyze.CreateAnalyzer()
+	dir := analyzer.AnalyzeDir(path, func(_, _ string) bool { return false }, nil)
+	analyzer.GetDone().Wait()
+	dir.UpdateStats(make(fs.HardLinkedItems))
+	return dir
+}
+
+func categoryByName(categories []Category, name string) (Category, bool) {
+	for _, category := range categories {
+		if category.Name == name {
+			return category, true
+		}
+	}
+	return Category{}, false
+}
+
+func TestClassify(t *testing.T) {
+	root := t.TempDir()
+	createTree(t, root,
+		"web/package.json", "web/node_modules/a/index.js", "web/node_modules/a/node_modules/b/index.js",
+		"rust/Cargo.toml", "rust/target/debug/app", "java/target/app.jar",
+		"py/__pycache__/main.cpython-312.pyc", "py/main.pyc", "py/main.py",
+		"core.1234", "core",
+	)
+
+	classifier, err := NewClassifier(nil, DefaultRules())
+	require.NoError(t, err)
+	result := classifier.Classify(analyzeDir(t, root))
+
+	categories := result.Categories()
+	node, ok := categoryByName(categories, "Node.js modules")
+	require.True(t, ok)
+	assert.Len(t, node.Items, 1) // the nested node_modules is removed together with the outer one
+	assert.Equal(t, int64(200), node.Size)
+	assert.Equal(t, "reinstalled by npm install", node.Suggestion)
+
+	rust, ok := categoryByName(categories, "Rust build outputs")
+	require.True(t, ok)
+	assert.Len(t, rust.Items, 1)
+	assert.Equal(t, filepath.Join(root, "rust", "target"), rust.Items[0].GetPath())
+
+	python, ok := categoryByName(categories, "Python caches")
+	require.True(t, ok)
+	assert.Len(t, python.Items, 2)
+
+	dumps, ok := categoryByName(categories, "Core dumps")
+	require.True(t, ok)
+	assert.Len(t, dumps.Items, 1)
+
+	name, ok := result.Get(filepath.Join(root, "py", "main.pyc"))
+	assert.True(t, ok)
+	assert.Equal(t, "Python caches", name)
+	_, ok = result.Get(filepath.Join(root, "py", "main.py"))
+	assert.False(t, ok)
+	_, ok = result.Get(filepath.Join(root, "java", "target"))
+	assert.False(t, ok)
+
+	// categories are sorted by usage
+	for i := 1; i < len(categories); i++ {
+		assert.GreaterOrEqual(t, categories[i-1].Usage, categories[i].Usage)
+	}
+}
+
+func TestClassifyUserRules(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("HOME", root)
+	t.Setenv("USERPROFILE", root)
+	createTree(t, root,
+		".cache/pip/wheel", "build/out/app", "build/keep/app", "node_modules/x/index.js",
+	)
+
+	classifier, err := NewClassifier([]Rule{
+		{Category: "Build outputs", Patterns: []string{"**/build/*/", "!**/build/keep/"}, Suggestion: "make clean"},
+		{Category: "Node.js modules"},
+	}, DefaultRules())
+	require.NoError(t, err)
+	result := classifier.Classify(analyzeDir(t, root))
+
+	categories := result.Categories()
+	build, ok := categoryByName(categories, "Build outputs")
+	require.True(t, ok)
+	assert.Len(t, build.Items, 1)
+	assert.Equal(t, "out", build.Items[0].GetName())
+
+	cache, ok := categoryByName(categories, "User caches")
+	require.True(t, ok)
+	assert.Equal(t, int64(100), cache.Size)
+
+	_, ok = categoryByName(categories, "Node.js modules")
+	assert.False(t, ok)
+}
+
+func TestResultRemove(t *testing.T) {
+	root := t.TempDir()
+	createTree(t, root, "a/node_modules/x", "b/node_modules/x", "b/__pycache__/y.pyc")
+
+	classifier, err := NewClassifier(nil, DefaultRules())
+	require.NoError(t, err)
+	result := classifier.Classify(analyzeDir(t, root))
+
+	result.Remove(filepath.Join(root, "a", "node_modules"))
+	node, ok := categoryByName(result.Categories(), "Node.js modules")
+	require.True(t, ok)
+	assert.Len(t, node.Items, 1)
+	assert.Equal(t, int64(100), node.Size)
+
+	// removing the parent directory removes the tagged items inside of it
+	result.Remove(filepath.Join(root, "b"))
+	assert.Empty(t, result.Categories())
+	_, ok = result.Get(filepath.Join(root, "b", "node_modules"))
+	assert.False(t, ok)
+
+	result.Remove(filepath.Join(root, "missing"))
+}
@@ -0,0 +1,161 @@
+// Package junk tags well-known reclaimable items like dependency directories,
+// build outputs, caches, old kernels and core dumps with a category
+// and sums the space which can be freed in each of them.
+package junk
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dundee/gdu/v5/pkg/ignore"
+)
+
+// Rule tags items matching its patterns with the category.
+// Patterns are in the format of .gitignore files, patterns containing a slash match absolute paths
+// and ~/ is expanded to the home directory.
+type Rule struct {
+	Category string   `yaml:"category"`
+	Patterns []string `yaml:"patterns"`
+	// Marker is a file which must exist next to the matched item, e.g. Cargo.toml next to target/
+	Marker string `yaml:"marker,omitempty"`
+	// Suggestion describes how to clean up the items
+	Suggestion string `yaml:"suggestion,omitempty"`
+}
+
+// DefaultRules returns the built-in rules
+func DefaultRules() []Rule {
+	rules := []Rule{
+		{
+			Category:   "Node.js modules",
+			Patterns:   []string{"node_modules/"},
+			Suggestion: "reinstalled by npm install",
+		},
+		{
+			Category:   "Rust build outputs",
+			Patterns:   []string{"target/"},
+			Marker:     "Cargo.toml",
+			Suggestion: "cargo clean",
+		},
+		{
+			Category: "Python caches",
+			Patterns: []string{
+				"__pycache__/", ".pytest_cache/", ".mypy_cache/", ".ruff_cache/", ".tox/", "*.pyc",
+			},
+			Suggestion: "recreated when needed",
+		},
+		{
+			Category:   "Gradle caches",
+			Patterns:   []string{".gradle/"},
+			Suggestion: "gradle clean, dependencies are downloaded again",
+		},
+		{
+			Category:   "User caches",
+			Patterns:   []string{"~/.cache/"},
+			Suggestion: "recreated by the applications",
+		},
+		{
+			Category:   "Core dumps",
+			Patterns:   []string{"core.[0-9]*", "/var/lib/systemd/coredump/*", "/var/crash/*"},
+			Suggestion: "coredumpctl list, delete after inspecting",
+		},
+		{
+			Category:   "Docker layers",
+			Patterns:   []string{"/var/lib/docker/overlay2/"},
+			Suggestion: "docker system prune",
+		},
+	}
+	if release := kernelRelease(); release != "" {
+		rules = append(rules, Rule{
+			Category: "Old kernels",
+			Patterns: []string{
+				"/boot/vmlinuz-*", "/boot/initrd.img-*", "/boot/System.map-*", "/boot/config-*",
+				"/lib/modules/*/", "/usr/lib/modules/*/",
+				"!/boot/*-" + release, "!/lib/modules/" + release + "/", "!/usr/lib/modules/" + release + "/",
+			},
+			Suggestion: "remove old kernel packages by the package manager",
+		})
+	}
+	return rules
+}
+
+// kernelRelease returns the release of the running kernel so that it is not tagged as old
+func kernelRelease() string {
+	data, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// Classifier tags items by the rules
+type Classifier struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	Rule
+	patterns []ignore.Pattern
+}
+
+// NewClassifier creates classifier from the user rules followed by the built-in rules.
+// User rules with the category of a built-in rule replace it, so a rule without patterns disables the category.
+func NewClassifier(userRules, builtinRules []Rule) (*Classifier, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = ""
+	}
+
+	c := &Classifier{}
+	categories := make(map[string]struct{}, len(userRules))
+	for _, rule := range userRules {
+		if rule.Category == "" {
+			return nil, errors.New("junk rule without category")
+		}
+		categories[rule.Category] = struct{}{}
+		compiled, err := compile(rule, home)
+		if err != nil {
+			return nil, err
+		}
+		c.rules = append(c.rules, compiled)
+	}
+	for _, rule := range builtinRules {
+		if _, ok := categories[rule.Category]; ok {
+			continue
+		}
+		compiled, err := compile(rule, home)
+		if err != nil {
+			return nil, err
+		}
+		c.rules = append(c.rules, compiled)
+	}
+	return c, nil
+}
+
+func compile(rule Rule, home string) (compiledRule, error) {
+	compiled := compiledRule{Rule: rule}
+	for _, line := range rule.Patterns {
+		negate := strings.HasPrefix(line, "!")
+		expanded := strings.TrimPrefix(line, "!")
+		if strings.HasPrefix(expanded, "~/") {
+			if home == "" {
+				continue
+			}
+			// paths are matched relative to the root of the volume
+			home = strings.TrimPrefix(home, filepath.VolumeName(home))
+			expanded = strings.TrimSuffix(filepath.ToSlash(home), "/") + expanded[1:]
+		}
+		if negate {
+			expanded = "!" + expanded
+		}
+
+		pattern, ok := ignore.ParsePattern(expanded)
+		if !ok {
+			return compiled, fmt.Errorf("invalid pattern %q of junk rule %q", line, rule.Category)
+		}
+		compiled.patterns = append(compiled.patterns, pattern)
+	}
+	return compiled, nil
+}
@@ -0,0 +1,53 @@
+package junk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/pkg/ignore"
+)
+
+func TestDefaultRules(t *testing.T) {
+	rules := DefaultRules()
+	assert.NotEmpty(t, rules)
+
+	for _, rule := range rules {
+		assert.NotEmpty(t, rule.Category)
+		assert.NotEmpty(t, rule.Patterns, rule.Category)
+		assert.NotEmpty(t, rule.Suggestion, rule.Category)
+	}
+
+	_, err := NewClassifier(nil, rules)
+	assert.NoError(t, err)
+}
+
+func TestNewClassifierErrors(t *testing.T) {
+	_, err := NewClassifier([]Rule{{Patterns: []string{"*.tmp"}}}, nil)
+	assert.EqualError(t, err, "junk rule without category")
+
+	_, err = NewClassifier([]Rule{{Category: "Temp", Patterns: []string{"#comment"}}}, nil)
+	assert.EqualError(t, err, `invalid pattern "#comment" of junk rule "Temp"`)
+}
+
+func TestOldKernels(t *testing.T) {
+	release := kernelRelease()
+	if release == "" {
+		t.Skip("kernel release is not available")
+	}
+
+	classifier, err := NewClassifier(nil, DefaultRules())
+	assert.NoError(t, err)
+
+	var kernels compiledRule
+	for _, rule := range classifier.rules {
+		if rule.Category == "Old kernels" {
+			kernels = rule
+		}
+	}
+	rules := ignore.New("/", kernels.patterns)
+	assert.False(t, rules.Match("/boot/vmlinuz-"+release, false))
+	assert.False(t, rules.Match("/lib/modules/"+release, true))
+	assert.True(t, rules.Match("/boot/vmlinuz-1.0.0-old", false))
+	assert.True(t, rules.Match("/lib/modules/1.0.0-old", true))
+}
@@ -0,0 +1,264 @@
+// Package plan implements cleanup plans: a saved list of paths marked for
+// removal together with the action to perform on each of them. A plan can be
+// written in one session, reviewed against the current filesystem state and
+// applied later, possibly on another machine.
+package plan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/remove"
+)
+
+// Version is the version of the plan file format
+const Version = 1
+
+// Action defines what should be done with a planned item
+type Action string
+
+const (
+	// ActionDelete removes the item permanently
+	ActionDelete Action = "delete"
+	// ActionTrash moves the item to trash
+	ActionTrash Action = "trash"
+	// ActionEmpty truncates a file or removes the content of a directory
+	ActionEmpty Action = "empty"
+)
+
+// Actions lists all supported actions
+var Actions = []Action{ActionDelete, ActionTrash, ActionEmpty}
+
+// Valid reports whether the action is supported
+func (a Action) Valid() bool {
+	for _, known := range Actions {
+		if a == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Entry is one planned item
+type Entry struct {
+	Path   string    `json:"path"`
+	Size   int64     `json:"size"`
+	Usage  int64     `json:"usage"`
+	Mtime  time.Time `json:"mtime"`
+	IsDir  bool      `json:"isDir"`
+	Action Action    `json:"action"`
+}
+
+// Plan is a list of planned items
+type Plan struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	Host    string    `json:"host,omitempty"`
+	Entries []Entry   `json:"entries"`
+}
+
+// New creates an empty plan
+func New() *Plan {
+	host, _ := os.Hostname()
+	return &Plan{
+		Version: Version,
+		Created: time.Now(),
+		Host:    host,
+	}
+}
+
+// EntryFromItem creates plan entry for the given item. The modification time
+// is taken from the filesystem, because the mtime of an analyzed directory
+// is the latest mtime of its whole subtree.
+func EntryFromItem(item fs.Item, action Action) Entry {
+	path := ItemPath(item)
+	mtime := item.GetMtime()
+	if info, err := os.Lstat(path); err == nil {
+		mtime = info.ModTime()
+	}
+	return Entry{
+		Path:   path,
+		Size:   item.GetSize(),
+		Usage:  item.GetUsage(),
+		Mtime:  mtime,
+		IsDir:  item.IsDir(),
+		Action: action,
+	}
+}
+
+// ItemPath returns the absolute path of the item as stored in plans
+func ItemPath(item fs.Item) string {
+	path, err := filepath.Abs(item.GetPath())
+	if err != nil {
+		return item.GetPath()
+	}
+	return path
+}
+
+// Add adds entry to the plan, replacing an entry with the same path
+func (p *Plan) Add(entry Entry) {
+	for i := range p.Entries {
+		if p.Entries[i].Path == entry.Path {
+			p.Entries[i] = entry
+			return
+		}
+	}
+	p.Entries = append(p.Entries, entry)
+}
+
+// Save writes the plan as JSON
+func (p *Plan) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p)
+}
+
+// SaveFile writes the plan into the file at the given path
+func (p *Plan) SaveFile(path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := p.Save(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Load reads plan from JSON
+func Load(r io.Reader) (*Plan, error) {
+	p := &Plan{}
+	if err := json.NewDecoder(r).Decode(p); err != nil {
+		return nil, fmt.Errorf("decoding plan: %w", err)
+	}
+	if p.Version != Version {
+		return nil, fmt.Errorf("unsupported plan version %d", p.Version)
+	}
+	for _, entry := range p.Entries {
+		if !filepath.IsAbs(entry.Path) {
+			return nil, fmt.Errorf("plan entry path is not absolute: %s", entry.Path)
+		}
+		if !entry.Action.Valid() {
+			return nil, fmt.Errorf("unknown action %q for %s", entry.Action, entry.Path)
+		}
+	}
+	return p, nil
+}
+
+// LoadFile reads plan from the file at the given path
+func LoadFile(path string) (*Plan, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Load(file)
+}
+
+// Status is the state of a planned item compared to the filesystem
+type Status int
+
+const (
+	// StatusUnchanged means the item looks the same as when it was planned
+	StatusUnchanged Status = iota
+	// StatusChanged means the item was modified since it was planned
+	StatusChanged
+	// StatusMissing means the item does not exist anymore
+	StatusMissing
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusChanged:
+		return "changed"
+	case StatusMissing:
+		return "missing"
+	case StatusUnchanged:
+		return "unchanged"
+	}
+	return "unknown"
+}
+
+// Reviewed is a plan entry together with its current status
+type Reviewed struct {
+	Entry
+	Status Status
+	Reason string
+}
+
+// Review compares plan entries with the current state of the filesystem.
+// Files are considered changed when their size or mtime differs, directories
+// when their own mtime differs (i.e. when direct children were added or removed).
+func (p *Plan) Review(lstat func(string) (os.FileInfo, error)) []Reviewed {
+	result := make([]Reviewed, 0, len(p.Entries))
+	for _, entry := range p.Entries {
+		result = append(result, reviewEntry(entry, lstat))
+	}
+	return result
+}
+
+func reviewEntry(entry Entry, lstat func(string) (os.FileInfo, error)) Reviewed {
+	reviewed := Reviewed{Entry: entry}
+
+	info, err := lstat(entry.Path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		reviewed.Status = StatusMissing
+		reviewed.Reason = "does not exist"
+	case err != nil:
+		reviewed.Status = StatusMissing
+		reviewed.Reason = err.Error()
+	case info.IsDir() != entry.IsDir:
+		reviewed.Status = StatusChanged
+		reviewed.Reason = "type changed"
+	case !entry.IsDir && info.Size() != entry.Size:
+		reviewed.Status = StatusChanged
+		reviewed.Reason = fmt.Sprintf("size changed from %d to %d", entry.Size, info.Size())
+	case !info.ModTime().Equal(entry.Mtime):
+		reviewed.Status = StatusChanged
+		reviewed.Reason = "modified at " + info.ModTime().Format(time.RFC3339)
+	}
+	return reviewed
+}
+
+// ErrChanged is returned when the item of the entry changed since it was reviewed
+var ErrChanged = errors.New("item changed since it was planned")
+
+// Apply performs the planned action on the entry.
+// The item is checked again right before the action, so that an item changed since the review is kept.
+func Apply(entry Entry) error {
+	if !entry.Action.Valid() {
+		return fmt.Errorf("unknown action %q", entry.Action)
+	}
+	if reviewed := reviewEntry(entry, os.Lstat); reviewed.Status != StatusUnchanged {
+		return fmt.Errorf("%w: %s", ErrChanged, reviewed.Reason)
+	}
+
+	switch entry.Action {
+	case ActionTrash:
+		return remove.MovePathToTrash(entry.Path)
+	case ActionEmpty:
+		if !entry.IsDir {
+			return remove.EmptyFile(entry.Path)
+		}
+		children, err := os.ReadDir(entry.Path)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			if err := remove.Path(filepath.Join(entry.Path, child.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return remove.Path(entry.Path)
+	}
+}
@@ -0,0 +1,205 @@
+package plan
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+)
+
+func entryFor(t *testing.T, path string, action Action) Entry {
+	t.Helper()
+	abs, err := filepath.Abs(path)
+	assert.Nil(t, err)
+	info, err := os.Lstat(abs)
+	assert.Nil(t, err)
+	return Entry{
+		Path:   abs,
+		Size:   info.Size(),
+		Mtime:  info.ModTime(),
+		IsDir:  info.IsDir(),
+		Action: action,
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	p := New()
+	p.Add(Entry{Path: "/a/b", Size: 10, Action: ActionDelete})
+	p.Add(Entry{Path: "/a/c", Size: 20, IsDir: true, Action: ActionTrash})
+	p.Add(Entry{Path: "/a/b", Size: 30, Action: ActionEmpty})
+
+	var buff bytes.Buffer
+	assert.Nil(t, p.Save(&buff))
+
+	loaded, err := Load(&buff)
+	assert.Nil(t, err)
+	assert.Equal(t, Version, loaded.Version)
+	assert.Len(t, loaded.Entries, 2)
+	assert.Equal(t, int64(30), loaded.Entries[0].Size)
+	assert.Equal(t, ActionEmpty, loaded.Entries[0].Action)
+	assert.True(t, loaded.Entries[1].IsDir)
+}
+
+func TestSaveAndLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+
+	p := New()
+	p.Add(Entry{Path: "/a/b", Action: ActionDelete})
+	assert.Nil(t, p.SaveFile(path))
+
+	loaded, err := LoadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "/a/b", loaded.Entries[0].Path)
+
+	_, err = LoadFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	_, err := Load(bytes.NewBufferString("xxx"))
+	assert.ErrorContains(t, err, "decoding plan")
+
+	_, err = Load(bytes.NewBufferString(`{"version": 99}`))
+	assert.ErrorContains(t, err, "unsupported plan version")
+
+	_, err = Load(bytes.NewBufferString(`{"version": 1, "entries": [{"path": "a", "action": "delete"}]}`))
+	assert.ErrorContains(t, err, "not absolute")
+
+	_, err = Load(bytes.NewBufferString(`{"version": 1, "entries": [{"path": "/a", "action": "burn"}]}`))
+	assert.ErrorContains(t, err, "unknown action")
+}
+
+func TestEntryFromItem(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	abs, _ := filepath.Abs("test_dir")
+	dir := &analyze.Dir{
+		File: &analyze.File{
+			Name:  "nested",
+			Size:  100,
+			Usage: 200,
+		},
+		BasePath: abs,
+	}
+
+	entry := EntryFromItem(dir, ActionTrash)
+	info, _ := os.Lstat(filepath.Join(abs, "nested"))
+
+	assert.Equal(t, filepath.Join(abs, "nested"), entry.Path)
+	assert.Equal(t, int64(100), entry.Size)
+	assert.Equal(t, int64(200), entry.Usage)
+	assert.True(t, entry.IsDir)
+	assert.Equal(t, info.ModTime(), entry.Mtime)
+	assert.Equal(t, ActionTrash, entry.Action)
+}
+
+func TestReview(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	p := New()
+	p.Add(entryFor(t, "test_dir/nested/file2", ActionDelete))
+	p.Add(entryFor(t, "test_dir/nested/subnested/file", ActionDelete))
+	p.Add(entryFor(t, "test_dir/nested/subnested", ActionDelete))
+	p.Add(Entry{Path: "/nonexistent/path", Action: ActionDelete})
+
+	changed := p.Entries[1]
+	changed.Size++
+	p.Add(changed)
+
+	typeChanged := p.Entries[2]
+	typeChanged.IsDir = false
+	p.Add(typeChanged)
+
+	reviewed := p.Review(os.Lstat)
+
+	assert.Equal(t, StatusUnchanged, reviewed[0].Status)
+	assert.Equal(t, StatusChanged, reviewed[1].Status)
+	assert.Contains(t, reviewed[1].Reason, "size changed")
+	assert.Equal(t, StatusChanged, reviewed[2].Status)
+	assert.Equal(t, "type changed", reviewed[2].Reason)
+	assert.Equal(t, StatusMissing, reviewed[3].Status)
+	assert.Equal(t, "missing", reviewed[3].Status.String())
+}
+
+func TestReviewModified(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	p := New()
+	entry := entryFor(t, "test_dir/nested", ActionDelete)
+	entry.Mtime = entry.Mtime.Add(-time.Hour)
+	p.Add(entry)
+
+	reviewed := p.Review(os.Lstat)
+	assert.Equal(t, StatusChanged, reviewed[0].Status)
+	assert.Contains(t, reviewed[0].Reason, "modified at")
+}
+
+func TestApplyDelete(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	assert.Nil(t, Apply(entryFor(t, "test_dir/nested/subnested", ActionDelete)))
+	assert.NoDirExists(t, "test_dir/nested/subnested")
+	assert.FileExists(t, "test_dir/nested/file2")
+}
+
+func TestApplyEmpty(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	assert.Nil(t, Apply(entryFor(t, "test_dir/nested/file2", ActionEmpty)))
+	info, err := os.Stat("test_dir/nested/file2")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), info.Size())
+
+	assert.Nil(t, Apply(entryFor(t, "test_dir/nested", ActionEmpty)))
+	assert.DirExists(t, "test_dir/nested")
+	entries, err := os.ReadDir("test_dir/nested")
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestApplyChanged(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	entry := entryFor(t, "test_dir/nested/file2", ActionDelete)
+	assert.Nil(t, os.WriteFile("test_dir/nested/file2", []byte("changed content"), 0o600))
+
+	err := Apply(entry)
+	assert.ErrorIs(t, err, ErrChanged)
+	assert.ErrorContains(t, err, "size changed")
+	assert.FileExists(t, "test_dir/nested/file2")
+}
+
+func TestApplyTypeChanged(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	// the file was replaced by a directory of the same name
+	entry := entryFor(t, "test_dir/nested/file2", ActionEmpty)
+	assert.Nil(t, os.Remove("test_dir/nested/file2"))
+	assert.Nil(t, os.Mkdir("test_dir/nested/file2", 0o755))
+
+	err := Apply(entry)
+	assert.ErrorIs(t, err, ErrChanged)
+	assert.DirExists(t, "test_dir/nested/file2")
+}
+
+func TestApplyMissing(t *testing.T) {
+	err := Apply(Entry{Path: "/nonexistent/dir", IsDir: true, Action: ActionEmpty})
+	assert.ErrorIs(t, err, ErrChanged)
+	assert.ErrorContains(t, err, "does not exist")
+
+	err = Apply(Entry{Path: "/nonexistent/dir", Action: Action("burn")})
+	assert.ErrorContains(t, err, "unknown action")
+}
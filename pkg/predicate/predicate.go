@@ -0,0 +1,161 @@
+// Package predicate filters the files found during the scan by their size and mode
+package predicate
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dundee/gdu/v5/pkg/query"
+)
+
+// Predicate returns true if the file should be included in the analysis
+type Predicate func(info os.FileInfo) bool
+
+// Mode is a kind of file selected by its mode
+type Mode string
+
+const (
+	// ModeExecutable selects regular files executable by anyone
+	ModeExecutable Mode = "executable"
+	// ModeSetuid selects files with the set-user-ID bit
+	ModeSetuid Mode = "setuid"
+	// ModeSetgid selects files with the set-group-ID bit
+	ModeSetgid Mode = "setgid"
+	// ModeSocket selects Unix domain sockets
+	ModeSocket Mode = "socket"
+	// ModeFifo selects named pipes
+	ModeFifo Mode = "fifo"
+	// ModeSymlink selects symbolic links
+	ModeSymlink Mode = "symlink"
+	// ModeDevice selects block and character devices
+	ModeDevice Mode = "device"
+)
+
+// Modes lists all supported modes
+var Modes = []Mode{ModeExecutable, ModeSetuid, ModeSetgid, ModeSocket, ModeFifo, ModeSymlink, ModeDevice}
+
+// ParseMode parses the name of the mode
+func ParseMode(value string) (Mode, error) {
+	mode := Mode(strings.ToLower(strings.TrimSpace(value)))
+	for _, known := range Modes {
+		if mode == known {
+			return mode, nil
+		}
+	}
+	names := make([]string, 0, len(Modes))
+	for _, known := range Modes {
+		names = append(names, string(known))
+	}
+	return "", fmt.Errorf("unknown file mode %q, use one of %s", value, strings.Join(names, ", "))
+}
+
+// Match returns true if the file mode is of the kind
+func (m Mode) Match(mode os.FileMode) bool {
+	switch m {
+	case ModeExecutable:
+		return mode.IsRegular() && mode.Perm()&0o111 != 0
+	case ModeSetuid:
+		return mode&os.ModeSetuid != 0
+	case ModeSetgid:
+		return mode&os.ModeSetgid != 0
+	case ModeSocket:
+		return mode&os.ModeSocket != 0
+	case ModeFifo:
+		return mode&os.ModeNamedPipe != 0
+	case ModeSymlink:
+		return mode&os.ModeSymlink != 0
+	case ModeDevice:
+		return mode&os.ModeDevice != 0
+	}
+	return false
+}
+
+// MinSize selects files with apparent size of at least size bytes
+func MinSize(size int64) Predicate {
+	return func(info os.FileInfo) bool {
+		return info.Size() >= size
+	}
+}
+
+// MaxSize selects files with apparent size of at most size bytes
+func MaxSize(size int64) Predicate {
+	return func(info os.FileInfo) bool {
+		return info.Size() <= size
+	}
+}
+
+// AnyMode selects files of any of the modes
+func AnyMode(modes ...Mode) Predicate {
+	return func(info os.FileInfo) bool {
+		for _, mode := range modes {
+			if mode.Match(info.Mode()) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// And selects files selected by all the predicates. Nil predicates are left out,
+// nil is returned when there is no predicate left.
+func And(predicates ...Predicate) Predicate {
+	var used []Predicate
+	for _, p := range predicates {
+		if p != nil {
+			used = append(used, p)
+		}
+	}
+	switch len(used) {
+	case 0:
+		return nil
+	case 1:
+		return used[0]
+	}
+	return func(info os.FileInfo) bool {
+		for _, p := range used {
+			if !p(info) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// New creates predicate from the minimal and maximal size (e.g. 100M) and the file modes.
+// Empty values are not applied, nil is returned when none is given.
+func New(minSize, maxSize string, modes []string) (Predicate, error) {
+	var predicates []Predicate
+	var minBytes int64 = -1
+
+	if minSize != "" {
+		size, err := query.ParseSize(minSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid minimal size: %w", err)
+		}
+		minBytes = size
+		predicates = append(predicates, MinSize(size))
+	}
+	if maxSize != "" {
+		size, err := query.ParseSize(maxSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maximal size: %w", err)
+		}
+		if size < minBytes {
+			return nil, fmt.Errorf("maximal size %s is lower than minimal size %s", maxSize, minSize)
+		}
+		predicates = append(predicates, MaxSize(size))
+	}
+	if len(modes) > 0 {
+		parsed := make([]Mode, 0, len(modes))
+		for _, value := range modes {
+			mode, err := ParseMode(value)
+			if err != nil {
+				return nil, err
+			}
+			parsed = append(parsed, mode)
+		}
+		predicates = append(predicates, AnyMode(parsed...))
+	}
+	return And(predicates...), nil
+}
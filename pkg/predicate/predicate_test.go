@@ -0,0 +1,108 @@
+package predicate
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fileInfo struct {
+	size int64
+	mode os.FileMode
+}
+
+func (f fileInfo) Name() string       { return "file" }
+func (f fileInfo) Size() int64        { return f.size }
+func (f fileInfo) Mode() os.FileMode  { return f.mode }
+func (f fileInfo) ModTime() time.Time { return time.Time{} }
+func (f fileInfo) IsDir() bool        { return f.mode.IsDir() }
+func (f fileInfo) Sys() any           { return nil }
+
+func TestSizes(t *testing.T) {
+	p, err := New("1K", "1M", nil)
+	require.NoError(t, err)
+
+	assert.False(t, p(fileInfo{size: 1023}))
+	assert.True(t, p(fileInfo{size: 1024}))
+	assert.True(t, p(fileInfo{size: 1 << 20}))
+	assert.False(t, p(fileInfo{size: 1<<20 + 1}))
+}
+
+func TestModes(t *testing.T) {
+	p, err := New("", "", []string{"executable", "Setuid", "socket"})
+	require.NoError(t, err)
+
+	assert.True(t, p(fileInfo{mode: 0o755}))
+	assert.False(t, p(fileInfo{mode: 0o644}))
+	assert.False(t, p(fileInfo{mode: os.ModeDir | 0o755}))
+	assert.True(t, p(fileInfo{mode: os.ModeSetuid | 0o644}))
+	assert.True(t, p(fileInfo{mode: os.ModeSocket | 0o600}))
+	assert.False(t, p(fileInfo{mode: os.ModeNamedPipe | 0o600}))
+
+	for mode, fileMode := range map[Mode]os.FileMode{
+		ModeSetgid:  os.ModeSetgid,
+		ModeFifo:    os.ModeNamedPipe,
+		ModeSymlink: os.ModeSymlink,
+		ModeDevice:  os.ModeDevice | os.ModeCharDevice,
+	} {
+		assert.True(t, mode.Match(fileMode), mode)
+		assert.False(t, mode.Match(0o644), mode)
+	}
+	assert.False(t, Mode("other").Match(0o777))
+}
+
+func TestCombined(t *testing.T) {
+	p, err := New("100", "", []string{"executable"})
+	require.NoError(t, err)
+
+	assert.True(t, p(fileInfo{size: 100, mode: 0o700}))
+	assert.False(t, p(fileInfo{size: 99, mode: 0o700}))
+	assert.False(t, p(fileInfo{size: 100, mode: 0o600}))
+}
+
+func TestNewEmpty(t *testing.T) {
+	p, err := New("", "", nil)
+	require.NoError(t, err)
+	assert.Nil(t, p)
+
+	assert.Nil(t, And(nil, nil))
+	assert.NotNil(t, And(nil, MinSize(1)))
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := New("lots", "", nil)
+	assert.ErrorContains(t, err, "invalid minimal size")
+
+	_, err = New("", "1X", nil)
+	assert.ErrorContains(t, err, "invalid maximal size")
+
+	_, err = New("1G", "1M", nil)
+	assert.EqualError(t, err, "maximal size 1M is lower than minimal size 1G")
+
+	_, err = New("", "", []string{"hidden"})
+	assert.ErrorContains(t, err, `unknown file mode "hidden", use one of executable, setuid`)
+}
+
+func TestRealFiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported on Windows")
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "script.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n"), 0o755))
+	data := filepath.Join(dir, "data")
+	require.NoError(t, os.WriteFile(data, []byte("data"), 0o600))
+
+	p := AnyMode(ModeExecutable)
+	info, err := os.Lstat(script)
+	require.NoError(t, err)
+	assert.True(t, p(info))
+	info, err = os.Lstat(data)
+	require.NoError(t, err)
+	assert.False(t, p(info))
+}
@@ -0,0 +1,9 @@
+// Package priority lowers the CPU and I/O priority of gdu so that the scan slows down other processes as little as possible
+package priority
+
+const (
+	// Nice is the niceness of the process with lowered priority
+	Nice = 19
+	// IOLevel is the level of best-effort I/O class of the process with lowered priority (0-7, 7 is the lowest)
+	IOLevel = 7
+)
@@ -0,0 +1,46 @@
+package priority
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	ioprioWhoProcess = 1
+	ioprioClassBE    = 2
+	ioprioClassShift = 13
+)
+
+// Lower sets the niceness and the best-effort I/O class with the lowest level to all threads of the process.
+// Threads started later inherit the priority of the thread starting them.
+func Lower() error {
+	tasks, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		tid, err := strconv.Atoi(task.Name())
+		if err != nil {
+			continue
+		}
+		err = unix.Setpriority(unix.PRIO_PROCESS, tid, Nice)
+		if errors.Is(err, unix.ESRCH) {
+			continue // thread exited
+		}
+		if err != nil {
+			return fmt.Errorf("setting nice: %w", err)
+		}
+		_, _, errno := unix.Syscall(
+			unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), ioprioClassBE<<ioprioClassShift|IOLevel,
+		)
+		if errno != 0 && errno != unix.ESRCH {
+			return fmt.Errorf("setting I/O priority: %w", errno)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package priority
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+func TestLower(t *testing.T) {
+	assert.NoError(t, Lower())
+
+	// the raw value of getpriority is 20 - nice
+	prio, err := unix.Getpriority(unix.PRIO_PROCESS, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 20-Nice, prio)
+
+	ioprio, _, errno := unix.Syscall(unix.SYS_IOPRIO_GET, ioprioWhoProcess, 0, 0)
+	assert.Zero(t, errno)
+	assert.Equal(t, uintptr(ioprioClassBE<<ioprioClassShift|IOLevel), ioprio)
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package priority
+
+import "errors"
+
+// Lower is not supported on this platform
+func Lower() error {
+	return errors.ErrUnsupported
+}
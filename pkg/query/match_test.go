@@ -0,0 +1,41 @@
+package query_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/query"
+)
+
+func TestExprMatch(t *testing.T) {
+	now := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	dir := &analyze.Dir{
+		File:      &analyze.File{Name: "logs", Usage: 8192, Mtime: now.AddDate(0, 0, -10)},
+		ItemCount: 3,
+	}
+	log := &analyze.File{Name: "app.log", Size: 2 << 30, Usage: 2 << 30, Mtime: now.AddDate(-2, 0, 0), Parent: dir}
+	core := &analyze.File{Name: "core.123", Size: 100, Usage: 4096, Mtime: now, Parent: dir}
+
+	for expr, expected := range map[string][]bool{
+		`size > 1G and mtime < -1y and name ~ '*.log'`: {false, true, false},
+		`type = dir`:                       {true, false, false},
+		`type != dir and usage <= 4K`:      {false, false, true},
+		`name ~ /^core\.[0-9]+$/`:          {false, false, true},
+		`name !~ /^core/ and depth = 1`:    {false, true, false},
+		`path ~ 'logs/*'`:                  {false, true, true},
+		`not (items = 1) or name = 'x'`:    {true, false, false},
+		`mtime = 2026-09-01`:               {false, false, true},
+		`mtime != 2026-09-01 and depth>=0`: {true, true, false},
+		`mtime > -30d`:                     {true, false, true},
+	} {
+		parsed, err := query.Parse(expr, now, time.UTC)
+		require.NoError(t, err, expr)
+		assert.Equal(t, expected, []bool{
+			parsed.Match(dir, 0), parsed.Match(log, 1), parsed.Match(core, 1),
+		}, expr)
+	}
+}
@@ -0,0 +1,619 @@
+// Package query parses filter expressions like `size > 1G and mtime < -1y and name ~ '*.log'`.
+// The expressions can be compiled into SQL conditions over the items of the analysis stored in SQLite database
+// or matched against the items of the in-memory tree.
+package query
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/timefilter"
+)
+
+type fieldKind int
+
+const (
+	stringField fieldKind = iota
+	numberField
+	sizeField
+	timeField
+	typeField
+)
+
+type field struct {
+	column string
+	kind   fieldKind
+}
+
+// fields maps names usable in filter expressions to the columns of the view of items
+var fields = map[string]field{
+	"name":  {"name", stringField},
+	"path":  {"path", stringField},
+	"size":  {"size", sizeField},
+	"asize": {"size", sizeField},
+	"usage": {"usage", sizeField},
+	"dsize": {"usage", sizeField},
+	"items": {"item_count", numberField},
+	"depth": {"depth", numberField},
+	"mtime": {"mtime", timeField},
+	"type":  {"is_dir", typeField},
+}
+
+var sizeRe = regexp.MustCompile(`^(\d+(?:\.\d+)?)([kmgtpe]?)(?:i?b)?$`)
+
+// Expr is a parsed filter expression
+type Expr struct {
+	root node
+}
+
+// IsSQL returns true if the query is a SQL statement rather than a filter expression
+func IsSQL(q string) bool {
+	word, _, _ := strings.Cut(strings.TrimSpace(q), " ")
+	word = strings.ToLower(strings.TrimSpace(word))
+	return word == "select" || word == "with" || word == "explain" || word == "pragma"
+}
+
+// Parse parses the filter expression.
+// Relative times (e.g. -1y, -30d) are counted back from now, dates are in the given location.
+func Parse(expr string, now time.Time, loc *time.Location) (*Expr, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, now: now, loc: loc}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != endToken {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+	return &Expr{root: root}, nil
+}
+
+// SQL returns the expression as SQL condition with its arguments.
+// Regular expressions are matched by the REGEXP operator.
+func (e *Expr) SQL() (where string, args []any) {
+	var b strings.Builder
+	e.root.sql(&b, &args)
+	return b.String(), args
+}
+
+// Match returns true if the item at the given depth below the searched directory matches the expression
+func (e *Expr) Match(item fs.Item, depth int) bool {
+	return e.root.match(item, depth)
+}
+
+// node is a node of the parsed expression
+type node interface {
+	sql(b *strings.Builder, args *[]any)
+	match(item fs.Item, depth int) bool
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) sql(b *strings.Builder, args *[]any) {
+	n.left.sql(b, args)
+	b.WriteString(" " + n.op + " ")
+	n.right.sql(b, args)
+}
+
+func (n *binaryNode) match(item fs.Item, depth int) bool {
+	if n.op == "AND" {
+		return n.left.match(item, depth) && n.right.match(item, depth)
+	}
+	return n.left.match(item, depth) || n.right.match(item, depth)
+}
+
+type notNode struct {
+	expr node
+}
+
+func (n *notNode) sql(b *strings.Builder, args *[]any) {
+	b.WriteString("NOT ")
+	n.expr.sql(b, args)
+}
+
+func (n *notNode) match(item fs.Item, depth int) bool {
+	return !n.expr.match(item, depth)
+}
+
+type groupNode struct {
+	expr node
+}
+
+func (n *groupNode) sql(b *strings.Builder, args *[]any) {
+	b.WriteString("(")
+	n.expr.sql(b, args)
+	b.WriteString(")")
+}
+
+func (n *groupNode) match(item fs.Item, depth int) bool {
+	return n.expr.match(item, depth)
+}
+
+// compareNode compares a column with a number or a string
+type compareNode struct {
+	column string
+	op     string
+	value  any
+}
+
+func (n *compareNode) sql(b *strings.Builder, args *[]any) {
+	b.WriteString(n.column + " " + n.op + " ?")
+	*args = append(*args, n.value)
+}
+
+func (n *compareNode) match(item fs.Item, depth int) bool {
+	var c int
+	switch value := n.value.(type) {
+	case string:
+		c = strings.Compare(stringValue(item, n.column), value)
+	case int64:
+		c = compareInt(numberValue(item, n.column, depth), value)
+	}
+	switch n.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+// patternNode matches a string column with a glob pattern or a regular expression
+type patternNode struct {
+	column  string
+	pattern string
+	re      *regexp.Regexp
+	glob    bool
+	not     bool
+}
+
+func (n *patternNode) sql(b *strings.Builder, args *[]any) {
+	b.WriteString(n.column)
+	if n.not {
+		b.WriteString(" NOT")
+	}
+	if n.glob {
+		b.WriteString(" GLOB ?")
+	} else {
+		b.WriteString(" REGEXP ?")
+	}
+	*args = append(*args, n.pattern)
+}
+
+func (n *patternNode) match(item fs.Item, _ int) bool {
+	return n.re.MatchString(stringValue(item, n.column)) != n.not
+}
+
+// betweenNode checks that a number column is within the range, used for whole days
+type betweenNode struct {
+	column   string
+	from, to int64
+	not      bool
+}
+
+func (n *betweenNode) sql(b *strings.Builder, args *[]any) {
+	b.WriteString(n.column)
+	if n.not {
+		b.WriteString(" NOT")
+	}
+	b.WriteString(" BETWEEN ? AND ?")
+	*args = append(*args, n.from, n.to)
+}
+
+func (n *betweenNode) match(item fs.Item, depth int) bool {
+	value := numberValue(item, n.column, depth)
+	return (value >= n.from && value <= n.to) != n.not
+}
+
+func stringValue(item fs.Item, column string) string {
+	if column == "path" {
+		return item.GetPath()
+	}
+	return item.GetName()
+}
+
+func numberValue(item fs.Item, column string, depth int) int64 {
+	switch column {
+	case "size":
+		return item.GetSize()
+	case "usage":
+		return item.GetUsage()
+	case "item_count":
+		return item.GetItemCount()
+	case "depth":
+		return int64(depth)
+	case "mtime":
+		return item.GetMtime().Unix()
+	case "is_dir":
+		if item.IsDir() {
+			return 1
+		}
+		return 0
+	}
+	return 0
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+type tokenKind int
+
+const (
+	endToken tokenKind = iota
+	wordToken
+	stringToken
+	regexToken
+	opToken
+	openToken
+	closeToken
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var operators = []string{"!=", "<=", ">=", "!~", "==", "=", "<", ">", "~"}
+
+func isOpChar(r rune) bool {
+	return strings.ContainsRune("=!<>~", r)
+}
+
+// nolint:gocognit // Why: tokenizer handles all kinds of tokens in one place
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{openToken, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{closeToken, ")", i})
+			i++
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			tokens = append(tokens, token{stringToken, string(runes[i+1 : end]), i})
+			i = end + 1
+		case r == '/' && len(tokens) > 0 && isPatternOp(tokens[len(tokens)-1]):
+			// regular expression delimited by slashes, \/ stands for a slash
+			var pattern strings.Builder
+			end := i + 1
+			for ; end < len(runes) && runes[end] != '/'; end++ {
+				if runes[end] == '\\' && end+1 < len(runes) && runes[end+1] == '/' {
+					end++
+				}
+				pattern.WriteRune(runes[end])
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated regular expression at position %d", i)
+			}
+			tokens = append(tokens, token{regexToken, pattern.String(), i})
+			i = end + 1
+		case isOpChar(r):
+			rest := string(runes[i:])
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(rest, o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unknown operator at position %d", i)
+			}
+			tokens = append(tokens, token{opToken, op, i})
+			i += len(op)
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) &&
+				!isOpChar(runes[end]) && runes[end] != '(' && runes[end] != ')' {
+				end++
+			}
+			tokens = append(tokens, token{wordToken, string(runes[i:end]), i})
+			i = end
+		}
+	}
+	return append(tokens, token{endToken, "end of expression", len(runes)}), nil
+}
+
+func isPatternOp(tok token) bool {
+	return tok.kind == opToken && (tok.text == "~" || tok.text == "!~")
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	now    time.Time
+	loc    *time.Location
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != endToken {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == wordToken && strings.EqualFold(tok.text, keyword)
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "OR", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "AND", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.isKeyword("not") {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{expr: expr}, nil
+	}
+	if p.peek().kind == openToken {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.next(); tok.kind != closeToken {
+			return nil, fmt.Errorf("expected ')' at position %d, got %q", tok.pos, tok.text)
+		}
+		return &groupNode{expr: expr}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	name := p.next()
+	if name.kind != wordToken {
+		return nil, fmt.Errorf("expected field name at position %d, got %q", name.pos, name.text)
+	}
+	f, ok := fields[strings.ToLower(name.text)]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q at position %d", name.text, name.pos)
+	}
+	op := p.next()
+	if op.kind != opToken {
+		return nil, fmt.Errorf("expected operator after %s at position %d, got %q", name.text, op.pos, op.text)
+	}
+	// regular expressions are tokenized only after ~ and !~
+	value := p.next()
+	if value.kind != wordToken && value.kind != stringToken && value.kind != regexToken {
+		return nil, fmt.Errorf("expected value at position %d, got %q", value.pos, value.text)
+	}
+	if value.kind == regexToken && f.kind != stringField {
+		return nil, fmt.Errorf("%s can not be matched with regular expression", f.column)
+	}
+
+	opText := op.text
+	if opText == "==" {
+		opText = "="
+	}
+	switch f.kind {
+	case stringField:
+		return compareString(f.column, opText, value)
+	case typeField:
+		return compareType(f.column, opText, value.text)
+	case timeField:
+		return p.compareTime(f.column, opText, value.text)
+	default:
+		return compareNumber(f, opText, value.text)
+	}
+}
+
+func compareString(column, op string, value token) (node, error) {
+	if op != "~" && op != "!~" {
+		return &compareNode{column: column, op: op, value: value.text}, nil
+	}
+
+	n := &patternNode{column: column, pattern: value.text, glob: value.kind != regexToken, not: op == "!~"}
+	var err error
+	if n.glob {
+		n.re, err = regexp.Compile(globToRegexp(value.text))
+	} else {
+		n.re, err = regexp.Compile(value.text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", value.text, err)
+	}
+	return n, nil
+}
+
+func compareType(column, op, value string) (node, error) {
+	if op != "=" && op != "!=" {
+		return nil, fmt.Errorf("type can be compared only with = or !=")
+	}
+	switch strings.ToLower(value) {
+	case "dir", "d":
+		return &compareNode{column: column, op: op, value: int64(1)}, nil
+	case "file", "f":
+		return &compareNode{column: column, op: op, value: int64(0)}, nil
+	default:
+		return nil, fmt.Errorf("invalid type %q, use dir or file", value)
+	}
+}
+
+func compareNumber(f field, op, value string) (node, error) {
+	if op == "~" || op == "!~" {
+		return nil, fmt.Errorf("%s can not be matched with %s", f.column, op)
+	}
+	var (
+		number int64
+		err    error
+	)
+	if f.kind == sizeField {
+		number, err = ParseSize(value)
+	} else if number, err = strconv.ParseInt(value, 10, 64); err != nil {
+		err = fmt.Errorf("invalid number %q", value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &compareNode{column: f.column, op: op, value: number}, nil
+}
+
+// compareTime compares the modification time with a relative time (-30d) or a date.
+// Whole day is taken into account for dates, e.g. `mtime > 2026-01-01` means after the end of the day.
+func (p *parser) compareTime(column, op, value string) (node, error) {
+	if op == "~" || op == "!~" {
+		return nil, fmt.Errorf("mtime can not be matched with %s", op)
+	}
+	if duration, ok := strings.CutPrefix(value, "-"); ok {
+		at, err := timefilter.Ago(duration, p.now)
+		if err != nil {
+			return nil, err
+		}
+		return &compareNode{column: column, op: op, value: at.Unix()}, nil
+	}
+
+	start, err := timefilter.StartOf(value, p.loc)
+	if err != nil {
+		return nil, err
+	}
+	end, err := timefilter.EndOf(value, p.loc)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "<", ">=":
+		return &compareNode{column: column, op: op, value: start.Unix()}, nil
+	case ">", "<=":
+		return &compareNode{column: column, op: op, value: end.Unix()}, nil
+	default:
+		return &betweenNode{column: column, from: start.Unix(), to: end.Unix(), not: op == "!="}, nil
+	}
+}
+
+// globToRegexp converts glob pattern to regular expression with the semantics of SQLite GLOB,
+// i.e. * and ? match any characters including the path separator
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString(`(?s)^`)
+	runes := []rune(glob)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := i + 1
+			if end < len(runes) && runes[end] == '^' {
+				end++
+			}
+			if end < len(runes) && runes[end] == ']' {
+				end++
+			}
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				b.WriteString(regexp.QuoteMeta(string(r)))
+				continue
+			}
+			class := string(runes[i+1 : end])
+			class = strings.ReplaceAll(class, `\`, `\\`)
+			if strings.HasPrefix(class, "]") || strings.HasPrefix(class, "^]") {
+				class = strings.Replace(class, "]", `\]`, 1)
+			}
+			b.WriteString("[" + class + "]")
+			i = end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// ParseSize parses size with optional binary unit, e.g. 512, 10K, 1.5G or 2TiB
+func ParseSize(value string) (int64, error) {
+	m := sizeRe.FindStringSubmatch(strings.ToLower(value))
+	if m == nil {
+		return 0, fmt.Errorf("invalid size %q, use e.g. 512, 10K, 1.5G", value)
+	}
+	number, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, err
+	}
+	if m[2] != "" {
+		number *= math.Pow(1024, float64(strings.Index("kmgtpe", m[2])+1))
+	}
+	if number > math.MaxInt64 {
+		return 0, fmt.Errorf("size %q is too big", value)
+	}
+	return int64(number), nil
+}
@@ -0,0 +1,127 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+
+func TestIsSQL(t *testing.T) {
+	assert.True(t, IsSQL("SELECT * FROM files"))
+	assert.True(t, IsSQL("  with x as (select 1) select * from x"))
+	assert.False(t, IsSQL("size > 1G"))
+	assert.False(t, IsSQL("selected = 1"))
+	assert.False(t, IsSQL(""))
+}
+
+func TestParse(t *testing.T) {
+	expr, err := Parse(`size > 1G and mtime < -1y and name ~ '*.log'`, now, time.UTC)
+	require.NoError(t, err)
+	where, args := expr.SQL()
+	assert.Equal(t, "size > ? AND mtime < ? AND name GLOB ?", where)
+	assert.Equal(t, []any{int64(1 << 30), now.Add(-365 * 24 * time.Hour).Unix(), "*.log"}, args)
+}
+
+func TestParsePrecedence(t *testing.T) {
+	expr, err := Parse(`type = dir and (usage >= 10M or items > 1000) or not depth <= 2`, now, time.UTC)
+	require.NoError(t, err)
+	where, args := expr.SQL()
+	assert.Equal(t, "is_dir = ? AND (usage >= ? OR item_count > ?) OR NOT depth <= ?", where)
+	assert.Equal(t, []any{int64(1), int64(10 << 20), int64(1000), int64(2)}, args)
+}
+
+func TestParseStrings(t *testing.T) {
+	expr, err := Parse(`path !~ "*/node_modules/*" AND name == 'a b' and name!=x`, now, time.UTC)
+	require.NoError(t, err)
+	where, args := expr.SQL()
+	assert.Equal(t, "path NOT GLOB ? AND name = ? AND name != ?", where)
+	assert.Equal(t, []any{"*/node_modules/*", "a b", "x"}, args)
+}
+
+func TestParseDates(t *testing.T) {
+	loc := time.FixedZone("CEST", 2*3600)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, loc).Unix()
+	end := time.Date(2026, 1, 1, 23, 59, 59, 0, loc).Unix()
+
+	expr, err := Parse(`mtime < 2026-01-01 or mtime > 2026-01-01 or mtime = 2026-01-01`, now, loc)
+	require.NoError(t, err)
+	where, args := expr.SQL()
+	assert.Equal(t, "mtime < ? OR mtime > ? OR mtime BETWEEN ? AND ?", where)
+	assert.Equal(t, []any{start, end, start, end}, args)
+
+	expr, err = Parse(`mtime >= 2026-01-01T10:00:00Z`, now, loc)
+	require.NoError(t, err)
+	_, args = expr.SQL()
+	assert.Equal(t, []any{time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC).Unix()}, args)
+}
+
+func TestParseRegexp(t *testing.T) {
+	expr, err := Parse(`name ~ /^core\.[0-9]+$/ or path !~ /a\/(b|c)/`, now, time.UTC)
+	require.NoError(t, err)
+	where, args := expr.SQL()
+	assert.Equal(t, "name REGEXP ? OR path NOT REGEXP ?", where)
+	assert.Equal(t, []any{`^core\.[0-9]+$`, `a/(b|c)`}, args)
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	for glob, expected := range map[string]string{
+		"*.log":     `(?s)^.*\.log$`,
+		"file?.txt": `(?s)^file.\.txt$`,
+		"[a-c]*":    `(?s)^[a-c].*$`,
+		"[^]x]":     `(?s)^[^\]x]$`,
+		"a[":        `(?s)^a\[$`,
+	} {
+		assert.Equal(t, expected, globToRegexp(glob), glob)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for expr, msg := range map[string]string{
+		"":                    `expected field name at position 0, got "end of expression"`,
+		"owner = root":        `unknown field "owner"`,
+		"size 1G":             `expected operator after size`,
+		"size >":              `expected value`,
+		"size > big":          `invalid size "big"`,
+		"size ~ 1G":           `size can not be matched with ~`,
+		"name = 'x":           `unterminated string`,
+		"(size > 1":           `expected ')'`,
+		"size > 1 size < 2":   `unexpected "size"`,
+		"type = link":         `invalid type "link"`,
+		"type > dir":          `type can be compared only with = or !=`,
+		"mtime < -1 year":     `invalid duration format`,
+		"mtime < yesterday":   `invalid time value`,
+		"mtime ~ 2026-01-01":  `mtime can not be matched with ~`,
+		"items > 1K":          `invalid number "1K"`,
+		"depth =< 1":          `expected value`,
+		"size > 99999999999E": `too big`,
+		"size ~ /1/":          `size can not be matched with regular expression`,
+		"name ~ /x":           `unterminated regular expression`,
+		"name ~ /(/":          `invalid pattern "("`,
+	} {
+		_, err := Parse(expr, now, time.UTC)
+		assert.ErrorContains(t, err, msg, expr)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for value, expected := range map[string]int64{
+		"512":  512,
+		"10k":  10 << 10,
+		"10K":  10 << 10,
+		"1.5G": 3 << 29,
+		"2TiB": 2 << 40,
+		"1MB":  1 << 20,
+		"3b":   3,
+	} {
+		size, err := ParseSize(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, size, value)
+	}
+
+	_, err := ParseSize("1X")
+	assert.Error(t, err)
+}
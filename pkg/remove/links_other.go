@@ -0,0 +1,17 @@
+//go:build windows || plan9
+
+package remove
+
+import "os"
+
+// linkCount returns the number of hard links of the file,
+// which is not available here so files are taken as not linked
+func linkCount(_ os.FileInfo) uint64 {
+	return 1
+}
+
+// deviceID returns the ID of the device containing the file,
+// which is not available here so all files are taken as on one device
+func deviceID(_ os.FileInfo) uint64 {
+	return 0
+}
@@ -0,0 +1,24 @@
+//go:build !windows && !plan9
+
+package remove
+
+import (
+	"os"
+	"syscall"
+)
+
+// linkCount returns the number of hard links of the file
+func linkCount(info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Nlink) //nolint:unconvert // Nlink is not uint64 on all platforms
+	}
+	return 1
+}
+
+// deviceID returns the ID of the device containing the file
+func deviceID(info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Dev) //nolint:unconvert,gosec // Dev is not uint64 on all platforms
+	}
+	return 0
+}
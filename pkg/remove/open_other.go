@@ -0,0 +1,6 @@
+//go:build windows || plan9
+
+package remove
+
+// openNoFollow is not available here, the opened file is checked after opening
+const openNoFollow = 0
@@ -0,0 +1,8 @@
+//go:build !windows && !plan9
+
+package remove
+
+import "syscall"
+
+// openNoFollow makes opening fail on symlinks and keeps it from blocking on FIFOs
+const openNoFollow = syscall.O_NOFOLLOW | syscall.O_NONBLOCK
@@ -9,7 +9,7 @@ import (
 
 // ItemFromDir removes item from dir
 func ItemFromDir(dir, item fs.Item) error {
-	err := os.RemoveAll(item.GetPath())
+	err := Path(item.GetPath())
 	if err != nil {
 		return err
 	}
@@ -18,9 +18,14 @@ func ItemFromDir(dir, item fs.Item) error {
 	return nil
 }
 
+// Path removes the file or directory with all its content
+func Path(path string) error {
+	return os.RemoveAll(path)
+}
+
 // EmptyFileFromDir empties file from dir (truncates to 0 bytes)
 func EmptyFileFromDir(dir, file fs.Item) error {
-	err := os.Truncate(file.GetPath(), 0)
+	err := EmptyFile(file.GetPath())
 	if err != nil {
 		return err
 	}
@@ -37,3 +42,8 @@ func EmptyFileFromDir(dir, file fs.Item) error {
 	dir.AddFile(newFile)
 	return nil
 }
+
+// EmptyFile truncates the file to 0 bytes
+func EmptyFile(path string) error {
+	return os.Truncate(path, 0)
+}
@@ -0,0 +1,20 @@
+package remove
+
+import "os"
+
+// RenameNoReplace renames oldpath to newpath. Existing newpath is never replaced,
+// os.ErrExist is returned instead.
+func RenameNoReplace(oldpath, newpath string) error {
+	return renameNoReplace(oldpath, newpath)
+}
+
+// renameChecked renames oldpath to newpath if newpath does not exist.
+// It is used where the atomic no-replace rename is not available.
+func renameChecked(oldpath, newpath string) error {
+	if _, err := os.Lstat(newpath); err == nil {
+		return os.ErrExist
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	return os.Rename(oldpath, newpath)
+}
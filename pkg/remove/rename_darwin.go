@@ -0,0 +1,20 @@
+//go:build darwin
+
+package remove
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func renameNoReplace(oldpath, newpath string) error {
+	err := unix.RenamexNp(oldpath, newpath, unix.RENAME_EXCL)
+	switch err {
+	case unix.EEXIST:
+		return os.ErrExist
+	case unix.ENOTSUP:
+		return renameChecked(oldpath, newpath)
+	}
+	return err
+}
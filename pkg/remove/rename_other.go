@@ -0,0 +1,7 @@
+//go:build !windows && !linux && !darwin
+
+package remove
+
+func renameNoReplace(oldpath, newpath string) error {
+	return renameChecked(oldpath, newpath)
+}
@@ -0,0 +1,29 @@
+package remove
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenameNoReplace(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "source")
+	dst := filepath.Join(root, "destination")
+	require.NoError(t, os.WriteFile(src, []byte("source"), 0o600))
+	require.NoError(t, os.WriteFile(dst, []byte("destination"), 0o600))
+
+	err := RenameNoReplace(src, dst)
+	assert.ErrorIs(t, err, os.ErrExist)
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "destination", string(data))
+
+	require.NoError(t, os.Remove(dst))
+	require.NoError(t, RenameNoReplace(src, dst))
+	assert.NoFileExists(t, src)
+	assert.FileExists(t, dst)
+}
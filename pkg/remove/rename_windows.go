@@ -0,0 +1,29 @@
+//go:build windows
+
+package remove
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+func renameNoReplace(oldpath, newpath string) error {
+	from, err := windows.UTF16PtrFromString(oldpath)
+	if err != nil {
+		return err
+	}
+	to, err := windows.UTF16PtrFromString(newpath)
+	if err != nil {
+		return err
+	}
+	// without MOVEFILE_REPLACE_EXISTING an existing target is kept
+	err = windows.MoveFileEx(from, to, 0)
+	switch err {
+	case nil:
+		return nil
+	case windows.ERROR_ALREADY_EXISTS, windows.ERROR_FILE_EXISTS:
+		return os.ErrExist
+	}
+	return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: err}
+}
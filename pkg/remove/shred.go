@@ -0,0 +1,205 @@
+package remove
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	gfs "github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// DefaultShredPasses is the number of overwrite passes used when none is configured
+const DefaultShredPasses = 3
+
+// ErrCopyOnWrite is returned when shredding on a copy-on-write filesystem is not forced
+var ErrCopyOnWrite = errors.New(
+	"overwriting files is not effective on copy-on-write filesystem, force shredding to do it anyway",
+)
+
+const shredBufferSize = 64 * 1024
+
+// ErrHardLinked is returned when a file to shred has other hard links and shredding is not forced
+var ErrHardLinked = errors.New(
+	"file has other hard links which would be overwritten too, force shredding to do it anyway",
+)
+
+// ErrFileChanged is returned when a file was replaced after it was walked and before it was overwritten
+var ErrFileChanged = errors.New("file changed before it could be overwritten")
+
+// ErrNoShredPasses is returned when the number of overwrite passes is not positive
+var ErrNoShredPasses = errors.New("number of shred passes must be positive")
+
+// checkCopyOnWrite reports whether the path is on a copy-on-write filesystem
+var checkCopyOnWrite = isCopyOnWrite
+
+// Shredder overwrites contents of files before removing them
+type Shredder struct {
+	// Passes is the number of times the file contents are overwritten with random data
+	Passes int
+	// Force allows shredding on copy-on-write filesystems where overwriting is not effective
+	// and shredding of files with other hard links, which are overwritten too
+	Force bool
+	// Parallel shreds items of directories in parallel
+	Parallel bool
+}
+
+// ItemFromDir overwrites all files of the item and removes it from dir
+func (s Shredder) ItemFromDir(dir, item gfs.Item) error {
+	path := item.GetPath()
+	if s.Passes < 1 {
+		return ErrNoShredPasses
+	}
+	if !s.Force {
+		// check all files before anything is overwritten
+		if err := checkShreddable(path); err != nil {
+			return err
+		}
+	}
+
+	if s.Parallel && item.IsDir() {
+		if err := s.shredFilesParallel(item); err != nil {
+			return err
+		}
+	} else if err := s.shredPath(path); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		return err
+	}
+
+	dir.RemoveFile(item)
+	return nil
+}
+
+func (s Shredder) shredFilesParallel(item gfs.Item) error {
+	errChan := make(chan error, 1) // we show only first error
+	var wait sync.WaitGroup
+
+	for file := range item.GetFilesLocked(gfs.SortBySize, gfs.SortDesc) {
+		wait.Add(1)
+		go func(itemPath string) {
+			concurrencyLimit <- struct{}{}
+			defer func() { <-concurrencyLimit }()
+
+			if err := s.shredPath(itemPath); err != nil {
+				select {
+				// write error to channel if it's empty
+				case errChan <- err:
+				default:
+				}
+			}
+			wait.Done()
+		}(file.GetPath())
+	}
+
+	wait.Wait()
+
+	select {
+	case err := <-errChan:
+		return err
+	default:
+	}
+	return nil
+}
+
+// shredPath overwrites all regular files in the path, symlinks are not followed
+func (s Shredder) shredPath(path string) error {
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return s.overwriteFile(p, info)
+	})
+}
+
+// checkShreddable returns ErrCopyOnWrite if any part of the path is on a copy-on-write filesystem
+// and ErrHardLinked if any regular file in the path has other hard links.
+// Filesystem is checked once for every device found, so nested mounts are checked too.
+func checkShreddable(path string) error {
+	checkedDevices := make(map[uint64]struct{})
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if dev := deviceID(info); !hasDevice(checkedDevices, dev) {
+			cow, err := checkCopyOnWrite(p)
+			if err != nil {
+				return err
+			}
+			if cow {
+				return fmt.Errorf("%s: %w", p, ErrCopyOnWrite)
+			}
+			checkedDevices[dev] = struct{}{}
+		}
+		if !d.IsDir() && linkCount(info) > 1 {
+			return fmt.Errorf("%s: %w", p, ErrHardLinked)
+		}
+		return nil
+	})
+}
+
+func hasDevice(devices map[uint64]struct{}, dev uint64) bool {
+	_, ok := devices[dev]
+	return ok
+}
+
+// overwriteFile overwrites the file found by the walk, symlinks are not followed
+// and nothing is written when the path points to a different file now
+func (s Shredder) overwriteFile(path string, walked os.FileInfo) (err error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|openNoFollow, 0)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() || !os.SameFile(info, walked) {
+		return fmt.Errorf("%s: %w", path, ErrFileChanged)
+	}
+
+	buf := make([]byte, shredBufferSize)
+	for range s.Passes {
+		var offset int64
+		for offset < info.Size() {
+			chunk := buf[:min(int64(len(buf)), info.Size()-offset)]
+			if _, err := rand.Read(chunk); err != nil {
+				return err
+			}
+			n, err := file.WriteAt(chunk, offset)
+			if err != nil {
+				return err
+			}
+			offset += int64(n)
+		}
+		if err := file.Sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package remove
+
+import "golang.org/x/sys/unix"
+
+func isCopyOnWrite(path string) (bool, error) {
+	info := &unix.Statfs_t{}
+	if err := unix.Statfs(path, info); err != nil {
+		return false, err
+	}
+	switch unix.ByteSliceToString(info.Fstypename[:]) {
+	case "apfs", "zfs":
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,20 @@
+package remove
+
+import "golang.org/x/sys/unix"
+
+// magic numbers of copy-on-write filesystems (see statfs(2))
+var copyOnWriteFilesystems = map[uint32]struct{}{
+	unix.BTRFS_SUPER_MAGIC: {},
+	0x2fc12fc1:             {}, // ZFS
+	0xca451a4e:             {}, // bcachefs
+	unix.XFS_SUPER_MAGIC:   {}, // reflinks are enabled by default
+}
+
+func isCopyOnWrite(path string) (bool, error) {
+	info := &unix.Statfs_t{}
+	if err := unix.Statfs(path, info); err != nil {
+		return false, err
+	}
+	_, ok := copyOnWriteFilesystems[uint32(info.Type)] //nolint:gosec // magic numbers fit into 32 bits
+	return ok, nil
+}
@@ -0,0 +1,67 @@
+//go:build linux
+
+package remove
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsCopyOnWrite(t *testing.T) {
+	_, err := isCopyOnWrite(t.TempDir())
+	assert.NoError(t, err)
+
+	_, err = isCopyOnWrite("/nonexistent/path")
+	assert.Error(t, err)
+}
+
+func TestShredHardLinked(t *testing.T) {
+	mockCopyOnWrite(t, false, nil)
+	dir, nested, _, link := createShredDir(t)
+
+	err := Shredder{Passes: 1}.ItemFromDir(dir, nested)
+	assert.ErrorIs(t, err, ErrHardLinked)
+	assert.ErrorContains(t, err, "nested/sub/file")
+
+	// nothing is overwritten, not even the files without other links
+	assert.DirExists(t, nested.GetPath())
+	assert.Len(t, dir.Files, 1)
+	data, err := os.ReadFile(link)
+	require.NoError(t, err)
+	assert.Equal(t, "secret content", string(data))
+	data, err = os.ReadFile(nested.GetPath() + "/file2")
+	require.NoError(t, err)
+	assert.Equal(t, "secret content", string(data))
+}
+
+func TestShredHardLinkedParallel(t *testing.T) {
+	mockCopyOnWrite(t, false, nil)
+	dir, nested, _, link := createShredDir(t)
+
+	err := Shredder{Passes: 1, Parallel: true}.ItemFromDir(dir, nested)
+	assert.ErrorIs(t, err, ErrHardLinked)
+
+	data, err := os.ReadFile(link)
+	require.NoError(t, err)
+	assert.Equal(t, "secret content", string(data))
+}
+
+func TestOverwriteSymlink(t *testing.T) {
+	_, nested, file, _ := createShredDir(t)
+	walked, err := os.Lstat(file.GetPath())
+	require.NoError(t, err)
+
+	// the file is replaced by a symlink to itself after it was walked
+	target := nested.GetPath() + "/target"
+	require.NoError(t, os.Rename(file.GetPath(), target))
+	require.NoError(t, os.Symlink(target, file.GetPath()))
+
+	err = Shredder{Passes: 1}.overwriteFile(file.GetPath(), walked)
+	assert.Error(t, err)
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "secret content", string(data))
+}
@@ -0,0 +1,7 @@
+//go:build !linux && !darwin
+
+package remove
+
+func isCopyOnWrite(path string) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,166 @@
+package remove
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func mockCopyOnWrite(t *testing.T, cow bool, err error) {
+	t.Helper()
+	original := checkCopyOnWrite
+	t.Cleanup(func() { checkCopyOnWrite = original })
+	checkCopyOnWrite = func(string) (bool, error) { return cow, err }
+}
+
+func createShredDir(t *testing.T) (dir, nested *analyze.Dir, file *analyze.File, link string) {
+	t.Helper()
+	base := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(base, "nested", "sub"), 0o755))
+	content := []byte("secret content")
+	require.NoError(t, os.WriteFile(filepath.Join(base, "nested", "sub", "file"), content, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(base, "nested", "file2"), content, 0o600))
+
+	// hard link keeps the overwritten data reachable after the removal
+	link = filepath.Join(base, "link")
+	require.NoError(t, os.Link(filepath.Join(base, "nested", "sub", "file"), link))
+
+	dir = &analyze.Dir{
+		File:     &analyze.File{Name: filepath.Base(base)},
+		BasePath: filepath.Dir(base),
+	}
+	nested = &analyze.Dir{
+		File: &analyze.File{Name: "nested", Parent: dir},
+	}
+	sub := &analyze.Dir{
+		File: &analyze.File{Name: "sub", Parent: nested},
+	}
+	file = &analyze.File{Name: "file2", Size: int64(len(content)), Parent: nested}
+	dir.Files = fs.Files{nested}
+	nested.Files = fs.Files{sub, file}
+	sub.Files = fs.Files{&analyze.File{Name: "file", Size: int64(len(content)), Parent: sub}}
+	return dir, nested, file, link
+}
+
+func TestShredDir(t *testing.T) {
+	mockCopyOnWrite(t, false, nil)
+	dir, nested, _, link := createShredDir(t)
+
+	// forced to overwrite the hard linked file, so the data can be checked through the link
+	err := Shredder{Passes: 2, Force: true}.ItemFromDir(dir, nested)
+	require.NoError(t, err)
+
+	assert.NoDirExists(t, nested.GetPath())
+	assert.Empty(t, dir.Files)
+
+	data, err := os.ReadFile(link)
+	require.NoError(t, err)
+	assert.Len(t, data, len("secret content"))
+	assert.NotEqual(t, "secret content", string(data))
+}
+
+func TestShredDirParallel(t *testing.T) {
+	mockCopyOnWrite(t, false, nil)
+	dir, nested, _, link := createShredDir(t)
+
+	err := Shredder{Passes: 1, Force: true, Parallel: true}.ItemFromDir(dir, nested)
+	require.NoError(t, err)
+
+	assert.NoDirExists(t, nested.GetPath())
+	data, err := os.ReadFile(link)
+	require.NoError(t, err)
+	assert.NotEqual(t, "secret content", string(data))
+}
+
+func TestShredFile(t *testing.T) {
+	mockCopyOnWrite(t, false, nil)
+	_, nested, file, _ := createShredDir(t)
+
+	err := Shredder{Passes: 1}.ItemFromDir(nested, file)
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, file.GetPath())
+	assert.Len(t, nested.Files, 1)
+}
+
+func TestShredCopyOnWrite(t *testing.T) {
+	mockCopyOnWrite(t, true, nil)
+	_, nested, file, _ := createShredDir(t)
+
+	err := Shredder{Passes: 1}.ItemFromDir(nested, file)
+	assert.ErrorIs(t, err, ErrCopyOnWrite)
+	assert.FileExists(t, file.GetPath())
+
+	err = Shredder{Passes: 1, Force: true}.ItemFromDir(nested, file)
+	assert.NoError(t, err)
+	assert.NoFileExists(t, file.GetPath())
+}
+
+func TestShredCopyOnWriteCheckError(t *testing.T) {
+	mockCopyOnWrite(t, false, errors.New("statfs failed"))
+	_, nested, file, _ := createShredDir(t)
+
+	err := Shredder{Passes: 1}.ItemFromDir(nested, file)
+	assert.EqualError(t, err, "statfs failed")
+	assert.FileExists(t, file.GetPath())
+}
+
+func TestShredNonExistingFile(t *testing.T) {
+	mockCopyOnWrite(t, false, nil)
+	dir := &analyze.Dir{
+		File:     &analyze.File{Name: "missing"},
+		BasePath: t.TempDir(),
+	}
+	file := &analyze.File{Name: "file", Parent: dir}
+
+	err := Shredder{Passes: 1}.ItemFromDir(dir, file)
+	assert.Error(t, err)
+}
+
+func TestShredWithoutPasses(t *testing.T) {
+	mockCopyOnWrite(t, false, nil)
+	_, nested, file, _ := createShredDir(t)
+
+	err := Shredder{}.ItemFromDir(nested, file)
+	assert.ErrorIs(t, err, ErrNoShredPasses)
+	assert.FileExists(t, file.GetPath())
+}
+
+func TestShredChecksCopyOnWriteOncePerDevice(t *testing.T) {
+	var checked []string
+	original := checkCopyOnWrite
+	t.Cleanup(func() { checkCopyOnWrite = original })
+	checkCopyOnWrite = func(path string) (bool, error) {
+		checked = append(checked, path)
+		return false, nil
+	}
+	_, nested, _, link := createShredDir(t)
+	require.NoError(t, os.Remove(link))
+
+	require.NoError(t, checkShreddable(nested.GetPath()))
+	assert.Equal(t, []string{nested.GetPath()}, checked)
+}
+
+func TestOverwriteReplacedFile(t *testing.T) {
+	_, nested, file, _ := createShredDir(t)
+	walked, err := os.Lstat(file.GetPath())
+	require.NoError(t, err)
+
+	// the file is replaced after it was walked
+	replacement := filepath.Join(nested.GetPath(), "replacement")
+	require.NoError(t, os.WriteFile(replacement, []byte("other content!"), 0o600))
+	require.NoError(t, os.Rename(replacement, file.GetPath()))
+
+	err = Shredder{Passes: 1}.overwriteFile(file.GetPath(), walked)
+	assert.ErrorIs(t, err, ErrFileChanged)
+	data, err := os.ReadFile(file.GetPath())
+	require.NoError(t, err)
+	assert.Equal(t, "other content!", string(data))
+}
@@ -62,6 +62,15 @@ var trashOS = trashOSOps{
 
 // MoveItemToTrash moves item into the XDG trash and updates the in-memory dir tree.
 func MoveItemToTrash(dir, item fs.Item) error {
+	if err := MovePathToTrash(item.GetPath()); err != nil {
+		return err
+	}
+	dir.RemoveFile(item)
+	return nil
+}
+
+// MovePathToTrash moves the file or directory into the XDG trash.
+func MovePathToTrash(path string) error {
 	trashRoot, err := trashDir()
 	if err != nil {
 		return err
@@ -75,13 +84,14 @@ func MoveItemToTrash(dir, item fs.Item) error {
 		return err
 	}
 
-	absSrc, err := trashOS.abs(item.GetPath())
+	absSrc, err := trashOS.abs(path)
 	if err != nil {
 		return err
 	}
+	name := filepath.Base(absSrc)
 
 	for range 10001 {
-		destName, infoPath, err := reserveTrashInfo(filesDir, infoDir, item.GetName(), absSrc)
+		destName, infoPath, err := reserveTrashInfo(filesDir, infoDir, name, absSrc)
 		if err != nil {
 			return err
 		}
@@ -95,12 +105,10 @@ func MoveItemToTrash(dir, item fs.Item) error {
 			}
 			return err
 		}
-
-		dir.RemoveFile(item)
 		return nil
 	}
 
-	return fmt.Errorf("could not find unique trash name for %s", item.GetName())
+	return fmt.Errorf("could not find unique trash name for %s", name)
 }
 
 func trashDir() (string, error) {
@@ -200,7 +208,10 @@ func movePath(src, dst string) error {
 		return err
 	}
 	if err := copyRecursively(src, dst); err != nil {
-		_ = trashOS.removeAll(dst) //nolint:errcheck // Best-effort rollback.
+		// An existing destination was not created by us, keep it.
+		if !os.IsExist(err) {
+			_ = trashOS.removeAll(dst) //nolint:errcheck // Best-effort rollback.
+		}
 		return err
 	}
 	return trashOS.removeAll(src)
@@ -0,0 +1,191 @@
+//go:build !windows && !darwin
+
+package remove
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ListTrash lists items from the home trash and from the trash directories
+// (.Trash/$uid and .Trash-$uid) on top of the given mount points.
+// Items are sorted by deletion date, the most recently deleted first.
+func ListTrash(topDirs []string) ([]TrashedItem, error) {
+	home, err := trashDir()
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := listTrashDir(home, "")
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	uid := strconv.Itoa(os.Getuid())
+	for _, topDir := range topDirs {
+		for _, root := range []string{
+			filepath.Join(topDir, ".Trash", uid),
+			filepath.Join(topDir, ".Trash-"+uid),
+		} {
+			if root == home {
+				continue
+			}
+			mountItems, err := listTrashDir(root, topDir)
+			if err != nil {
+				continue
+			}
+			items = append(items, mountItems...)
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletionDate.After(items[j].DeletionDate)
+	})
+	return items, nil
+}
+
+// listTrashDir lists items of one trash directory. Relative original paths
+// (used by per-mount trash directories) are resolved against topDir.
+func listTrashDir(root, topDir string) ([]TrashedItem, error) {
+	entries, err := os.ReadDir(filepath.Join(root, "info"))
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]TrashedItem, 0, len(entries))
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".trashinfo")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		item := TrashedItem{Name: name, TrashDir: root}
+		if err := readTrashInfo(item.InfoPath(), topDir, &item); err != nil {
+			continue
+		}
+		info, err := os.Lstat(item.FilesPath())
+		if err != nil {
+			continue
+		}
+		item.IsDir = info.IsDir()
+		item.Size = pathSize(item.FilesPath(), info)
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func readTrashInfo(path, topDir string, item *TrashedItem) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "Path":
+			originalPath, err := url.PathUnescape(value)
+			if err != nil {
+				return err
+			}
+			if !filepath.IsAbs(originalPath) {
+				originalPath, err = resolveTrashPath(topDir, originalPath)
+				if err != nil {
+					return fmt.Errorf("%w in %s", err, path)
+				}
+			}
+			item.OriginalPath = originalPath
+		case "DeletionDate":
+			if date, err := time.ParseInLocation("2006-01-02T15:04:05", value, time.Local); err == nil {
+				item.DeletionDate = date
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if item.OriginalPath == "" {
+		return fmt.Errorf("missing original path in %s", path)
+	}
+	return nil
+}
+
+// resolveTrashPath joins the relative original path with topDir.
+// Paths which would leave topDir are rejected.
+func resolveTrashPath(topDir, relPath string) (string, error) {
+	if topDir == "" {
+		return "", fmt.Errorf("relative original path %q", relPath)
+	}
+	joined := filepath.Join(topDir, relPath)
+	rel, err := filepath.Rel(topDir, joined)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("original path %q outside of %s", relPath, topDir)
+	}
+	return joined, nil
+}
+
+func pathSize(path string, info os.FileInfo) int64 {
+	if !info.IsDir() {
+		return info.Size()
+	}
+	var size int64
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if fi, err := d.Info(); err == nil && !fi.IsDir() {
+			size += fi.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// RestoreFromTrash moves the trashed item to the target path and removes its .trashinfo file.
+// ErrRestoreConflict is returned when the target path already exists.
+// The target is never replaced, even when it is created concurrently.
+func RestoreFromTrash(item TrashedItem, target string) error {
+	if err := trashOS.mkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if err := movePath(item.FilesPath(), target); err != nil {
+		if os.IsExist(err) {
+			return ErrRestoreConflict
+		}
+		return err
+	}
+	return trashOS.remove(item.InfoPath())
+}
+
+// FreeRestorePath returns a path next to the given one which does not exist yet
+func FreeRestorePath(path string) string {
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s.restored", path)
+		if i > 1 {
+			candidate = fmt.Sprintf("%s.restored.%d", path, i)
+		}
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// PurgeFromTrash removes the trashed item permanently
+func PurgeFromTrash(item TrashedItem) error {
+	if err := trashOS.removeAll(item.FilesPath()); err != nil {
+		return err
+	}
+	return trashOS.remove(item.InfoPath())
+}
@@ -0,0 +1,27 @@
+//go:build windows || darwin
+
+package remove
+
+import "errors"
+
+var errTrashNotSupported = errors.New("browsing trash is not supported on this platform")
+
+// ListTrash is not supported on this platform
+func ListTrash(topDirs []string) ([]TrashedItem, error) {
+	return nil, errTrashNotSupported
+}
+
+// RestoreFromTrash is not supported on this platform
+func RestoreFromTrash(item TrashedItem, target string) error {
+	return errTrashNotSupported
+}
+
+// FreeRestorePath returns a path next to the given one
+func FreeRestorePath(path string) string {
+	return path + ".restored"
+}
+
+// PurgeFromTrash is not supported on this platform
+func PurgeFromTrash(item TrashedItem) error {
+	return errTrashNotSupported
+}
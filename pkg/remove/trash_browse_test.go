@@ -0,0 +1,192 @@
+//go:build !windows && !darwin
+
+package remove
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+)
+
+func writeTrashed(t *testing.T, root, name, path, date, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "files"), 0o700))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "info"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "files", name), []byte(content), 0o600))
+	info := "[Trash Info]\nPath=" + path + "\nDeletionDate=" + date + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(root, "info", name+".trashinfo"), []byte(info), 0o600))
+}
+
+func TestListTrash(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", xdg)
+	mount := t.TempDir()
+
+	writeTrashed(t, filepath.Join(xdg, "Trash"), "a", "/tmp/with%20space", "2024-01-02T10:00:00", "abc")
+	writeTrashed(t, filepath.Join(mount, ".Trash-"+strconv.Itoa(os.Getuid())), "b", "dir/b", "2024-03-02T10:00:00", "abcdef")
+	// info file without the trashed item is ignored
+	require.NoError(t, os.WriteFile(filepath.Join(xdg, "Trash", "info", "c.trashinfo"), []byte("[Trash Info]\nPath=/c\n"), 0o600))
+
+	items, err := ListTrash([]string{mount})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	assert.Equal(t, filepath.Join(mount, "dir/b"), items[0].OriginalPath)
+	assert.Equal(t, int64(6), items[0].Size)
+	assert.Equal(t, "/tmp/with space", items[1].OriginalPath)
+	assert.Equal(t, int64(3), items[1].Size)
+	assert.Equal(t, time.Date(2024, 1, 2, 10, 0, 0, 0, time.Local), items[1].DeletionDate)
+	assert.False(t, items[1].IsDir)
+}
+
+func TestListTrashEmpty(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	items, err := ListTrash(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestListTrashDirSize(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", xdg)
+	root := filepath.Join(xdg, "Trash")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "files", "d", "nested"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "files", "d", "nested", "f"), []byte("12345"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "info"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "info", "d.trashinfo"), []byte("[Trash Info]\nPath=/d\n"), 0o600))
+
+	items, err := ListTrash(nil)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.True(t, items[0].IsDir)
+	assert.Equal(t, int64(5), items[0].Size)
+}
+
+func TestRestoreFromTrash(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", xdg)
+	target := filepath.Join(t.TempDir(), "sub", "file")
+
+	writeTrashed(t, filepath.Join(xdg, "Trash"), "file", escapeTrashPath(target), "2024-01-02T10:00:00", "abc")
+	items, err := ListTrash(nil)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	require.NoError(t, RestoreFromTrash(items[0], items[0].OriginalPath))
+
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", string(data))
+	_, err = os.Stat(items[0].InfoPath())
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRestoreFromTrashConflict(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", xdg)
+	target := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(target, []byte("new"), 0o600))
+
+	writeTrashed(t, filepath.Join(xdg, "Trash"), "file", escapeTrashPath(target), "2024-01-02T10:00:00", "old")
+	items, err := ListTrash(nil)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	err = RestoreFromTrash(items[0], target)
+	assert.ErrorIs(t, err, ErrRestoreConflict)
+
+	alternative := FreeRestorePath(target)
+	assert.Equal(t, target+".restored", alternative)
+	require.NoError(t, RestoreFromTrash(items[0], alternative))
+
+	data, err := os.ReadFile(alternative)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(data))
+	data, err = os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+}
+
+func TestListTrashSkipsPathsOutsideTopDir(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	top := t.TempDir()
+	root := filepath.Join(top, ".Trash-"+strconv.Itoa(os.Getuid()))
+
+	writeTrashed(t, root, "a", "dir/a", "2024-01-02T10:00:00", "a")
+	writeTrashed(t, root, "b", "../../etc/b", "2024-01-02T10:00:00", "b")
+	writeTrashed(t, root, "c", "dir/../../c", "2024-01-02T10:00:00", "c")
+	writeTrashed(t, root, "d", "..", "2024-01-02T10:00:00", "d")
+
+	items, err := ListTrash([]string{top})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, filepath.Join(top, "dir", "a"), items[0].OriginalPath)
+}
+
+func TestResolveTrashPathWithoutTopDir(t *testing.T) {
+	_, err := resolveTrashPath("", "a")
+	assert.Error(t, err)
+}
+
+func TestFreeRestorePath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(path+".restored", nil, 0o600))
+
+	assert.Equal(t, path+".restored.2", FreeRestorePath(path))
+}
+
+func TestPurgeFromTrash(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", xdg)
+
+	writeTrashed(t, filepath.Join(xdg, "Trash"), "file", "/file", "2024-01-02T10:00:00", "abc")
+	items, err := ListTrash(nil)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	require.NoError(t, PurgeFromTrash(items[0]))
+
+	items, err = ListTrash(nil)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestPurgeFromTrashWithErr(t *testing.T) {
+	mockTrashOS(t, func(ops *trashOSOps) {
+		ops.removeAll = func(string) error { return errors.New("boom") }
+	})
+
+	err := PurgeFromTrash(TrashedItem{Name: "x", TrashDir: t.TempDir()})
+	assert.EqualError(t, err, "boom")
+}
+
+func TestMoveItemToTrashAndList(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", xdg)
+	base := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(base, "file"), []byte("abcd"), 0o600))
+
+	dir := &analyze.Dir{
+		File:     &analyze.File{Name: filepath.Base(base)},
+		BasePath: filepath.Dir(base),
+	}
+	file := &analyze.File{Name: "file", Size: 4, Parent: dir}
+
+	require.NoError(t, MoveItemToTrash(dir, file))
+
+	items, err := ListTrash(nil)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, filepath.Join(base, "file"), items[0].OriginalPath)
+	assert.Equal(t, int64(4), items[0].Size)
+}
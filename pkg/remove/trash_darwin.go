@@ -11,5 +11,10 @@ import (
 // MoveItemToTrash is not supported on macOS. The XDG trash location is ignored
 // by Finder, so use permanent delete (d) or empty (e) instead.
 func MoveItemToTrash(dir, item fs.Item) error {
+	return MovePathToTrash(item.GetPath())
+}
+
+// MovePathToTrash is not supported on macOS.
+func MovePathToTrash(path string) error {
 	return fmt.Errorf("move to trash is not supported on macOS")
 }
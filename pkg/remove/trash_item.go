@@ -0,0 +1,32 @@
+package remove
+
+import (
+	"errors"
+	"path/filepath"
+	"time"
+)
+
+// ErrRestoreConflict is returned when the original path of a trashed item is occupied
+var ErrRestoreConflict = errors.New("original path already exists")
+
+// TrashedItem is an item stored in a trash directory
+type TrashedItem struct {
+	// Name is the name of the item in the files directory of the trash
+	Name string
+	// TrashDir is the root of the trash directory containing the item
+	TrashDir     string
+	OriginalPath string
+	DeletionDate time.Time
+	Size         int64
+	IsDir        bool
+}
+
+// FilesPath returns path of the trashed item itself
+func (t TrashedItem) FilesPath() string {
+	return filepath.Join(t.TrashDir, "files", t.Name)
+}
+
+// InfoPath returns path of the .trashinfo file of the item
+func (t TrashedItem) InfoPath() string {
+	return filepath.Join(t.TrashDir, "info", t.Name+".trashinfo")
+}
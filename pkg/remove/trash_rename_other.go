@@ -1,14 +0,0 @@
-//go:build !windows && !linux && !darwin
-
-package remove
-
-import "os"
-
-func renameNoReplace(oldpath, newpath string) error {
-	if _, err := os.Lstat(newpath); err == nil {
-		return os.ErrExist
-	} else if !os.IsNotExist(err) {
-		return err
-	}
-	return os.Rename(oldpath, newpath)
-}
@@ -440,3 +440,26 @@ func TestCopyRecursivelyMkdirFailsWhenDestinationIsFile(t *testing.T) {
 	err := copyRecursively(src, dst)
 	require.Error(t, err)
 }
+
+func TestMovePathFallbackKeepsExistingDestination(t *testing.T) {
+	mockTrashOS(t, func(ops *trashOSOps) {
+		ops.rename = func(oldpath, newpath string) error {
+			return &os.PathError{Op: "rename", Path: oldpath, Err: syscall.EXDEV}
+		}
+	})
+
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	dst := filepath.Join(root, "dst")
+	require.NoError(t, os.WriteFile(src, []byte("payload"), 0o600))
+	require.NoError(t, os.WriteFile(dst, []byte("existing"), 0o600))
+
+	err := movePath(src, dst)
+	assert.True(t, os.IsExist(err))
+
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "existing", string(data))
+	_, err = os.Stat(src)
+	assert.NoError(t, err)
+}
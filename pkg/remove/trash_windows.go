@@ -10,5 +10,10 @@ import (
 
 // MoveItemToTrash is not supported on Windows; use Unix XDG trash builds instead.
 func MoveItemToTrash(dir, item fs.Item) error {
+	return MovePathToTrash(item.GetPath())
+}
+
+// MovePathToTrash is not supported on Windows.
+func MovePathToTrash(path string) error {
 	return fmt.Errorf("move to trash is not supported on Windows")
 }
@@ -226,6 +226,47 @@ func parseDuration(input string) (time.Duration, error) {
 	return total, nil
 }
 
+// EndOf returns the latest instant described by the time value,
+// which is the end of the day for date-only values (YYYY-MM-DD)
+func EndOf(arg string, loc *time.Location) (time.Time, error) {
+	bound, err := parseTimeValue(arg, loc)
+	switch {
+	case err != nil:
+		return time.Time{}, err
+	case bound.instant != nil:
+		return *bound.instant, nil
+	case bound.dateOnly != nil:
+		return bound.dateOnly.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	default:
+		return time.Time{}, fmt.Errorf("empty time value")
+	}
+}
+
+// StartOf returns the earliest instant described by the time value,
+// which is the local midnight for date-only values (YYYY-MM-DD)
+func StartOf(arg string, loc *time.Location) (time.Time, error) {
+	bound, err := parseTimeValue(arg, loc)
+	switch {
+	case err != nil:
+		return time.Time{}, err
+	case bound.instant != nil:
+		return *bound.instant, nil
+	case bound.dateOnly != nil:
+		return *bound.dateOnly, nil
+	default:
+		return time.Time{}, fmt.Errorf("empty time value")
+	}
+}
+
+// Ago returns the instant the duration (e.g. 7d, 1y2mo) before now
+func Ago(duration string, now time.Time) (time.Time, error) {
+	d, err := parseDuration(duration)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return now.Add(-d), nil
+}
+
 // parseTimeValue parses a time value into either a timestamp instant or a date-only value
 func parseTimeValue(arg string, loc *time.Location) (TimeBound, error) {
 	if arg == "" {
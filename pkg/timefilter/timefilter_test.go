@@ -809,3 +809,70 @@ func TestIncludeByTimeBound(t *testing.T) {
 		})
 	}
 }
+
+func TestEndOf(t *testing.T) {
+	loc := time.UTC
+
+	end, err := EndOf("2026-09-01", loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2026, 9, 1, 23, 59, 59, 999999999, loc); !end.Equal(want) {
+		t.Errorf("expected %s, got %s", want, end)
+	}
+
+	end, err = EndOf("2026-09-01T10:00:00Z", loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC); !end.Equal(want) {
+		t.Errorf("expected %s, got %s", want, end)
+	}
+
+	if _, err := EndOf("yesterday", loc); err == nil {
+		t.Error("expected error for invalid value")
+	}
+	if _, err := EndOf("", loc); err == nil {
+		t.Error("expected error for empty value")
+	}
+}
+
+func TestStartOf(t *testing.T) {
+	loc := time.FixedZone("CEST", 2*3600)
+
+	start, err := StartOf("2026-09-01", loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2026, 9, 1, 0, 0, 0, 0, loc); !start.Equal(want) {
+		t.Errorf("expected %s, got %s", want, start)
+	}
+
+	start, err = StartOf("2026-09-01T10:00:00Z", loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("expected %s, got %s", want, start)
+	}
+
+	if _, err := StartOf("-1y", loc); err == nil {
+		t.Error("expected error for invalid value")
+	}
+}
+
+func TestAgo(t *testing.T) {
+	now := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	ago, err := Ago("1y2d", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := now.Add(-367 * 24 * time.Hour); !ago.Equal(want) {
+		t.Errorf("expected %s, got %s", want, ago)
+	}
+
+	if _, err := Ago("1 year", now); err == nil {
+		t.Error("expected error for invalid duration")
+	}
+}
@@ -2,6 +2,7 @@ package report
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -230,11 +231,9 @@ func (ui *UI) exportDir(dir fs.Item, waitWritten *sync.WaitGroup) error {
 		err  error
 	)
 
-	buff.Write([]byte(`[1,2,{"progname":"gdu","progver":"`))
-	buff.Write([]byte(build.Version))
-	buff.Write([]byte(`","timestamp":`))
-	buff.Write([]byte(strconv.FormatInt(time.Now().Unix(), 10)))
-	buff.Write([]byte("},\n"))
+	if err := EncodeHeader(&buff, ui.GetScanErrors()); err != nil {
+		return err
+	}
 
 	switch {
 	case ui.summarize:
@@ -270,6 +269,25 @@ func (ui *UI) exportDir(dir fs.Item, waitWritten *sync.WaitGroup) error {
 	return nil
 }
 
+// EncodeHeader writes the start of the exported JSON with the metadata of the analysis.
+// Errors of the scan are listed in the metadata so that the readers know the results are partial.
+func EncodeHeader(buff *bytes.Buffer, scanErrors []common.ScanError) error {
+	buff.WriteString(`[1,2,{"progname":"gdu","progver":"`)
+	buff.WriteString(build.Version)
+	buff.WriteString(`","timestamp":`)
+	buff.WriteString(strconv.FormatInt(time.Now().Unix(), 10))
+	if len(scanErrors) > 0 {
+		encoded, err := json.Marshal(scanErrors)
+		if err != nil {
+			return err
+		}
+		buff.WriteString(`,"errors":`)
+		buff.Write(encoded)
+	}
+	buff.WriteString("},\n")
+	return nil
+}
+
 func (ui *UI) updateProgress() {
 	waitingForWrite := false
 
@@ -307,6 +325,12 @@ func (ui *UI) updateProgress() {
 				ui.red.Sprint(common.FormatNumber(int64(progress.ItemCount)))+
 				" size: "+
 				ui.formatSize(progress.TotalUsage))
+			if progress.IOWorkers > 0 {
+				fmt.Fprint(ui.output, " I/O workers: "+ui.red.Sprint(progress.IOWorkers))
+			}
+			if progress.Throttle != "" {
+				fmt.Fprint(ui.output, " throttled: "+ui.red.Sprint(progress.Throttle))
+			}
 		}
 
 		time.Sleep(100 * time.Millisecond)
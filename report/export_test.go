@@ -2,6 +2,7 @@ package report
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"io"
 	"os"
@@ -10,6 +11,8 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/dundee/gdu/v5/internal/testanalyze"
 	"github.com/dundee/gdu/v5/internal/testdir"
 	"github.com/dundee/gdu/v5/pkg/analyze"
 	"github.com/dundee/gdu/v5/pkg/device"
@@ -62,6 +65,36 @@ func TestExportDirReturnsEncodeError(t *testing.T) {
 	assert.ErrorIs(t, err, sentinel)
 }
 
+func TestAnalyzePathWithScanErrors(t *testing.T) {
+	reportOutput := &bytes.Buffer{}
+
+	ui := CreateExportUI(&bytes.Buffer{}, reportOutput, false, false, false, 0, 0, false, nil)
+	ui.Analyzer = &testanalyze.MockedAnalyzer{ScanErrors: []common.ScanError{
+		common.NewScanError("test_dir/aaa", &os.PathError{Op: "open", Path: "test_dir/aaa", Err: errors.New("permission denied")}),
+	}}
+	err := ui.AnalyzePath("test_dir", nil)
+	assert.Nil(t, err)
+
+	var data []any
+	assert.NoError(t, json.Unmarshal(reportOutput.Bytes(), &data))
+	metadata := data[2].(map[string]any)
+	assert.Equal(t, []any{
+		map[string]any{"path": "test_dir/aaa", "op": "open", "error": "permission denied"},
+	}, metadata["errors"])
+
+	// the errors do not prevent reading the analysis
+	dir, err := ReadAnalysis(bytes.NewReader(reportOutput.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, "test_dir", dir.GetName())
+}
+
+func TestEncodeHeaderWithoutErrors(t *testing.T) {
+	buff := &bytes.Buffer{}
+	assert.NoError(t, EncodeHeader(buff, nil))
+
+	assert.Regexp(t, `^\[1,2,\{"progname":"gdu","progver":"[^"]+","timestamp":\d+\},\n$`, buff.String())
+}
+
 func TestAnalyzePathWithTop(t *testing.T) {
 	fin := testdir.CreateTestDir()
 	defer fin()
@@ -66,6 +66,9 @@ func processDir(items []any) (dir *analyze.Dir, err error) {
 			if dsize, ok := item["dsize"].(float64); ok {
 				file.Usage = int64(dsize)
 			}
+			if shared, ok := item["shared"].(float64); ok {
+				file.Shared = int64(shared)
+			}
 			if mtime, ok := item["mtime"].(float64); ok {
 				file.Mtime = time.Unix(int64(mtime), 0)
 			}
@@ -126,6 +129,9 @@ func parseDirectoryMetadata(item any) (
 		dir.Usage = int64(dsize)
 		hasUsage = true
 	}
+	if shared, ok := dirMap["shared"].(float64); ok {
+		dir.Shared = int64(shared)
+	}
 	if itemCount, ok := dirMap["items"].(float64); ok {
 		dir.ItemCount = int64(itemCount)
 		hasItemCount = true
@@ -47,6 +47,22 @@ func TestReadAnalysis(t *testing.T) {
 	assert.Equal(t, 'H', alt2.Flag)
 }
 
+func TestReadAnalysisWithSharedUsage(t *testing.T) {
+	buff := bytes.NewBuffer([]byte(`
+		[1,2,{"progname":"gdu","progver":"development","timestamp":1626806293},
+		[{"name":"/home/xxx","asize":8192,"dsize":8192,"shared":4096},
+		{"name":"clone.img","asize":4096,"dsize":4096,"shared":4096},
+		{"name":"own.img","asize":4096,"dsize":4096}]]
+	`))
+
+	dir, err := ReadAnalysis(buff)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4096), analyze.SharedUsage(dir))
+	assert.Equal(t, int64(4096), analyze.SharedUsage(dir.Files[0]))
+	assert.Equal(t, int64(0), analyze.SharedUsage(dir.Files[1]))
+}
+
 func TestReadAnalysisPreservesTruncatedDirectoryStats(t *testing.T) {
 	input := bytes.NewBufferString(`
 		[1,2,{"progname":"gdu","progver":"development","timestamp":0},
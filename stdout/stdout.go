@@ -37,6 +37,9 @@ type UI struct {
 	reverseSort       bool
 }
 
+// maxPrintedScanErrors is the number of errors of the scan listed in the summary
+const maxPrintedScanErrors = 20
+
 var (
 	progressRunes      = []rune(`⠇⠏⠋⠙⠹⠸⠼⠴⠦⠧`)
 	progressRunesOld   = []rune(`-\\|/`)
@@ -116,6 +119,15 @@ func (ui *UI) SetFixedUnit(unitChar string) {
 	}
 }
 
+// SetShowInodes ranks items by the number of inodes they use.
+// The top dir analyzer does not count nested items, so the full analyzer is used instead.
+func (ui *UI) SetShowInodes(capacity int64) {
+	ui.UI.SetShowInodes(capacity)
+	if _, ok := ui.Analyzer.(*analyze.TopDirAnalyzer); ok {
+		ui.Analyzer = analyze.CreateAnalyzer()
+	}
+}
+
 func (ui *UI) SetShowItemCount() {
 	ui.showItemCnt = true
 }
@@ -169,6 +181,11 @@ func (ui *UI) ListDevices(getter device.DevicesInfoGetter) error {
 		percentLength,
 	)
 
+	if ui.ShowInodes {
+		ui.printDevicesInodes(devices, maxDeviceNameLength, lineFormat)
+		return nil
+	}
+
 	fmt.Fprintf(
 		ui.output,
 		fmt.Sprintf("%%%ds %%9s %%9s %%9s %%5s %%s\n", maxDeviceNameLength),
@@ -197,6 +214,37 @@ func (ui *UI) ListDevices(getter device.DevicesInfoGetter) error {
 	return nil
 }
 
+// printDevicesInodes prints inode usage of devices like `df -i`
+func (ui *UI) printDevicesInodes(devices device.Devices, maxDeviceNameLength int, lineFormat string) {
+	fmt.Fprintf(
+		ui.output,
+		fmt.Sprintf("%%%ds %%9s %%9s %%9s %%5s %%s\n", maxDeviceNameLength),
+		"Device",
+		"Inodes",
+		"IUsed",
+		"IFree",
+		"IUse%",
+		"Mount point",
+	)
+
+	for _, device := range devices {
+		var usedPercent float64
+		if device.Inodes > 0 {
+			usedPercent = math.Round(float64(device.GetUsedInodes()) / float64(device.Inodes) * 100)
+		}
+
+		fmt.Fprintf(
+			ui.output,
+			lineFormat,
+			device.Name,
+			ui.orange.Sprint(device.Inodes),
+			ui.orange.Sprint(device.GetUsedInodes()),
+			ui.orange.Sprint(device.FreeInodes),
+			ui.red.Sprintf("%.f%%", usedPercent),
+			device.MountPoint)
+	}
+}
+
 // AnalyzePath analyzes recursively disk usage in given path
 func (ui *UI) AnalyzePath(path string, _ fs.Item) error {
 	// When path is a regular file, create a File item directly so that
@@ -249,10 +297,44 @@ func (ui *UI) AnalyzePath(path string, _ fs.Item) error {
 	default:
 		ui.showDir(dir)
 	}
+	ui.printScanErrors()
 
 	return nil
 }
 
+// printScanErrors prints summary of the directories and files which could not be read
+func (ui *UI) printScanErrors() {
+	errs := ui.GetScanErrors()
+	if len(errs) == 0 {
+		return
+	}
+
+	fmt.Fprintf(
+		ui.output,
+		"\n%s %s could not be read, results are partial:\n",
+		ui.red.Sprint(common.FormatNumber(int64(len(errs)))),
+		plural(len(errs), "item", "items"),
+	)
+	for i, e := range errs {
+		if i == maxPrintedScanErrors {
+			fmt.Fprintf(ui.output, "  ... and %s more\n", common.FormatNumber(int64(len(errs)-i)))
+			break
+		}
+		reason := e.Reason()
+		if errno := e.Errno(); errno != "" {
+			reason = errno + " (" + reason + ")"
+		}
+		fmt.Fprintf(ui.output, "  %s: %s: %s\n", e.Path, e.Op, reason)
+	}
+}
+
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
+
 // ReadFromStorage reads analysis data from persistent key-value storage
 func (ui *UI) ReadFromStorage(storagePath, path string) error {
 	storage := analyze.NewStorage(storagePath, path)
@@ -282,7 +364,9 @@ func (ui *UI) showDir(dir fs.Item) {
 	}
 
 	sort := fs.SortBySize
-	if ui.ShowApparentSize {
+	if ui.ShowInodes {
+		sort = fs.SortByItemCount
+	} else if ui.ShowApparentSize {
 		sort = fs.SortByApparentSize
 	}
 
@@ -306,21 +390,23 @@ func (ui *UI) printTotalItem(file fs.Item) {
 		lineFormat = "%9s %s\n"
 	}
 
-	var size int64
-	if ui.ShowApparentSize {
-		size = file.GetSize()
-	} else {
-		size = file.GetUsage()
-	}
-
 	fmt.Fprintf(
 		ui.output,
 		lineFormat,
-		ui.formatSize(size),
+		ui.formatValue(file),
 		file.GetName(),
 	)
 }
 
+// dirName returns the name of dir prefixed with slash.
+// Roots of a multi-path scan are shown with the full path instead.
+func dirName(dir fs.Item) string {
+	if name := analyze.DisplayName(dir); name != dir.GetName() {
+		return name
+	}
+	return "/" + dir.GetName()
+}
+
 func (ui *UI) printItem(file fs.Item) {
 	var lineFormat string
 	if ui.showItemCnt {
@@ -337,16 +423,9 @@ func (ui *UI) printItem(file fs.Item) {
 		}
 	}
 
-	var size int64
-	if ui.ShowApparentSize {
-		size = file.GetSize()
-	} else {
-		size = file.GetUsage()
-	}
-
 	name := file.GetName()
 	if file.IsDir() {
-		name = ui.blue.Sprint("/" + file.GetName())
+		name = ui.blue.Sprint(dirName(file))
 	}
 
 	// Append symlink target with cyan name (like ls --color)
@@ -363,7 +442,7 @@ func (ui *UI) printItem(file fs.Item) {
 			ui.output,
 			lineFormat,
 			string(file.GetFlag()),
-			ui.formatSize(size),
+			ui.formatValue(file),
 			ui.formatCount(file.GetItemCount()),
 			name,
 		)
@@ -374,7 +453,7 @@ func (ui *UI) printItem(file fs.Item) {
 		ui.output,
 		lineFormat,
 		string(file.GetFlag()),
-		ui.formatSize(size),
+		ui.formatValue(file),
 		name,
 	)
 }
@@ -387,22 +466,15 @@ func (ui *UI) printItemPath(file fs.Item) {
 		lineFormat = "%9s %s\n"
 	}
 
-	var size int64
-	if ui.ShowApparentSize {
-		size = file.GetSize()
-	} else {
-		size = file.GetUsage()
-	}
-
 	if file.IsDir() {
 		fmt.Fprintf(ui.output,
 			lineFormat,
-			ui.formatSize(size),
+			ui.formatValue(file),
 			ui.blue.Sprint(file.GetPath()))
 	} else {
 		fmt.Fprintf(ui.output,
 			lineFormat,
-			ui.formatSize(size),
+			ui.formatValue(file),
 			file.GetPath())
 	}
 }
@@ -418,7 +490,12 @@ func (ui *UI) printDirWithDepth(dir fs.Item, currentDepth int) {
 			sortOrder = fs.SortAsc
 		}
 
-		files := dir.GetFiles(fs.SortBySize, sortOrder)
+		sortBy := fs.SortBySize
+		if ui.ShowInodes {
+			sortBy = fs.SortByItemCount
+		}
+
+		files := dir.GetFiles(sortBy, sortOrder)
 
 		// Print all files at this depth level
 		for file := range files {
@@ -536,6 +613,12 @@ func (ui *UI) updateProgress(updateStatsDone <-chan struct{}) {
 				ui.red.Sprint(common.FormatNumber(int64(progress.ItemCount)))+
 				" size: "+
 				ui.formatSize(progress.TotalUsage))
+			if progress.IOWorkers > 0 {
+				fmt.Fprint(ui.output, " I/O workers: "+ui.red.Sprint(progress.IOWorkers))
+			}
+			if progress.Throttle != "" {
+				fmt.Fprint(ui.output, " throttled: "+ui.red.Sprint(progress.Throttle))
+			}
 			i++
 			i %= progressRunesCount
 		case <-analysisDoneChan:
@@ -561,6 +644,24 @@ func (ui *UI) updateProgress(updateStatsDone <-chan struct{}) {
 	}
 }
 
+// formatValue formats the size of the item, or the number of inodes used in the inode mode
+func (ui *UI) formatValue(file fs.Item) string {
+	switch {
+	case ui.ShowInodes:
+		value := ui.formatCount(file.GetItemCount())
+		if percent, ok := ui.InodePercent(file.GetItemCount()); ok {
+			value += fmt.Sprintf(" (%.1f%%)", percent)
+		}
+		return value
+	case ui.ShowApparentSize:
+		return ui.formatSize(file.GetSize())
+	case ui.ShowSharedUsage:
+		return ui.formatSize(file.GetUsage()) + " (" + ui.formatSize(analyze.SharedUsage(file)) + " shared)"
+	default:
+		return ui.formatSize(file.GetUsage())
+	}
+}
+
 func (ui *UI) formatCount(count int64) string {
 	count64 := float64(count)
 
@@ -2,6 +2,7 @@ package stdout
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -10,6 +11,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/dundee/gdu/v5/internal/common"
 	"github.com/dundee/gdu/v5/internal/testanalyze"
 	"github.com/dundee/gdu/v5/internal/testdev"
 	"github.com/dundee/gdu/v5/internal/testdir"
@@ -45,6 +47,27 @@ func TestAnalyzePath(t *testing.T) {
 	assert.Contains(t, output.String(), "nested")
 }
 
+func TestAnalyzeMultiplePaths(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	output := &bytes.Buffer{}
+	ui := CreateStdoutUI(output, false, false, false, false, false, false, false, "", 0, false, 0)
+
+	first, _ := filepath.Abs("test_dir/nested/subnested")
+	second := t.TempDir()
+	analyzer := analyze.CreateMultiPathAnalyzer([]string{first, second}, func() common.Analyzer {
+		return analyze.CreateAnalyzer()
+	})
+	ui.SetAnalyzer(analyzer)
+
+	err := ui.AnalyzePath(analyzer.RootPath(), nil)
+	assert.Nil(t, err)
+
+	assert.Contains(t, output.String(), " "+first+"\n")
+	assert.Contains(t, output.String(), " "+second+"\n")
+}
+
 func TestShowItemCountInNonInteractiveMode(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -332,6 +355,38 @@ func TestItemRows(t *testing.T) {
 	assert.Contains(t, output.String(), "B")
 }
 
+func TestScanErrorsSummary(t *testing.T) {
+	output := &bytes.Buffer{}
+
+	ui := CreateStdoutUI(output, false, false, false, false, false, false, false, "", 0, false, 0)
+	ui.Analyzer = &testanalyze.MockedAnalyzer{ScanErrors: []common.ScanError{
+		common.NewScanError("test_dir/aaa", &os.PathError{Op: "open", Path: "test_dir/aaa", Err: errors.New("permission denied")}),
+		common.NewScanError("test_dir/bbb/x", errors.New("broken")),
+	}}
+	err := ui.AnalyzePath("test_dir", nil)
+
+	assert.Nil(t, err)
+	assert.Contains(t, output.String(), "2 items could not be read, results are partial:\n")
+	assert.Contains(t, output.String(), "  test_dir/aaa: open: permission denied\n")
+	assert.Contains(t, output.String(), "  test_dir/bbb/x: read: broken\n")
+}
+
+func TestScanErrorsSummaryLimited(t *testing.T) {
+	output := &bytes.Buffer{}
+
+	errs := make([]common.ScanError, maxPrintedScanErrors+5)
+	for i := range errs {
+		errs[i] = common.NewScanError("test_dir/aaa", errors.New("broken"))
+	}
+	ui := CreateStdoutUI(output, false, false, false, false, false, false, false, "", 0, false, 0)
+	ui.Analyzer = &testanalyze.MockedAnalyzer{ScanErrors: errs}
+	err := ui.AnalyzePath("test_dir", nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, maxPrintedScanErrors, strings.Count(output.String(), "test_dir/aaa: read: broken"))
+	assert.Contains(t, output.String(), "  ... and 5 more\n")
+}
+
 func TestAnalyzePathWithProgress(t *testing.T) {
 	fin := testdir.CreateTestDir()
 	defer fin()
@@ -368,6 +423,49 @@ func TestShowDevicesWithColor(t *testing.T) {
 	assert.Contains(t, output.String(), "xxx")
 }
 
+func TestShowDevicesInodes(t *testing.T) {
+	output := bytes.NewBuffer(make([]byte, 10))
+
+	mock := testdev.DevicesInfoGetterMock{}
+	mock.Devices = []*device.Device{{Name: "xxx", MountPoint: "/", Inodes: 1000, FreeInodes: 250}}
+
+	ui := CreateStdoutUI(output, false, true, false, false, false, false, false, "", 0, false, 0)
+	ui.SetShowInodes(0)
+	err := ui.ListDevices(mock)
+
+	assert.Nil(t, err)
+	assert.Contains(t, output.String(), "IUse%")
+	assert.Contains(t, output.String(), "1000       750       250   75% /")
+}
+
+func TestAnalyzePathInodes(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	output := bytes.NewBuffer(nil)
+
+	ui := CreateStdoutUI(output, false, false, false, false, false, false, false, "", 0, false, 0)
+	ui.SetShowInodes(400)
+	err := ui.AnalyzePath("test_dir", nil)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "   4 (1.0%) /nested\n", output.String())
+}
+
+func TestShowSummaryInodes(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	output := bytes.NewBuffer(nil)
+
+	ui := CreateStdoutUI(output, false, false, false, false, true, false, false, "", 0, false, 0)
+	ui.SetShowInodes(0)
+	err := ui.AnalyzePath("test_dir", nil)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "        5 test_dir\n", output.String())
+}
+
 func TestReadAnalysisWithColor(t *testing.T) {
 	input, err := os.OpenFile("../internal/testdata/test.json", os.O_RDONLY, 0o644)
 	assert.Nil(t, err)
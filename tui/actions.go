@@ -8,7 +8,6 @@ import (
 	"os/exec"
 	"runtime"
 	"runtime/debug"
-	"strconv"
 	"strings"
 	"time"
 
@@ -17,8 +16,10 @@ import (
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	log "github.com/sirupsen/logrus"
 
 	"github.com/dundee/gdu/v5/build"
+	"github.com/dundee/gdu/v5/internal/common"
 	"github.com/dundee/gdu/v5/pkg/analyze"
 	"github.com/dundee/gdu/v5/pkg/device"
 	"github.com/dundee/gdu/v5/pkg/fs"
@@ -85,6 +86,10 @@ func (ui *UI) AnalyzePath(path string, parentDir fs.Item) error {
 	go func() {
 		defer debug.FreeOSMemory()
 		currentDir := analyzer.AnalyzeDir(path, ui.CreateIgnoreFunc(), ui.CreateFileTypeFilter())
+		var scanErrors []common.ScanError
+		if reporter, ok := analyzer.(common.ScanErrorReporter); ok {
+			scanErrors = reporter.GetScanErrors()
+		}
 
 		if parentDir != nil {
 			currentDir.SetParent(parentDir)
@@ -101,8 +106,9 @@ func (ui *UI) AnalyzePath(path string, parentDir fs.Item) error {
 		} else {
 			ui.topDir.UpdateStats(ui.linkedItems)
 		}
-
 		ui.app.QueueUpdateDraw(func() {
+			ui.classifyGitFiles()
+			ui.junkResult = nil
 			ui.scanning = false
 			ui.scanCancelled = false
 			// remember the longest scan so we can guard against accidental quits
@@ -112,6 +118,7 @@ func (ui *UI) AnalyzePath(path string, parentDir fs.Item) error {
 			// the finished scan replaces any mid-scan preview
 			ui.previewing = false
 			ui.previewSavedDir = nil
+			ui.updateScanErrors(path, scanErrors, parentDir != nil)
 			ui.currentDir = currentDir
 			ui.showDir()
 			ui.pages.RemovePage("progress")
@@ -162,8 +169,9 @@ func (ui *UI) ReadAnalysis(input io.Reader) error {
 
 		links := make(fs.HardLinkedItems, 10)
 		ui.topDir.UpdateStats(links)
-
 		ui.app.QueueUpdateDraw(func() {
+			ui.classifyGitFiles()
+			ui.junkResult = nil
 			ui.showDir()
 			ui.pages.RemovePage("progress")
 		})
@@ -190,6 +198,8 @@ func (ui *UI) ReadFromStorage(storagePath, path string) error {
 	ui.currentDir = dir
 	ui.topDirPath = ui.currentDir.GetPath()
 	ui.topDir = ui.currentDir
+	ui.classifyGitFiles()
+	ui.junkResult = nil
 
 	ui.showDir()
 	return nil
@@ -203,6 +213,36 @@ func (ui *UI) delete(action DeleteAction) {
 	}
 }
 
+// getDeleteFunc returns the function performing the action on the item,
+// recording it to the audit log when one is set
+func (ui *UI) getDeleteFunc(action DeleteAction, item fs.Item) func(fs.Item, fs.Item) error {
+	var deleteFun func(fs.Item, fs.Item) error
+	switch action {
+	case ActionEmpty:
+		if !item.IsDir() {
+			deleteFun = ui.emptier
+		} else {
+			deleteFun = ui.remover
+		}
+	case ActionMoveToTrash:
+		deleteFun = ui.trasher
+	case ActionShred:
+		deleteFun = ui.shredder
+	case ActionDelete:
+		deleteFun = ui.remover
+	}
+	if ui.gitUsage != nil {
+		deleteFun = ui.wrapGitStats(deleteFun)
+	}
+	if ui.junkResult != nil {
+		deleteFun = ui.wrapJunk(deleteFun)
+	}
+	if ui.auditLog != nil {
+		return ui.auditLog.Wrap(action.auditName(), deleteFun)
+	}
+	return deleteFun
+}
+
 func (ui *UI) deleteSelected(action DeleteAction) {
 	row, column := ui.table.GetSelection()
 	selectedItem := ui.table.GetCell(row, column).GetReference().(fs.Item)
@@ -233,19 +273,7 @@ func (ui *UI) deleteSelected(action DeleteAction) {
 		deleteItems = append(deleteItems, selectedItem)
 	}
 
-	var deleteFun func(fs.Item, fs.Item) error
-	switch action {
-	case ActionEmpty:
-		if !selectedItem.IsDir() {
-			deleteFun = ui.emptier
-		} else {
-			deleteFun = ui.remover
-		}
-	case ActionMoveToTrash:
-		deleteFun = ui.trasher
-	case ActionDelete:
-		deleteFun = ui.remover
-	}
+	deleteFun := ui.getDeleteFunc(action, selectedItem)
 	go func() {
 		for _, item := range deleteItems {
 			if err := deleteFun(currentDir, item); err != nil {
@@ -323,6 +351,27 @@ func (ui *UI) showInfo() {
 	content += numberColor + ui.formatSize(selectedFile.GetSize(), false, true)
 	content += fmt.Sprintf(" (%s%d[-::] B)", numberColor, selectedFile.GetSize()) + "\n"
 
+	if ui.ShowSharedUsage {
+		shared := analyze.SharedUsage(selectedFile)
+		linesCount += 2
+		content += "       [::b]Shared:[::-] "
+		content += numberColor + ui.formatSize(shared, false, true)
+		content += fmt.Sprintf(" (%s%d[-::] B)", numberColor, shared) + "\n"
+		content += "    [::b]Exclusive:[::-] "
+		content += numberColor + ui.formatSize(selectedFile.GetUsage()-shared, false, true)
+		content += fmt.Sprintf(" (%s%d[-::] B)", numberColor, selectedFile.GetUsage()-shared) + "\n"
+	}
+
+	if lines := ui.gitInfoLines(selectedFile, numberColor); lines != "" {
+		content += lines
+		linesCount += 3
+	}
+
+	if line := ui.usageHistoryInfoLine(selectedFile, numberColor); line != "" {
+		content += line
+		linesCount++
+	}
+
 	if selectedFile.GetMultiLinkedInode() > 0 {
 		linkedItems := ui.linkedItems[selectedFile.GetMultiLinkedInode()]
 		linesCount += 2 + len(linkedItems)
@@ -345,6 +394,31 @@ func (ui *UI) showInfo() {
 	ui.pages.AddPage("info", flex, true, true)
 }
 
+// usageHistoryInfoLine returns a sparkline of usage of the item in the scans stored in the history of SQLite database
+func (ui *UI) usageHistoryInfoLine(item fs.Item, numberColor string) string {
+	sqliteItem, ok := item.(*analyze.SqliteItem)
+	if !ok {
+		return ""
+	}
+	entries, err := sqliteItem.GetUsageHistory()
+	if err != nil {
+		log.Printf("loading usage history of %s: %s", item.GetPath(), err)
+		return ""
+	}
+	if len(entries) < 2 {
+		return ""
+	}
+
+	usages := make([]int64, 0, len(entries))
+	for _, entry := range entries {
+		usages = append(usages, entry.Usage)
+	}
+	return fmt.Sprintf(
+		"      [::b]History:[::-] %s%s[-::] (%d scans)\n",
+		numberColor, sparkline(usages), len(entries),
+	)
+}
+
 // symlinkTargetInfoLine returns the "Target:" info-panel line for a symlink
 // item, or an empty string when symlink-target display is disabled or the item
 // has no symlink target.
@@ -417,6 +491,7 @@ func (ui *UI) exportAnalysis() {
 	flex := modal(text, 50, 3)
 	ui.pages.AddPage("exporting", flex, true, true)
 
+	scanErrors := ui.scanErrors
 	go func() {
 		var err error
 		defer ui.app.QueueUpdateDraw(func() {
@@ -432,12 +507,10 @@ func (ui *UI) exportAnalysis() {
 		}
 
 		var buff bytes.Buffer
-
-		buff.Write([]byte(`[1,2,{"progname":"gdu","progver":"`))
-		buff.Write([]byte(build.Version))
-		buff.Write([]byte(`","timestamp":`))
-		buff.Write([]byte(strconv.FormatInt(time.Now().Unix(), 10)))
-		buff.Write([]byte("},\n"))
+		if err = report.EncodeHeader(&buff, scanErrors); err != nil {
+			ui.showErrFromGo("Error encoding JSON", err)
+			return
+		}
 
 		file, err := os.Create(ui.exportName)
 		if err != nil {
@@ -11,8 +11,10 @@ import (
 	"github.com/dundee/gdu/v5/internal/common"
 	"github.com/dundee/gdu/v5/internal/testanalyze"
 	"github.com/dundee/gdu/v5/internal/testapp"
+	"github.com/dundee/gdu/v5/internal/testdev"
 	"github.com/dundee/gdu/v5/internal/testdir"
 	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/device"
 	"github.com/dundee/gdu/v5/pkg/fs"
 	"github.com/gdamore/tcell/v2"
 	"github.com/stretchr/testify/assert"
@@ -58,6 +60,34 @@ func TestShowDevicesBW(t *testing.T) {
 	}
 }
 
+func TestShowDevicesGroupedByClass(t *testing.T) {
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(false)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, false, false, false, false)
+	ui.topDirPath = "/mnt/share"
+	err := ui.ListDevices(testdev.DevicesInfoGetterMock{
+		Devices: device.Devices{
+			{Name: "tmpfs", MountPoint: "/tmp", Fstype: "tmpfs", Size: 1e9},
+			{Name: "//host/share", MountPoint: "/mnt/share", Fstype: "cifs", Size: 1e9},
+			{Name: "/dev/sda1", MountPoint: "/", Fstype: "ext4", Size: 1e9},
+		},
+	})
+	assert.Nil(t, err)
+
+	assert.Contains(t, ui.table.GetCell(1, 0).Text, "local")
+	assert.Contains(t, ui.table.GetCell(2, 0).Text, "/dev/sda1")
+	assert.Contains(t, ui.table.GetCell(3, 0).Text, "network")
+	assert.Contains(t, ui.table.GetCell(4, 0).Text, "//host/share")
+	assert.Contains(t, ui.table.GetCell(5, 0).Text, "virtual")
+	assert.Contains(t, ui.table.GetCell(6, 0).Text, "tmpfs")
+	assert.True(t, ui.table.GetCell(3, 0).NotSelectable)
+
+	row, _ := ui.table.GetSelection()
+	assert.Equal(t, 4, row)
+}
+
 func TestDeviceSelected(t *testing.T) {
 	simScreen := testapp.CreateSimScreen()
 	defer simScreen.Fini()
@@ -32,19 +32,7 @@ func (ui *UI) deleteItem(item fs.Item, action DeleteAction) {
 	ui.increaseActiveWorkers()
 	defer ui.decreaseActiveWorkers()
 
-	var deleteFun func(fs.Item, fs.Item) error
-	switch action {
-	case ActionEmpty:
-		if !item.IsDir() {
-			deleteFun = ui.emptier
-		} else {
-			deleteFun = ui.remover
-		}
-	case ActionMoveToTrash:
-		deleteFun = ui.trasher
-	case ActionDelete:
-		deleteFun = ui.remover
-	}
+	deleteFun := ui.getDeleteFunc(action, item)
 
 	var parentDir fs.Item
 	var deleteItems []fs.Item
@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/dundee/gdu/v5/pkg/compress"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// SetCompressFormat sets format used for compressing items in place
+func (ui *UI) SetCompressFormat(format compress.Format) {
+	ui.compressFormat = format
+}
+
+// itemsToCompress returns the marked items or the selected one when nothing is marked
+func (ui *UI) itemsToCompress() []fs.Item {
+	var items []fs.Item
+	if len(ui.markedRows) > 0 {
+		rows := make([]int, 0, len(ui.markedRows))
+		for row := range ui.markedRows {
+			rows = append(rows, row)
+		}
+		slices.Sort(rows)
+		for _, row := range rows {
+			if item, ok := ui.table.GetCell(row, 0).GetReference().(fs.Item); ok {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+
+	row, column := ui.table.GetSelection()
+	item, ok := ui.table.GetCell(row, column).GetReference().(fs.Item)
+	if !ok || item == ui.currentDir.GetParent() {
+		return nil
+	}
+	return []fs.Item{item}
+}
+
+func (ui *UI) confirmCompress() {
+	if ui.currentDir == nil {
+		return
+	}
+	if ui.noDelete {
+		previousHeaderText := ui.header.GetText(false)
+
+		// show feedback to user
+		ui.header.SetText(" Compressing is disabled!")
+
+		go func() {
+			time.Sleep(2 * time.Second)
+			ui.app.QueueUpdateDraw(func() {
+				ui.header.Clear()
+				ui.header.SetText(previousHeaderText)
+			})
+		}()
+		return
+	}
+
+	items := ui.itemsToCompress()
+	if len(items) == 0 {
+		return
+	}
+
+	text := fmt.Sprintf("Are you sure you want to compress %d items", len(items))
+	if len(items) == 1 {
+		text = "Are you sure you want to compress \"" + tview.Escape(items[0].GetName()) + "\""
+	}
+	text += " (" + string(ui.compressFormat) + ")? The originals will be replaced."
+
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"no", "yes"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonIndex == 1 {
+				ui.markedRows = make(map[int]struct{})
+				go ui.compressItems(ui.currentDir, items)
+			}
+			ui.pages.RemovePage("confirm")
+		})
+
+	if !ui.UseColors {
+		modal.SetBackgroundColor(tcell.ColorGray)
+	} else {
+		modal.SetBackgroundColor(tcell.ColorBlack)
+	}
+	modal.SetBorderColor(tcell.ColorDefault)
+
+	ui.pages.AddPage("confirm", modal, true, true)
+}
+
+// compressItems compresses the items in the background, replacing them in the tree with the compressed files
+func (ui *UI) compressItems(currentDir fs.Item, items []fs.Item) {
+	ui.app.QueueUpdateDraw(func() {
+		ui.header.SetText(fmt.Sprintf(" Compressing %d items in background...", len(items)))
+	})
+
+	var compressErr error
+	var failed fs.Item
+	for _, item := range items {
+		err := ui.auditLog.Do("compress", item.GetPath(), item.GetSize(), item.GetUsage(), item.IsDir(), func() error {
+			_, err := compress.ItemInDir(item.GetParent(), item, ui.compressFormat)
+			return err
+		})
+		if err != nil {
+			compressErr, failed = err, item
+			break
+		}
+	}
+
+	ui.app.QueueUpdateDraw(func() {
+		ui.header.SetText(ui.headerText())
+		if compressErr != nil {
+			ui.showErr("Can't compress "+tview.Escape(failed.GetName()), compressErr)
+		}
+		if ui.currentDir != nil && ui.currentDir.GetPath() == currentDir.GetPath() {
+			row, _ := ui.table.GetSelection()
+			ui.showDir()
+			ui.table.Select(min(row, ui.table.GetRowCount()-1), 0)
+		}
+	})
+	if ui.done != nil {
+		ui.done <- struct{}{}
+	}
+}
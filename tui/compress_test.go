@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testapp"
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/audit"
+	"github.com/dundee/gdu/v5/pkg/compress"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func runUpdateDraws(ui *UI) {
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+}
+
+func TestCompressSelected(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.done = make(chan struct{})
+	ui.SetCompressFormat(compress.FormatGzip)
+
+	ui.table.Select(0, 0)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'z', 0))
+	assert.True(t, ui.pages.HasPage("confirm"))
+
+	items := ui.itemsToCompress()
+	go ui.compressItems(ui.currentDir, items)
+	<-ui.done
+	runUpdateDraws(ui)
+
+	assert.NoDirExists(t, "test_dir/nested")
+	assert.FileExists(t, "test_dir/nested.tar.gz")
+	assert.Equal(t, "nested.tar.gz", ui.table.GetCell(0, 0).GetReference().(fs.Item).GetName())
+}
+
+func TestCompressFileUpdatesUsage(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	assert.Nil(t, os.WriteFile("test_dir/nested/big.log", bytes.Repeat([]byte("log line\n"), 10000), 0o600))
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.done = make(chan struct{})
+	before := ui.currentDir.GetUsage()
+
+	ui.table.Select(0, 0)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRight, 'l', 0))
+	assert.Equal(t, "nested", ui.currentDir.GetName())
+
+	ui.table.Select(1, 0)
+	items := ui.itemsToCompress()
+	assert.Equal(t, "big.log", items[0].GetName())
+
+	go ui.compressItems(ui.currentDir, items)
+	<-ui.done
+	runUpdateDraws(ui)
+
+	assert.FileExists(t, "test_dir/nested/big.log.zst")
+	assert.Less(t, ui.topDir.GetUsage(), before)
+	assert.Equal(t, " gdu ~ Use arrow keys to navigate, press ? for help ", ui.header.GetText(false))
+}
+
+func TestCompressWithError(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.done = make(chan struct{})
+	assert.Nil(t, os.WriteFile("test_dir/nested.tar.zst", nil, 0o600))
+
+	ui.table.Select(0, 0)
+	go ui.compressItems(ui.currentDir, ui.itemsToCompress())
+	<-ui.done
+	runUpdateDraws(ui)
+
+	assert.True(t, ui.pages.HasPage("error"))
+	assert.DirExists(t, "test_dir/nested")
+}
+
+func TestCompressDryRun(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.done = make(chan struct{})
+	var buff bytes.Buffer
+	ui.SetAuditLogger(audit.New(&buff, true))
+
+	ui.table.Select(0, 0)
+	go ui.compressItems(ui.currentDir, ui.itemsToCompress())
+	<-ui.done
+	runUpdateDraws(ui)
+
+	assert.DirExists(t, "test_dir/nested")
+	assert.Contains(t, buff.String(), `"action":"compress"`)
+}
+
+func TestCompressWithNoDelete(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.SetNoDelete()
+
+	ui.table.Select(0, 0)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'z', 0))
+
+	assert.False(t, ui.pages.HasPage("confirm"))
+	assert.Equal(t, " Compressing is disabled!", ui.header.GetText(false))
+}
+
+func TestCompressMarked(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.table.Select(0, 0)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRight, 'l', 0))
+
+	ui.table.Select(1, 0)
+	ui.markedRows[1] = struct{}{}
+	ui.markedRows[2] = struct{}{}
+
+	items := ui.itemsToCompress()
+	assert.Len(t, items, 2)
+
+	ui.confirmCompress()
+	assert.True(t, ui.pages.HasPage("confirm"))
+}
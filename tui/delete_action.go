@@ -7,6 +7,7 @@ const (
 	ActionDelete DeleteAction = iota
 	ActionEmpty
 	ActionMoveToTrash
+	ActionShred
 )
 
 func (a DeleteAction) Verb() string {
@@ -15,6 +16,8 @@ func (a DeleteAction) Verb() string {
 		return "empty"
 	case ActionMoveToTrash:
 		return "move to trash"
+	case ActionShred:
+		return "shred"
 	case ActionDelete:
 		return "delete"
 	}
@@ -27,8 +30,25 @@ func (a DeleteAction) Acting() string {
 		return "emptying"
 	case ActionMoveToTrash:
 		return "moving to trash"
+	case ActionShred:
+		return "shredding"
 	case ActionDelete:
 		return "deleting"
 	}
 	return "deleting"
 }
+
+// auditName returns the name of the action used in audit records
+func (a DeleteAction) auditName() string {
+	switch a {
+	case ActionEmpty:
+		return "empty"
+	case ActionMoveToTrash:
+		return "trash"
+	case ActionShred:
+		return "shred"
+	case ActionDelete:
+		return "delete"
+	}
+	return "delete"
+}
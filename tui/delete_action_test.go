@@ -10,10 +10,20 @@ func TestDeleteActionVerbAndActing(t *testing.T) {
 	assert.Equal(t, "delete", ActionDelete.Verb())
 	assert.Equal(t, "empty", ActionEmpty.Verb())
 	assert.Equal(t, "move to trash", ActionMoveToTrash.Verb())
+	assert.Equal(t, "shred", ActionShred.Verb())
 	assert.Equal(t, "delete", DeleteAction(99).Verb())
 
 	assert.Equal(t, "deleting", ActionDelete.Acting())
 	assert.Equal(t, "emptying", ActionEmpty.Acting())
 	assert.Equal(t, "moving to trash", ActionMoveToTrash.Acting())
+	assert.Equal(t, "shredding", ActionShred.Acting())
 	assert.Equal(t, "deleting", DeleteAction(99).Acting())
 }
+
+func TestDeleteActionAuditName(t *testing.T) {
+	assert.Equal(t, "delete", ActionDelete.auditName())
+	assert.Equal(t, "empty", ActionEmpty.auditName())
+	assert.Equal(t, "trash", ActionMoveToTrash.auditName())
+	assert.Equal(t, "shred", ActionShred.auditName())
+	assert.Equal(t, "delete", DeleteAction(99).auditName())
+}
@@ -0,0 +1,152 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/device"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// deviceScanRefreshInterval is how often the progress of background device scans is redrawn
+const deviceScanRefreshInterval = 200 * time.Millisecond
+
+// deviceScan is a background scan of a device started from the devices view
+type deviceScan struct {
+	analyzer    common.Analyzer
+	used        int64
+	dir         fs.Item
+	linkedItems fs.HardLinkedItems
+}
+
+// scanAllDevices scans all listed devices in background.
+// Mount points nested in a device are left to their own scans so every mount is counted only once.
+func (ui *UI) scanAllDevices() {
+	if ui.deviceScans == nil {
+		ui.deviceScans = make(map[string]*deviceScan, len(ui.devices))
+	}
+
+	scans := make([]*deviceScan, 0, len(ui.devices))
+	for _, dev := range ui.devices {
+		if _, ok := ui.deviceScans[dev.MountPoint]; ok {
+			continue // already scanned or being scanned
+		}
+		scans = append(scans, ui.startDeviceScan(dev))
+	}
+	if len(scans) > 0 {
+		go ui.updateDeviceScans(scans)
+	}
+	ui.redrawDevices()
+}
+
+func (ui *UI) startDeviceScan(dev *device.Device) *deviceScan {
+	scan := &deviceScan{
+		analyzer: ui.createDeviceAnalyzer(),
+		used:     dev.Size - dev.Free,
+	}
+	ui.deviceScans[dev.MountPoint] = scan
+
+	nested := device.GetNestedMountpointsPaths(dev.MountPoint, ui.devices)
+	ignoreDir := ui.CreateIgnoreFunc()
+	ignore := func(name, path string) bool {
+		return slices.Contains(nested, path) || ignoreDir(name, path)
+	}
+	fileTypeFilter := ui.CreateFileTypeFilter()
+	filteringFiles := ui.IsFilteringFiles()
+	mountPoint := dev.MountPoint
+
+	go func() {
+		dir := scan.analyzer.AnalyzeDir(mountPoint, ignore, fileTypeFilter)
+		linkedItems := make(fs.HardLinkedItems)
+		if filteringFiles {
+			dir.UpdateStatsWithFileFiltering(linkedItems)
+		} else {
+			dir.UpdateStats(linkedItems)
+		}
+
+		ui.app.QueueUpdateDraw(func() {
+			scan.dir = dir
+			scan.linkedItems = linkedItems
+			ui.redrawDevices()
+		})
+	}()
+	return scan
+}
+
+// createDeviceAnalyzer returns analyzer of the same kind and settings as the UI analyzer
+func (ui *UI) createDeviceAnalyzer() common.Analyzer {
+	var analyzer common.Analyzer
+	if _, ok := ui.Analyzer.(*analyze.SequentialAnalyzer); ok {
+		analyzer = analyze.CreateSeqAnalyzer()
+	} else {
+		analyzer = analyze.CreateAnalyzer()
+	}
+	ui.ConfigureAnalyzer(analyzer)
+	return analyzer
+}
+
+// updateDeviceScans redraws the progress of the scans until all of them are finished
+func (ui *UI) updateDeviceScans(scans []*deviceScan) {
+	done := make(chan struct{})
+	go func() {
+		for _, scan := range scans {
+			scan.analyzer.GetDone().Wait()
+		}
+		close(done)
+	}()
+
+	ticker := time.NewTicker(deviceScanRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			ui.app.QueueUpdateDraw(ui.redrawDevices)
+		}
+	}
+}
+
+// redrawDevices refreshes the devices view if it is shown, keeping the selected row
+func (ui *UI) redrawDevices() {
+	if ui.currentDir != nil || ui.devices == nil {
+		return
+	}
+	row, column := ui.table.GetSelection()
+	ui.showDevices()
+	ui.table.Select(row, column)
+}
+
+// openDeviceScan shows the result of a finished background scan of the device
+func (ui *UI) openDeviceScan(dev *device.Device, scan *deviceScan) {
+	ui.currentDeviceSize = dev.Size
+	ui.InodeCapacity = dev.Inodes
+	ui.linkedItems = scan.linkedItems
+	ui.topDir = scan.dir
+	ui.topDirPath = dev.MountPoint
+	ui.currentDir = scan.dir
+	ui.showDir()
+}
+
+// formatDeviceScan formats the progress of the background scan of the device
+func (ui *UI) formatDeviceScan(dev *device.Device) string {
+	scan, ok := ui.deviceScans[dev.MountPoint]
+	if !ok {
+		return ""
+	}
+	if scan.dir != nil {
+		return "done " + ui.formatSize(scan.dir.GetUsage(), false, true)
+	}
+
+	part := 0
+	if scan.used > 0 {
+		part = min(int(float64(scan.analyzer.GetProgress().TotalUsage)/float64(scan.used)*100.0), 100)
+	}
+	if ui.useOldSizeBar {
+		return getUsageGraphOld(part) + fmt.Sprintf(" %3d%%", part)
+	}
+	return getUsageGraph(part) + fmt.Sprintf(" %3d%%", part)
+}
@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testapp"
+	"github.com/dundee/gdu/v5/internal/testdev"
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/device"
+)
+
+func getScannedDevicesUI(t *testing.T) *UI {
+	t.Helper()
+
+	simScreen := testapp.CreateSimScreen()
+	t.Cleanup(simScreen.Fini)
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, true, false, false, false)
+	ui.Analyzer = analyze.CreateAnalyzer()
+	err := ui.ListDevices(testdev.DevicesInfoGetterMock{
+		Devices: []*device.Device{
+			{Name: "/dev/root", MountPoint: "test_dir", Size: 1e12, Free: 1e6},
+			{Name: "/dev/nested", MountPoint: "test_dir/nested", Size: 1e6, Free: 1e3},
+		},
+	})
+	assert.Nil(t, err)
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'A', 0))
+	assert.Len(t, ui.deviceScans, 2)
+
+	for _, scan := range ui.deviceScans {
+		scan.analyzer.GetDone().Wait()
+	}
+	assert.Eventually(t, func() bool {
+		for _, f := range app.(*testapp.MockedApp).GetUpdateDraws() {
+			f()
+		}
+		for _, scan := range ui.deviceScans {
+			if scan.dir == nil {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 10*time.Millisecond)
+	return ui
+}
+
+func TestScanAllDevices(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getScannedDevicesUI(t)
+
+	assert.Equal(t, "Scan", ui.table.GetCell(0, 6).Text)
+	assert.Contains(t, ui.table.GetCell(1, 6).Text, "done")
+
+	// nested mount point is counted only in its own scan
+	root := ui.deviceScans["test_dir"].dir
+	assert.Equal(t, 0, len(root.(*analyze.Dir).Files))
+	nested := ui.deviceScans["test_dir/nested"].dir
+	assert.Equal(t, "test_dir/nested", nested.GetPath())
+	assert.Equal(t, int64(7), nested.GetSize())
+}
+
+func TestSelectScannedDevice(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getScannedDevicesUI(t)
+
+	for i, dev := range ui.devices {
+		if dev.MountPoint == "test_dir/nested" {
+			ui.deviceItemSelected(i+1, 0)
+		}
+	}
+
+	assert.Equal(t, ui.deviceScans["test_dir/nested"].dir, ui.topDir)
+	assert.Equal(t, ui.topDir, ui.currentDir)
+	assert.Equal(t, "test_dir/nested", ui.topDirPath)
+	assert.Equal(t, int64(1e6), ui.currentDeviceSize)
+}
+
+func TestSelectDeviceWithRunningScan(t *testing.T) {
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, true, false, false, false)
+	err := ui.ListDevices(getDevicesInfoMock())
+	assert.Nil(t, err)
+
+	dev := ui.devices[0]
+	ui.deviceScans = map[string]*deviceScan{
+		dev.MountPoint: {analyzer: analyze.CreateAnalyzer(), used: dev.Size - dev.Free},
+	}
+	ui.showDevices()
+	assert.Contains(t, ui.table.GetCell(1, 6).Text, "0%")
+
+	ui.deviceItemSelected(1, 0)
+
+	assert.Nil(t, ui.currentDir)
+	assert.True(t, ui.pages.HasPage("error"))
+}
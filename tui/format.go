@@ -3,8 +3,11 @@ package tui
 import (
 	"fmt"
 	"math"
+	"slices"
+	"strings"
 
 	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/dundee/gdu/v5/pkg/analyze"
 	"github.com/dundee/gdu/v5/pkg/fs"
 	"github.com/rivo/tview"
 )
@@ -15,6 +18,8 @@ const (
 
 	defaultColor     = "[-::]"
 	defaultColorBold = "[::b]"
+
+	subvolumeColor = "[fuchsia::b]"
 )
 
 // getUsagePart returns the percentage (0-100) that the given item's size or
@@ -23,6 +28,12 @@ func (ui *UI) getUsagePart(item fs.Item, maxUsage, maxSize int64, ignored bool)
 	if ignored {
 		return 0
 	}
+	if ui.ShowInodes {
+		if count := item.GetItemCount(); count > 0 {
+			return float64(count) / float64(maxUsage) * 100.0
+		}
+		return 0
+	}
 	if ui.ShowApparentSize {
 		if size := item.GetSize(); size > 0 {
 			return float64(size) / float64(maxSize) * 100.0
@@ -40,6 +51,33 @@ func formatUsagePercentage(part float64) string {
 	return fmt.Sprintf(" %5.1f%%", part)
 }
 
+// formatValue formats the size column of the row, which shows
+// the number of inodes used by the item in the inode mode.
+func (ui *UI) formatValue(item fs.Item) string {
+	switch {
+	case ui.ShowInodes:
+		return fmt.Sprintf("%15s", ui.formatCount(item.GetItemCount()))
+	case ui.ShowApparentSize:
+		return fmt.Sprintf("%15s", ui.formatSize(item.GetSize(), false, true))
+	default:
+		return fmt.Sprintf("%15s", ui.formatSize(item.GetUsage(), false, true))
+	}
+}
+
+// formatPercentage formats the percentage shown next to the size bar.
+// In the inode mode it is relative to the inode capacity of the filesystem when known.
+func (ui *UI) formatPercentage(item fs.Item, part float64) string {
+	if ui.ShowInodes {
+		if percent, ok := ui.InodePercent(item.GetItemCount()); ok {
+			return formatUsagePercentage(percent)
+		}
+	}
+	if ui.showBarPercentage {
+		return formatUsagePercentage(part)
+	}
+	return ""
+}
+
 func (ui *UI) formatFileRow(item fs.Item, maxUsage, maxSize int64, marked, ignored bool) string {
 	partFloat := ui.getUsagePart(item, maxUsage, maxSize, ignored)
 	part := int(partFloat)
@@ -57,21 +95,32 @@ func (ui *UI) formatFileRow(item fs.Item, maxUsage, maxSize int64, marked, ignor
 		row += defaultColorBold
 	}
 
-	if ui.ShowApparentSize {
-		row += fmt.Sprintf("%15s", ui.formatSize(item.GetSize(), false, true))
-	} else {
-		row += fmt.Sprintf("%15s", ui.formatSize(item.GetUsage(), false, true))
-	}
-
-	if ui.showBarPercentage {
-		row += formatUsagePercentage(partFloat)
-	}
+	row += ui.formatValue(item)
+	row += ui.formatPercentage(item, partFloat)
 	if ui.useOldSizeBar {
 		row += " " + getUsageGraphOld(part) + " "
 	} else {
 		row += getUsageGraph(part)
 	}
 
+	if ui.ShowSharedUsage {
+		if ui.UseColors && !marked && !ignored {
+			row += numberColor
+		} else {
+			row += defaultColorBold
+		}
+		row += fmt.Sprintf("%15s ", ui.formatSize(analyze.SharedUsage(item), false, true))
+	}
+
+	if ui.showGitStatus {
+		if ui.UseColors && !marked && !ignored {
+			row += numberColor
+		} else {
+			row += defaultColorBold
+		}
+		row += ui.formatGitColumns(item)
+	}
+
 	if ui.showItemCount {
 		if ui.UseColors && !marked && !ignored {
 			row += numberColor
@@ -112,14 +161,29 @@ func (ui *UI) formatFileRow(item fs.Item, maxUsage, maxSize int64, marked, ignor
 		return row + name
 	}
 
+	name := item.GetName()
+	subvolume := item.IsDir() && item.GetFlag() == 'S'
 	if item.IsDir() {
-		if ui.UseColors && !marked && !ignored {
-			row += fmt.Sprintf("[%s::b]/", ui.resultRow.DirectoryColor)
+		switch {
+		case ui.UseColors && !marked && !ignored && subvolume:
+			row += subvolumeColor
+		case ui.UseColors && !marked && !ignored:
+			row += fmt.Sprintf("[%s::b]", ui.resultRow.DirectoryColor)
+		default:
+			row += defaultColorBold
+		}
+		// roots of a multi-path scan are shown with the full path
+		if displayName := analyze.DisplayName(item); displayName != name {
+			name = displayName
 		} else {
-			row += defaultColorBold + "/"
+			row += "/"
 		}
 	}
-	row += tview.Escape(item.GetName())
+	row += tview.Escape(name)
+	if subvolume {
+		row += " (subvolume)"
+	}
+	row += ui.formatJunkTag(item)
 
 	return row
 }
@@ -169,21 +233,23 @@ func (ui *UI) formatCollapsedRow(collapsedPath *CollapsedPath, maxUsage, maxSize
 		row += defaultColorBold
 	}
 
-	if ui.ShowApparentSize {
-		row += fmt.Sprintf("%15s", ui.formatSize(item.GetSize(), false, true))
-	} else {
-		row += fmt.Sprintf("%15s", ui.formatSize(item.GetUsage(), false, true))
-	}
-
-	if ui.showBarPercentage {
-		row += formatUsagePercentage(partFloat)
-	}
+	row += ui.formatValue(item)
+	row += ui.formatPercentage(item, partFloat)
 	if ui.useOldSizeBar {
 		row += " " + getUsageGraphOld(part) + " "
 	} else {
 		row += getUsageGraph(part)
 	}
 
+	if ui.showGitStatus {
+		if ui.UseColors && !marked && !ignored {
+			row += numberColor
+		} else {
+			row += defaultColorBold
+		}
+		row += ui.formatGitColumns(item)
+	}
+
 	if ui.showItemCount {
 		if ui.UseColors && !marked && !ignored {
 			row += numberColor
@@ -319,3 +385,24 @@ func formatWithDecPrefix(size int64, color string) string {
 		return fmt.Sprintf("%d%s B", size, color)
 	}
 }
+
+// sparkRunes are block characters of increasing height used by sparkline
+var sparkRunes = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws the values as a line of block characters scaled between the minimum and maximum
+func sparkline(values []int64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	low, high := slices.Min(values), slices.Max(values)
+
+	var sb strings.Builder
+	for _, v := range values {
+		idx := 0
+		if high > low {
+			idx = int((v - low) * int64(len(sparkRunes)-1) / (high - low))
+		}
+		sb.WriteRune(sparkRunes[idx])
+	}
+	return sb.String()
+}
@@ -3,6 +3,7 @@ package tui
 import (
 	"bytes"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/dundee/gdu/v5/internal/testapp"
@@ -236,3 +237,56 @@ func TestSizeBarWithPercentagePartial(t *testing.T) {
 
 	assert.Contains(t, ui.formatFileRow(file, dir.GetUsage(), dir.GetSize(), false, false), "50.0% [#####     ]")
 }
+
+func TestFormatFileRowOfMultiPathRoot(t *testing.T) {
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, false, false, false, false)
+
+	root := &analyze.Dir{File: &analyze.File{Name: "2 paths"}}
+	dir := &analyze.Dir{File: &analyze.File{Name: "data", Parent: root}, BasePath: "/mnt/nfs"}
+	nested := &analyze.Dir{File: &analyze.File{Name: "nested", Parent: dir}}
+
+	assert.Contains(t, ui.formatFileRow(dir, 1, 1, false, false), "[::b]/mnt/nfs/data")
+	assert.Contains(t, ui.formatFileRow(nested, 1, 1, false, false), "[::b]/nested")
+}
+
+func TestFormatFileRowOfSubvolume(t *testing.T) {
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, true, false, false, false)
+
+	parent := &analyze.Dir{File: &analyze.File{Name: "root"}}
+	subvolume := &analyze.Dir{File: &analyze.File{Name: "@home", Flag: 'S', Parent: parent}}
+	dir := &analyze.Dir{File: &analyze.File{Name: "home", Flag: ' ', Parent: parent}}
+
+	row := ui.formatFileRow(subvolume, 1, 1, false, false)
+	assert.True(t, strings.HasPrefix(row, "S"))
+	assert.Contains(t, row, subvolumeColor+"/@home (subvolume)")
+	assert.NotContains(t, ui.formatFileRow(dir, 1, 1, false, false), "subvolume")
+}
+
+func TestFormatFileRowWithSharedUsage(t *testing.T) {
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, false, false, false, false)
+	file := &analyze.File{Name: "clone.img", Usage: 8192, Shared: 4096}
+
+	assert.NotContains(t, ui.formatFileRow(file, 8192, 8192, false, false), "4.0[-::] KiB")
+
+	ui.ShowSharedUsage = true
+	assert.Contains(t, ui.formatFileRow(file, 8192, 8192, false, false), "4.0[-::] KiB")
+}
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "", sparkline(nil))
+	assert.Equal(t, "▁▁▁", sparkline([]int64{5, 5, 5}))
+	assert.Equal(t, "▁▄█", sparkline([]int64{0, 50, 100}))
+	assert.Equal(t, "█▁", sparkline([]int64{1 << 40, 0}))
+}
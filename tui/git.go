@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/gitrepo"
+)
+
+// SetShowGitStatus shows usage of tracked, ignored and untracked files of git repositories
+func (ui *UI) SetShowGitStatus() {
+	ui.showGitStatus = true
+}
+
+// classifyGitFiles finds git repositories in the analyzed tree when the git status is shown
+func (ui *UI) classifyGitFiles() {
+	if !ui.showGitStatus || ui.topDir == nil {
+		return
+	}
+	ui.gitUsage = gitrepo.Classify(ui.topDir)
+}
+
+// toggleGitStatus shows or hides usage of tracked, ignored and untracked files in git repositories
+func (ui *UI) toggleGitStatus() {
+	ui.showGitStatus = !ui.showGitStatus
+	if !ui.showGitStatus {
+		ui.gitIgnoredOnly = false
+		return
+	}
+	if ui.gitUsage == nil {
+		ui.classifyGitFiles()
+	}
+}
+
+// toggleGitIgnoredOnly lists only items containing files ignored by git, e.g. build outputs safe to delete
+func (ui *UI) toggleGitIgnoredOnly() {
+	if !ui.showGitStatus {
+		ui.toggleGitStatus()
+	}
+	ui.gitIgnoredOnly = !ui.gitIgnoredOnly
+	if ui.currentDir != nil {
+		ui.showDir()
+	}
+}
+
+// gitStats returns usage of tracked, ignored and untracked files in the item
+func (ui *UI) gitStats(item fs.Item) (gitrepo.Stats, bool) {
+	if !ui.showGitStatus || ui.gitUsage == nil {
+		return gitrepo.Stats{}, false
+	}
+	return ui.gitUsage.Get(item.GetPath())
+}
+
+// gitValue returns disk usage or apparent size of files of the status
+func (ui *UI) gitValue(stats gitrepo.Stats, status gitrepo.Status) int64 {
+	if ui.ShowApparentSize {
+		return stats.Size[status]
+	}
+	return stats.Usage[status]
+}
+
+// matchesGitFilter returns false for items without ignored files when only ignored items are listed
+func (ui *UI) matchesGitFilter(item fs.Item) bool {
+	if !ui.gitIgnoredOnly {
+		return true
+	}
+	stats, ok := ui.gitStats(item)
+	return ok && stats.Usage[gitrepo.Ignored]+stats.Size[gitrepo.Ignored] > 0
+}
+
+// formatGitColumns formats the tracked, ignored and untracked columns of the row
+func (ui *UI) formatGitColumns(item fs.Item) string {
+	stats, ok := ui.gitStats(item)
+	if !ok {
+		return fmt.Sprintf("%11s %11s %11s ", "", "", "")
+	}
+	return fmt.Sprintf(
+		"%11s %11s %11s ",
+		ui.formatSize(ui.gitValue(stats, gitrepo.Tracked), false, true),
+		ui.formatSize(ui.gitValue(stats, gitrepo.Ignored), false, true),
+		ui.formatSize(ui.gitValue(stats, gitrepo.Untracked), false, true),
+	)
+}
+
+// formatGitInfo returns footer text with usage of tracked, ignored and untracked files in the current dir
+func (ui *UI) formatGitInfo(numberColor, textColor string) string {
+	if !ui.showGitStatus || ui.currentDir == nil {
+		return ""
+	}
+	info := ""
+	if ui.gitIgnoredOnly {
+		info = " Git filter: " + numberColor + "ignored" + textColor
+	}
+	stats, ok := ui.gitStats(ui.currentDir)
+	if !ok {
+		return info
+	}
+	return info + " Tracked/ignored/untracked: " +
+		numberColor + ui.formatSize(ui.gitValue(stats, gitrepo.Tracked), true, false) + textColor + " / " +
+		numberColor + ui.formatSize(ui.gitValue(stats, gitrepo.Ignored), true, false) + textColor + " / " +
+		numberColor + ui.formatSize(ui.gitValue(stats, gitrepo.Untracked), true, false) + textColor
+}
+
+// gitInfoLines returns the lines of item info with usage of tracked, ignored and untracked files
+func (ui *UI) gitInfoLines(item fs.Item, numberColor string) string {
+	stats, ok := ui.gitStats(item)
+	if !ok {
+		return ""
+	}
+	lines := ""
+	for _, status := range []gitrepo.Status{gitrepo.Tracked, gitrepo.Ignored, gitrepo.Untracked} {
+		label := "Git " + status.String() + ":"
+		lines += strings.Repeat(" ", len("Apparent size:")-len(label)) + "[::b]" + label + "[::-] "
+		lines += numberColor + ui.formatSize(stats.Usage[status], false, true) + "[-::]"
+		lines += " (apparent " + numberColor + ui.formatSize(stats.Size[status], false, true) + "[-::])\n"
+	}
+	return lines
+}
+
+// wrapGitStats updates usage of the repositories after the item is deleted
+func (ui *UI) wrapGitStats(deleteFun func(fs.Item, fs.Item) error) func(fs.Item, fs.Item) error {
+	usage := ui.gitUsage
+	return func(parent, item fs.Item) error {
+		path := item.GetPath()
+		if err := deleteFun(parent, item); err != nil {
+			return err
+		}
+		usage.Remove(path)
+		return nil
+	}
+}
@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/gitrepo"
+)
+
+// createTestRepo turns test_dir into a git repository ignoring the subnested dir
+func createTestRepo(t *testing.T) {
+	t.Helper()
+	require.NoError(t, os.Mkdir(filepath.Join("test_dir", gitrepo.DirName), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join("test_dir", ".gitignore"), []byte("subnested/\n"), 0o600))
+}
+
+func TestToggleGitStatus(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	createTestRepo(t)
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	assert.Nil(t, ui.gitUsage)
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'R', 0))
+
+	assert.True(t, ui.showGitStatus)
+	assert.NotNil(t, ui.gitUsage)
+	stats, ok := ui.gitStats(ui.currentDir)
+	assert.True(t, ok)
+	assert.Equal(t, int64(5), stats.Size[gitrepo.Ignored])
+	assert.Equal(t, int64(2+11), stats.Size[gitrepo.Untracked])
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'R', 0))
+
+	assert.False(t, ui.showGitStatus)
+	_, ok = ui.gitStats(ui.currentDir)
+	assert.False(t, ok)
+}
+
+func TestGitIgnoredOnly(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	createTestRepo(t)
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'O', 0))
+
+	assert.True(t, ui.showGitStatus)
+	assert.True(t, ui.gitIgnoredOnly)
+	assert.Equal(t, 1, ui.table.GetRowCount())
+	assert.Contains(t, ui.table.GetCell(0, 0).Text, "nested")
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'O', 0))
+
+	assert.False(t, ui.gitIgnoredOnly)
+	assert.Equal(t, 3, ui.table.GetRowCount())
+}
+
+func TestFormatGitColumns(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	createTestRepo(t)
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.SetShowGitStatus()
+	ui.classifyGitFiles()
+
+	files := slices.Collect(ui.currentDir.GetFiles(fs.SortBySize, fs.SortDesc))
+	row := ui.formatFileRow(files[0], 4, 4, false, false)
+	assert.Contains(t, row, "0[-::] B    5[-::] B    2[-::] B")
+
+	assert.Contains(t, ui.formatGitInfo("", ""), "Tracked/ignored/untracked: 0")
+	assert.Contains(t, ui.gitInfoLines(files[0], ""), "Git ignored:")
+
+	ui.showGitStatus = false
+	assert.Equal(t, "", ui.formatGitInfo("", ""))
+	assert.Equal(t, "", ui.gitInfoLines(files[0], ""))
+}
+
+func TestWrapGitStats(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	createTestRepo(t)
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.SetShowGitStatus()
+	ui.classifyGitFiles()
+
+	nested := ui.currentDir.GetFiles(fs.SortByName, fs.SortAsc)
+	deleteFun := ui.wrapGitStats(func(_, _ fs.Item) error { return nil })
+
+	for item := range nested {
+		if item.GetName() == "nested" {
+			assert.NoError(t, deleteFun(ui.currentDir, item))
+		}
+	}
+
+	stats, _ := ui.gitStats(ui.currentDir)
+	assert.Equal(t, int64(0), stats.Size[gitrepo.Ignored])
+}
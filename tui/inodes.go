@@ -0,0 +1,42 @@
+package tui
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dundee/gdu/v5/pkg/device"
+)
+
+// SetShowInodes ranks items by the number of inodes they use instead of size.
+// Capacity is the total number of inodes of the filesystem, zero when unknown.
+func (ui *UI) SetShowInodes(capacity int64) {
+	ui.UI.SetShowInodes(capacity)
+	ui.resetSorting()
+}
+
+// toggleInodes switches between showing disk usage and inode usage
+func (ui *UI) toggleInodes() {
+	ui.ShowInodes = !ui.ShowInodes
+	if ui.ShowInodes && ui.InodeCapacity == 0 {
+		ui.updateInodeCapacity()
+	}
+	ui.resetSorting()
+}
+
+// updateInodeCapacity looks up the inode capacity of the filesystem containing the top dir
+func (ui *UI) updateInodeCapacity() {
+	if ui.topDirPath == "" {
+		return
+	}
+	getter := ui.getter
+	if getter == nil {
+		getter = device.Getter
+	}
+	devices, err := getter.GetDevicesInfo()
+	if err != nil {
+		log.Printf("loading inode capacity: %s", err)
+		return
+	}
+	if dev := device.GetDeviceForPath(ui.topDirPath, devices); dev != nil {
+		ui.InodeCapacity = dev.Inodes
+	}
+}
@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testapp"
+	"github.com/dundee/gdu/v5/internal/testdev"
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/device"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func TestToggleInodes(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.getter = testdev.DevicesInfoGetterMock{
+		Devices: []*device.Device{{Name: "/dev/root", MountPoint: "test_dir", Inodes: 400}},
+	}
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'u', 0))
+
+	assert.True(t, ui.ShowInodes)
+	assert.Equal(t, int64(400), ui.InodeCapacity)
+	assert.Equal(t, "itemCount", ui.sortBy)
+	assert.Equal(t, "desc", ui.sortOrder)
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'u', 0))
+
+	assert.False(t, ui.ShowInodes)
+	assert.Equal(t, "size", ui.sortBy)
+}
+
+func TestFormatFileRowInodes(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.SetShowInodes(400)
+
+	files := slices.Collect(ui.currentDir.GetFiles(fs.SortByName, fs.SortAsc))
+	row := ui.formatFileRow(files[0], 4, 4, false, false)
+
+	assert.Contains(t, row, "4[-::]")
+	assert.Contains(t, row, "  1.0%")
+	assert.Contains(t, row, "/nested")
+}
+
+func TestShowDevicesInodes(t *testing.T) {
+	app, simScreen := testapp.CreateTestAppWithSimScreen(50, 50)
+	defer simScreen.Fini()
+
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, false, false, false, false)
+	ui.SetShowInodes(0)
+	err := ui.ListDevices(testdev.DevicesInfoGetterMock{
+		Devices: []*device.Device{{Name: "/dev/root", MountPoint: "/", Inodes: 1000, FreeInodes: 250}},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, "Inodes", ui.table.GetCell(0, 1).Text)
+	assert.Contains(t, ui.table.GetCell(1, 2).Text, "750")
+}
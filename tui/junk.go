@@ -0,0 +1,201 @@
+package tui
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/junk"
+	"github.com/dundee/gdu/v5/pkg/plan"
+)
+
+// SetJunkClassifier sets the classifier with the user rules used to find the known junk
+func (ui *UI) SetJunkClassifier(classifier *junk.Classifier) {
+	ui.junkClassifier = classifier
+}
+
+// classifyJunk tags the known junk in the analyzed tree
+func (ui *UI) classifyJunk() {
+	if ui.junkClassifier == nil {
+		// the built-in rules are always valid
+		ui.junkClassifier, _ = junk.NewClassifier(nil, junk.DefaultRules())
+	}
+	ui.junkResult = ui.junkClassifier.Classify(ui.topDir)
+}
+
+// showJunk lists the categories of known junk with the space which can be reclaimed
+func (ui *UI) showJunk() *tview.Table {
+	if ui.topDir == nil {
+		return nil
+	}
+	if ui.junkResult == nil {
+		ui.classifyJunk()
+	}
+
+	table := tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+	table.SetBorder(true)
+	ui.fillJunkTable(table)
+
+	table.SetInputCapture(func(key *tcell.EventKey) *tcell.EventKey {
+		if key.Key() == tcell.KeyEsc || key.Rune() == 'q' {
+			ui.pages.RemovePage("junk")
+			ui.app.SetFocus(ui.table)
+			return nil
+		}
+		row, _ := table.GetSelection()
+		category, ok := table.GetCell(row, 0).GetReference().(junk.Category)
+		if !ok {
+			return key
+		}
+		switch {
+		case key.Key() == tcell.KeyEnter:
+			ui.showJunkItems(category)
+			return nil
+		case key.Rune() == ' ':
+			ui.markJunk(category)
+			ui.fillJunkTable(table)
+			table.Select(min(row+1, table.GetRowCount()-1), 0)
+			return nil
+		}
+		return key
+	})
+
+	ui.pages.AddPage("junk", modal(table, 120, 20), true, true)
+	ui.app.SetFocus(table)
+	return table
+}
+
+func (ui *UI) fillJunkTable(table *tview.Table) {
+	row, _ := table.GetSelection()
+	table.Clear()
+
+	categories := ui.junkResult.Categories()
+	slices.SortStableFunc(categories, func(a, b junk.Category) int {
+		return cmp.Compare(ui.junkValue(b), ui.junkValue(a))
+	})
+	var total int64
+	for _, category := range categories {
+		total += ui.junkValue(category)
+	}
+	table.SetTitle(
+		" Known junk ~ " + ui.formatSize(total, false, false) +
+			" reclaimable ~ enter list items, space mark all, esc close ",
+	)
+
+	header := []string{"", "Category", "Items", "Size", "Suggestion"}
+	for i, title := range header {
+		table.SetCell(0, i, tview.NewTableCell("[::b]"+title).SetSelectable(false))
+	}
+	if len(categories) == 0 {
+		table.SetCell(1, 1, tview.NewTableCell("No known junk found").SetSelectable(false))
+		return
+	}
+
+	for i, category := range categories {
+		mark := " "
+		if ui.junkMarked(category) {
+			mark = "*"
+		}
+		table.SetCell(i+1, 0, tview.NewTableCell(mark).SetReference(category))
+		table.SetCell(i+1, 1, tview.NewTableCell(tview.Escape(category.Name)))
+		table.SetCell(i+1, 2, tview.NewTableCell(strconv.Itoa(len(category.Items))).SetAlign(tview.AlignRight))
+		table.SetCell(i+1, 3, tview.NewTableCell(ui.formatSize(ui.junkValue(category), false, true)).
+			SetAlign(tview.AlignRight))
+		table.SetCell(i+1, 4, tview.NewTableCell(tview.Escape(category.Suggestion)).SetExpansion(1))
+	}
+	table.Select(max(1, min(row, len(categories))), 0)
+}
+
+// junkValue returns disk usage or apparent size of the items of the category
+func (ui *UI) junkValue(category junk.Category) int64 {
+	if ui.ShowApparentSize {
+		return category.Size
+	}
+	return category.Usage
+}
+
+// junkMarked returns true if all items of the category are marked
+func (ui *UI) junkMarked(category junk.Category) bool {
+	for _, item := range category.Items {
+		if _, ok := ui.plannedItems[plan.ItemPath(item)]; !ok {
+			return false
+		}
+	}
+	return len(category.Items) > 0
+}
+
+// markJunk marks all items of the category, or unmarks them if they are all marked already.
+// The marked items are shown in the directories and can be saved to a cleanup plan.
+func (ui *UI) markJunk(category junk.Category) {
+	if ui.junkMarked(category) {
+		for _, item := range category.Items {
+			delete(ui.plannedItems, plan.ItemPath(item))
+		}
+	} else {
+		for _, item := range category.Items {
+			ui.plannedItems[plan.ItemPath(item)] = plan.EntryFromItem(item, plan.ActionDelete)
+		}
+	}
+	if ui.currentDir != nil {
+		ui.markedRows = make(map[int]struct{})
+		ui.showDir()
+	}
+}
+
+// showJunkItems lists the items of the category in the table of search results where they can be deleted
+func (ui *UI) showJunkItems(category junk.Category) *tview.Table {
+	results := &searchResults{
+		dir:    ui.topDir,
+		title:  "Junk",
+		text:   category.Name,
+		marked: make(map[fs.Item]struct{}),
+		find: func() (fs.Files, error) {
+			for _, current := range ui.junkResult.Categories() {
+				if current.Name == category.Name {
+					return current.Items, nil
+				}
+			}
+			return nil, nil
+		},
+	}
+	if err := results.search(); err != nil {
+		ui.showErr("Error listing junk", err)
+		return nil
+	}
+	for _, item := range results.items {
+		if _, ok := ui.plannedItems[plan.ItemPath(item)]; ok {
+			results.marked[item] = struct{}{}
+		}
+	}
+
+	ui.pages.RemovePage("junk")
+	return ui.showResultsTable(results)
+}
+
+// formatJunkTag returns the category of known junk the item is tagged with
+func (ui *UI) formatJunkTag(item fs.Item) string {
+	if ui.junkResult == nil {
+		return ""
+	}
+	if name, ok := ui.junkResult.Get(item.GetPath()); ok {
+		return " (" + tview.Escape(name) + ")"
+	}
+	return ""
+}
+
+// wrapJunk removes the deleted item from the categories of known junk
+func (ui *UI) wrapJunk(deleteFun func(fs.Item, fs.Item) error) func(fs.Item, fs.Item) error {
+	result := ui.junkResult
+	return func(parent, item fs.Item) error {
+		path := item.GetPath()
+		if err := deleteFun(parent, item); err != nil {
+			return err
+		}
+		result.Remove(path)
+		return nil
+	}
+}
@@ -0,0 +1,202 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/junk"
+)
+
+// createTestJunk adds dependencies of Node.js and Python cache to test_dir
+func createTestJunk(t *testing.T) {
+	t.Helper()
+	for path, content := range map[string]string{
+		"test_dir/nested/node_modules/pkg/index.js": "module.exports = 1",
+		"test_dir/nested/__pycache__/main.pyc":      "pyc",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+}
+
+// sendKey passes the key through the global key handler to the table as the application does
+func sendKey(ui *UI, table *tview.Table, key *tcell.EventKey) {
+	if key = ui.keyPressed(key); key != nil {
+		table.GetInputCapture()(key)
+	}
+}
+
+func TestShowJunk(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	createTestJunk(t)
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'J', 0))
+	assert.True(t, ui.pages.HasPage("junk"))
+
+	table := ui.showJunk()
+	require.NotNil(t, table)
+	assert.Equal(t, 3, table.GetRowCount())
+	assert.Equal(t, "Node.js modules", table.GetCell(1, 1).Text)
+	assert.Equal(t, "Python caches", table.GetCell(2, 1).Text)
+	assert.Contains(t, table.GetTitle(), "Known junk ~ 21")
+
+	pressSearchKey(table, tcell.NewEventKey(tcell.KeyEsc, 0, 0))
+	assert.False(t, ui.pages.HasPage("junk"))
+}
+
+func TestShowJunkWithoutJunk(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+
+	table := ui.showJunk()
+	require.NotNil(t, table)
+	assert.Equal(t, "No known junk found", table.GetCell(1, 1).Text)
+}
+
+func TestMarkJunk(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	createTestJunk(t)
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	table := ui.showJunk()
+	table.Select(1, 0)
+
+	pressSearchKey(table, tcell.NewEventKey(tcell.KeyRune, ' ', 0))
+	assert.Equal(t, "*", table.GetCell(1, 0).Text)
+	assert.Len(t, ui.plannedItems, 1)
+	path, err := filepath.Abs(filepath.Join("test_dir", "nested", "node_modules"))
+	require.NoError(t, err)
+	assert.Contains(t, ui.plannedItems, path)
+
+	table.Select(1, 0)
+	pressSearchKey(table, tcell.NewEventKey(tcell.KeyRune, ' ', 0))
+	assert.Equal(t, " ", table.GetCell(1, 0).Text)
+	assert.Empty(t, ui.plannedItems)
+}
+
+func TestJunkKeysNotHandledByMainTable(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	createTestJunk(t)
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	table := ui.showJunk()
+	table.Select(1, 0)
+
+	sendKey(ui, table, tcell.NewEventKey(tcell.KeyRune, ' ', 0))
+	assert.Empty(t, ui.markedRows)
+	assert.Equal(t, "*", table.GetCell(1, 0).Text)
+	assert.Len(t, ui.plannedItems, 1)
+
+	// q closes the view instead of quitting gdu
+	key := ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'q', 0))
+	require.NotNil(t, key)
+	table.GetInputCapture()(key)
+	assert.False(t, ui.pages.HasPage("junk"))
+}
+
+func TestCloseJunkByEsc(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	createTestJunk(t)
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	table := ui.showJunk()
+
+	sendKey(ui, table, tcell.NewEventKey(tcell.KeyEsc, 0, 0))
+	assert.False(t, ui.pages.HasPage("junk"))
+}
+
+func TestShowJunkItems(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	createTestJunk(t)
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	table := ui.showJunk()
+	table.Select(1, 0)
+	pressSearchKey(table, tcell.NewEventKey(tcell.KeyRune, ' ', 0))
+	table.Select(1, 0)
+	pressSearchKey(table, tcell.NewEventKey(tcell.KeyEnter, 0, 0))
+
+	assert.False(t, ui.pages.HasPage("junk"))
+	assert.True(t, ui.pages.HasPage("search"))
+
+	// items of the marked category are marked in the list
+	var modules junk.Category
+	for _, category := range ui.junkResult.Categories() {
+		if category.Name == "Node.js modules" {
+			modules = category
+		}
+	}
+	results := ui.showJunkItems(modules)
+	require.NotNil(t, results)
+	assert.Contains(t, results.GetTitle(), "Junk: Node.js modules ~ 1 items")
+	assert.Equal(t, []string{"nested/node_modules/"}, searchResultPaths(results))
+	assert.Equal(t, "*", results.GetCell(1, 0).Text)
+}
+
+func TestFormatJunkTag(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	createTestJunk(t)
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	nested := ui.topDir.GetFiles(fs.SortByName, fs.SortAsc)
+	var dir fs.Item
+	for item := range nested {
+		dir = item
+	}
+	var modules fs.Item
+	for item := range dir.GetFiles(fs.SortByName, fs.SortAsc) {
+		if item.GetName() == "node_modules" {
+			modules = item
+		}
+	}
+	require.NotNil(t, modules)
+
+	assert.Equal(t, "", ui.formatJunkTag(modules))
+	ui.classifyJunk()
+	assert.Equal(t, " (Node.js modules)", ui.formatJunkTag(modules))
+	assert.Contains(t, ui.formatFileRow(modules, 100, 100, false, false), "node_modules (Node.js modules)")
+
+	deleteFun := ui.wrapJunk(func(_, _ fs.Item) error { return nil })
+	assert.NoError(t, deleteFun(dir, modules))
+	assert.Equal(t, "", ui.formatJunkTag(modules))
+	assert.Len(t, ui.junkResult.Categories(), 1)
+}
+
+func TestSetJunkClassifier(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	createTestJunk(t)
+
+	classifier, err := junk.NewClassifier([]junk.Rule{
+		{Category: "Python caches"},
+		{Category: "Text files", Patterns: []string{"file*"}, Suggestion: "rm"},
+	}, junk.DefaultRules())
+	require.NoError(t, err)
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.SetJunkClassifier(classifier)
+	table := ui.showJunk()
+
+	names := []string{}
+	for row := 1; row < table.GetRowCount(); row++ {
+		names = append(names, table.GetCell(row, 1).Text)
+	}
+	assert.ElementsMatch(t, []string{"Node.js modules", "Text files"}, names)
+}
@@ -0,0 +1,200 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// keyAction is an action of the interactive mode which can be bound to a key
+type keyAction struct {
+	name        string
+	defaultKey  rune
+	description string
+	section     string
+}
+
+// help sections of the key actions, in the order they are shown in the help modal
+const (
+	sectionNavigation = ""
+	sectionGeneral    = "general"
+	sectionItem       = "Item under cursor:"
+	sectionSort       = "Sort by (twice toggles asc/desc):"
+)
+
+// keyActions is the registry of all actions which can be rebound, in the order shown in help
+var keyActions = []keyAction{
+	{"right", 'l', "Go to directory/device", sectionNavigation},
+	{"left", 'h', "Go to parent directory", sectionNavigation},
+
+	{"help", '?', "Show this help", sectionGeneral},
+	{"rescan", 'r', "Rescan current directory", sectionGeneral},
+	{"scan-all-devices", 'A', "Scan all devices in background (devices view)", sectionGeneral},
+	{"export", 'E', "Export analysis data to file as JSON", sectionGeneral},
+	{"browse-trash", 't', "Browse trash, restore or purge trashed items", sectionGeneral},
+	{"search", '/', "Search items by name", sectionGeneral},
+	{"find", 'F', "Search items recursively (glob, regex, size, mtime)", sectionGeneral},
+	{"junk", 'J', "Show known junk (caches, build outputs) by category", sectionGeneral},
+	{"scan-errors", '!', "Show directories and files which could not be read", sectionGeneral},
+	{"filter-type", 'T', "Filter items by file type (extension)", sectionGeneral},
+	{"toggle-apparent-size", 'a', "Toggle between showing disk usage and apparent size", sectionGeneral},
+	{"toggle-relative-size", 'B', "Toggle bar alignment to biggest file or directory", sectionGeneral},
+	{"toggle-item-count", 'c', "Show/hide file count", sectionGeneral},
+	{"toggle-mtime", 'm', "Show/hide latest mtime", sectionGeneral},
+	{"toggle-inodes", 'u', "Toggle between showing disk usage and inode usage", sectionGeneral},
+	{"toggle-git-status", 'R', "Show/hide git tracked/ignored/untracked usage", sectionGeneral},
+	{"git-ignored-only", 'O', "Show only items ignored by git (build outputs)", sectionGeneral},
+	{"shell", 'b', "Spawn shell in current directory", sectionGeneral},
+	{"quit", 'q', "Quit gdu (asks to confirm after a long scan)", sectionGeneral},
+	{"quit-print-path", 'Q', "Quit gdu and print current directory path", sectionGeneral},
+
+	{"delete", 'd', "Delete file or directory", sectionItem},
+	{"empty", 'e', "Empty file or directory", sectionItem},
+	{"move-to-trash", 'D', "Move file or directory to trash", sectionItem},
+	{"shred", 'S', "Shred file or directory (overwrite and delete)", sectionItem},
+	{"compress", 'z', "Compress file or directory in place (zstd/gzip)", sectionItem},
+	{"mark", ' ', "Mark file or directory for deletion", sectionItem},
+	{"print-marked", 'p', "Print marked items paths to stdout after quitting", sectionItem},
+	{"save-plan", 'P', "Review marked items and save them to a cleanup plan", sectionItem},
+	{"ignore", 'I', "Ignore file or directory", sectionItem},
+	{"view", 'v', "Show content of file", sectionItem},
+	{"open", 'o', "Open file or directory in external program", sectionItem},
+	{"info", 'i', "Show info about item", sectionItem},
+
+	{"sort-name", 'n', "Sort by name (asc/desc)", sectionSort},
+	{"sort-size", 's', "Sort by size (asc/desc)", sectionSort},
+	{"sort-count", 'C', "Sort by file count (asc/desc)", sectionSort},
+	{"sort-mtime", 'M', "Sort by mtime (asc/desc)", sectionSort},
+}
+
+// reservedKeys are used by the table for moving the cursor and cannot be bound to actions
+var reservedKeys = []rune{'j', 'k', 'g', 'G'}
+
+// KeyBindings maps keys to the actions of the interactive mode
+type KeyBindings struct {
+	byAction map[string]rune
+	byKey    map[rune]string
+	defaults map[rune]string
+}
+
+// NewKeyBindings creates key bindings from the defaults overridden by the given map of action names to keys.
+// An empty key or "none" disables the action.
+func NewKeyBindings(overrides map[string]string) (*KeyBindings, error) {
+	k := &KeyBindings{
+		byAction: make(map[string]rune, len(keyActions)),
+		byKey:    make(map[rune]string, len(keyActions)),
+		defaults: make(map[rune]string, len(keyActions)),
+	}
+	for _, action := range keyActions {
+		k.byAction[action.name] = action.defaultKey
+		k.defaults[action.defaultKey] = action.name
+	}
+
+	// iterate in sorted order so that the reported errors are deterministic
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, ok := k.byAction[name]; !ok {
+			return nil, fmt.Errorf("unknown action %q in key bindings", name)
+		}
+		key, err := parseKey(overrides[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid key for action %q: %w", name, err)
+		}
+		if slices.Contains(reservedKeys, key) {
+			return nil, fmt.Errorf("key %q of action %q is reserved for moving the cursor", keyName(key), name)
+		}
+		k.byAction[name] = key
+	}
+
+	for _, action := range keyActions {
+		key := k.byAction[action.name]
+		if key == 0 {
+			continue
+		}
+		if other, ok := k.byKey[key]; ok {
+			return nil, fmt.Errorf("key %q is bound to both %q and %q", keyName(key), other, action.name)
+		}
+		k.byKey[key] = action.name
+	}
+	return k, nil
+}
+
+// DefaultKeyBindings returns the default key bindings
+func DefaultKeyBindings() *KeyBindings {
+	k, _ := NewKeyBindings(nil)
+	return k
+}
+
+// Key returns the key bound to the action, false is returned for disabled actions
+func (k *KeyBindings) Key(action string) (rune, bool) {
+	key := k.byAction[action]
+	return key, key != 0
+}
+
+// Name returns the displayed name of the key bound to the action
+func (k *KeyBindings) Name(action string) string {
+	key, ok := k.Key(action)
+	if !ok {
+		return ""
+	}
+	return keyName(key)
+}
+
+// translate converts the pressed key to the default key of the bound action
+// so that the key handlers can work with the default keys only.
+// Default keys of rebound or disabled actions are swallowed.
+func (k *KeyBindings) translate(key *tcell.EventKey) *tcell.EventKey {
+	if key.Key() != tcell.KeyRune {
+		return key
+	}
+	if action, ok := k.byKey[key.Rune()]; ok {
+		defaultKey := k.defaultKey(action)
+		if defaultKey == key.Rune() {
+			return key
+		}
+		return tcell.NewEventKey(tcell.KeyRune, defaultKey, key.Modifiers())
+	}
+	if _, ok := k.defaults[key.Rune()]; ok {
+		return nil
+	}
+	return key
+}
+
+func (k *KeyBindings) defaultKey(action string) rune {
+	for _, a := range keyActions {
+		if a.name == action {
+			return a.defaultKey
+		}
+	}
+	return 0
+}
+
+func parseKey(value string) (rune, error) {
+	switch strings.ToLower(value) {
+	case "", "none":
+		return 0, nil
+	case "space":
+		return ' ', nil
+	}
+	if utf8.RuneCountInString(value) != 1 {
+		return 0, fmt.Errorf("%q is not a single character", value)
+	}
+	key, _ := utf8.DecodeRuneInString(value)
+	return key, nil
+}
+
+func keyName(key rune) string {
+	if key == ' ' {
+		return "space"
+	}
+	return string(key)
+}
@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testapp"
+	"github.com/dundee/gdu/v5/internal/testdir"
+)
+
+func TestDefaultKeyBindings(t *testing.T) {
+	k := DefaultKeyBindings()
+
+	key, ok := k.Key("delete")
+	assert.True(t, ok)
+	assert.Equal(t, 'd', key)
+	assert.Equal(t, "space", k.Name("mark"))
+}
+
+func TestNewKeyBindings(t *testing.T) {
+	k, err := NewKeyBindings(map[string]string{
+		"delete":       "x",
+		"mark":         "m",
+		"toggle-mtime": "none",
+		"shred":        "",
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, "x", k.Name("delete"))
+	assert.Equal(t, "m", k.Name("mark"))
+	_, ok := k.Key("toggle-mtime")
+	assert.False(t, ok)
+	assert.Equal(t, "", k.Name("shred"))
+}
+
+func TestNewKeyBindingsWithErrors(t *testing.T) {
+	_, err := NewKeyBindings(map[string]string{"xxx": "x"})
+	assert.EqualError(t, err, `unknown action "xxx" in key bindings`)
+
+	_, err = NewKeyBindings(map[string]string{"delete": "ctrl+d"})
+	assert.EqualError(t, err, `invalid key for action "delete": "ctrl+d" is not a single character`)
+
+	_, err = NewKeyBindings(map[string]string{"delete": "j"})
+	assert.EqualError(t, err, `key "j" of action "delete" is reserved for moving the cursor`)
+
+	_, err = NewKeyBindings(map[string]string{"delete": "S"})
+	assert.EqualError(t, err, `key "S" is bound to both "delete" and "shred"`)
+}
+
+func TestTranslateKey(t *testing.T) {
+	k, err := NewKeyBindings(map[string]string{"delete": "x", "empty": "none"})
+	assert.Nil(t, err)
+
+	key := k.translate(tcell.NewEventKey(tcell.KeyRune, 'x', 0))
+	assert.Equal(t, 'd', key.Rune())
+
+	assert.Nil(t, k.translate(tcell.NewEventKey(tcell.KeyRune, 'd', 0)))
+	assert.Nil(t, k.translate(tcell.NewEventKey(tcell.KeyRune, 'e', 0)))
+
+	key = k.translate(tcell.NewEventKey(tcell.KeyRune, 's', 0))
+	assert.Equal(t, 's', key.Rune())
+	key = k.translate(tcell.NewEventKey(tcell.KeyRune, 'j', 0))
+	assert.Equal(t, 'j', key.Rune())
+	key = k.translate(tcell.NewEventKey(tcell.KeyEnter, 0, 0))
+	assert.Equal(t, tcell.KeyEnter, key.Key())
+}
+
+func TestReboundKeyPressed(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	k, err := NewKeyBindings(map[string]string{"sort-name": "N", "sort-size": "none"})
+	assert.Nil(t, err)
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.SetKeyBindings(k)
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'n', 0))
+	assert.Equal(t, "size", ui.sortBy)
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'N', 0))
+	assert.Equal(t, "name", ui.sortBy)
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 's', 0))
+	assert.Equal(t, "name", ui.sortBy)
+}
+
+func TestHelpWithReboundKeys(t *testing.T) {
+	app, simScreen := testapp.CreateTestAppWithSimScreen(50, 50)
+	defer simScreen.Fini()
+
+	k, err := NewKeyBindings(map[string]string{"delete": "x", "right": "L", "shell": "none", "help": "H"})
+	assert.Nil(t, err)
+
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, false, true, false, false, func(ui *UI) {
+		ui.SetKeyBindings(k)
+	})
+	text := ui.formatHelpTextFor()
+
+	assert.Contains(t, text, "[::b]x     [white:black:-]Delete file or directory")
+	assert.Contains(t, text, "[::b]enter, right, L     [white:black:-]Go to directory/device")
+	assert.NotContains(t, text, "Spawn shell")
+	assert.Equal(t, " gdu ~ Use arrow keys to navigate, press H for help ", ui.header.GetText(false))
+}
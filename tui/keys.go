@@ -24,13 +24,23 @@ func (ui *UI) keyPressed(key *tcell.EventKey) *tcell.EventKey {
 		return nil
 	}
 
-	if ui.pages.HasPage("file") || ui.pages.HasPage("export") {
+	if ui.pages.HasPage("file") || ui.pages.HasPage("export") || ui.pages.HasPage("plan") ||
+		ui.pages.HasPage("trash") || ui.pages.HasPage("search") || ui.pages.HasPage("search-input") ||
+		ui.pages.HasPage("junk") || ui.pages.HasPage("scan-errors") {
 		return key // send event to primitive
 	}
 	if ui.filtering || ui.typeFiltering {
 		return key
 	}
 
+	// buttons of confirmation dialogs keep their fixed keys
+	if !ui.pages.HasPage("confirm") {
+		key = ui.keyBindings.translate(key)
+		if key == nil {
+			return nil
+		}
+	}
+
 	key = ui.handleClosingModals(key)
 	if key == nil {
 		return nil
@@ -59,7 +69,8 @@ func (ui *UI) keyPressed(key *tcell.EventKey) *tcell.EventKey {
 	if ui.pages.HasPage("progress") ||
 		ui.pages.HasPage("deleting") ||
 		ui.pages.HasPage("emptying") ||
-		ui.pages.HasPage("moving to trash") {
+		ui.pages.HasPage("moving to trash") ||
+		ui.pages.HasPage("shredding") {
 		// allow peeking at the results found so far during a scan
 		if key.Key() == tcell.KeyTab && ui.pages.HasPage("progress") {
 			ui.enterPreview()
@@ -232,8 +243,10 @@ func (ui *UI) confirmQuitDialog(printCurrentDirPath bool) {
 	} else {
 		text = "Do you really want to quit gdu?\n\n" +
 			"This scan took " + ui.scanDuration.Round(time.Second).String() +
-			" and the results are not saved.\n" +
-			"Choose \"no\" and press E to export them first."
+			" and the results are not saved."
+		if key := ui.keyBindings.Name("export"); key != "" {
+			text += "\nChoose \"no\" and press " + key + " to export them first."
+		}
 	}
 	modal := tview.NewModal().
 		SetText(text).
@@ -334,7 +347,7 @@ func (ui *UI) handlePreviewKeys(key *tcell.EventKey) *tcell.EventKey {
 	case 's', 'C', 'n', 'M':
 		ui.handleSorting(key)
 		return nil
-	case 'a', 'B', 'c', 'm':
+	case 'a', 'B', 'c', 'm', 'u', 'R':
 		ui.handleToggles(key)
 		return nil
 	}
@@ -445,6 +458,18 @@ func (ui *UI) handleMainActions(key *tcell.EventKey) *tcell.EventKey {
 			return nil
 		}
 		ui.handleDelete(ActionMoveToTrash)
+	case 'S':
+		if ui.isInArchive() {
+			ui.showErr("Deletion is not supported in archives", nil)
+			return nil
+		}
+		ui.handleDelete(ActionShred)
+	case 'z':
+		if ui.isInArchive() {
+			ui.showErr("Compressing is not supported in archives", nil)
+			return nil
+		}
+		ui.confirmCompress()
 	case 'v':
 		if ui.isInArchive() {
 			ui.showErr("Viewing content is not supported in archives", nil)
@@ -485,20 +510,36 @@ func (ui *UI) handleMainActions(key *tcell.EventKey) *tcell.EventKey {
 		ui.openItem()
 	case 'i':
 		ui.showInfo()
-	case 'a', 'B', 'c', 'm':
+	case 'a', 'B', 'c', 'm', 'u', 'R':
 		ui.handleToggles(key)
 	case 'r':
 		if ui.currentDir != nil {
 			ui.rescanDir()
 		}
+	case 'A':
+		if ui.currentDir == nil && ui.devices != nil {
+			ui.scanAllDevices()
+		}
 	case 'E':
 		ui.confirmExport()
 		return nil
+	case 't':
+		ui.showTrash()
+		return nil
 	case 's', 'C', 'n', 'M':
 		ui.handleSorting(key)
 	case '/':
 		ui.showFilterInput()
 		return nil
+	case 'F':
+		ui.showSearchInput()
+		return nil
+	case 'J':
+		ui.showJunk()
+		return nil
+	case '!':
+		ui.showScanErrors()
+		return nil
 	case 'T':
 		ui.showTypeFilterInput()
 		return nil
@@ -507,8 +548,14 @@ func (ui *UI) handleMainActions(key *tcell.EventKey) *tcell.EventKey {
 	case 'p':
 		ui.printMarked()
 		return nil
+	case 'P':
+		ui.showPlan()
+		return nil
 	case 'I':
 		ui.ignoreItem()
+	case 'O':
+		ui.toggleGitIgnoredOnly()
+		return nil
 	}
 	return key
 }
@@ -523,11 +570,17 @@ func (ui *UI) handleToggles(key *tcell.EventKey) {
 		ui.showItemCount = !ui.showItemCount
 	case 'm':
 		ui.showMtime = !ui.showMtime
+	case 'u':
+		ui.toggleInodes()
+	case 'R':
+		ui.toggleGitStatus()
 	}
 	if ui.currentDir != nil {
 		row, column := ui.table.GetSelection()
 		ui.showDir()
 		ui.table.Select(row, column)
+	} else if ui.devices != nil {
+		ui.showDevices()
 	}
 }
 
@@ -15,6 +15,7 @@ import (
 	"github.com/dundee/gdu/v5/pkg/analyze"
 	"github.com/dundee/gdu/v5/pkg/device"
 	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/remove"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/stretchr/testify/assert"
@@ -910,6 +911,60 @@ func TestMoveToTrashWithNoDelete(t *testing.T) {
 	assert.DirExists(t, "test_dir/nested")
 }
 
+func TestShred(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, false, true, false, false)
+	ui.done = make(chan struct{})
+	ui.askBeforeDelete = false
+	ui.SetShredder(remove.Shredder{Passes: 1, Force: true})
+	err := ui.AnalyzePath("test_dir", nil)
+	assert.Nil(t, err)
+
+	<-ui.done // wait for analyzer
+
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+
+	assert.Equal(t, 1, ui.table.GetRowCount())
+	ui.table.Select(0, 0)
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'S', 0))
+
+	<-ui.done
+
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+
+	assert.NoDirExists(t, "test_dir/nested")
+	assert.Equal(t, 0, ui.table.GetRowCount())
+}
+
+func TestShredWithNoDelete(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	var shredded fs.Item
+	ui.shredder = func(dir, item fs.Item) error {
+		shredded = item
+		return nil
+	}
+
+	ui.table.Select(0, 0)
+	ui.SetNoDelete()
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'S', 0))
+
+	assert.Nil(t, shredded)
+	assert.DirExists(t, "test_dir/nested")
+}
+
 func TestDeleteWithNoDelete(t *testing.T) {
 	fin := testdir.CreateTestDir()
 	defer fin()
@@ -7,6 +7,7 @@ import (
 	"golang.org/x/text/language"
 
 	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/plan"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
@@ -14,6 +15,7 @@ import (
 func (ui *UI) fileItemMarked(row int) {
 	if _, ok := ui.markedRows[row]; ok {
 		delete(ui.markedRows, row)
+		ui.unplanItem(row)
 	} else {
 		ui.markedRows[row] = struct{}{}
 	}
@@ -42,8 +44,6 @@ func (ui *UI) deleteMarked(action DeleteAction) {
 
 	currentRow, _ := ui.table.GetSelection()
 
-	var deleteFun func(fs.Item, fs.Item) error
-
 	go func() {
 		for _, one := range markedItems {
 			ui.app.QueueUpdateDraw(func() {
@@ -55,18 +55,7 @@ func (ui *UI) deleteMarked(action DeleteAction) {
 				)
 			})
 
-			switch action {
-			case ActionEmpty:
-				if !one.IsDir() {
-					deleteFun = ui.emptier
-				} else {
-					deleteFun = ui.remover
-				}
-			case ActionMoveToTrash:
-				deleteFun = ui.trasher
-			case ActionDelete:
-				deleteFun = ui.remover
-			}
+			deleteFun := ui.getDeleteFunc(action, one)
 
 			var deleteItems []fs.Item
 			if action == ActionEmpty && one.IsDir() {
@@ -97,6 +86,9 @@ func (ui *UI) deleteMarked(action DeleteAction) {
 		ui.app.QueueUpdateDraw(func() {
 			ui.pages.RemovePage(acting)
 			ui.pages.RemovePage(acting)
+			for _, one := range markedItems {
+				delete(ui.plannedItems, plan.ItemPath(one))
+			}
 			ui.markedRows = make(map[int]struct{})
 			x, y := ui.table.GetOffset()
 			ui.showDir()
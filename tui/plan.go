@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/dundee/gdu/v5/build"
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/plan"
+)
+
+// SetPlan loads entries of a cleanup plan so that the planned items are shown as marked
+func (ui *UI) SetPlan(p *plan.Plan) {
+	for _, entry := range p.Entries {
+		ui.plannedItems[entry.Path] = entry
+	}
+}
+
+// markPlannedItem marks the row of an item which is part of the loaded cleanup plan
+func (ui *UI) markPlannedItem(item fs.Item, row int) bool {
+	if _, ok := ui.plannedItems[plan.ItemPath(item)]; !ok {
+		return false
+	}
+	ui.markedRows[row] = struct{}{}
+	return true
+}
+
+// unplanItem removes item from the cleanup plan when it is unmarked
+func (ui *UI) unplanItem(row int) {
+	if item, ok := ui.table.GetCell(row, 0).GetReference().(fs.Item); ok {
+		delete(ui.plannedItems, plan.ItemPath(item))
+	}
+}
+
+// collectPlan creates cleanup plan from the loaded plan entries and the currently marked rows
+func (ui *UI) collectPlan(action plan.Action) *plan.Plan {
+	p := plan.New()
+
+	paths := make([]string, 0, len(ui.plannedItems))
+	for path := range ui.plannedItems {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	for _, path := range paths {
+		p.Add(ui.plannedItems[path])
+	}
+
+	rows := make([]int, 0, len(ui.markedRows))
+	for row := range ui.markedRows {
+		rows = append(rows, row)
+	}
+	slices.Sort(rows)
+	for _, row := range rows {
+		item, ok := ui.table.GetCell(row, 0).GetReference().(fs.Item)
+		if !ok {
+			continue
+		}
+		if _, planned := ui.plannedItems[plan.ItemPath(item)]; planned {
+			continue
+		}
+		p.Add(plan.EntryFromItem(item, action))
+	}
+	return p
+}
+
+func (ui *UI) showPlan() *tview.Form {
+	if ui.currentDir == nil {
+		return nil
+	}
+
+	action := plan.ActionDelete
+	options := make([]string, 0, len(plan.Actions))
+	for _, a := range plan.Actions {
+		options = append(options, string(a))
+	}
+
+	review := tview.NewTextView().SetDynamicColors(true)
+	review.SetBorder(true).SetTitle(" Planned items ")
+	review.SetText(ui.formatPlanReview(ui.collectPlan(action)))
+
+	form := tview.NewForm().
+		AddInputField("File name", ui.planName, 30, nil, func(v string) {
+			ui.planName = v
+		}).
+		AddDropDown("Action for marked items", options, 0, func(option string, _ int) {
+			action = plan.Action(option)
+			review.SetText(ui.formatPlanReview(ui.collectPlan(action)))
+		}).
+		AddButton("Save", func() {
+			ui.savePlan(action)
+		}).
+		SetButtonsAlign(tview.AlignCenter)
+	form.SetInputCapture(func(key *tcell.EventKey) *tcell.EventKey {
+		if key.Key() == tcell.KeyEsc {
+			ui.pages.RemovePage("plan")
+			ui.app.SetFocus(ui.table)
+			return nil
+		}
+		return key
+	})
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(review, 0, 1, false).
+		AddItem(form, 9, 1, true)
+	flex.SetBorder(true).SetTitle(" Save cleanup plan ")
+
+	ui.pages.AddPage("plan", modal(flex, 100, 30), true, true)
+	ui.app.SetFocus(form)
+	return form
+}
+
+// formatPlanReview lists the planned items, highlighting those which changed since they were planned
+func (ui *UI) formatPlanReview(p *plan.Plan) string {
+	if len(p.Entries) == 0 {
+		return "No items are marked."
+	}
+
+	var b strings.Builder
+	for _, reviewed := range p.Review(os.Lstat) {
+		color := "[::b]"
+		if ui.UseColors {
+			switch reviewed.Status {
+			case plan.StatusChanged:
+				color = "[yellow::b]"
+			case plan.StatusMissing:
+				color = "[red::b]"
+			case plan.StatusUnchanged:
+				color = "[green::b]"
+			}
+		}
+		b.WriteString(color + reviewed.Status.String() + "[-::-] ")
+		b.WriteString(string(reviewed.Action) + " ")
+		b.WriteString(ui.formatSize(reviewed.Usage, false, true) + " ")
+		b.WriteString(tview.Escape(strings.TrimPrefix(reviewed.Path, build.RootPathPrefix)))
+		if reviewed.Reason != "" {
+			b.WriteString(" (" + tview.Escape(reviewed.Reason) + ")")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (ui *UI) savePlan(action plan.Action) {
+	p := ui.collectPlan(action)
+	if err := p.SaveFile(ui.planName); err != nil {
+		ui.showErr("Error saving cleanup plan", err)
+		return
+	}
+	for _, entry := range p.Entries {
+		ui.plannedItems[entry.Path] = entry
+	}
+	ui.pages.RemovePage("plan")
+	ui.app.SetFocus(ui.table)
+}
@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/plan"
+)
+
+func TestShowAndSavePlan(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, true, true, false)
+	ui.planName = filepath.Join(t.TempDir(), "plan.json")
+
+	ui.table.Select(0, 0)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, ' ', 0))
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'P', 0))
+	assert.True(t, ui.pages.HasPage("plan"))
+
+	ui.savePlan(plan.ActionTrash)
+	assert.False(t, ui.pages.HasPage("plan"))
+
+	p, err := plan.LoadFile(ui.planName)
+	assert.Nil(t, err)
+	assert.Len(t, p.Entries, 1)
+	abs, _ := filepath.Abs("test_dir/nested")
+	assert.Equal(t, abs, p.Entries[0].Path)
+	assert.Equal(t, plan.ActionTrash, p.Entries[0].Action)
+	assert.True(t, p.Entries[0].IsDir)
+}
+
+func TestShowPlanEsc(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	form := ui.showPlan()
+	assert.True(t, ui.pages.HasPage("plan"))
+
+	// keys are passed to the form while the plan is shown
+	assert.NotNil(t, ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'q', 0)))
+
+	form.GetInputCapture()(tcell.NewEventKey(tcell.KeyEsc, 0, 0))
+	assert.False(t, ui.pages.HasPage("plan"))
+}
+
+func TestSavePlanError(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.planName = filepath.Join(t.TempDir(), "nonexistent", "plan.json")
+	ui.showPlan()
+	ui.savePlan(plan.ActionDelete)
+
+	assert.True(t, ui.pages.HasPage("error"))
+}
+
+func TestLoadedPlanMarksItems(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	abs, _ := filepath.Abs("test_dir/nested/file2")
+	info, _ := os.Lstat(abs)
+	p := plan.New()
+	p.Add(plan.Entry{Path: abs, Size: info.Size(), Mtime: info.ModTime(), Action: plan.ActionEmpty})
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.SetPlan(p)
+
+	ui.fileItemSelected(0, 0) // enter nested
+	assert.Equal(t, "nested", ui.currentDir.GetName())
+
+	row := -1
+	for i := 0; i < ui.table.GetRowCount(); i++ {
+		if item, ok := ui.table.GetCell(i, 0).GetReference().(fs.Item); ok && item.GetName() == "file2" {
+			row = i
+		}
+	}
+	assert.Contains(t, ui.markedRows, row)
+	assert.Contains(t, ui.formatPlanReview(ui.collectPlan(plan.ActionDelete)), "unchanged[-::-] empty")
+
+	ui.fileItemMarked(row)
+	assert.NotContains(t, ui.markedRows, row)
+	assert.Empty(t, ui.plannedItems)
+	assert.Equal(t, "No items are marked.", ui.formatPlanReview(ui.collectPlan(plan.ActionDelete)))
+}
+
+func TestFormatPlanReviewStatuses(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	abs, _ := filepath.Abs("test_dir/nested/file2")
+	ui := getAnalyzedPathMockedApp(t, true, true, false)
+
+	p := plan.New()
+	p.Add(plan.Entry{Path: abs, Size: 1000, Action: plan.ActionDelete})
+	p.Add(plan.Entry{Path: "/nonexistent", Action: plan.ActionTrash})
+
+	text := ui.formatPlanReview(p)
+	assert.Contains(t, text, "[yellow::b]changed")
+	assert.Contains(t, text, "[red::b]missing")
+}
@@ -3,6 +3,8 @@ package tui
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dundee/gdu/v5/internal/common"
@@ -38,9 +40,18 @@ func (ui *UI) updateProgress(analyzer common.Analyzer, doneChan common.SignalGro
 
 		progress := analyzer.GetProgress()
 
-		func(itemCount int64, totalUsage int64) {
+		func(itemCount int64, totalUsage int64, ioWorkers int, throttle string) {
 			delta := time.Since(start).Round(time.Second)
 
+			// shown on the second line which is visible in the progress box
+			var details []string
+			if ioWorkers > 0 {
+				details = append(details, "I/O workers: "+color+strconv.Itoa(ioWorkers))
+			}
+			if throttle != "" {
+				details = append(details, "Throttled: "+color+throttle)
+			}
+
 			if deviceSize > 0 && showBar {
 				percent := int(totalUsage * 100 / deviceSize)
 				writeTerminalProgress(percent)
@@ -59,10 +70,12 @@ func (ui *UI) updateProgress(analyzer common.Analyzer, doneChan common.SignalGro
 					"[white:black:-], elapsed time: " +
 					color +
 					delta.String() +
+					"[white:black:-]\n" +
+					strings.Join(details, "[white:black:-], ") +
 					"[white:black:-]\n\nPress Tab to preview results found so far\n" +
 					"Press Ctrl+C to stop scanning and keep results")
 			})
-		}(progress.ItemCount, progress.TotalUsage)
+		}(progress.ItemCount, progress.TotalUsage, progress.IOWorkers, progress.Throttle)
 	}
 }
 
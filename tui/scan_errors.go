@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// updateScanErrors keeps the errors of the finished scan of the path.
+// When a directory is rescanned, only the errors below it are replaced.
+func (ui *UI) updateScanErrors(path string, errs []common.ScanError, rescan bool) {
+	if rescan {
+		kept := slices.DeleteFunc(slices.Clone(ui.scanErrors), func(e common.ScanError) bool {
+			return e.Path == path || strings.HasPrefix(e.Path, path+string(filepath.Separator))
+		})
+		errs = append(kept, errs...)
+		common.SortScanErrors(errs)
+	}
+	ui.scanErrors = errs
+}
+
+// formatScanErrorsInfo returns footer text with the number of items which could not be read
+func (ui *UI) formatScanErrorsInfo(footerNumberColor, footerTextColor string) string {
+	if len(ui.scanErrors) == 0 {
+		return ""
+	}
+	return " Errors: " + footerNumberColor + strconv.Itoa(len(ui.scanErrors)) + footerTextColor
+}
+
+// showScanErrors lists the directories and files which could not be read during the scan
+func (ui *UI) showScanErrors() *tview.Table {
+	if ui.topDir == nil {
+		return nil
+	}
+
+	table := tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+	table.SetBorder(true)
+	ui.fillScanErrorsTable(table)
+
+	table.SetInputCapture(func(key *tcell.EventKey) *tcell.EventKey {
+		if key.Key() == tcell.KeyEsc || key.Rune() == 'q' {
+			ui.closeScanErrors()
+			return nil
+		}
+		row, _ := table.GetSelection()
+		scanErr, ok := table.GetCell(row, 0).GetReference().(common.ScanError)
+		if !ok {
+			return key
+		}
+		if key.Key() == tcell.KeyEnter {
+			ui.closeScanErrors()
+			ui.jumpToPath(scanErr.Path)
+			return nil
+		}
+		return key
+	})
+
+	ui.pages.AddPage("scan-errors", modal(table, 120, 30), true, true)
+	ui.app.SetFocus(table)
+	return table
+}
+
+func (ui *UI) fillScanErrorsTable(table *tview.Table) {
+	table.Clear()
+	table.SetTitle(" Scan errors ~ " + strconv.Itoa(len(ui.scanErrors)) + " items ~ enter go to, esc close ")
+
+	header := []string{"Path", "Operation", "Error"}
+	for i, title := range header {
+		table.SetCell(0, i, tview.NewTableCell("[::b]"+title).SetSelectable(false))
+	}
+	if len(ui.scanErrors) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell("All items were read").SetSelectable(false))
+		return
+	}
+
+	for i, e := range ui.scanErrors {
+		reason := e.Reason()
+		if errno := e.Errno(); errno != "" {
+			reason = errno + " (" + reason + ")"
+		}
+		table.SetCell(i+1, 0, tview.NewTableCell(tview.Escape(e.Path)).SetReference(e).SetExpansion(1))
+		table.SetCell(i+1, 1, tview.NewTableCell(tview.Escape(e.Op)))
+		table.SetCell(i+1, 2, tview.NewTableCell(tview.Escape(reason)))
+	}
+	table.Select(1, 0)
+}
+
+func (ui *UI) closeScanErrors() {
+	ui.pages.RemovePage("scan-errors")
+	ui.app.SetFocus(ui.table)
+}
+
+// jumpToPath shows the directory containing the item of the path and selects the item in it.
+// Files which could not be read are not in the analyzed tree, so the directory where they are is shown instead.
+func (ui *UI) jumpToPath(path string) {
+	item, found := findItem(ui.topDir, path)
+	if found && item.GetParent() != nil {
+		ui.jumpToItem(item)
+		return
+	}
+	if item.IsDir() {
+		ui.openDir(item)
+	}
+}
+
+// findItem returns the item of the path in the tree of the root dir,
+// or the deepest directory on the path with false if the item is not in the tree
+func findItem(root fs.Item, path string) (fs.Item, bool) {
+	item := root
+	for item.GetPath() != path {
+		var next fs.Item
+		for child := range item.GetFiles(fs.SortByName, fs.SortAsc) {
+			childPath := child.GetPath()
+			if childPath == path || child.IsDir() && strings.HasPrefix(path, childPath+string(filepath.Separator)) {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return item, false
+		}
+		item = next
+	}
+	return item, true
+}
@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/dundee/gdu/v5/internal/testanalyze"
+	"github.com/dundee/gdu/v5/internal/testapp"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// getAnalyzedPathWithScanErrors returns UI with the mocked analysis which failed to read the paths
+func getAnalyzedPathWithScanErrors(t *testing.T, paths ...string) *UI {
+	t.Helper()
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, false, true, false, false)
+
+	errs := make([]common.ScanError, 0, len(paths))
+	for _, path := range paths {
+		errs = append(errs, common.NewScanError(path, &os.PathError{Op: "open", Path: path, Err: errors.New("permission denied")}))
+	}
+	ui.Analyzer = &testanalyze.MockedAnalyzer{ScanErrors: errs}
+	ui.done = make(chan struct{})
+	require.NoError(t, ui.AnalyzePath("test_dir", nil))
+	<-ui.done
+
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+	return ui
+}
+
+func TestShowScanErrors(t *testing.T) {
+	ui := getAnalyzedPathWithScanErrors(t, "test_dir/bbb", "test_dir/ccc/missing")
+	assert.Contains(t, ui.footerLabel.GetText(true), "Errors: 2")
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, '!', 0))
+	assert.True(t, ui.pages.HasPage("scan-errors"))
+
+	table := ui.showScanErrors()
+	require.NotNil(t, table)
+	assert.Equal(t, 3, table.GetRowCount())
+	assert.Equal(t, "test_dir/bbb", table.GetCell(1, 0).Text)
+	assert.Equal(t, "open", table.GetCell(1, 1).Text)
+	assert.Equal(t, "permission denied", table.GetCell(1, 2).Text)
+	assert.Equal(t, "test_dir/ccc/missing", table.GetCell(2, 0).Text)
+	assert.Contains(t, table.GetTitle(), "Scan errors ~ 2 items")
+
+	pressSearchKey(table, tcell.NewEventKey(tcell.KeyEsc, 0, 0))
+	assert.False(t, ui.pages.HasPage("scan-errors"))
+}
+
+func TestCloseScanErrorsByKeys(t *testing.T) {
+	ui := getAnalyzedPathWithScanErrors(t, "test_dir/bbb")
+
+	table := ui.showScanErrors()
+	sendKey(ui, table, tcell.NewEventKey(tcell.KeyEsc, 0, 0))
+	assert.False(t, ui.pages.HasPage("scan-errors"))
+
+	// q closes the view instead of quitting gdu
+	table = ui.showScanErrors()
+	key := ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'q', 0))
+	require.NotNil(t, key)
+	table.GetInputCapture()(key)
+	assert.False(t, ui.pages.HasPage("scan-errors"))
+}
+
+func TestScanErrorsKeysNotHandledByMainTable(t *testing.T) {
+	ui := getAnalyzedPathWithScanErrors(t, "test_dir/bbb")
+
+	table := ui.showScanErrors()
+	sendKey(ui, table, tcell.NewEventKey(tcell.KeyRune, ' ', 0))
+	assert.Empty(t, ui.markedRows)
+	assert.True(t, ui.pages.HasPage("scan-errors"))
+}
+
+func TestShowScanErrorsWithoutErrors(t *testing.T) {
+	ui := getAnalyzedPathWithScanErrors(t)
+	assert.NotContains(t, ui.footerLabel.GetText(true), "Errors:")
+
+	table := ui.showScanErrors()
+	require.NotNil(t, table)
+	assert.Equal(t, "All items were read", table.GetCell(1, 0).Text)
+}
+
+func TestJumpToScanError(t *testing.T) {
+	ui := getAnalyzedPathWithScanErrors(t, "test_dir/bbb")
+
+	table := ui.showScanErrors()
+	table.Select(1, 0)
+	sendKey(ui, table, tcell.NewEventKey(tcell.KeyEnter, 0, 0))
+
+	assert.False(t, ui.pages.HasPage("scan-errors"))
+	assert.Equal(t, "test_dir", ui.currentDir.GetName())
+	row, _ := ui.table.GetSelection()
+	assert.Equal(t, "bbb", ui.table.GetCell(row, 0).GetReference().(fs.Item).GetName())
+}
+
+func TestJumpToScanErrorOfMissingFile(t *testing.T) {
+	ui := getAnalyzedPathWithScanErrors(t, "test_dir/ccc/missing")
+
+	table := ui.showScanErrors()
+	table.Select(1, 0)
+	sendKey(ui, table, tcell.NewEventKey(tcell.KeyEnter, 0, 0))
+
+	// the file is not in the analyzed tree, so the dir where it is is shown
+	assert.Equal(t, "ccc", ui.currentDir.GetName())
+}
+
+func TestUpdateScanErrorsAfterRescan(t *testing.T) {
+	ui := getAnalyzedPathWithScanErrors(t, "test_dir/aaa/x", "test_dir/bbb", "test_dir/bbb/y")
+
+	ui.updateScanErrors("test_dir/bbb", []common.ScanError{{Path: "test_dir/bbb/z"}}, true)
+
+	var paths []string
+	for _, e := range ui.scanErrors {
+		paths = append(paths, e.Path)
+	}
+	assert.Equal(t, []string{"test_dir/aaa/x", "test_dir/bbb/z"}, paths)
+
+	ui.updateScanErrors("test_dir", nil, false)
+	assert.Empty(t, ui.scanErrors)
+}
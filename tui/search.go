@@ -0,0 +1,368 @@
+package tui
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/plan"
+	"github.com/dundee/gdu/v5/pkg/query"
+)
+
+// searchLimit is the maximal number of items listed in the search results
+const searchLimit = 1000
+
+// searchResults is the state of the list of items found by the recursive search
+type searchResults struct {
+	table  *tview.Table
+	dir    fs.Item
+	find   func() (fs.Files, error)
+	title  string
+	text   string
+	items  fs.Files
+	marked map[fs.Item]struct{}
+}
+
+// showSearchInput asks for the expression to search for below the current directory
+func (ui *UI) showSearchInput() {
+	if ui.currentDir == nil {
+		return
+	}
+
+	input := tview.NewInputField().SetLabel("Find: ")
+	if !ui.UseColors {
+		input.SetFieldBackgroundColor(tcell.NewRGBColor(100, 100, 100))
+		input.SetFieldTextColor(tcell.NewRGBColor(255, 255, 255))
+	}
+	input.SetBorder(true).SetTitle(" Search in " + tview.Escape(ui.currentDir.GetName()) + " ")
+
+	help := tview.NewTextView().SetText(
+		" name, *.log, /regexp/ or expression:\n" +
+			" size > 1G and mtime < -1y and name ~ '*.log'",
+	)
+	form := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(input, 3, 0, true).
+		AddItem(help, 2, 0, false)
+
+	input.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			text := strings.TrimSpace(input.GetText())
+			if text == "" {
+				return
+			}
+			ui.pages.RemovePage("search-input")
+			ui.showSearchResults(text)
+		case tcell.KeyEsc:
+			ui.pages.RemovePage("search-input")
+			ui.app.SetFocus(ui.table)
+		}
+	})
+
+	ui.pages.AddPage("search-input", modal(form, 70, 5), true, true)
+	ui.app.SetFocus(input)
+}
+
+// searchExpression converts the text entered by user to the filter expression.
+// Plain text is searched as a case-insensitive part of the name, text with wildcards as a glob pattern of the name.
+func searchExpression(text string) string {
+	switch {
+	case strings.ContainsAny(text, "=<>~"):
+		return text
+	case strings.HasPrefix(text, "/") && strings.HasSuffix(text, "/") && len(text) > 1:
+		return "name ~ " + text
+	case strings.ContainsAny(text, "*?["):
+		if strings.Contains(text, "'") {
+			return `name ~ "` + text + `"`
+		}
+		return "name ~ '" + text + "'"
+	}
+	return "name ~ /(?i)" + strings.ReplaceAll(regexp.QuoteMeta(text), "/", `\/`) + "/"
+}
+
+// showSearchResults lists the items below the current directory matching the text
+func (ui *UI) showSearchResults(text string) *tview.Table {
+	expr, err := query.Parse(searchExpression(text), time.Now(), time.Local)
+	if err != nil {
+		ui.showErr("Invalid search expression", err)
+		return nil
+	}
+
+	dir := ui.currentDir
+	results := &searchResults{
+		dir:   dir,
+		title: "Search",
+		text:  text,
+		find: func() (fs.Files, error) {
+			return analyze.Search(dir, expr, searchLimit)
+		},
+		marked: make(map[fs.Item]struct{}),
+	}
+	if err := results.search(); err != nil {
+		ui.showErr("Error searching", err)
+		return nil
+	}
+	return ui.showResultsTable(results)
+}
+
+// showResultsTable shows the table of found items which can be marked, deleted or jumped to
+func (ui *UI) showResultsTable(results *searchResults) *tview.Table {
+	table := tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+	table.SetBorder(true)
+	results.table = table
+	ui.fillSearchTable(results)
+
+	table.SetInputCapture(func(key *tcell.EventKey) *tcell.EventKey {
+		if key.Key() == tcell.KeyEsc || key.Rune() == 'q' {
+			ui.closeSearch()
+			return nil
+		}
+		row, _ := table.GetSelection()
+		item, ok := table.GetCell(row, 0).GetReference().(fs.Item)
+		if !ok {
+			return key
+		}
+		if key.Key() == tcell.KeyEnter {
+			ui.closeSearch()
+			ui.jumpToItem(item)
+			return nil
+		}
+		switch key.Rune() {
+		case ' ':
+			if _, ok := results.marked[item]; ok {
+				delete(results.marked, item)
+			} else {
+				results.marked[item] = struct{}{}
+			}
+			ui.fillSearchTable(results)
+			table.Select(min(row+1, table.GetRowCount()-1), 0)
+			return nil
+		case 'd':
+			ui.confirmSearchDeletion(results, item)
+			return nil
+		}
+		return key
+	})
+
+	ui.pages.AddPage("search", modal(table, 120, 30), true, true)
+	ui.app.SetFocus(table)
+	return table
+}
+
+func (r *searchResults) search() error {
+	items, err := r.find()
+	if err != nil {
+		return err
+	}
+	r.items = items
+	return nil
+}
+
+func (ui *UI) fillSearchTable(results *searchResults) {
+	table := results.table
+	row, _ := table.GetSelection()
+	table.Clear()
+
+	title := " " + results.title + ": " + tview.Escape(results.text) + " ~ " + strconv.Itoa(len(results.items)) + " items"
+	if len(results.items) == searchLimit {
+		title += " (limited)"
+	}
+	table.SetTitle(title + " ~ enter go to, space mark, d delete, esc close ")
+
+	header := []string{"", "Disk usage", "Size", "Path"}
+	if ui.ShowApparentSize {
+		header[1], header[2] = "Size", "Disk usage"
+	}
+	for i, title := range header {
+		table.SetCell(0, i, tview.NewTableCell("[::b]"+title).SetSelectable(false))
+	}
+	if len(results.items) == 0 {
+		table.SetCell(1, 3, tview.NewTableCell("No items found").SetSelectable(false))
+		return
+	}
+
+	prefix := results.dir.GetPath() + string(filepath.Separator)
+	for i, item := range results.items {
+		mark := " "
+		if _, ok := results.marked[item]; ok {
+			mark = "*"
+		}
+		first, second := item.GetUsage(), item.GetSize()
+		if ui.ShowApparentSize {
+			first, second = second, first
+		}
+		path := strings.TrimPrefix(item.GetPath(), prefix)
+		if item.IsDir() {
+			path += "/"
+		}
+		table.SetCell(i+1, 0, tview.NewTableCell(mark).SetReference(item))
+		table.SetCell(i+1, 1, tview.NewTableCell(ui.formatSize(first, false, true)).SetAlign(tview.AlignRight))
+		table.SetCell(i+1, 2, tview.NewTableCell(ui.formatSize(second, false, true)).SetAlign(tview.AlignRight))
+		table.SetCell(i+1, 3, tview.NewTableCell(tview.Escape(path)).SetExpansion(1))
+	}
+	table.Select(max(1, min(row, len(results.items))), 0)
+}
+
+func (ui *UI) closeSearch() {
+	ui.pages.RemovePage("search")
+	ui.app.SetFocus(ui.table)
+}
+
+// jumpToItem shows the directory containing the item and selects the item in it
+func (ui *UI) jumpToItem(item fs.Item) {
+	parent := item.GetParent()
+	if parent == nil {
+		return
+	}
+	ui.openDir(parent)
+
+	// items of trees stored in database are loaded again for each listing, so compare names
+	row := 0
+	if ui.currentDirPath != ui.topDirPath {
+		row = 1 // skip the link to the parent directory
+	}
+	for ; row < ui.table.GetRowCount(); row++ {
+		ref, ok := ui.table.GetCell(row, 0).GetReference().(fs.Item)
+		if ok && ref.GetName() == item.GetName() {
+			ui.table.Select(row, 0)
+			return
+		}
+	}
+}
+
+// openDir shows the directory with the filters cleared
+func (ui *UI) openDir(dir fs.Item) {
+	ui.currentDir = dir
+	ui.hideFilterInput()
+	ui.hideTypeFilterInput()
+	ui.markedRows = make(map[int]struct{})
+	ui.ignoredRows = make(map[int]struct{})
+	ui.showDir()
+}
+
+// selectedForDeletion returns the marked items or the selected one.
+// Items inside other marked directories are left out as they are deleted together with the directory.
+func (r *searchResults) selectedForDeletion(selected fs.Item) []fs.Item {
+	if len(r.marked) == 0 {
+		return []fs.Item{selected}
+	}
+
+	var items []fs.Item
+	for _, item := range r.items {
+		if _, ok := r.marked[item]; !ok {
+			continue
+		}
+		nested := false
+		for parent := item.GetParent(); parent != nil && parent != r.dir; parent = parent.GetParent() {
+			if _, ok := r.marked[parent]; ok {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (ui *UI) confirmSearchDeletion(results *searchResults, selected fs.Item) {
+	if ui.noDelete {
+		ui.showErr("Deletion is disabled", nil)
+		return
+	}
+	if ui.noDeleteWithFilter {
+		ui.showErr("Deletion is disabled when a time filter is active", nil)
+		return
+	}
+
+	items := results.selectedForDeletion(selected)
+	for _, item := range items {
+		if isArchived(item) {
+			ui.showErr("Deletion is not supported in archives", nil)
+			return
+		}
+	}
+
+	text := "Are you sure you want to delete \"" + tview.Escape(selected.GetName()) + "\"?"
+	if len(results.marked) > 0 {
+		text = "Are you sure you want to delete [::b]" + strconv.Itoa(len(items)) + "[::-] items?"
+	}
+	confirm := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"no", "yes"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			ui.pages.RemovePage("confirm")
+			if buttonIndex == 1 {
+				ui.deleteFound(results, items)
+				return
+			}
+			ui.app.SetFocus(results.table)
+		})
+	if !ui.UseColors {
+		confirm.SetBackgroundColor(tcell.ColorGray)
+	}
+	ui.pages.AddPage("confirm", confirm, true, true)
+	ui.app.SetFocus(confirm)
+}
+
+// deleteFound deletes the items found by the search and refreshes the results
+func (ui *UI) deleteFound(results *searchResults, items []fs.Item) {
+	acting := ActionDelete.Acting()
+	progress := tview.NewModal()
+	ui.pages.AddPage(acting, progress, true, true)
+
+	go func() {
+		var deleteErr error
+		var failed fs.Item
+		for _, item := range items {
+			ui.app.QueueUpdateDraw(func() {
+				progress.SetText("Deleting " + tview.Escape(item.GetName()) + "...")
+			})
+			deleteFun := ui.getDeleteFunc(ActionDelete, item)
+			if err := deleteFun(item.GetParent(), item); err != nil {
+				deleteErr, failed = err, item
+				break
+			}
+		}
+
+		ui.app.QueueUpdateDraw(func() {
+			ui.pages.RemovePage(acting)
+			for _, item := range items {
+				delete(ui.plannedItems, plan.ItemPath(item))
+			}
+			results.marked = make(map[fs.Item]struct{})
+			if err := results.search(); err != nil {
+				results.items = nil
+			}
+			ui.fillSearchTable(results)
+			ui.showDir()
+			ui.app.SetFocus(results.table)
+			if deleteErr != nil {
+				ui.showErr("Can't delete "+tview.Escape(failed.GetName()), deleteErr)
+			}
+		})
+
+		if ui.done != nil {
+			ui.done <- struct{}{}
+		}
+	}()
+}
+
+// isArchived returns true if the item is stored inside of an archive
+func isArchived(item fs.Item) bool {
+	for parent := item.GetParent(); parent != nil; parent = parent.GetParent() {
+		switch parent.(type) {
+		case *analyze.ZipDir, *analyze.TarDir:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dundee/gdu/v5/internal/testapp"
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func pressSearchKey(table *tview.Table, key *tcell.EventKey) {
+	table.GetInputCapture()(key)
+}
+
+func searchResultPaths(table *tview.Table) []string {
+	var paths []string
+	for row := 1; row < table.GetRowCount(); row++ {
+		paths = append(paths, table.GetCell(row, 3).Text)
+	}
+	return paths
+}
+
+func TestSearchExpression(t *testing.T) {
+	for text, expected := range map[string]string{
+		"file":          "name ~ /(?i)file/",
+		"a.b/c":         `name ~ /(?i)a\.b\/c/`,
+		"*.log":         "name ~ '*.log'",
+		"it's*":         `name ~ "it's*"`,
+		"/^core$/":      "name ~ /^core$/",
+		"size > 1G":     "size > 1G",
+		"name ~ 'x*'":   "name ~ 'x*'",
+		"type=file":     "type=file",
+		"/":             "name ~ /(?i)\\//",
+		"mtime < -30d ": "mtime < -30d ",
+	} {
+		assert.Equal(t, expected, searchExpression(text), text)
+	}
+}
+
+func TestShowSearchInput(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'F', 0))
+	assert.True(t, ui.pages.HasPage("search-input"))
+
+	// keys are passed to the input while it is shown
+	assert.NotNil(t, ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'q', 0)))
+}
+
+func TestShowSearchResults(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, true, true, false)
+
+	table := ui.showSearchResults("file")
+	require.NotNil(t, table)
+	assert.True(t, ui.pages.HasPage("search"))
+	assert.ElementsMatch(t, []string{"nested/subnested/file", "nested/file2"}, searchResultPaths(table))
+	assert.Contains(t, table.GetTitle(), "2 items")
+
+	table = ui.showSearchResults("type = dir and depth = 2")
+	assert.Equal(t, []string{"nested/subnested/"}, searchResultPaths(table))
+
+	table = ui.showSearchResults("/^file\\d$/")
+	assert.Equal(t, []string{"nested/file2"}, searchResultPaths(table))
+
+	table = ui.showSearchResults("missing")
+	assert.Equal(t, "No items found", table.GetCell(1, 3).Text)
+
+	pressSearchKey(table, tcell.NewEventKey(tcell.KeyEsc, 0, 0))
+	assert.False(t, ui.pages.HasPage("search"))
+}
+
+func TestShowSearchResultsInvalid(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, true, true, false)
+
+	assert.Nil(t, ui.showSearchResults("size >"))
+	assert.True(t, ui.pages.HasPage("error"))
+}
+
+func TestSearchJumpToItem(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, true, true, false)
+
+	table := ui.showSearchResults("file2")
+	require.Equal(t, 2, table.GetRowCount())
+	pressSearchKey(table, tcell.NewEventKey(tcell.KeyEnter, 0, 0))
+
+	assert.False(t, ui.pages.HasPage("search"))
+	assert.Equal(t, "nested", ui.currentDir.GetName())
+	row, _ := ui.table.GetSelection()
+	assert.Equal(t, "file2", ui.table.GetCell(row, 0).GetReference().(fs.Item).GetName())
+}
+
+func TestSearchMarkAndDelete(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, true, true, false)
+	var deleted []string
+	ui.remover = func(dir, item fs.Item) error {
+		deleted = append(deleted, item.GetPath())
+		dir.RemoveFile(item)
+		return nil
+	}
+
+	table := ui.showSearchResults("name ~ 'sub*' or name = 'file'")
+	require.Equal(t, 3, table.GetRowCount())
+
+	// marking the directory and the file inside of it deletes only the directory
+	pressSearchKey(table, tcell.NewEventKey(tcell.KeyRune, ' ', 0))
+	pressSearchKey(table, tcell.NewEventKey(tcell.KeyRune, ' ', 0))
+	assert.Equal(t, "*", table.GetCell(1, 0).Text)
+	assert.Equal(t, "*", table.GetCell(2, 0).Text)
+
+	pressSearchKey(table, tcell.NewEventKey(tcell.KeyRune, 'd', 0))
+	assert.True(t, ui.pages.HasPage("confirm"))
+	selectConfirmationYes(t, ui)
+	<-ui.done
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+
+	assert.Equal(t, []string{"test_dir/nested/subnested"}, deleted)
+	assert.Equal(t, "No items found", table.GetCell(1, 3).Text)
+	assert.True(t, ui.pages.HasPage("search"))
+	assert.False(t, ui.pages.HasPage("deleting"))
+}
+
+func TestSearchDeleteDisabled(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, true, true, false)
+	ui.SetNoDelete()
+
+	table := ui.showSearchResults("file")
+	pressSearchKey(table, tcell.NewEventKey(tcell.KeyRune, 'd', 0))
+	assert.False(t, ui.pages.HasPage("confirm"))
+	assert.True(t, ui.pages.HasPage("error"))
+}
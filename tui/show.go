@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -10,55 +11,25 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/dundee/gdu/v5/build"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/device"
 	"github.com/dundee/gdu/v5/pkg/fs"
 )
 
-var (
-	helpDisabledSuffix = " (disabled)"
-
-	helpText = `     [::b]up/down, k/j    [white:black:-]Move cursor up/down
-  [::b]pgup/pgdn, g/G     [white:black:-]Move cursor top/bottom
- [::b]enter, right, l     [white:black:-]Go to directory/device
-         [::b]left, h     [white:black:-]Go to parent directory
-
-               [::b]r     [white:black:-]Rescan current directory
-               [::b]E     [white:black:-]Export analysis data to file as JSON
-               [::b]/     [white:black:-]Search items by name
-               [::b]T     [white:black:-]Filter items by file type (extension)
-               [::b]a     [white:black:-]Toggle between showing disk usage and apparent size
-               [::b]B     [white:black:-]Toggle bar alignment to biggest file or directory
-               [::b]c     [white:black:-]Show/hide file count
-               [::b]m     [white:black:-]Show/hide latest mtime
-               [::b]b     [white:black:-]Spawn shell in current directory
-               [::b]q     [white:black:-]Quit gdu (asks to confirm after a long scan)
-               [::b]Q     [white:black:-]Quit gdu and print current directory path
-
-Item under cursor:
-               [::b]d     [white:black:-]Delete file or directory
-               [::b]e     [white:black:-]Empty file or directory
-               [::b]D     [white:black:-]Move file or directory to trash
-			   [::b]space [white:black:-]Mark file or directory for deletion
-			   [::b]p     [white:black:-]Print marked items paths to stdout after quitting
-			   [::b]I     [white:black:-]Ignore file or directory
-               [::b]v     [white:black:-]Show content of file
-               [::b]o     [white:black:-]Open file or directory in external program
-               [::b]i     [white:black:-]Show info about item
-
-Sort by (twice toggles asc/desc):
-               [::b]n     [white:black:-]Sort by name (asc/desc)
-               [::b]s     [white:black:-]Sort by size (asc/desc)
-               [::b]C     [white:black:-]Sort by file count (asc/desc)
-               [::b]M     [white:black:-]Sort by mtime (asc/desc)`
-)
+const helpNavigationText = `     [::b]up/down, k/j    [white:black:-]Move cursor up/down
+  [::b]pgup/pgdn, g/G     [white:black:-]Move cursor top/bottom`
+
+var helpDisabledSuffix = " (disabled)"
 
 // currentDirLabelText builds the breadcrumb label shown above the table,
 // annotated when a mid-scan preview is being displayed.
 func (ui *UI) currentDirLabelText() string {
-	label := "[::b] --- " +
-		tview.Escape(
-			strings.TrimPrefix(ui.currentDirPath, build.RootPathPrefix),
-		) +
-		" ---"
+	name := strings.TrimPrefix(ui.currentDirPath, build.RootPathPrefix)
+	if name == "" && ui.currentDir != nil {
+		// synthetic root of a multi-path scan has no path
+		name = ui.currentDir.GetName()
+	}
+	label := "[::b] --- " + tview.Escape(name) + " ---"
 	if ui.previewing {
 		label += "  [::b][yellow]scanning… (preview, Tab to resume)[-]"
 	}
@@ -125,16 +96,21 @@ func (ui *UI) showDir() {
 			continue
 		}
 
+		usage := item.GetUsage()
+		if ui.ShowInodes {
+			usage = item.GetItemCount()
+		}
+
 		if ui.ShowRelativeSize {
-			if item.GetUsage() > maxUsage {
-				maxUsage = item.GetUsage()
+			if usage > maxUsage {
+				maxUsage = usage
 			}
 			if item.GetSize() > maxSize {
 				maxSize = item.GetSize()
 			}
 		} else {
 			maxSize += item.GetSize()
-			maxUsage += item.GetUsage()
+			maxUsage += usage
 		}
 		i++
 	}
@@ -151,6 +127,10 @@ func (ui *UI) showDir() {
 			continue
 		}
 
+		if !ui.matchesGitFilter(item) {
+			continue
+		}
+
 		_, ignored := ui.ignoredRows[rowIndex]
 
 		if !ignored {
@@ -160,6 +140,9 @@ func (ui *UI) showDir() {
 		}
 
 		_, marked := ui.markedRows[rowIndex]
+		if !marked {
+			marked = ui.markPlannedItem(item, rowIndex)
+		}
 
 		var cell *tview.TableCell
 		var reference fs.Item
@@ -238,8 +221,12 @@ func (ui *UI) showDir() {
 			" Apparent size: " +
 			footerNumberColor +
 			ui.formatSize(totalSize, true, false) +
+			ui.formatSharedUsageInfo(footerNumberColor) +
 			" Items: " + footerNumberColor + fmt.Sprintf("%d", itemCount) +
 			footerTextColor +
+			ui.formatInodeInfo(itemCount, footerNumberColor, footerTextColor) +
+			ui.formatGitInfo(footerNumberColor, footerTextColor) +
+			ui.formatScanErrorsInfo(footerNumberColor, footerTextColor) +
 			" Sorting by: " + ui.sortBy + " " + ui.sortOrder +
 			typeFilterText +
 			timeFilterText)
@@ -252,16 +239,31 @@ func (ui *UI) showDir() {
 	}
 }
 
+// formatSharedUsageInfo returns footer text with the usage of the current dir in shared extents
+func (ui *UI) formatSharedUsageInfo(footerNumberColor string) string {
+	if !ui.ShowSharedUsage || ui.currentDir == nil {
+		return ""
+	}
+	return " Shared: " + footerNumberColor + ui.formatSize(analyze.SharedUsage(ui.currentDir), true, false)
+}
+
 func (ui *UI) showDevices() {
 	var totalUsage int64
 
 	ui.table.Clear()
 	ui.table.SetCell(0, 0, tview.NewTableCell("Device name").SetSelectable(false))
-	ui.table.SetCell(0, 1, tview.NewTableCell("Size").SetSelectable(false))
+	if ui.ShowInodes {
+		ui.table.SetCell(0, 1, tview.NewTableCell("Inodes").SetSelectable(false))
+	} else {
+		ui.table.SetCell(0, 1, tview.NewTableCell("Size").SetSelectable(false))
+	}
 	ui.table.SetCell(0, 2, tview.NewTableCell("Used").SetSelectable(false))
 	ui.table.SetCell(0, 3, tview.NewTableCell("Used part").SetSelectable(false))
 	ui.table.SetCell(0, 4, tview.NewTableCell("Free").SetSelectable(false))
 	ui.table.SetCell(0, 5, tview.NewTableCell("Mount point").SetSelectable(false))
+	if len(ui.deviceScans) > 0 {
+		ui.table.SetCell(0, 6, tview.NewTableCell("Scan").SetSelectable(false))
+	}
 
 	var textColor, sizeColor string
 	if ui.UseColors {
@@ -273,15 +275,36 @@ func (ui *UI) showDevices() {
 	}
 
 	ui.sortDevices()
-
-	for i, device := range ui.devices {
-		totalUsage += device.GetUsage()
-		ui.table.SetCell(i+1, 0, tview.NewTableCell(textColor+device.Name).SetReference(ui.devices[i]))
-		ui.table.SetCell(i+1, 1, tview.NewTableCell(ui.formatSize(device.Size, false, true)))
-		ui.table.SetCell(i+1, 2, tview.NewTableCell(sizeColor+ui.formatSize(device.Size-device.Free, false, true)))
-		ui.table.SetCell(i+1, 3, tview.NewTableCell(getDeviceUsagePart(device, ui.useOldSizeBar)))
-		ui.table.SetCell(i+1, 4, tview.NewTableCell(ui.formatSize(device.Free, false, true)))
-		ui.table.SetCell(i+1, 5, tview.NewTableCell(textColor+device.MountPoint).SetReference(ui.devices[i]))
+	grouped := groupDevicesByClass(ui.devices)
+
+	row := 1
+	rows := make(map[*device.Device]int, len(ui.devices))
+	for i, dev := range ui.devices {
+		if grouped && (i == 0 || dev.GetClass() != ui.devices[i-1].GetClass()) {
+			ui.table.SetCell(row, 0, tview.NewTableCell("[::b]"+string(dev.GetClass())).SetSelectable(false))
+			row++
+		}
+		rows[dev] = row
+
+		ui.table.SetCell(row, 0, tview.NewTableCell(textColor+dev.Name).SetReference(dev))
+		if ui.ShowInodes {
+			totalUsage += dev.GetUsedInodes()
+			ui.table.SetCell(row, 1, tview.NewTableCell(ui.formatCount(dev.Inodes)))
+			ui.table.SetCell(row, 2, tview.NewTableCell(sizeColor+ui.formatCount(dev.GetUsedInodes())))
+			ui.table.SetCell(row, 3, tview.NewTableCell(getDeviceInodesPart(dev, ui.useOldSizeBar)))
+			ui.table.SetCell(row, 4, tview.NewTableCell(ui.formatCount(dev.FreeInodes)))
+		} else {
+			totalUsage += dev.GetUsage()
+			ui.table.SetCell(row, 1, tview.NewTableCell(ui.formatSize(dev.Size, false, true)))
+			ui.table.SetCell(row, 2, tview.NewTableCell(sizeColor+ui.formatSize(dev.Size-dev.Free, false, true)))
+			ui.table.SetCell(row, 3, tview.NewTableCell(getDeviceUsagePart(dev, ui.useOldSizeBar)))
+			ui.table.SetCell(row, 4, tview.NewTableCell(ui.formatSize(dev.Free, false, true)))
+		}
+		ui.table.SetCell(row, 5, tview.NewTableCell(textColor+dev.MountPoint).SetReference(dev))
+		if len(ui.deviceScans) > 0 {
+			ui.table.SetCell(row, 6, tview.NewTableCell(ui.formatDeviceScan(dev)).SetReference(dev))
+		}
+		row++
 	}
 
 	var footerNumberColor, footerTextColor string
@@ -301,24 +324,45 @@ func (ui *UI) showDevices() {
 		footerTextColor = blackOnWhite
 	}
 
+	total := ui.formatSize(totalUsage, true, false)
+	if ui.ShowInodes {
+		total = ui.formatCount(totalUsage) + footerTextColor + " inodes"
+	}
+
 	ui.footerLabel.SetText(
 		" Total usage: " +
 			footerNumberColor +
-			ui.formatSize(totalUsage, true, false) +
+			total +
 			footerTextColor +
 			" Sorting by: " + ui.sortBy + " " + ui.sortOrder)
 
-	ui.table.Select(1, 0)
 	ui.table.SetSelectedFunc(ui.deviceItemSelected)
 
+	selected := 1
+	if len(ui.devices) > 0 {
+		selected = rows[ui.devices[0]]
+	}
 	if ui.topDirPath != "" {
-		for i, device := range ui.devices {
-			if device.MountPoint == ui.topDirPath {
-				ui.table.Select(i+1, 0)
+		for _, dev := range ui.devices {
+			if dev.MountPoint == ui.topDirPath {
+				selected = rows[dev]
 				break
 			}
 		}
 	}
+	ui.table.Select(selected, 0)
+}
+
+// groupDevicesByClass orders the devices by their class keeping the order inside each class.
+// Returns true if there is more than one class.
+func groupDevicesByClass(devices device.Devices) bool {
+	if len(devices) == 0 {
+		return false
+	}
+	slices.SortStableFunc(devices, func(a, b *device.Device) int {
+		return slices.Index(device.Classes, a.GetClass()) - slices.Index(device.Classes, b.GetClass())
+	})
+	return devices[0].GetClass() != devices[len(devices)-1].GetClass()
 }
 
 func (ui *UI) showErr(msg string, err error) {
@@ -377,8 +421,46 @@ func (ui *UI) showHelp() {
 	ui.app.SetFocus(text)
 }
 
+// helpText generates the help from the live key bindings, disabled actions are left out
+func (ui *UI) helpText() string {
+	lines := []string{helpNavigationText}
+	section := sectionNavigation
+	for _, action := range keyActions {
+		if action.section != section {
+			section = action.section
+			lines = append(lines, "")
+			if section != sectionGeneral {
+				lines = append(lines, section)
+			}
+		}
+
+		key, ok := ui.keyBindings.Key(action.name)
+		switch {
+		case action.name == "right":
+			label := "enter, right"
+			if ok {
+				label += ", " + keyName(key)
+			}
+			lines = append(lines, formatHelpLine(label, action.description))
+		case action.name == "left":
+			label := "left"
+			if ok {
+				label += ", " + keyName(key)
+			}
+			lines = append(lines, formatHelpLine(label, action.description))
+		case ok:
+			lines = append(lines, fmt.Sprintf("               [::b]%-6s[white:black:-]%s", keyName(key), action.description))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatHelpLine(keys, description string) string {
+	return fmt.Sprintf("%s[::b]%s     [white:black:-]%s", strings.Repeat(" ", max(16-len(keys), 0)), keys, description)
+}
+
 func (ui *UI) formatHelpTextFor() string {
-	lines := strings.Split(helpText, "\n")
+	lines := strings.Split(ui.helpText(), "\n")
 
 	for i, line := range lines {
 		if ui.UseColors {
@@ -391,7 +473,9 @@ func (ui *UI) formatHelpTextFor() string {
 
 		isFound := (strings.Contains(line, "Empty file or directory") ||
 			strings.Contains(line, "Delete file or directory") ||
-			strings.Contains(line, "Move file or directory to trash"))
+			strings.Contains(line, "Move file or directory to trash") ||
+			strings.Contains(line, "Shred file or directory") ||
+			strings.Contains(line, "Compress file or directory"))
 
 		if ui.noDelete && isFound {
 			lines[i] += helpDisabledSuffix
@@ -412,6 +496,18 @@ func (ui *UI) formatHelpTextFor() string {
 	return strings.Join(lines, "\n")
 }
 
+// formatInodeInfo returns part of the inode capacity of the filesystem used by the shown items
+func (ui *UI) formatInodeInfo(itemCount int64, numberColor, textColor string) string {
+	if !ui.ShowInodes {
+		return ""
+	}
+	percent, ok := ui.InodePercent(itemCount)
+	if !ok {
+		return ""
+	}
+	return " Inodes of filesystem: " + numberColor + fmt.Sprintf("%.1f%%", percent) + textColor
+}
+
 func (ui *UI) formatTypeFilterInfo(numberColor, textColor string) string {
 	if ui.typeFilterValue == "" {
 		return ""
@@ -77,6 +77,14 @@ func (ui *UI) getSortParams() (fs.SortBy, fs.SortOrder) {
 }
 
 func (ui *UI) sortDevices() {
+	if ui.ShowInodes && (ui.sortBy == sizeSortKey || ui.sortBy == itemCountSortKey) {
+		if ui.sortOrder == descOrder {
+			sort.Sort(sort.Reverse(device.ByUsedInodes(ui.devices)))
+		} else {
+			sort.Sort(device.ByUsedInodes(ui.devices))
+		}
+		return
+	}
 	if ui.sortBy == sizeSortKey {
 		if ui.sortOrder == descOrder {
 			sort.Sort(sort.Reverse(device.ByUsedSize(ui.devices)))
@@ -0,0 +1,193 @@
+package tui
+
+import (
+	"errors"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/dundee/gdu/v5/pkg/device"
+	"github.com/dundee/gdu/v5/pkg/remove"
+)
+
+// showTrash opens the trash browser listing items from the trash directories
+func (ui *UI) showTrash() *tview.Table {
+	items, err := remove.ListTrash(ui.trashTopDirs())
+	if err != nil {
+		ui.showErr("Error listing trash", err)
+		return nil
+	}
+
+	table := tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+	table.SetBorder(true).SetTitle(" Trash ~ r restore, d purge, esc close ")
+	ui.fillTrashTable(table, items)
+
+	table.SetInputCapture(func(key *tcell.EventKey) *tcell.EventKey {
+		if key.Key() == tcell.KeyEsc || key.Rune() == 'q' {
+			ui.closeTrash()
+			return nil
+		}
+		row, _ := table.GetSelection()
+		item, ok := table.GetCell(row, 0).GetReference().(remove.TrashedItem)
+		if !ok {
+			return key
+		}
+		switch key.Rune() {
+		case 'r':
+			ui.restoreTrashed(table, item, item.OriginalPath)
+			return nil
+		case 'd':
+			ui.confirmPurge(table, item)
+			return nil
+		}
+		return key
+	})
+
+	ui.pages.AddPage("trash", modal(table, 120, 30), true, true)
+	ui.app.SetFocus(table)
+	return table
+}
+
+func (ui *UI) fillTrashTable(table *tview.Table, items []remove.TrashedItem) {
+	table.Clear()
+
+	header := []string{"Original path", "Deleted", "Size"}
+	for i, title := range header {
+		table.SetCell(0, i, tview.NewTableCell("[::b]"+title).SetSelectable(false).SetExpansion(1))
+	}
+	if len(items) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell("Trash is empty").SetSelectable(false))
+		return
+	}
+
+	for i, item := range items {
+		path := item.OriginalPath
+		if item.IsDir {
+			path += "/"
+		}
+		table.SetCell(i+1, 0, tview.NewTableCell(tview.Escape(path)).SetReference(item).SetExpansion(3))
+		table.SetCell(i+1, 1, tview.NewTableCell(item.DeletionDate.Format("2006-01-02 15:04:05")))
+		table.SetCell(i+1, 2, tview.NewTableCell(ui.formatSize(item.Size, false, true)).SetAlign(tview.AlignRight))
+	}
+	table.Select(1, 0)
+}
+
+func (ui *UI) closeTrash() {
+	ui.pages.RemovePage("trash")
+	ui.app.SetFocus(ui.table)
+}
+
+// reloadTrash refreshes the listing after an item has been restored or purged
+func (ui *UI) reloadTrash(table *tview.Table) {
+	row, _ := table.GetSelection()
+	items, err := remove.ListTrash(ui.trashTopDirs())
+	if err != nil {
+		ui.showErr("Error listing trash", err)
+		return
+	}
+	ui.fillTrashTable(table, items)
+	if row > len(items) {
+		row = len(items)
+	}
+	if row > 0 {
+		table.Select(row, 0)
+	}
+	ui.app.SetFocus(table)
+}
+
+// trashTopDirs returns mount points which can contain their own trash directories
+func (ui *UI) trashTopDirs() []string {
+	getter := ui.getter
+	if getter == nil {
+		getter = device.Getter
+	}
+	mounts, err := getter.GetMounts()
+	if err != nil {
+		return nil
+	}
+	return device.GetMountPoints(mounts)
+}
+
+// restoreTrashed moves the trashed item back to the target path,
+// offering an alternative name when the target is occupied
+func (ui *UI) restoreTrashed(table *tview.Table, item remove.TrashedItem, target string) {
+	if ui.noDelete {
+		ui.showErr("Restoring from trash is disabled", nil)
+		return
+	}
+
+	err := ui.auditLog.Do("restore", target, item.Size, item.Size, item.IsDir, func() error {
+		return remove.RestoreFromTrash(item, target)
+	})
+	if errors.Is(err, remove.ErrRestoreConflict) {
+		ui.confirmRestoreConflict(table, item, target)
+		return
+	}
+	if err != nil {
+		ui.showErr("Error restoring "+item.OriginalPath, err)
+		return
+	}
+	ui.reloadTrash(table)
+}
+
+func (ui *UI) confirmRestoreConflict(table *tview.Table, item remove.TrashedItem, target string) {
+	alternative := remove.FreeRestorePath(target)
+	confirm := tview.NewModal().
+		SetText(
+			"\"" + tview.Escape(target) + "\" already exists.\n\n" +
+				"Restore it as \"" + tview.Escape(alternative) + "\"?",
+		).
+		AddButtons([]string{"cancel", "restore as new name"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			ui.pages.RemovePage("confirm")
+			if buttonIndex == 1 {
+				ui.restoreTrashed(table, item, alternative)
+				return
+			}
+			ui.app.SetFocus(table)
+		})
+	if !ui.UseColors {
+		confirm.SetBackgroundColor(tcell.ColorGray)
+	}
+	ui.pages.AddPage("confirm", confirm, true, true)
+	ui.app.SetFocus(confirm)
+}
+
+func (ui *UI) confirmPurge(table *tview.Table, item remove.TrashedItem) {
+	if ui.noDelete {
+		ui.showErr("Deletion is disabled", nil)
+		return
+	}
+
+	confirm := tview.NewModal().
+		SetText(
+			"Are you sure you want to permanently remove \"" +
+				tview.Escape(item.OriginalPath) +
+				"\" from trash?",
+		).
+		AddButtons([]string{"no", "yes"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			ui.pages.RemovePage("confirm")
+			if buttonIndex == 1 {
+				ui.purgeTrashed(table, item)
+				return
+			}
+			ui.app.SetFocus(table)
+		})
+	if !ui.UseColors {
+		confirm.SetBackgroundColor(tcell.ColorGray)
+	}
+	ui.pages.AddPage("confirm", confirm, true, true)
+	ui.app.SetFocus(confirm)
+}
+
+func (ui *UI) purgeTrashed(table *tview.Table, item remove.TrashedItem) {
+	err := ui.auditLog.Do("purge", item.OriginalPath, item.Size, item.Size, item.IsDir, func() error {
+		return remove.PurgeFromTrash(item)
+	})
+	if err != nil {
+		ui.showErr("Error purging "+item.OriginalPath, err)
+		return
+	}
+	ui.reloadTrash(table)
+}
@@ -0,0 +1,179 @@
+//go:build !windows && !darwin
+
+package tui
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testdev"
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/audit"
+	"github.com/dundee/gdu/v5/pkg/remove"
+)
+
+func trashFile(t *testing.T, original, content string) {
+	t.Helper()
+	root := filepath.Join(os.Getenv("XDG_DATA_HOME"), "Trash")
+	name := filepath.Base(original)
+	assert.Nil(t, os.MkdirAll(filepath.Join(root, "files"), 0o700))
+	assert.Nil(t, os.MkdirAll(filepath.Join(root, "info"), 0o700))
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "files", name), []byte(content), 0o600))
+	assert.Nil(t, os.WriteFile(
+		filepath.Join(root, "info", name+".trashinfo"),
+		[]byte("[Trash Info]\nPath="+original+"\nDeletionDate=2024-01-02T10:00:00\n"),
+		0o600,
+	))
+}
+
+func getTrashUI(t *testing.T) *UI {
+	t.Helper()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.getter = testdev.DevicesInfoGetterMock{}
+	return ui
+}
+
+func pressTrashKey(table *tview.Table, key *tcell.EventKey) {
+	table.GetInputCapture()(key)
+}
+
+func TestShowTrash(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getTrashUI(t)
+	trashFile(t, "/tmp/file", "abc")
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 't', 0))
+	assert.True(t, ui.pages.HasPage("trash"))
+
+	// keys are passed to the trash browser while it is shown
+	assert.NotNil(t, ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'q', 0)))
+}
+
+func TestShowTrashItems(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getTrashUI(t)
+	trashFile(t, "/tmp/file", "abc")
+
+	table := ui.showTrash()
+	assert.Equal(t, 2, table.GetRowCount())
+	assert.Equal(t, "/tmp/file", table.GetCell(1, 0).Text)
+	assert.Equal(t, "2024-01-02 10:00:00", table.GetCell(1, 1).Text)
+
+	pressTrashKey(table, tcell.NewEventKey(tcell.KeyEsc, 0, 0))
+	assert.False(t, ui.pages.HasPage("trash"))
+}
+
+func TestShowTrashEmpty(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getTrashUI(t)
+
+	table := ui.showTrash()
+	assert.Equal(t, "Trash is empty", table.GetCell(1, 0).Text)
+
+	// nothing to restore
+	pressTrashKey(table, tcell.NewEventKey(tcell.KeyRune, 'r', 0))
+	assert.False(t, ui.pages.HasPage("confirm"))
+}
+
+func TestRestoreFromTrash(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getTrashUI(t)
+	var buff bytes.Buffer
+	ui.SetAuditLogger(audit.New(&buff, false))
+	target, _ := filepath.Abs("test_dir/restored")
+	trashFile(t, target, "abc")
+
+	table := ui.showTrash()
+	pressTrashKey(table, tcell.NewEventKey(tcell.KeyRune, 'r', 0))
+
+	data, err := os.ReadFile(target)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", string(data))
+	assert.Equal(t, "Trash is empty", table.GetCell(1, 0).Text)
+	assert.Contains(t, buff.String(), `"action":"restore"`)
+}
+
+func TestRestoreFromTrashConflict(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getTrashUI(t)
+	target, _ := filepath.Abs("test_dir/nested/file2")
+	trashFile(t, target, "old")
+
+	table := ui.showTrash()
+	pressTrashKey(table, tcell.NewEventKey(tcell.KeyRune, 'r', 0))
+	assert.True(t, ui.pages.HasPage("confirm"))
+
+	ui.restoreTrashed(table, table.GetCell(1, 0).GetReference().(remove.TrashedItem), remove.FreeRestorePath(target))
+
+	data, err := os.ReadFile(target + ".restored")
+	assert.Nil(t, err)
+	assert.Equal(t, "old", string(data))
+}
+
+func TestRestoreFromTrashDryRun(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getTrashUI(t)
+	var buff bytes.Buffer
+	ui.SetAuditLogger(audit.New(&buff, true))
+	target, _ := filepath.Abs("test_dir/restored")
+	trashFile(t, target, "abc")
+
+	table := ui.showTrash()
+	pressTrashKey(table, tcell.NewEventKey(tcell.KeyRune, 'r', 0))
+
+	assert.NoFileExists(t, target)
+	assert.Equal(t, target, table.GetCell(1, 0).Text)
+	assert.Contains(t, buff.String(), `"result":"dry-run"`)
+}
+
+func TestRestoreFromTrashDisabled(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getTrashUI(t)
+	ui.SetNoDelete()
+	target, _ := filepath.Abs("test_dir/restored")
+	trashFile(t, target, "abc")
+
+	table := ui.showTrash()
+	pressTrashKey(table, tcell.NewEventKey(tcell.KeyRune, 'r', 0))
+
+	assert.True(t, ui.pages.HasPage("error"))
+	assert.NoFileExists(t, target)
+}
+
+func TestPurgeFromTrash(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getTrashUI(t)
+	trashFile(t, "/tmp/file", "abc")
+
+	table := ui.showTrash()
+	pressTrashKey(table, tcell.NewEventKey(tcell.KeyRune, 'd', 0))
+	assert.True(t, ui.pages.HasPage("confirm"))
+
+	ui.purgeTrashed(table, table.GetCell(1, 0).GetReference().(remove.TrashedItem))
+
+	assert.Equal(t, "Trash is empty", table.GetCell(1, 0).Text)
+	assert.NoFileExists(t, filepath.Join(os.Getenv("XDG_DATA_HOME"), "Trash", "files", "file"))
+}
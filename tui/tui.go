@@ -16,8 +16,13 @@ import (
 
 	"github.com/dundee/gdu/v5/internal/common"
 	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/audit"
+	"github.com/dundee/gdu/v5/pkg/compress"
 	"github.com/dundee/gdu/v5/pkg/device"
 	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/gitrepo"
+	"github.com/dundee/gdu/v5/pkg/junk"
+	"github.com/dundee/gdu/v5/pkg/plan"
 	"github.com/dundee/gdu/v5/pkg/remove"
 	"github.com/dundee/gdu/v5/pkg/timefilter"
 	"github.com/gdamore/tcell/v2"
@@ -50,12 +55,17 @@ type UI struct {
 	remover                 func(fs.Item, fs.Item) error
 	emptier                 func(fs.Item, fs.Item) error
 	trasher                 func(fs.Item, fs.Item) error
+	shredder                func(fs.Item, fs.Item) error
+	compressFormat          compress.Format
+	keyBindings             *KeyBindings
+	auditLog                *audit.Logger
 	exec                    func(argv0 string, argv []string, envv []string) error
 	changeCwdFn             func(string) error
 	linkedItems             fs.HardLinkedItems
 	ignoredRows             map[int]struct{}
 	markedRows              map[int]struct{}
 	markedPaths             []string
+	plannedItems            map[string]plan.Entry
 	deleteQueue             chan deleteQueueItem
 	resultRow               ResultRow
 	topDirPath              string
@@ -72,7 +82,9 @@ type UI struct {
 	defaultSortBy           string
 	defaultSortOrder        string
 	exportName              string
+	planName                string
 	devices                 []*device.Device
+	deviceScans             map[string]*deviceScan
 	selectedTextColor       tcell.Color
 	selectedBackgroundColor tcell.Color
 	markedTextColor         tcell.Color
@@ -109,6 +121,12 @@ type UI struct {
 	scanDuration            time.Duration
 	previewing              bool
 	previewSavedDir         fs.Item
+	showGitStatus           bool
+	gitIgnoredOnly          bool
+	gitUsage                *gitrepo.Usage
+	junkClassifier          *junk.Classifier
+	junkResult              *junk.Result
+	scanErrors              []common.ScanError
 	progressFlex            *tview.Flex
 }
 
@@ -154,6 +172,7 @@ func CreateUI(
 		remover:                 remove.ItemFromDir,
 		emptier:                 remove.EmptyFileFromDir,
 		trasher:                 remove.MoveItemToTrash,
+		shredder:                remove.Shredder{Passes: remove.DefaultShredPasses}.ItemFromDir,
 		exec:                    Execute,
 		linkedItems:             make(fs.HardLinkedItems, 10),
 		selectedTextColor:       tview.Styles.TitleColor,
@@ -166,6 +185,10 @@ func CreateUI(
 		ignoredRows:             make(map[int]struct{}),
 		markedRows:              make(map[int]struct{}),
 		exportName:              "export.json",
+		plannedItems:            make(map[string]plan.Entry),
+		planName:                "plan.json",
+		compressFormat:          compress.FormatZstd,
+		keyBindings:             DefaultKeyBindings(),
 		noDelete:                false,
 		noViewFile:              false,
 		noSpawnShell:            false,
@@ -190,7 +213,7 @@ func CreateUI(
 	ui.app.SetMouseCapture(ui.onMouse)
 
 	ui.header = tview.NewTextView()
-	ui.header.SetText(" gdu ~ Use arrow keys to navigate, press ? for help ")
+	ui.header.SetText(ui.headerText())
 	ui.header.SetTextColor(tcell.GetColor(ui.headerTextColor))
 	ui.header.SetBackgroundColor(tcell.GetColor(ui.headerBackgroundColor))
 
@@ -231,6 +254,19 @@ func CreateUI(
 	return ui
 }
 
+// headerText returns the default text of the header
+func (ui *UI) headerText() string {
+	text := " gdu ~ "
+	if ui.auditLog != nil && ui.auditLog.IsDryRun() {
+		text += "DRY RUN, nothing will be removed ~ "
+	}
+	text += "Use arrow keys to navigate"
+	if key := ui.keyBindings.Name("help"); key != "" {
+		text += ", press " + key + " for help"
+	}
+	return text + " "
+}
+
 // createGrid creates the main grid layout
 func (ui *UI) createGrid() {
 	if ui.headerHidden {
@@ -484,6 +520,22 @@ func (ui *UI) SetShowDiskProgressBar(value bool) {
 	ui.showDiskProgressBar = value
 }
 
+// SetAuditLogger sets the logger recording all deletions.
+// In dry-run mode the deletions are only recorded, not performed.
+func (ui *UI) SetAuditLogger(l *audit.Logger) {
+	ui.auditLog = l
+}
+
+// SetShredder sets how files are overwritten before they are removed by the shred action
+func (ui *UI) SetShredder(s remove.Shredder) {
+	ui.shredder = s.ItemFromDir
+}
+
+// SetKeyBindings sets the keys bound to the actions of the interactive mode
+func (ui *UI) SetKeyBindings(k *KeyBindings) {
+	ui.keyBindings = k
+}
+
 // SetDeleteInBackground sets the flag to delete files in background
 func (ui *UI) SetDeleteInBackground() {
 	ui.deleteInBackground = true
@@ -495,6 +547,11 @@ func (ui *UI) SetDeleteInBackground() {
 }
 
 func (ui *UI) resetSorting() {
+	if ui.ShowInodes {
+		ui.sortBy = itemCountSortKey
+		ui.sortOrder = descOrder
+		return
+	}
 	ui.sortBy = ui.defaultSortBy
 	ui.sortOrder = ui.defaultSortOrder
 }
@@ -585,7 +642,17 @@ func (ui *UI) deviceItemSelected(row, column int) {
 
 	ui.resetSorting()
 
+	if scan, ok := ui.deviceScans[selectedDevice.MountPoint]; ok {
+		if scan.dir == nil {
+			ui.showErr("Scan of the device has not finished yet", nil)
+			return
+		}
+		ui.openDeviceScan(selectedDevice, scan)
+		return
+	}
+
 	ui.currentDeviceSize = selectedDevice.Size
+	ui.InodeCapacity = selectedDevice.Inodes
 	ui.Analyzer.ResetProgress()
 	ui.linkedItems = make(fs.HardLinkedItems)
 	err = ui.AnalyzePath(selectedDevice.MountPoint, nil)
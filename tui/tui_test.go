@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -16,8 +18,10 @@ import (
 	"github.com/dundee/gdu/v5/internal/testdev"
 	"github.com/dundee/gdu/v5/internal/testdir"
 	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/audit"
 	"github.com/dundee/gdu/v5/pkg/device"
 	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/remove"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/stretchr/testify/assert"
@@ -164,7 +168,7 @@ func TestHelp(t *testing.T) {
 
 	b, _, _ := simScreen.GetContents()
 
-	cells := b[607 : 607+9]
+	cells := b[907 : 907+9]
 
 	text := []byte("directory")
 	for i, r := range cells {
@@ -185,7 +189,7 @@ func TestHelpBw(t *testing.T) {
 
 	b, _, _ := simScreen.GetContents()
 
-	cells := b[607 : 607+9]
+	cells := b[907 : 907+9]
 
 	text := []byte("directory")
 	for i, r := range cells {
@@ -450,6 +454,84 @@ func TestDeleteSelectedInParallel(t *testing.T) {
 	assert.NoDirExists(t, "test_dir/nested")
 }
 
+func TestDeleteSelectedWithAudit(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	var buff bytes.Buffer
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.done = make(chan struct{})
+	ui.SetAuditLogger(audit.New(&buff, false))
+
+	ui.table.Select(0, 0)
+	ui.deleteSelected(ActionDelete)
+
+	<-ui.done
+
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+
+	assert.NoDirExists(t, "test_dir/nested")
+	assert.Contains(t, buff.String(), `"path":"test_dir/nested"`)
+	assert.Contains(t, buff.String(), `"action":"delete","result":"ok"`)
+}
+
+func TestDeleteSelectedDryRun(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	var buff bytes.Buffer
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.done = make(chan struct{})
+	ui.SetAuditLogger(audit.New(&buff, true))
+
+	ui.table.Select(0, 0)
+	ui.deleteSelected(ActionEmpty)
+
+	<-ui.done
+
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+
+	assert.FileExists(t, "test_dir/nested/file2")
+	assert.Equal(t, 1, ui.table.GetRowCount())
+	assert.Contains(t, buff.String(), `"path":"test_dir/nested/file2"`)
+	assert.Contains(t, buff.String(), `"action":"empty","result":"dry-run"`)
+}
+
+func TestDeleteSelectedInBackgroundDryRun(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	var buff bytes.Buffer
+	ui := getAnalyzedPathMockedApp(t, true, true, false)
+	ui.done = make(chan struct{})
+	ui.SetAuditLogger(audit.New(&buff, true))
+	ui.SetDeleteInBackground()
+
+	ui.table.Select(0, 0)
+	ui.deleteSelected(ActionMoveToTrash)
+
+	<-ui.done
+
+	assert.DirExists(t, "test_dir/nested")
+	assert.Contains(t, buff.String(), `"action":"trash","result":"dry-run"`)
+}
+
+func TestDryRunHeader(t *testing.T) {
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, false, true, false, false, func(ui *UI) {
+		ui.SetAuditLogger(audit.New(&bytes.Buffer{}, true))
+	})
+
+	assert.Contains(t, ui.header.GetText(false), "DRY RUN")
+}
+
 func TestDeleteSelectedInBackground(t *testing.T) {
 	fin := testdir.CreateTestDir()
 	defer fin()
@@ -502,6 +584,52 @@ func TestMoveToTrashSelectedInBackground(t *testing.T) {
 	assert.Equal(t, 0, ui.table.GetRowCount())
 }
 
+func TestShredSelectedInBackground(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, true, true, false)
+	ui.done = make(chan struct{})
+	ui.SetDeleteInBackground()
+	ui.SetShredder(remove.Shredder{Passes: 1, Force: true, Parallel: true})
+
+	assert.Equal(t, 1, ui.table.GetRowCount())
+	ui.table.Select(0, 0)
+	ui.deleteSelected(ActionShred)
+
+	<-ui.done
+
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+
+	assert.NoDirExists(t, "test_dir/nested")
+	assert.Equal(t, 0, ui.table.GetRowCount())
+}
+
+func TestShredCopyOnWriteError(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, true, true, false)
+	ui.done = make(chan struct{})
+	ui.shredder = func(dir, item fs.Item) error {
+		return remove.ErrCopyOnWrite
+	}
+
+	ui.table.Select(0, 0)
+	ui.deleteSelected(ActionShred)
+
+	<-ui.done
+
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+
+	assert.True(t, ui.pages.HasPage("error"))
+	assert.DirExists(t, "test_dir/nested")
+}
+
 func TestDeleteSelectedInBackgroundAndParallel(t *testing.T) {
 	fin := testdir.CreateTestDir()
 	defer fin()
@@ -1217,3 +1345,41 @@ func TestConfirmDeletionMarkedCase1(t *testing.T) {
 
 	assert.NoDirExists(t, "test_dir/nested/subnested")
 }
+
+func TestRescanRootOfMultiPathScan(t *testing.T) {
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	first := t.TempDir()
+	second := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(first, "file"), []byte("x"), 0o600))
+	assert.Nil(t, os.WriteFile(filepath.Join(second, "file"), []byte("hello"), 0o600))
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, false, true, false, false)
+	analyzer := analyze.CreateMultiPathAnalyzer([]string{first, second}, func() common.Analyzer {
+		return analyze.CreateAnalyzer()
+	})
+	ui.SetAnalyzer(analyzer)
+
+	ui.done = make(chan struct{})
+	assert.Nil(t, ui.AnalyzePath(analyzer.RootPath(), nil))
+	<-ui.done
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+	assert.Equal(t, "2 paths", ui.currentDir.GetName())
+	assert.Equal(t, int64(6), ui.topDir.GetSize())
+
+	assert.Nil(t, os.WriteFile(filepath.Join(second, "file2"), []byte("go"), 0o600))
+
+	files := slices.Collect(ui.topDir.GetFiles(fs.SortBySize, fs.SortDesc))
+	ui.currentDir = files[0]
+	ui.currentDirPath = second
+	ui.rescanDir()
+	<-ui.done
+	ui.topDir.UpdateStats(make(fs.HardLinkedItems))
+
+	assert.Equal(t, 2, len(slices.Collect(ui.topDir.GetFiles(fs.SortBySize, fs.SortDesc))))
+	assert.Equal(t, int64(8), ui.topDir.GetSize())
+}
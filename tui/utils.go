@@ -31,6 +31,17 @@ func getDeviceUsagePart(item *device.Device, useOld bool) string {
 	return getUsageGraph(part)
 }
 
+func getDeviceInodesPart(item *device.Device, useOld bool) string {
+	var part int
+	if item.Inodes > 0 {
+		part = int(float64(item.GetUsedInodes()) / float64(item.Inodes) * 100.0)
+	}
+	if useOld {
+		return getUsageGraphOld(part)
+	}
+	return getUsageGraph(part)
+}
+
 func getUsageGraph(part int) string {
 	graph := " "
 	whole := part / 10
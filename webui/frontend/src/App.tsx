@@ -1,20 +1,39 @@
 import { useCallback, useEffect, useMemo, useState } from 'react';
-import type { Node, NodeResponse, SortKey, SortOrder, Status } from './types';
+import type { Metric, Node, NodeResponse, SortKey, SortOrder, Status } from './types';
 import { fetchNode, fetchStatus, subscribeStatus } from './api';
 import { colorMapFor, computeSlices } from './slices';
-import { formatSize } from './format';
+import { formatMetric } from './format';
 import { DonutChart } from './components/DonutChart';
 import { FileTable } from './components/FileTable';
 import { Breadcrumbs } from './components/Breadcrumbs';
 import { ProgressBar } from './components/ProgressBar';
 
+const METRIC_LABELS: Record<Metric, string> = {
+  usage: 'Disk usage',
+  apparent: 'Apparent size',
+  inodes: 'Inodes',
+};
+
+const NEXT_METRIC: Record<Metric, Metric> = {
+  usage: 'apparent',
+  apparent: 'inodes',
+  inodes: 'usage',
+};
+
+function defaultMetric(status: Status): Metric {
+  if (status.showInodes) {
+    return 'inodes';
+  }
+  return status.showApparentSize ? 'apparent' : 'usage';
+}
+
 export function App() {
   const [status, setStatus] = useState<Status | null>(null);
   const [currentPath, setCurrentPath] = useState<string | null>(null);
   const [nodeResp, setNodeResp] = useState<NodeResponse | null>(null);
   const [sort, setSort] = useState<SortKey>('size');
   const [order, setOrder] = useState<SortOrder>('desc');
-  const [apparent, setApparent] = useState<boolean | null>(null);
+  const [metric, setMetric] = useState<Metric | null>(null);
   const [hoveredPath, setHoveredPath] = useState<string | null>(null);
   const [loadError, setLoadError] = useState<string | null>(null);
 
@@ -26,10 +45,13 @@ export function App() {
 
   // Adopt the display default for size metric once, from the server flags.
   useEffect(() => {
-    if (status && apparent === null) {
-      setApparent(status.showApparentSize);
+    if (status && metric === null) {
+      setMetric(defaultMetric(status));
+      if (status.showInodes) {
+        setSort('itemCount');
+      }
     }
-  }, [status, apparent]);
+  }, [status, metric]);
 
   // Once a scan is done, default into the root directory.
   useEffect(() => {
@@ -61,19 +83,32 @@ export function App() {
     };
   }, [currentPath, sort, order, status?.state]);
 
-  const effectiveApparent = apparent ?? status?.showApparentSize ?? false;
+  const effectiveMetric = metric ?? (status ? defaultMetric(status) : 'usage');
   const useSIPrefix = status?.useSIPrefix ?? false;
   const children = nodeResp?.children ?? [];
 
   const { total } = useMemo(
-    () => computeSlices(children, effectiveApparent),
-    [children, effectiveApparent],
+    () => computeSlices(children, effectiveMetric),
+    [children, effectiveMetric],
   );
   const colorMap = useMemo(
-    () => colorMapFor(children, effectiveApparent),
-    [children, effectiveApparent],
+    () => colorMapFor(children, effectiveMetric),
+    [children, effectiveMetric],
   );
 
+  // Cycle disk usage -> apparent size -> inodes, ranking items by count in the inode mode.
+  const handleMetricToggle = useCallback(() => {
+    const next = NEXT_METRIC[effectiveMetric];
+    setMetric(next);
+    if (next === 'inodes') {
+      setSort('itemCount');
+      setOrder('desc');
+    } else if (sort === 'itemCount') {
+      setSort('size');
+      setOrder('desc');
+    }
+  }, [effectiveMetric, sort]);
+
   const handleSortChange = useCallback(
     (key: SortKey) => {
       if (key === sort) {
@@ -125,14 +160,14 @@ export function App() {
           )}
         </div>
         <div className="app-actions">
-          <span className="total">{formatSize(total, useSIPrefix)}</span>
+          <span className="total">{formatMetric(total, effectiveMetric, useSIPrefix)}</span>
           <button
             type="button"
             className="toggle"
-            onClick={() => setApparent((a) => !(a ?? status.showApparentSize))}
-            title="Toggle between disk usage and apparent size"
+            onClick={handleMetricToggle}
+            title="Toggle between disk usage, apparent size and inodes"
           >
-            {effectiveApparent ? 'Apparent size' : 'Disk usage'}
+            {METRIC_LABELS[effectiveMetric]}
           </button>
           {status.state === 'scanning' && <span className="scanning-badge">scanning…</span>}
         </div>
@@ -144,7 +179,7 @@ export function App() {
         <section className="chart-panel">
           <DonutChart
             children={children}
-            apparent={effectiveApparent}
+            metric={effectiveMetric}
             useSIPrefix={useSIPrefix}
             hoveredPath={hoveredPath}
             onHover={setHoveredPath}
@@ -155,7 +190,8 @@ export function App() {
           <FileTable
             children={children}
             colorMap={colorMap}
-            apparent={effectiveApparent}
+            metric={effectiveMetric}
+            inodeCapacity={status.inodeCapacity}
             useSIPrefix={useSIPrefix}
             total={total}
             sort={sort}
@@ -1,11 +1,11 @@
 import { useMemo } from 'react';
-import type { Node } from '../types';
+import type { Metric, Node } from '../types';
 import { computeSlices } from '../slices';
-import { formatSize, percent } from '../format';
+import { formatMetric, percent } from '../format';
 
 interface DonutChartProps {
   children: Node[];
-  apparent: boolean;
+  metric: Metric;
   useSIPrefix: boolean;
   hoveredPath: string | null;
   onHover: (path: string | null) => void;
@@ -20,21 +20,21 @@ const CIRCUMFERENCE = 2 * Math.PI * RADIUS;
 
 export function DonutChart({
   children,
-  apparent,
+  metric,
   useSIPrefix,
   hoveredPath,
   onHover,
   onSelect,
 }: DonutChartProps) {
   const { slices, total } = useMemo(
-    () => computeSlices(children, apparent),
-    [children, apparent],
+    () => computeSlices(children, metric),
+    [children, metric],
   );
 
   const hovered = slices.find((s) => s.node?.path === hoveredPath) ?? null;
-  const centerPrimary = hovered ? hovered.label : formatSize(total, useSIPrefix);
+  const centerPrimary = hovered ? hovered.label : formatMetric(total, metric, useSIPrefix);
   const centerSecondary = hovered
-    ? `${formatSize(hovered.value, useSIPrefix)} · ${percent(hovered.value, total).toFixed(1)}%`
+    ? `${formatMetric(hovered.value, metric, useSIPrefix)} · ${percent(hovered.value, total).toFixed(1)}%`
     : `${slices.length} item${slices.length === 1 ? '' : 's'}`;
 
   let offsetFraction = 0;
@@ -1,12 +1,13 @@
 import { useMemo } from 'react';
-import type { Node, SortKey, SortOrder } from '../types';
+import type { Metric, Node, SortKey, SortOrder } from '../types';
 import { metricValue } from '../slices';
-import { formatCount, formatMtime, formatSize, percent } from '../format';
+import { formatCount, formatMetric, formatMtime, percent } from '../format';
 
 interface FileTableProps {
   children: Node[];
   colorMap: Map<string, string>;
-  apparent: boolean;
+  metric: Metric;
+  inodeCapacity: number;
   useSIPrefix: boolean;
   total: number;
   sort: SortKey;
@@ -27,7 +28,8 @@ const COLUMNS: { key: SortKey; label: string; numeric: boolean }[] = [
 export function FileTable({
   children,
   colorMap,
-  apparent,
+  metric,
+  inodeCapacity,
   useSIPrefix,
   total,
   sort,
@@ -38,10 +40,19 @@ export function FileTable({
   onSelect,
 }: FileTableProps) {
   const maxValue = useMemo(
-    () => children.reduce((max, n) => Math.max(max, metricValue(n, apparent)), 0),
-    [children, apparent],
+    () => children.reduce((max, n) => Math.max(max, metricValue(n, metric)), 0),
+    [children, metric],
   );
 
+  // in the inode mode values are also shown relative to the inode capacity of the filesystem
+  const formatValue = (value: number): string => {
+    const formatted = formatMetric(value, metric, useSIPrefix);
+    if (metric === 'inodes' && inodeCapacity > 0) {
+      return `${formatted} (${percent(value, inodeCapacity).toFixed(1)}%)`;
+    }
+    return formatted;
+  };
+
   return (
     <table className="file-table">
       <thead>
@@ -62,7 +73,7 @@ export function FileTable({
       </thead>
       <tbody>
         {children.map((node) => {
-          const value = metricValue(node, apparent);
+          const value = metricValue(node, metric);
           const barWidth = maxValue > 0 ? (value / maxValue) * 100 : 0;
           const color = colorMap.get(node.path) ?? 'var(--other)';
           const isHovered = node.path === hoveredPath;
@@ -84,7 +95,7 @@ export function FileTable({
                 </span>
                 <span className="bar" style={{ width: `${barWidth}%`, backgroundColor: color }} />
               </td>
-              <td className="num">{formatSize(value, useSIPrefix)}</td>
+              <td className="num">{formatValue(value)}</td>
               <td className="num">{formatCount(node.itemCount)}</td>
               <td className="num muted">{formatMtime(node.mtime)}</td>
             </tr>
@@ -101,7 +112,7 @@ export function FileTable({
       <tfoot>
         <tr>
           <td className="muted">{children.length} items</td>
-          <td className="num">{formatSize(total, useSIPrefix)}</td>
+          <td className="num">{formatValue(total)}</td>
           <td className="num muted" colSpan={2}>
             {percent(total, total) > 0 ? '100%' : ''}
           </td>
@@ -14,6 +14,8 @@ export function ProgressBar({ progress, useSIPrefix }: ProgressBarProps) {
       <div className="scan-stats">
         <span>{formatCount(progress.itemCount)} items</span>
         <span>{formatSize(progress.totalUsage, useSIPrefix)}</span>
+        {progress.ioWorkers ? <span>{progress.ioWorkers} I/O workers</span> : null}
+        {progress.throttle ? <span>throttled: {progress.throttle}</span> : null}
       </div>
       <div className="scan-current" title={progress.currentItem}>
         {progress.currentItem}
@@ -1,5 +1,5 @@
 import { describe, expect, it } from 'vitest';
-import { formatSize, formatCount, percent } from './format';
+import { formatSize, formatCount, formatMetric, percent } from './format';
 
 describe('formatSize', () => {
   it('formats bytes with binary prefixes by default', () => {
@@ -27,6 +27,14 @@ describe('formatCount', () => {
   });
 });
 
+describe('formatMetric', () => {
+  it('formats inodes as a count and other metrics as a size', () => {
+    expect(formatMetric(1234, 'inodes')).toBe('1,234');
+    expect(formatMetric(1024, 'usage')).toBe('1.0 KiB');
+    expect(formatMetric(1000, 'apparent', true)).toBe('1.0 kB');
+  });
+});
+
 describe('percent', () => {
   it('computes a bounded percentage', () => {
     expect(percent(50, 200)).toBe(25);
@@ -1,6 +1,8 @@
 // Human-readable formatting that mirrors Gdu's own size formatting so the
 // numbers shown in the browser match the terminal UI.
 
+import type { Metric } from './types';
+
 const BINARY_PREFIXES = ['', 'Ki', 'Mi', 'Gi', 'Ti', 'Pi', 'Ei'];
 const SI_PREFIXES = ['', 'k', 'M', 'G', 'T', 'P', 'E'];
 
@@ -25,6 +27,14 @@ export function formatCount(n: number): string {
   return n.toLocaleString('en-US');
 }
 
+// formatMetric formats a value of the given metric: a count of inodes or a size.
+export function formatMetric(value: number, metric: Metric, useSIPrefix = false): string {
+  if (metric === 'inodes') {
+    return formatCount(value);
+  }
+  return formatSize(value, useSIPrefix);
+}
+
 export function formatMtime(unixSeconds: number): string {
   if (!unixSeconds) {
     return '';
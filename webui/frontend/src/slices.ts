@@ -1,4 +1,4 @@
-import type { Node } from './types';
+import type { Metric, Node } from './types';
 import { OTHER_COLOR, colorAt } from './colors';
 
 // Maximum number of individually colored slices/rows before the remainder is
@@ -6,9 +6,16 @@ import { OTHER_COLOR, colorAt } from './colors';
 export const MAX_SLICES = 11;
 
 // metricValue returns the number a node contributes to charts/bars: disk usage
-// by default, apparent size when the user toggles it.
-export function metricValue(node: Node, showApparent: boolean): number {
-  return Math.max(showApparent ? node.size : node.usage, 0);
+// by default, apparent size or number of inodes when the user toggles it.
+export function metricValue(node: Node, metric: Metric): number {
+  switch (metric) {
+    case 'apparent':
+      return Math.max(node.size, 0);
+    case 'inodes':
+      return Math.max(node.itemCount, 0);
+    default:
+      return Math.max(node.usage, 0);
+  }
 }
 
 export interface Slice {
@@ -21,11 +28,9 @@ export interface Slice {
 
 // colorMapFor assigns a stable color to each child by descending metric rank so
 // that donut slices and table rows always agree regardless of table sort order.
-export function colorMapFor(children: Node[], showApparent: boolean): Map<string, string> {
+export function colorMapFor(children: Node[], metric: Metric): Map<string, string> {
   const map = new Map<string, string>();
-  const ranked = [...children].sort(
-    (a, b) => metricValue(b, showApparent) - metricValue(a, showApparent),
-  );
+  const ranked = [...children].sort((a, b) => metricValue(b, metric) - metricValue(a, metric));
   ranked.forEach((child, index) => {
     map.set(child.path, index < MAX_SLICES ? colorAt(index) : OTHER_COLOR);
   });
@@ -37,10 +42,10 @@ export function colorMapFor(children: Node[], showApparent: boolean): Map<string
 // assigned by descending metric rank, matching colorMapFor.
 export function computeSlices(
   children: Node[],
-  showApparent: boolean,
+  metric: Metric,
 ): { slices: Slice[]; total: number } {
   const withValue = children
-    .map((n) => ({ node: n, value: metricValue(n, showApparent) }))
+    .map((n) => ({ node: n, value: metricValue(n, metric) }))
     .filter((s) => s.value > 0)
     .sort((a, b) => b.value - a.value);
 
@@ -21,6 +21,8 @@ export interface Progress {
   currentItem: string;
   itemCount: number;
   totalUsage: number;
+  ioWorkers?: number;
+  throttle?: string;
 }
 
 export type ScanState = 'scanning' | 'done' | 'error';
@@ -32,8 +34,14 @@ export interface Status {
   progress: Progress;
   showApparentSize: boolean;
   showRelativeSize: boolean;
+  showInodes: boolean;
+  inodeCapacity: number;
   useSIPrefix: boolean;
 }
 
+// Metric is the number compared by charts and bars: disk usage, apparent size
+// or the number of inodes (items) used.
+export type Metric = 'usage' | 'apparent' | 'inodes';
+
 export type SortKey = 'size' | 'name' | 'itemCount' | 'mtime';
 export type SortOrder = 'asc' | 'desc';
@@ -17,6 +17,8 @@ type statusResponse struct {
 	Progress         progressJSON `json:"progress"`
 	ShowApparentSize bool         `json:"showApparentSize"`
 	ShowRelativeSize bool         `json:"showRelativeSize"`
+	ShowInodes       bool         `json:"showInodes"`
+	InodeCapacity    int64        `json:"inodeCapacity"`
 	UseSIPrefix      bool         `json:"useSIPrefix"`
 }
 
@@ -24,6 +26,8 @@ type progressJSON struct {
 	CurrentItem string `json:"currentItem"`
 	ItemCount   int64  `json:"itemCount"`
 	TotalUsage  int64  `json:"totalUsage"`
+	IOWorkers   int    `json:"ioWorkers,omitempty"`
+	Throttle    string `json:"throttle,omitempty"`
 }
 
 type deviceJSON struct {
@@ -32,6 +36,8 @@ type deviceJSON struct {
 	Fstype     string `json:"fstype"`
 	Size       int64  `json:"size"`
 	Free       int64  `json:"free"`
+	Inodes     int64  `json:"inodes"`
+	FreeInodes int64  `json:"freeInodes"`
 }
 
 func (ui *UI) buildStatus() statusResponse {
@@ -55,9 +61,13 @@ func (ui *UI) buildStatus() statusResponse {
 			CurrentItem: ui.progress.CurrentItemName,
 			ItemCount:   ui.progress.ItemCount,
 			TotalUsage:  ui.progress.TotalUsage,
+			IOWorkers:   ui.progress.IOWorkers,
+			Throttle:    ui.progress.Throttle,
 		},
 		ShowApparentSize: ui.ShowApparentSize,
 		ShowRelativeSize: ui.ShowRelativeSize,
+		ShowInodes:       ui.ShowInodes,
+		InodeCapacity:    ui.InodeCapacity,
 		UseSIPrefix:      ui.UseSIPrefix,
 	}
 	if ui.scanErr != nil {
@@ -103,6 +113,8 @@ func (ui *UI) handleDevices(w http.ResponseWriter, _ *http.Request) {
 			Fstype:     d.Fstype,
 			Size:       d.Size,
 			Free:       d.Free,
+			Inodes:     d.Inodes,
+			FreeInodes: d.FreeInodes,
 		})
 	}
 	writeJSON(w, http.StatusOK, out)
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/dundee/gdu/v5/pkg/analyze"
 	"github.com/dundee/gdu/v5/pkg/fs"
 )
 
@@ -42,7 +43,7 @@ func toNodeJSON(it fs.Item) nodeJSON {
 		flag = string(f)
 	}
 	return nodeJSON{
-		Name:      it.GetName(),
+		Name:      analyze.DisplayName(it),
 		Path:      it.GetPath(),
 		IsDir:     it.IsDir(),
 		Size:      it.GetSize(),
@@ -77,15 +78,41 @@ func (ui *UI) findNode(path string) (fs.Item, error) {
 		return root, nil
 	}
 
-	rel, err := filepath.Rel(cleanRoot, cleanPath)
+	rel, ok := relativePath(cleanRoot, cleanPath)
+	if !ok {
+		return findInRoots(root, cleanRoot, cleanPath)
+	}
+	return descend(root, rel)
+}
+
+// findInRoots locates an item in the roots of a multi-path scan,
+// which are not located inside their synthetic parent.
+func findInRoots(root fs.Item, rootPath, path string) (fs.Item, error) {
+	for child := range root.GetFilesLocked(fs.SortByName, fs.SortAsc) {
+		childPath := filepath.Clean(child.GetPath())
+		if !child.IsDir() || filepath.Dir(childPath) == rootPath {
+			continue
+		}
+		if rel, ok := relativePath(childPath, path); ok {
+			return descend(child, rel)
+		}
+	}
+	return nil, errOutsideRoot
+}
+
+// relativePath returns path relative to the base, false is returned when the path is outside of it
+func relativePath(base, path string) (string, bool) {
+	rel, err := filepath.Rel(base, path)
 	if err != nil {
-		return nil, errOutsideRoot
+		return "", false
 	}
 	if rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
-		return nil, errOutsideRoot
+		return "", false
 	}
+	return rel, true
+}
 
-	current := root
+func descend(current fs.Item, rel string) (fs.Item, error) {
 	for _, segment := range strings.Split(rel, string(os.PathSeparator)) {
 		if segment == "" || segment == "." {
 			continue
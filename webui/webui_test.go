@@ -89,6 +89,16 @@ func TestStatusEndpoint(t *testing.T) {
 	}
 }
 
+func TestStatusInodes(t *testing.T) {
+	ui := newTestUI()
+	ui.SetShowInodes(1000)
+
+	status := ui.buildStatus()
+	if !status.ShowInodes || status.InodeCapacity != 1000 {
+		t.Errorf("unexpected inode status: %+v", status)
+	}
+}
+
 func TestNodesEndpoint(t *testing.T) {
 	ui := newTestUI()
 	root := makeTree(t)
@@ -184,6 +194,61 @@ func TestNodesPathTraversalRejected(t *testing.T) {
 	}
 }
 
+func TestNodesMultiplePaths(t *testing.T) {
+	ui := newTestUI()
+	first := makeTree(t)
+	second := makeTree(t)
+	analyzer := analyze.CreateMultiPathAnalyzer([]string{first, second}, func() common.Analyzer {
+		return analyze.CreateAnalyzer()
+	})
+	ui.SetAnalyzer(analyzer)
+	scan(t, ui, analyzer.RootPath())
+
+	srv := httptest.NewServer(ui.routes())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/v1/nodes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var node nodeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&node); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if node.Node.Name != "2 paths" {
+		t.Errorf("root name = %q, want 2 paths", node.Node.Name)
+	}
+	if len(node.Children) != 2 || (node.Children[0].Name != first && node.Children[0].Name != second) {
+		t.Errorf("unexpected children: %+v", node.Children)
+	}
+
+	sub := filepath.Join(second, "sub")
+	resp, err = http.Get(srv.URL + "/api/v1/nodes?path=" + url.QueryEscape(sub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&node); err != nil {
+		t.Fatal(err)
+	}
+	if node.Node.Path != sub {
+		t.Errorf("node path = %q, want %q", node.Node.Path, sub)
+	}
+	if len(node.Breadcrumbs) != 3 {
+		t.Errorf("breadcrumbs = %d, want 3 (synthetic root, root, sub)", len(node.Breadcrumbs))
+	}
+
+	resp2, err := http.Get(srv.URL + "/api/v1/nodes?path=" + url.QueryEscape("/etc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp2.Body.Close()
+	if resp2.StatusCode != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", resp2.StatusCode)
+	}
+}
+
 func TestNodesNotFound(t *testing.T) {
 	ui := newTestUI()
 	root := makeTree(t)
@@ -286,6 +351,8 @@ func TestListDevicesAndHandleDevices(t *testing.T) {
 			Fstype:     "ext4",
 			Size:       1000,
 			Free:       400,
+			Inodes:     100,
+			FreeInodes: 60,
 		},
 	}
 	getter := testdev.DevicesInfoGetterMock{Devices: devices}
@@ -315,7 +382,7 @@ func TestListDevicesAndHandleDevices(t *testing.T) {
 	}
 	d := out[0]
 	if d.Name != "/dev/sda1" || d.MountPoint != "/" || d.Fstype != "ext4" ||
-		d.Size != 1000 || d.Free != 400 {
+		d.Size != 1000 || d.Free != 400 || d.Inodes != 100 || d.FreeInodes != 60 {
 		t.Errorf("unexpected device payload: %+v", d)
 	}
 }
@@ -469,6 +536,8 @@ func TestStatusJSON(t *testing.T) {
 		CurrentItemName: "current",
 		ItemCount:       3,
 		TotalUsage:      100,
+		IOWorkers:       8,
+		Throttle:        "paused",
 	}
 	ui.mu.Unlock()
 
@@ -482,7 +551,8 @@ func TestStatusJSON(t *testing.T) {
 		t.Errorf("rootPath = %q, want /tmp/root", status.RootPath)
 	}
 	if status.Progress.ItemCount != 3 || status.Progress.TotalUsage != 100 ||
-		status.Progress.CurrentItem != "current" {
+		status.Progress.CurrentItem != "current" || status.Progress.IOWorkers != 8 ||
+		status.Progress.Throttle != "paused" {
 		t.Errorf("unexpected progress: %+v", status.Progress)
 	}
 }